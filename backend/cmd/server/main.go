@@ -76,7 +76,7 @@ func main() {
 
 	// Initialize database
 	logger.AppLogger.Info("Initializing database...")
-	if err := repository.InitDatabase(); err != nil {
+	if err := repository.InitDatabase(cfg); err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to initialize database")
 		println("[ERROR] Failed to initialize database:", err.Error())
 		logger.AppLogger.WithError(err).Fatal("Failed to initialize database")
@@ -137,7 +137,7 @@ const docTemplate = `{
         },
         "/api/phases/transition": {
             "post": {
-                "description": "Request a phase transition for a session",
+                "description": "Move a session to a phase as a manual override, which only requires the target phase to exist. A dry run returns the would-be result, including the current phase's missing fields, without updating the session or broadcasting.",
                 "consumes": [
                     "application/json"
                 ],
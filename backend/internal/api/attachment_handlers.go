@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// attachmentAllowed reports whether contentType is in the configured allow-list
+func attachmentAllowed(contentType string) bool {
+	for _, allowed := range strings.Split(Services.Config.AttachmentContentTypes, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentReference is the text the conversation (and so the coach) sees instead of the file
+func attachmentReference(attachment *repository.Attachment) string {
+	reference := "Shared a file: " + attachment.FileName
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		reference = "Shared an image: " + attachment.FileName
+	}
+	if attachment.Description != "" {
+		reference += " - " + attachment.Description
+	}
+	return reference
+}
+
+// UploadAttachmentHandler stores a file shared during a session, as shared by the
+// authenticated user's participant in it
+// @Summary Upload a session attachment
+// @Description Store a file (drawing, photo) for the session. The conversation gets a textual reference so the coach knows it was shared; the file itself never enters the prompt.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param file formData file true "Attachment file"
+// @Param description formData string false "What the file shows"
+// @Success 201 {object} repository.Attachment
+// @Failure 403 {object} map[string]string
+// @Router /api/sessions/{sessionId}/attachments [post]
+func UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if Services == nil || Services.AttachmentStore == nil {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, map[string]string{"error": "Attachment storage is not configured"})
+		return
+	}
+
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id", "organization_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	// The uploader is whoever is authenticated, never a form field
+	participant, err := requestParticipant(r, sessionID)
+	if err != nil {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "Not a participant in this session"})
+		return
+	}
+	uploadedBy, participantID := repository.ParticipantRoleClient, ""
+	if participant != nil {
+		uploadedBy, participantID = participant.Role, participant.PersonID
+	}
+
+	maxBytes := Services.Config.AttachmentMaxBytes
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20) // Headroom for the multipart envelope
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		render.Status(r, http.StatusRequestEntityTooLarge)
+		render.JSON(w, r, map[string]string{"error": fmt.Sprintf("Attachment must be multipart form data under %d bytes", maxBytes)})
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Missing file"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxBytes {
+		render.Status(r, http.StatusRequestEntityTooLarge)
+		render.JSON(w, r, map[string]string{"error": fmt.Sprintf("Attachment must be under %d bytes", maxBytes)})
+		return
+	}
+
+	// Trust the bytes, not the client's declared type
+	reader := bufio.NewReader(file)
+	sniff, _ := reader.Peek(512)
+	contentType := strings.SplitN(http.DetectContentType(sniff), ";", 2)[0]
+	if !attachmentAllowed(contentType) {
+		render.Status(r, http.StatusUnsupportedMediaType)
+		render.JSON(w, r, map[string]string{"error": fmt.Sprintf("Attachments of type %s are not allowed", contentType)})
+		return
+	}
+
+	attachment := &repository.Attachment{
+		ID:             uuid.New().String(), // Needed up front for the storage key
+		SessionID:      sessionID,
+		OrganizationID: session.OrganizationID,
+		FileName:       filepath.Base(header.Filename),
+		ContentType:    contentType,
+		SizeBytes:      header.Size,
+		Description:    strings.TrimSpace(r.FormValue("description")),
+		UploadedBy:     uploadedBy,
+		StorageBackend: Services.AttachmentStore.Backend(),
+	}
+	attachment.StorageKey = fmt.Sprintf("sessions/%s/%s%s", sessionID, attachment.ID, strings.ToLower(filepath.Ext(attachment.FileName)))
+
+	if err := Services.AttachmentStore.Put(r.Context(), attachment.StorageKey, contentType, reader); err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to store attachment")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to store attachment"})
+		return
+	}
+
+	// The conversation carries a textual reference so the coach knows what was shared
+	message := &repository.Message{
+		SessionID:     sessionID,
+		Role:          uploadedBy,
+		Content:       attachmentReference(attachment),
+		MessageType:   "attachment",
+		ParticipantID: participantID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	err = repository.Scoped(r.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return fmt.Errorf("failed to save attachment message: %w", err)
+		}
+		attachment.MessageID = message.ID
+		if err := tx.Create(attachment).Error; err != nil {
+			return fmt.Errorf("failed to save attachment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to save attachment")
+		// Don't leave a stored file no row points to
+		if deleteErr := Services.AttachmentStore.Delete(r.Context(), attachment.StorageKey); deleteErr != nil {
+			logger.AppLogger.WithError(deleteErr).WithField("storage_key", attachment.StorageKey).Warn("Failed to delete orphaned attachment")
+		}
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to save attachment"})
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id":    sessionID,
+		"attachment_id": attachment.ID,
+		"content_type":  contentType,
+		"size_bytes":    attachment.SizeBytes,
+	}).Info("📎 Attachment uploaded")
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:    shared.MessageTypeAttachment,
+		Message: convertMessage(message),
+		Metadata: map[string]interface{}{
+			"attachment": attachment,
+		},
+		Timestamp: time.Now(),
+	})
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, attachment)
+}
+
+// GetSessionAttachmentsHandler lists a session's attachments
+// @Summary List session attachments
+// @Tags attachments
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {array} repository.Attachment
+// @Router /api/sessions/{sessionId}/attachments [get]
+func GetSessionAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var attachments []repository.Attachment
+	if err := repository.Scoped(r.Context()).Where("session_id = ?", sessionID).Order("created_at").Find(&attachments).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to fetch attachments")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch attachments"})
+		return
+	}
+
+	render.JSON(w, r, attachments)
+}
+
+// GetAttachmentContentHandler streams an attachment's file
+// @Summary Download a session attachment
+// @Tags attachments
+// @Produce octet-stream
+// @Param sessionId path string true "Session ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Router /api/sessions/{sessionId}/attachments/{attachmentId} [get]
+func GetAttachmentContentHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+	attachmentID := chi.URLParam(r, "attachmentId")
+
+	var attachment repository.Attachment
+	if err := repository.Scoped(r.Context()).First(&attachment, "id = ? AND session_id = ?", attachmentID, sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Attachment not found"})
+		return
+	}
+
+	if Services == nil || Services.AttachmentStore == nil || Services.AttachmentStore.Backend() != attachment.StorageBackend {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, map[string]string{"error": "Attachment storage is not available"})
+		return
+	}
+
+	content, err := Services.AttachmentStore.Open(r.Context(), attachment.StorageKey)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("attachment_id", attachmentID).Error("Failed to open attachment")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to read attachment"})
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", attachment.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, content)
+}
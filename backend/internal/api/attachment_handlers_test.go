@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/services"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// uploadTestAttachment posts a text file, with the given extra form fields, to the session
+func uploadTestAttachment(t *testing.T, sessionID string, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	file, _ := form.CreateFormFile("file", "notes.txt")
+	file.Write([]byte("what the client drew in session"))
+	form.Close()
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{sessionId}/attachments", UploadAttachmentHandler)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/attachments", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// useTestAttachmentStore stores attachments under a temporary directory, which it returns
+func useTestAttachmentStore(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := services.NewLocalAttachmentStore(dir)
+	if err != nil {
+		t.Fatalf("failed to create attachment store: %v", err)
+	}
+	Services.AttachmentStore = store
+	Services.Config.AttachmentMaxBytes = 1 << 20
+	Services.Config.AttachmentContentTypes = "text/plain"
+	return dir
+}
+
+// storedFiles lists the files under dir
+func storedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+func TestUploadAttachmentIgnoresClaimedUploader(t *testing.T) {
+	db := newTestEnv(t)
+	if err := db.AutoMigrate(&repository.Attachment{}); err != nil {
+		t.Fatalf("failed to migrate attachments: %v", err)
+	}
+	dir := useTestAttachmentStore(t)
+	session := createTestSession(t, db, "intake")
+
+	rec := uploadTestAttachment(t, session.ID, map[string]string{"uploaded_by": "therapist"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var attachment repository.Attachment
+	if err := db.First(&attachment, "session_id = ?", session.ID).Error; err != nil {
+		t.Fatalf("attachment not saved: %v", err)
+	}
+	if attachment.UploadedBy != repository.ParticipantRoleClient {
+		t.Errorf("uploaded_by = %q, want the unbound uploader recorded as the client", attachment.UploadedBy)
+	}
+	var message repository.Message
+	if err := db.First(&message, "id = ?", attachment.MessageID).Error; err != nil {
+		t.Fatalf("attachment message not saved: %v", err)
+	}
+	if message.Role != repository.ParticipantRoleClient {
+		t.Errorf("message role = %q, want client", message.Role)
+	}
+	if files := storedFiles(t, dir); len(files) != 1 {
+		t.Errorf("stored %d files, want 1", len(files))
+	}
+}
+
+func TestUploadAttachmentDeletesFileWhenRowsFail(t *testing.T) {
+	// Without the attachments table the rows can't be created
+	db := newTestEnv(t)
+	dir := useTestAttachmentStore(t)
+	session := createTestSession(t, db, "intake")
+
+	rec := uploadTestAttachment(t, session.ID, nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500: %s", rec.Code, rec.Body.String())
+	}
+	if files := storedFiles(t, dir); len(files) != 0 {
+		t.Errorf("left orphaned files: %v", files)
+	}
+	var messages int64
+	db.Model(&repository.Message{}).Where("session_id = ?", session.ID).Count(&messages)
+	if messages != 0 {
+		t.Errorf("saved %d attachment messages, want the transaction rolled back", messages)
+	}
+}
@@ -0,0 +1,347 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BulkExtractFilters select the sessions a bulk extraction covers
+type BulkExtractFilters struct {
+	From     *time.Time `json:"from,omitempty"` // Sessions starting at or after
+	To       *time.Time `json:"to,omitempty"`   // Sessions starting before
+	ClientID string     `json:"client_id,omitempty"`
+	Status   string     `json:"status,omitempty"`
+}
+
+// BulkExtractRequest starts a bulk extraction, or resumes one by ID from where it stopped
+type BulkExtractRequest struct {
+	BulkExtractFilters
+	ResumeJobID string `json:"resume_job_id,omitempty"`
+}
+
+// runningExtractionJobs guards against resuming a job that is still running
+var (
+	runningExtractionMutex sync.Mutex
+	runningExtractionJobs  = make(map[string]bool)
+)
+
+// BulkExtractHandler re-runs intake extraction for many historical sessions
+// @Summary Bulk intake re-extraction
+// @Description Extract uncollected intake fields from the transcripts of sessions matching the filters and re-score their intake, with bounded concurrency and rate limiting. Pass resume_job_id to continue a failed or interrupted job. Requires the admin role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body BulkExtractRequest true "Session filters or job to resume"
+// @Success 202 {object} repository.ExtractionJob
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/extract [post]
+func BulkExtractHandler(w http.ResponseWriter, r *http.Request) {
+	var req BulkExtractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	var job repository.ExtractionJob
+	if req.ResumeJobID != "" {
+		if err := repository.Scoped(r.Context()).First(&job, "id = ?", req.ResumeJobID).Error; err != nil {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "Extraction job not found"})
+			return
+		}
+		if job.Status == "completed" {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": "Extraction job already completed"})
+			return
+		}
+	} else {
+		filters, _ := json.Marshal(req.BulkExtractFilters)
+		job = repository.ExtractionJob{
+			ID:             uuid.New().String(),
+			OrganizationID: repository.OrganizationFromContext(r.Context()),
+			Filters:        string(filters),
+			Status:         "pending",
+		}
+		if err := repository.Scoped(r.Context()).Create(&job).Error; err != nil {
+			logger.AppLogger.WithError(err).Error("Failed to create extraction job")
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to create extraction job"})
+			return
+		}
+	}
+
+	runningExtractionMutex.Lock()
+	if runningExtractionJobs[job.ID] {
+		runningExtractionMutex.Unlock()
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Extraction job is already running"})
+		return
+	}
+	runningExtractionJobs[job.ID] = true
+	runningExtractionMutex.Unlock()
+
+	go runExtractionJob(job.ID)
+
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, job)
+}
+
+// GetExtractionJobHandler reports a bulk extraction's progress
+// @Summary Get bulk extraction progress
+// @Tags admin
+// @Produce json
+// @Param jobId path string true "Extraction job ID"
+// @Success 200 {object} repository.ExtractionJob
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/extract/{jobId} [get]
+func GetExtractionJobHandler(w http.ResponseWriter, r *http.Request) {
+	var job repository.ExtractionJob
+	if err := repository.Scoped(r.Context()).First(&job, "id = ?", chi.URLParam(r, "jobId")).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Extraction job not found"})
+		return
+	}
+	render.JSON(w, r, job)
+}
+
+// bulkExtractLimits returns the worker count and sessions started per second
+func bulkExtractLimits() (int, float64) {
+	concurrency, rate := 4, 2.0
+	if Services != nil && Services.Config != nil {
+		if Services.Config.BulkExtractConcurrency > 0 {
+			concurrency = Services.Config.BulkExtractConcurrency
+		}
+		if Services.Config.BulkExtractRatePerSecond > 0 {
+			rate = Services.Config.BulkExtractRatePerSecond
+		}
+	}
+	return concurrency, rate
+}
+
+// extractionContext returns a context scoped to the job's organization
+func extractionContext(job *repository.ExtractionJob) context.Context {
+	if job.OrganizationID == "" {
+		return repository.WithSystemScope(context.Background())
+	}
+	return repository.WithOrganization(context.Background(), job.OrganizationID)
+}
+
+// runExtractionJob processes the job's remaining sessions in ID order, one batch of
+// `concurrency` sessions at a time. The cursor advances only after a whole batch is done,
+// so a resumed job never skips a session (it may redo at most one batch).
+func runExtractionJob(jobID string) {
+	defer func() {
+		runningExtractionMutex.Lock()
+		delete(runningExtractionJobs, jobID)
+		runningExtractionMutex.Unlock()
+	}()
+
+	var job repository.ExtractionJob
+	if err := repository.DB.First(&job, "id = ?", jobID).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("job_id", jobID).Error("Failed to load extraction job")
+		return
+	}
+	fail := func(err error) {
+		logger.AppLogger.WithError(err).WithField("job_id", jobID).Error("❌ Bulk extraction failed")
+		repository.DB.Model(&job).Updates(map[string]interface{}{"status": "failed", "error": err.Error()})
+	}
+
+	var filters BulkExtractFilters
+	if err := json.Unmarshal([]byte(job.Filters), &filters); err != nil {
+		fail(err)
+		return
+	}
+
+	ctx := extractionContext(&job)
+	db := repository.Scoped(ctx)
+	matching := func() *gorm.DB {
+		q := db.Model(&repository.Session{})
+		if filters.From != nil {
+			q = q.Where("start_time >= ?", *filters.From)
+		}
+		if filters.To != nil {
+			q = q.Where("start_time < ?", *filters.To)
+		}
+		if filters.ClientID != "" {
+			q = q.Where("client_id = ?", filters.ClientID)
+		}
+		if filters.Status != "" {
+			q = q.Where("status = ?", filters.Status)
+		}
+		return q
+	}
+
+	var total int64
+	if err := matching().Count(&total).Error; err != nil {
+		fail(err)
+		return
+	}
+	job.Total = int(total)
+	job.Status = "running"
+	job.Error = ""
+	repository.DB.Model(&job).Updates(map[string]interface{}{"status": job.Status, "total": job.Total, "error": ""})
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"job_id": jobID,
+		"total":  job.Total,
+		"cursor": job.Cursor,
+	}).Info("🔁 Bulk extraction started")
+
+	concurrency, rate := bulkExtractLimits()
+	limiter := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer limiter.Stop()
+
+	for {
+		var batch []string
+		q := matching().Order("id ASC").Limit(concurrency)
+		if job.Cursor != "" {
+			q = q.Where("id > ?", job.Cursor)
+		}
+		if err := q.Pluck("id", &batch).Error; err != nil {
+			fail(err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		var wg sync.WaitGroup
+		var failedMutex sync.Mutex
+		failed := 0
+		for _, sessionID := range batch {
+			// Rate limit session starts to respect downstream quota
+			<-limiter.C
+			wg.Add(1)
+			go func(sessionID string) {
+				defer wg.Done()
+				if err := extractSession(ctx, sessionID); err != nil {
+					logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Bulk extraction failed for session")
+					failedMutex.Lock()
+					failed++
+					failedMutex.Unlock()
+				}
+			}(sessionID)
+		}
+		wg.Wait()
+
+		job.Cursor = batch[len(batch)-1]
+		job.Processed += len(batch)
+		job.Failed += failed
+		if err := repository.DB.Model(&job).Updates(map[string]interface{}{
+			"cursor":    job.Cursor,
+			"processed": job.Processed,
+			"failed":    job.Failed,
+		}).Error; err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	repository.DB.Model(&job).Update("status", "completed")
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"job_id":    jobID,
+		"processed": job.Processed,
+		"failed":    job.Failed,
+	}).Info("✅ Bulk extraction completed")
+}
+
+// extractSession runs intake extraction over one session's transcript, stores the fields it
+// finds that the session hasn't collected, and re-scores the session's intake
+func extractSession(ctx context.Context, sessionID string) error {
+	start := time.Now()
+	fieldsCount, completion, err := extractSessionFields(ctx, sessionID)
+	if err != nil {
+		UpdateIntakeExtractionMetrics("bulk", "error", time.Since(start), 0, 0)
+		return err
+	}
+	UpdateIntakeExtractionMetrics("bulk", "success", time.Since(start), fieldsCount, completion.Percentage)
+	UpdateIntakeMetrics(completion.Percentage)
+	return nil
+}
+
+// extractSessionFields stores the extracted values of the session's uncollected rubric
+// fields and returns how many it stored with the resulting completion. Fields already
+// collected are left alone, so extraction never overwrites the coach's or a clinician's value.
+func extractSessionFields(ctx context.Context, sessionID string) (int, *repository.IntakeCompletion, error) {
+	if !assistantAvailable() {
+		return 0, nil, errors.New("assistant unavailable")
+	}
+	db := repository.Scoped(ctx)
+
+	var session repository.Session
+	if err := db.Select("id", "phase").First(&session, "id = ?", sessionID).Error; err != nil {
+		return 0, nil, err
+	}
+	completion, err := repository.ComputeIntakeCompletion(db, sessionID)
+	if err != nil {
+		return 0, nil, err
+	}
+	var missing []repository.IntakeFieldCompletion
+	for _, field := range completion.Fields {
+		if !field.Collected {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, completion, nil
+	}
+
+	values, err := newIntakeExtractor().ExtractIntake(ctx, sessionID, missing)
+	if err != nil {
+		return 0, nil, err
+	}
+	stored := 0
+	for _, field := range missing {
+		value, ok := values[field.FieldName]
+		if !ok {
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		if err := repository.UpsertSessionFieldValue(db, &repository.SessionFieldValue{
+			SessionID:  sessionID,
+			PhaseID:    session.Phase,
+			FieldName:  field.FieldName,
+			FieldValue: string(encoded),
+			FieldType:  "string",
+			Source:     repository.FieldSourceExtraction,
+		}); err != nil {
+			return stored, nil, err
+		}
+		stored++
+	}
+	if stored == 0 {
+		return 0, completion, nil
+	}
+
+	completion, err = repository.ComputeIntakeCompletion(db, sessionID)
+	if err != nil {
+		return stored, nil, err
+	}
+	return stored, completion, nil
+}
+
+// markInterruptedExtractionJobs flags jobs left running by a previous process so they can
+// be resumed
+func markInterruptedExtractionJobs() {
+	result := repository.DB.Model(&repository.ExtractionJob{}).
+		Where("status IN ?", []string{"pending", "running"}).
+		Update("status", "interrupted")
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		logger.AppLogger.WithError(result.Error).Warn("Failed to mark interrupted extraction jobs")
+	} else if result.RowsAffected > 0 {
+		logger.AppLogger.WithField("jobs", result.RowsAffected).Info("⏸️ Marked interrupted bulk extraction jobs - resume with resume_job_id")
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// fakeExtractor answers every field it knows, recording which fields it was asked for
+type fakeExtractor struct {
+	values map[string]string
+	asked  []string
+}
+
+func (e *fakeExtractor) ExtractIntake(ctx context.Context, sessionID string, fields []repository.IntakeFieldCompletion) (map[string]string, error) {
+	for _, field := range fields {
+		e.asked = append(e.asked, field.FieldName)
+	}
+	return e.values, nil
+}
+
+func TestExtractSessionStoresOnlyUncollectedFields(t *testing.T) {
+	db := newTestEnv(t)
+	if err := db.AutoMigrate(&repository.IntakeRubricField{}); err != nil {
+		t.Fatalf("failed to migrate rubric: %v", err)
+	}
+	createTestPhases(t, db, "intake")
+	session := createTestSession(t, db, "intake")
+	for _, name := range []string{"presenting_problem", "target_memory", "support_system"} {
+		if err := db.Create(&repository.IntakeRubricField{FieldName: name, Label: name, Weight: 1}).Error; err != nil {
+			t.Fatalf("failed to create rubric field: %v", err)
+		}
+	}
+	if err := repository.UpsertSessionFieldValue(db, &repository.SessionFieldValue{
+		SessionID: session.ID, PhaseID: "intake", FieldName: "presenting_problem",
+		FieldValue: `"panic at work"`, FieldType: "string", Source: repository.FieldSourceManual,
+	}); err != nil {
+		t.Fatalf("failed to store field: %v", err)
+	}
+
+	extractor := &fakeExtractor{values: map[string]string{
+		"presenting_problem": "something else",
+		"target_memory":      "the car accident",
+	}}
+	previous := newIntakeExtractor
+	newIntakeExtractor = func() intakeExtractor { return extractor }
+	t.Cleanup(func() { newIntakeExtractor = previous })
+
+	if err := extractSession(repository.WithSystemScope(context.Background()), session.ID); err != nil {
+		t.Fatalf("extractSession: %v", err)
+	}
+
+	if len(extractor.asked) != 2 || extractor.asked[0] == "presenting_problem" || extractor.asked[1] == "presenting_problem" {
+		t.Errorf("extractor asked for %v, want only the uncollected fields", extractor.asked)
+	}
+
+	var values []repository.SessionFieldValue
+	db.Where("session_id = ?", session.ID).Order("field_name ASC").Find(&values)
+	if len(values) != 2 {
+		t.Fatalf("got %d stored fields, want 2", len(values))
+	}
+	if got := values[0]; got.FieldName != "presenting_problem" || got.FieldValue != `"panic at work"` || got.Source != repository.FieldSourceManual {
+		t.Errorf("collected field was changed: %+v", got)
+	}
+	if got := values[1]; got.FieldName != "target_memory" || got.FieldValue != `"the car accident"` ||
+		got.Source != repository.FieldSourceExtraction || got.PhaseID != "intake" {
+		t.Errorf("extracted field stored as %+v", got)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+// checkInPollInterval is how often a running timer checks whether a check-in is due
+const checkInPollInterval = 5 * time.Second
+
+// checkInState tracks how many check-ins have fired in the session's current phase
+type checkInState struct {
+	phase string
+	fired int
+}
+
+var (
+	checkInMutex  sync.Mutex
+	checkInStates = make(map[string]*checkInState)
+)
+
+// checkInsEnabled reports whether the timer schedules phase check-ins
+func checkInsEnabled() bool {
+	return Services == nil || Services.Config == nil || Services.Config.CheckInScheduling
+}
+
+// checkInIfDue prompts a check-in each time the phase's elapsed (unpaused) time passes a
+// multiple of its CheckInIntervalSeconds
+func checkInIfDue(sessionID string, phaseElapsed time.Duration) {
+	if !checkInsEnabled() {
+		return
+	}
+
+	var row struct {
+		Phase                  string
+		CheckInIntervalSeconds int
+	}
+	if err := repository.DB.Table("sessions").
+		Select("sessions.phase, phases.check_in_interval_seconds").
+		Joins("JOIN phases ON phases.id = sessions.phase").
+		Where("sessions.id = ?", sessionID).
+		Scan(&row).Error; err != nil || row.CheckInIntervalSeconds <= 0 {
+		return
+	}
+	due := int(phaseElapsed.Seconds()) / row.CheckInIntervalSeconds
+
+	checkInMutex.Lock()
+	state, tracked := checkInStates[sessionID]
+	if !tracked || state.phase != row.Phase {
+		// New phase (or restart): don't replay check-ins that were already due
+		checkInStates[sessionID] = &checkInState{phase: row.Phase, fired: due}
+		checkInMutex.Unlock()
+		return
+	}
+	if due <= state.fired {
+		checkInMutex.Unlock()
+		return
+	}
+	state.fired = due
+	checkInMutex.Unlock()
+
+	minutes := int(phaseElapsed.Minutes())
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id":       sessionID,
+		"phase":            row.Phase,
+		"interval_seconds": row.CheckInIntervalSeconds,
+		"elapsed_seconds":  int(phaseElapsed.Seconds()),
+	}).Info("⏰ Phase check-in due")
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:  shared.MessageTypePhaseTimerCheckin,
+		Phase: row.Phase,
+		Metadata: map[string]interface{}{
+			"elapsed_seconds":  int(phaseElapsed.Seconds()),
+			"interval_seconds": row.CheckInIntervalSeconds,
+			"check_in":         due,
+		},
+		Timestamp: time.Now(),
+	})
+
+	// Same system annotation as a client-triggered check-in
+	go handleSessionMessage(repository.WithSystemScope(context.Background()), sessionID, []byte(fmt.Sprintf(`{"type":"message","role":"system","content":"[%d minutes elapsed - trigger check-in]"}`, minutes)), true)
+}
+
+// clearCheckIns forgets a session's check-in progress when its timer stops
+func clearCheckIns(sessionID string) {
+	checkInMutex.Lock()
+	delete(checkInStates, sessionID)
+	checkInMutex.Unlock()
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/services"
+	"therapy-navigation-system/shared"
+)
+
+// fakeCoach replies with fixed text, streamed as two chunks when asked to stream
+type fakeCoach struct {
+	reply string
+}
+
+func (c fakeCoach) GenerateResponse(ctx context.Context, sessionID string, userMessage string, currentPhase string) (*services.CoachResponse, error) {
+	return &services.CoachResponse{Message: c.reply}, nil
+}
+
+func (c fakeCoach) GenerateResponseStream(ctx context.Context, sessionID string, userMessage string, currentPhase string, onText func(text string)) (*services.CoachResponse, error) {
+	half := len(c.reply) / 2
+	onText(c.reply[:half])
+	onText(c.reply[half:])
+	return &services.CoachResponse{Message: c.reply}, nil
+}
+
+func useFakeCoach(t *testing.T, reply string) {
+	t.Helper()
+	previous := newCoachResponder
+	newCoachResponder = func() coachResponder { return fakeCoach{reply: reply} }
+	t.Cleanup(func() { newCoachResponder = previous })
+}
+
+// coachMessages returns the conversational coach messages among the updates
+func coachMessages(updates []shared.TherapySessionUpdate) []*shared.Message {
+	var messages []*shared.Message
+	for _, update := range updates {
+		if update.Type == "message" && update.Message != nil && update.Message.Role == "coach" &&
+			update.Message.MessageType != "tool_call" {
+			messages = append(messages, update.Message)
+		}
+	}
+	return messages
+}
+
+func TestPatientTurnBroadcastsOneCoachMessage(t *testing.T) {
+	db := newTestEnv(t)
+	useFakeCoach(t, "How are you feeling right now?")
+	session := createTestSession(t, db, "pre_session")
+	socket := connectTestSocket(t, session.ID)
+
+	handlePatientMessage(context.Background(), session.ID, []byte(`{"type":"message","content":"Hi"}`))
+
+	messages := coachMessages(socket.drain(200 * time.Millisecond))
+	if len(messages) != 1 {
+		t.Fatalf("got %d coach messages for one turn, want 1", len(messages))
+	}
+
+	var stored repository.Message
+	if err := db.First(&stored, "session_id = ? AND role = ?", session.ID, "coach").Error; err != nil {
+		t.Fatalf("coach reply wasn't saved: %v", err)
+	}
+	if messages[0].ID != stored.ID {
+		t.Errorf("broadcast message ID %s doesn't match the saved message %s", messages[0].ID, stored.ID)
+	}
+}
+
+func TestStreamedTurnCompletesWithTheSavedMessage(t *testing.T) {
+	db := newTestEnv(t)
+	useFakeCoach(t, "Take a slow breath with me.")
+	session := createTestSession(t, db, "pre_session")
+	socket := connectTestSocket(t, session.ID)
+
+	handlePatientMessage(context.Background(), session.ID, []byte(`{"type":"message","content":"Hi","stream":true}`))
+
+	updates := socket.drain(200 * time.Millisecond)
+	if messages := coachMessages(updates); len(messages) != 0 {
+		t.Fatalf("got %d whole coach messages alongside the stream, want 0", len(messages))
+	}
+	var chunks int
+	for _, update := range updates {
+		if update.Type == shared.MessageTypeMessageChunk {
+			chunks++
+		}
+	}
+	if chunks != 2 {
+		t.Errorf("got %d chunks, want 2", chunks)
+	}
+
+	var count int64
+	db.Model(&repository.Message{}).Where("session_id = ? AND role = ?", session.ID, "coach").Count(&count)
+	if count != 1 {
+		t.Errorf("saved %d coach messages, want 1", count)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+// coachStream relays a streamed coach reply to the session's clients as message_chunk
+// events, all keyed by the ID the assembled message is saved under
+type coachStream struct {
+	sessionID  string
+	phaseID    string
+	messageID  string
+	receivedAt time.Time // When the client's message arrived, for response pacing
+	chunks     int
+}
+
+// send broadcasts the next piece of reply text. The phase's response pacing holds the
+// first chunk, as it would hold a complete reply.
+func (s *coachStream) send(text string) {
+	if s.chunks == 0 {
+		holdCoachResponse(s.sessionID, s.phaseID, s.receivedAt)
+	}
+	broadcastSessionUpdate(s.sessionID, shared.TherapySessionUpdate{
+		Type:  shared.MessageTypeMessageChunk,
+		Phase: s.phaseID,
+		Metadata: map[string]interface{}{
+			"message_id": s.messageID,
+			"index":      s.chunks,
+			"delta":      text,
+		},
+		Timestamp: time.Now(),
+	})
+	s.chunks++
+}
+
+// complete ends the stream with the saved message (nil when the reply had no text). A
+// failed stream tells the client to discard the chunks it already shows.
+func (s *coachStream) complete(msg *repository.Message, failed bool) {
+	update := shared.TherapySessionUpdate{
+		Type:  shared.MessageTypeMessageComplete,
+		Phase: s.phaseID,
+		Metadata: map[string]interface{}{
+			"message_id": s.messageID,
+			"chunks":     s.chunks,
+			"failed":     failed,
+		},
+		Timestamp: time.Now(),
+	}
+	if msg != nil {
+		update.Message = convertMessage(msg)
+	}
+	broadcastSessionUpdate(s.sessionID, update)
+}
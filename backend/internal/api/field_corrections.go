@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+// handleFieldCorrection applies a clinician's correct_field message and reports the outcome
+// to the session as field_corrected. Only sockets bound to a clinician participant may
+// correct fields. The workflow_update (or transition) that follows a successful correction
+// is broadcast by the MCP server.
+func handleFieldCorrection(ctx context.Context, sessionID string, fieldName string, value interface{}) {
+	if mcpServer == nil {
+		logger.AppLogger.WithField("session_id", sessionID).Error("Field correction received before the MCP server was initialized")
+		return
+	}
+
+	var result map[string]interface{}
+	var err error
+	if participant := socketParticipant(ctx); participant == nil || !repository.ParticipantIsClinician(participant.Role) {
+		err = fmt.Errorf("only the session's clinicians can correct fields")
+	} else {
+		result, err = mcpServer.CorrectField(ctx, sessionID, fieldName, value)
+	}
+	if err != nil {
+		logger.AppLogger.WithError(err).WithFields(map[string]interface{}{
+			"session_id": sessionID,
+			"field":      fieldName,
+		}).Warn("Rejected field correction")
+		broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+			Type: shared.MessageTypeFieldCorrected,
+			Metadata: map[string]interface{}{
+				"success":    false,
+				"field_name": fieldName,
+				"error":      err.Error(),
+			},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:      shared.MessageTypeFieldCorrected,
+		Metadata:  result,
+		Timestamp: time.Now(),
+	})
+}
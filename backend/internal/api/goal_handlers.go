@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetClientGoalsHandler returns a client's goals across all their sessions
+// @Summary Get client goals
+// @Description Desired outcomes the client has stated, with progress from their latest completed session
+// @Tags goals
+// @Produce json
+// @Param clientId path string true "Client ID"
+// @Success 200 {array} repository.SessionGoal
+// @Router /api/clients/{clientId}/goals [get]
+func GetClientGoalsHandler(w http.ResponseWriter, r *http.Request) {
+	clientID := chi.URLParam(r, "clientId")
+	db := repository.Scoped(r.Context())
+
+	if err := db.Select("id").First(&repository.Client{}, "id = ?", clientID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Client not found"})
+		return
+	}
+
+	var goals []repository.SessionGoal
+	if err := db.Where("client_id = ?", clientID).Order("created_at DESC").Find(&goals).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("client_id", clientID).Error("Failed to fetch client goals")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch goals"})
+		return
+	}
+
+	render.JSON(w, r, goals)
+}
+
+// GetSessionGoalsHandler returns the goals stated in a session
+// @Summary Get session goals
+// @Tags goals
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {array} repository.SessionGoal
+// @Router /api/sessions/{sessionId}/goals [get]
+func GetSessionGoalsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+	db := repository.Scoped(r.Context())
+
+	if err := db.Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	var goals []repository.SessionGoal
+	if err := db.Where("session_id = ?", sessionID).Order("created_at DESC").Find(&goals).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to fetch session goals")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch goals"})
+		return
+	}
+
+	render.JSON(w, r, goals)
+}
@@ -10,6 +10,7 @@ import (
 	"therapy-navigation-system/internal/repository"
 
 	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
 )
 
 // Global build info (set by main.go)
@@ -21,6 +22,18 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 		"status":    "healthy",
 		"timestamp": time.Now(),
 		"database":  repository.DB != nil,
+		"assistant": assistantAvailable(),
+	}
+
+	// The server keeps running without the assistant, but clients get no replies. The endpoint
+	// is unauthenticated, so the init error (which can name projects or credential files) is
+	// only logged; it's also logged at startup.
+	if !assistantAvailable() {
+		health["status"] = "degraded"
+		health["assistant_init_failed"] = assistantInitError != nil
+		if assistantInitError != nil {
+			logger.AppLogger.WithError(assistantInitError).Debug("Health check: assistant failed to initialize")
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -43,7 +56,7 @@ func VersionHandler(w http.ResponseWriter, r *http.Request) {
 // GetTherapistsHandler returns all therapists
 func GetTherapistsHandler(w http.ResponseWriter, r *http.Request) {
 	var therapists []repository.Therapist
-	if err := repository.DB.Find(&therapists).Error; err != nil {
+	if err := repository.Scoped(r.Context()).Find(&therapists).Error; err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to fetch therapists")
 		http.Error(w, "Failed to fetch therapists", http.StatusInternalServerError)
 		return
@@ -56,7 +69,7 @@ func GetTherapistsHandler(w http.ResponseWriter, r *http.Request) {
 // GetClientsHandler returns all clients
 func GetClientsHandler(w http.ResponseWriter, r *http.Request) {
 	var clients []repository.Client
-	if err := repository.DB.Find(&clients).Error; err != nil {
+	if err := repository.Scoped(r.Context()).Find(&clients).Error; err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to fetch clients")
 		http.Error(w, "Failed to fetch clients", http.StatusInternalServerError)
 		return
@@ -69,7 +82,7 @@ func GetClientsHandler(w http.ResponseWriter, r *http.Request) {
 // GetSessionsHandler returns all sessions
 func GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
 	var sessions []repository.Session
-	if err := repository.DB.Preload("Client").Preload("Therapist").Find(&sessions).Error; err != nil {
+	if err := repository.Scoped(r.Context()).Preload("Client").Preload("Therapist").Find(&sessions).Error; err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to fetch sessions")
 		http.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
 		return
@@ -82,9 +95,11 @@ func GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
 // CreateSessionHandler creates a new therapy session
 func CreateSessionHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		ClientID    string `json:"client_id"`
-		TherapistID string `json:"therapist_id"`
-		StartTime   string `json:"start_time"`
+		ClientID    string   `json:"client_id"`
+		TherapistID string   `json:"therapist_id"`
+		StartTime   string   `json:"start_time"`
+		Features    []string `json:"features,omitempty"`   // Feature flags enabling experimental phases/prompts
+		CoachSeed   *int32   `json:"coach_seed,omitempty"` // Fixed seed for reproducible coach output (test sessions)
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -98,34 +113,76 @@ func CreateSessionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	features, err := repository.EncodeSessionFeatures(req.Features)
+	if err != nil {
+		http.Error(w, "Invalid features", http.StatusBadRequest)
+		return
+	}
+
+	// Client and therapist must belong to the caller's organization
+	db := repository.Scoped(r.Context())
+	if err := db.Select("id").First(&repository.Client{}, "id = ?", req.ClientID).Error; err != nil {
+		http.Error(w, "Client not found", http.StatusNotFound)
+		return
+	}
+	if err := db.Select("id").First(&repository.Therapist{}, "id = ?", req.TherapistID).Error; err != nil {
+		http.Error(w, "Therapist not found", http.StatusNotFound)
+		return
+	}
+
 	session := repository.Session{
 		ClientID:    req.ClientID,
 		TherapistID: req.TherapistID,
-		Status:      "scheduled",
+		Status:      repository.SessionStatusScheduled,
 		Phase:       "pre_session",
 		StartTime:   startTime,
+		Features:    features,
+		CoachSeed:   req.CoachSeed,
 	}
 
-	if err := repository.DB.Create(&session).Error; err != nil {
+	if err := createSession(db, &session); err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to create session")
 		http.Error(w, "Failed to create session", http.StatusInternalServerError)
 		return
 	}
 
 	// Load with relations
-	repository.DB.Preload("Client").Preload("Therapist").First(&session, "id = ?", session.ID)
+	db.Preload("Client").Preload("Therapist").First(&session, "id = ?", session.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(session)
 }
 
+// sessionAutoMode reports whether new sessions start in auto mode. Services is unset when
+// service initialization failed, so this falls back to the default rather than panicking.
+func sessionAutoMode() bool {
+	return Services == nil || Services.Config == nil || Services.Config.SessionAutoMode
+}
+
+// createSession stores a new session in the configured auto mode. It's applied once here so a
+// session's own setting, changed through therapy_session_enable_auto_mode, survives
+// reconnects. The column defaults to true, so a false setting is written after the insert.
+func createSession(db *gorm.DB, session *repository.Session) error {
+	autoMode := sessionAutoMode()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		session.AutoMode = autoMode
+		if autoMode {
+			return nil
+		}
+		return tx.Model(session).Update("auto_mode", false).Error
+	})
+}
+
 // GetSessionHandler returns a specific session
 func GetSessionHandler(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionId")
 
 	var session repository.Session
-	if err := repository.DB.Preload("Client").Preload("Therapist").First(&session, "id = ?", sessionID).Error; err != nil {
+	if err := repository.Scoped(r.Context()).Preload("Client").Preload("Therapist").First(&session, "id = ?", sessionID).Error; err != nil {
 		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
@@ -134,10 +191,77 @@ func GetSessionHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(session)
 }
 
+// GetPhaseHistoryHandler returns the parsed phase timeline for a session
+func GetPhaseHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id", "phase_history").First(&session, "id = ?", sessionID).Error; err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	history, err := repository.ParsePhaseHistory(session.PhaseHistory)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to parse phase history")
+		http.Error(w, "Failed to parse phase history", http.StatusInternalServerError)
+		return
+	}
+
+	pauses, err := repository.PauseHistory(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to load pause history")
+		http.Error(w, "Failed to load pause history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"session_id":    sessionID,
+		"phase_history": history,
+		"pause_history": pauses,
+	})
+}
+
+// GetSessionTimelineHandler returns how long each phase took, including the one in progress
+// @Summary Get session phase timeline
+// @Description Completed phases with entry/exit times, duration and message count, the current in-progress phase, and any phases entered more than once
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} repository.PhaseTimeline
+// @Failure 404 {string} string
+// @Router /api/sessions/{sessionId}/timeline [get]
+func GetSessionTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	timeline, err := repository.SessionTimeline(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to build phase timeline")
+		http.Error(w, "Failed to build phase timeline", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(timeline)
+}
+
 // GetMessagesHandler returns messages for a session
 func GetMessagesHandler(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionId")
 
+	// Messages are scoped through their session
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id").First(&session, "id = ?", sessionID).Error; err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
 	var messages []repository.Message
 	if err := repository.DB.Where("session_id = ?", sessionID).Order("created_at ASC").Find(&messages).Error; err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to fetch messages")
@@ -162,6 +286,13 @@ func CreateMessageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Messages are scoped through their session
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id").First(&session, "id = ?", req.SessionID).Error; err != nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
 	message := repository.Message{
 		ID:        fmt.Sprintf("msg_%d", time.Now().UnixNano()),
 		SessionID: req.SessionID,
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+func TestCreateSessionAppliesTheConfiguredAutoMode(t *testing.T) {
+	db := newTestEnv(t)
+	therapist := repository.Therapist{Name: "Dr. Rivera", Email: "rivera@example.com"}
+	client := repository.Client{Name: "Ana"}
+	for _, row := range []interface{}{&therapist, &client} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", row, err)
+		}
+	}
+
+	for _, autoMode := range []bool{false, true} {
+		Services.Config.SessionAutoMode = autoMode
+		body := `{"client_id":"` + client.ID + `","therapist_id":"` + therapist.ID + `","start_time":"` + time.Now().Format(time.RFC3339) + `"}`
+		rec := httptest.NewRecorder()
+		CreateSessionHandler(rec, httptest.NewRequest(http.MethodPost, "/api/sessions", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create session: status = %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var created repository.Session
+		json.Unmarshal(rec.Body.Bytes(), &created)
+		var stored repository.Session
+		if err := db.First(&stored, "id = ?", created.ID).Error; err != nil {
+			t.Fatalf("session not stored: %v", err)
+		}
+		if stored.AutoMode != autoMode || created.AutoMode != autoMode {
+			t.Errorf("SESSION_AUTO_MODE=%t: stored auto_mode %t, responded %t", autoMode, stored.AutoMode, created.AutoMode)
+		}
+	}
+}
+
+func TestHealthDoesNotExposeTheAssistantInitError(t *testing.T) {
+	newTestEnv(t)
+	previousServices, previousErr := Services, assistantInitError
+	Services = nil
+	assistantInitError = errors.New("credentials file /secrets/acme-prod-sa.json not found")
+	t.Cleanup(func() { Services, assistantInitError = previousServices, previousErr })
+
+	rec := httptest.NewRecorder()
+	HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if strings.Contains(rec.Body.String(), "acme-prod-sa") {
+		t.Errorf("health response leaks the init error: %s", rec.Body.String())
+	}
+	var health map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &health)
+	if health["status"] != "degraded" || health["assistant"] != false || health["assistant_init_failed"] != true {
+		t.Errorf("health = %v, want degraded with assistant_init_failed", health)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+// initialState is everything a connecting client needs to render the session
+type initialState struct {
+	Session         repository.Session
+	CurrentPhase    repository.Phase
+	CurrentFields   []repository.PhaseData
+	Messages        []repository.Message
+	PhaseDataValues map[string]interface{}
+	Phases          []shared.Phase
+}
+
+// loadInitialState loads the connect-time state in one pass: the session first, scoped to
+// ctx's organization, then messages, stored values and phases - concurrently unless disabled
+// by config. Phase data is preloaded with the phases rather than queried per phase.
+func loadInitialState(ctx context.Context, sessionID string) (*initialState, error) {
+	started := time.Now()
+
+	initial := &initialState{}
+	if err := repository.Scoped(ctx).First(&initial.Session, "id = ?", sessionID).Error; err != nil {
+		return nil, err
+	}
+
+	var (
+		storedValues []repository.SessionFieldValue
+		allPhases    []repository.Phase
+	)
+	queries := []func(){
+		func() {
+			// All messages for the session (enterprise chatbot experience)
+			if err := repository.DB.Where("session_id = ?", sessionID).Order("created_at DESC").Find(&initial.Messages).Error; err != nil {
+				logger.AppLogger.WithError(err).Error("Failed to get messages")
+			}
+		},
+		func() {
+			if err := repository.DB.Where("session_id = ?", sessionID).Find(&storedValues).Error; err != nil {
+				logger.AppLogger.WithError(err).Error("Failed to get stored field values")
+			}
+		},
+		func() {
+			if err := repository.DB.Preload("PhaseData").Order("position ASC").Find(&allPhases).Error; err != nil {
+				logger.AppLogger.WithError(err).Error("Failed to get all phases")
+			}
+		},
+	}
+	if Services != nil && Services.Config != nil && !Services.Config.InitialStateConcurrent {
+		for _, query := range queries {
+			query()
+		}
+	} else {
+		var wg sync.WaitGroup
+		for _, query := range queries {
+			wg.Add(1)
+			go func(query func()) {
+				defer wg.Done()
+				query()
+			}(query)
+		}
+		wg.Wait()
+	}
+
+	initial.Phases = convertPhases(allPhases)
+	for _, phase := range allPhases {
+		if phase.ID == initial.Session.Phase {
+			initial.CurrentPhase = phase
+			initial.CurrentFields = phase.PhaseData
+		}
+	}
+	if initial.CurrentPhase.ID == "" {
+		logger.AppLogger.WithField("phase", initial.Session.Phase).Error("Failed to get current phase")
+	}
+
+	// Map ALL stored values, not just current phase
+	var parseErrs []error
+	initial.PhaseDataValues, parseErrs = repository.ParsedFieldValues(storedValues)
+	for _, err := range parseErrs {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to parse stored field value")
+	}
+	// Also include null for current phase fields that don't have values yet
+	for _, pd := range initial.CurrentFields {
+		if _, exists := initial.PhaseDataValues[pd.Name]; !exists {
+			initial.PhaseDataValues[pd.Name] = nil
+		}
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"load_ms":    time.Since(started).Milliseconds(),
+		"phases":     len(allPhases),
+		"messages":   len(initial.Messages),
+	}).Info("📊 Loaded initial session state")
+
+	return initial, nil
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// countTableQueries counts the SELECTs run against table from here on
+func countTableQueries(t *testing.T, db *gorm.DB, table string) *int64 {
+	t.Helper()
+	var count int64
+	name := "test:count_" + table
+	if err := db.Callback().Query().After("gorm:query").Register(name, func(tx *gorm.DB) {
+		if tx.Statement.Table == table {
+			atomic.AddInt64(&count, 1)
+		}
+	}); err != nil {
+		t.Fatalf("failed to register query counter: %v", err)
+	}
+	t.Cleanup(func() { db.Callback().Query().Remove(name) })
+	return &count
+}
+
+// seedPhasesWithData stores n phases with two fields each
+func seedPhasesWithData(t *testing.T, db *gorm.DB, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		phaseID := fmt.Sprintf("phase_%d", i)
+		if err := db.Create(&repository.Phase{ID: phaseID, DisplayName: phaseID, Position: i}).Error; err != nil {
+			t.Fatalf("failed to create phase: %v", err)
+		}
+		for _, name := range []string{"first", "second"} {
+			if err := db.Create(&repository.PhaseData{
+				ID: phaseID + "_" + name, PhaseID: phaseID, Name: name, Requirement: repository.PhaseDataRequired,
+			}).Error; err != nil {
+				t.Fatalf("failed to create phase data: %v", err)
+			}
+		}
+	}
+}
+
+func TestLoadInitialStateQueriesPhaseDataOnce(t *testing.T) {
+	db := newTestEnv(t)
+	seedPhasesWithData(t, db, 6)
+	session := createTestSession(t, db, "phase_2")
+	queries := countTableQueries(t, db, "phase_data")
+
+	initial, err := loadInitialState(context.Background(), session.ID)
+	if err != nil {
+		t.Fatalf("loadInitialState: %v", err)
+	}
+
+	if got := atomic.LoadInt64(queries); got != 1 {
+		t.Errorf("phase_data queried %d times for 6 phases, want 1", got)
+	}
+	if len(initial.Phases) != 6 {
+		t.Fatalf("got %d phases, want 6", len(initial.Phases))
+	}
+	for _, phase := range initial.Phases {
+		if len(phase.PhaseData) != 2 {
+			t.Errorf("phase %s has %d fields, want 2", phase.ID, len(phase.PhaseData))
+		}
+	}
+	if len(initial.CurrentFields) != 2 {
+		t.Errorf("current phase has %d fields, want 2", len(initial.CurrentFields))
+	}
+}
+
+func TestWorkflowStatusQueriesPhaseDataOnce(t *testing.T) {
+	db := newTestEnv(t)
+	seedPhasesWithData(t, db, 6)
+	session := createTestSession(t, db, "phase_2")
+	socket := connectTestSocket(t, session.ID)
+	queries := countTableQueries(t, db, "phase_data")
+
+	handlePatientMessage(context.Background(), session.ID, []byte(`{"type":"get_workflow_status"}`))
+
+	if got := atomic.LoadInt64(queries); got != 1 {
+		t.Errorf("phase_data queried %d times for 6 phases, want 1", got)
+	}
+	updates := socket.drain(100 * time.Millisecond)
+	if len(updates) != 1 || len(updates[0].Phases) != 6 {
+		t.Fatalf("got %d updates, want one session_updated with 6 phases", len(updates))
+	}
+	for _, phase := range updates[0].Phases {
+		if len(phase.PhaseData) != 2 {
+			t.Errorf("phase %s has %d fields, want 2", phase.ID, len(phase.PhaseData))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"gorm.io/gorm"
+)
+
+// refreshIntakeMetrics recomputes a session's intake completion and publishes it
+func refreshIntakeMetrics(sessionID string) {
+	completion, err := repository.ComputeIntakeCompletion(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to compute intake completion")
+		return
+	}
+	UpdateIntakeMetrics(completion.Percentage)
+}
+
+// GetIntakeCompletionHandler returns the session's intake completion against the rubric
+// @Summary Get intake completion
+// @Description Weighted intake completeness for a session with per-field completion state
+// @Tags intake
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} repository.IntakeCompletion
+// @Router /api/sessions/{sessionId}/intake/completion [get]
+func GetIntakeCompletionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	completion, err := repository.ComputeIntakeCompletion(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to compute intake completion")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to compute intake completion"})
+		return
+	}
+
+	UpdateIntakeMetrics(completion.Percentage)
+	render.JSON(w, r, completion)
+}
+
+// GetIntakeRubricHandler returns the intake scoring rubric
+// @Summary Get intake rubric
+// @Tags intake
+// @Produce json
+// @Success 200 {array} repository.IntakeRubricField
+// @Router /api/intake/rubric [get]
+func GetIntakeRubricHandler(w http.ResponseWriter, r *http.Request) {
+	var rubric []repository.IntakeRubricField
+	if err := repository.DB.Order("weight DESC, field_name ASC").Find(&rubric).Error; err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch intake rubric"})
+		return
+	}
+	render.JSON(w, r, rubric)
+}
+
+// UpdateIntakeRubricHandler replaces the intake scoring rubric. The rubric is global - it
+// scores every organization's sessions - so only admins may replace it.
+// @Summary Replace intake rubric
+// @Description Replace the weighted fields that define a complete intake, for every organization. Requires the admin role.
+// @Tags intake
+// @Accept json
+// @Produce json
+// @Param rubric body []repository.IntakeRubricField true "Rubric fields"
+// @Success 200 {array} repository.IntakeRubricField
+// @Failure 403 {object} map[string]string
+// @Router /api/intake/rubric [put]
+func UpdateIntakeRubricHandler(w http.ResponseWriter, r *http.Request) {
+	var rubric []repository.IntakeRubricField
+	if err := json.NewDecoder(r.Body).Decode(&rubric); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+	seen := make(map[string]bool, len(rubric))
+	for _, field := range rubric {
+		if field.FieldName == "" || field.Weight < 0 || seen[field.FieldName] {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Each rubric field needs a unique field_name and a non-negative weight"})
+			return
+		}
+		seen[field.FieldName] = true
+	}
+
+	err := repository.DB.Transaction(func(tx *gorm.DB) error {
+		// Replaces the whole (global) rubric
+		if err := tx.Where("1 = 1").Delete(&repository.IntakeRubricField{}).Error; err != nil {
+			return err
+		}
+		for i := range rubric {
+			rubric[i].CreatedAt = time.Now()
+			rubric[i].UpdatedAt = time.Now()
+			if err := tx.Create(&rubric[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to update intake rubric")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to update intake rubric"})
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"fields": len(rubric),
+		"email":  r.Context().Value("user_email"),
+	}).Info("Intake rubric updated")
+	render.JSON(w, r, rubric)
+}
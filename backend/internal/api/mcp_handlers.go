@@ -1,8 +1,12 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
 	"therapy-navigation-system/internal/mcp"
+	"therapy-navigation-system/internal/repository"
 
 	"github.com/sirupsen/logrus"
 )
@@ -20,6 +24,11 @@ func InitializeMCPServer(logger *logrus.Logger, broadcast func(event interface{}
 	logger.Info("🔧 Creating NewMCPServer...")
 	mcpServer = mcp.NewMCPServer(logger, broadcast)
 	logger.Info("✅ MCP server created successfully")
+
+	// Tools defined in the database extend (or redefine) the built-in ones
+	if err := mcpServer.LoadDatabaseTools(repository.DB); err != nil {
+		logger.WithError(err).Warn("⚠️ Failed to load database tools - continuing with built-in tools")
+	}
 	
 	// Create MCP transport
 	logger.Info("🔧 Creating MCP transport...")
@@ -46,17 +55,57 @@ func MCPHTTPHandler(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	
+
+	// Tools act on the session their arguments name; refuse sessions outside the caller's
+	// organization before dispatch. The handlers query through the request's scoped context.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if sessionID := mcpToolCallSession(body); sessionID != "" {
+		if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+	}
+
 	mcpTransport.ServeHTTP(w, r)
 }
 
-// MCPWebSocketHandler handles MCP requests over WebSocket
+// mcpToolCallSession returns the session_id argument of a tools/call request, if any
+func mcpToolCallSession(body []byte) string {
+	var req struct {
+		Method string `json:"method"`
+		Params struct {
+			Arguments struct {
+				SessionID string `json:"session_id"`
+			} `json:"arguments"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Method != "tools/call" {
+		return ""
+	}
+	return req.Params.Arguments.SessionID
+}
+
+// MCPWebSocketHandler handles MCP requests over WebSocket. The connection receives
+// notifications for its organization's sessions, or only for the session_id query
+// parameter's session when given.
 func MCPWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	if mcpTransport == nil {
 		http.Error(w, "MCP server not initialized", http.StatusServiceUnavailable)
 		return
 	}
-	
+
+	if sessionID := r.URL.Query().Get("session_id"); sessionID != "" {
+		if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+	}
+
 	mcpTransport.HandleWebSocket(w, r)
 }
 
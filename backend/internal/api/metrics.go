@@ -1,10 +1,13 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 	"strconv"
 	"time"
 
+	"therapy-navigation-system/internal/repository"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -45,6 +48,11 @@ var (
 		Help: "Gemini API request latencies in seconds",
 	}, []string{"agent_type"})
 
+	geminiRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gemini_retries_total",
+		Help: "Gemini API calls retried, by failure reason (status code or timeout); reason exhausted counts calls that failed after every attempt",
+	}, []string{"agent_type", "reason"})
+
 	// ChromaDB metrics
 	chromadbEmbeddingsTotal = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "chromadb_embeddings_total",
@@ -63,15 +71,17 @@ var (
 		Buckets: []float64{0.1, 0.5, 1.0, 2.0, 5.0, 10.0, 30.0, 60.0},
 	}, []string{"trigger"})
 
-	intakeFieldsExtracted = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	intakeFieldsExtracted = promauto.NewHistogram(prometheus.HistogramOpts{
 		Name: "intake_fields_extracted",
-		Help: "Number of intake fields extracted per session",
-	}, []string{"session_id"})
+		Help: "Number of intake fields stored by a session's extraction",
+		Buckets: []float64{0, 1, 2, 5, 10, 20},
+	})
 
-	intakeCompletionScore = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	intakeCompletionScore = promauto.NewHistogram(prometheus.HistogramOpts{
 		Name: "intake_completion_score",
-		Help: "Intake completion percentage per session",
-	}, []string{"session_id"})
+		Help: "Intake completion percentage after a session's extraction",
+		Buckets: []float64{10, 25, 50, 75, 90, 100},
+	})
 
 	// Knowledge graph metrics
 	knowledgeGraphEntities = promauto.NewGaugeVec(prometheus.GaugeOpts{
@@ -104,10 +114,11 @@ var (
 	})
 
 	// Intake metrics
-	intakeCompletionPercentage = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	intakeCompletionPercentage = promauto.NewHistogram(prometheus.HistogramOpts{
 		Name: "intake_completion_percentage",
-		Help: "Percentage of intake fields completed per session",
-	}, []string{"session_id"})
+		Help: "Percentage of intake fields completed when a session's intake is scored",
+		Buckets: []float64{10, 25, 50, 75, 90, 100},
+	})
 
 	// Prompt metrics
 	promptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
@@ -137,11 +148,41 @@ var (
 		Help: "Number of successful prompt executions",
 	}, []string{"prompt_name", "status"})
 
+	toolCallsCapped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tool_calls_capped_total",
+		Help: "Number of model turns whose tool calls exceeded the per-turn cap",
+	}, []string{"phase"})
+
+	toolCallsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tool_calls_dropped_total",
+		Help: "Number of tool calls dropped because of the per-turn cap",
+	}, []string{"phase", "tool_name"})
+
 	// Database metrics
 	databaseTableRows = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "database_table_rows",
 		Help: "Number of rows in database tables",
 	}, []string{"table"})
+
+	databasePoolConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "database_pool_connections",
+		Help: "Database connection pool usage by state (open, in_use, idle, max_open)",
+	}, []string{"state"})
+
+	databasePoolWaitCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "database_pool_wait_count",
+		Help: "Total number of connections waited for because the pool was saturated",
+	})
+
+	databasePoolWaitSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "database_pool_wait_seconds",
+		Help: "Total time blocked waiting for a pooled connection in seconds",
+	})
+
+	databasePoolSaturation = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "database_pool_saturation_ratio",
+		Help: "Ratio of in-use connections to the maximum open connections",
+	})
 )
 
 // PrometheusMiddleware tracks HTTP metrics
@@ -188,11 +229,24 @@ func UpdateSessionMetrics(action string) {
 	case "created":
 		sessionsTotal.Inc()
 		sessionsActive.Inc()
+	case "activated":
+		sessionsActive.Inc()
 	case "ended":
 		sessionsActive.Dec()
 	}
 }
 
+// RecordSessionStatusChange keeps the active-session gauge in step with status changes:
+// entering active counts the session, leaving it (completed or cancelled) uncounts it
+func RecordSessionStatusChange(from string, to string) {
+	switch {
+	case to == repository.SessionStatusActive:
+		UpdateSessionMetrics("activated")
+	case from == repository.SessionStatusActive:
+		UpdateSessionMetrics("ended")
+	}
+}
+
 // InitializeSessionCounter sets the session counter to the current total from database
 func InitializeSessionCounter(currentTotal int) {
 	// Add the current total to the counter (since counters start at 0)
@@ -206,19 +260,24 @@ func UpdateGeminiMetrics(agentType string, tokens int, duration time.Duration) {
 	promptsTotal.WithLabelValues(agentType).Inc()
 }
 
+// RecordGeminiRetry counts a retried Gemini call, or one that failed after every attempt
+func RecordGeminiRetry(agentType string, reason string) {
+	geminiRetriesTotal.WithLabelValues(agentType, reason).Inc()
+}
+
 // UpdateChromaDBMetrics updates ChromaDB metrics
 func UpdateChromaDBMetrics() {
 	chromadbEmbeddingsTotal.Inc()
 }
 
 // UpdateIntakeExtractionMetrics updates intake extraction metrics
-func UpdateIntakeExtractionMetrics(trigger string, status string, duration time.Duration, sessionID string, fieldsCount int, completionScore float64) {
+func UpdateIntakeExtractionMetrics(trigger string, status string, duration time.Duration, fieldsCount int, completionScore float64) {
 	intakeExtractionTotal.WithLabelValues(trigger, status).Inc()
 	intakeExtractionDuration.WithLabelValues(trigger).Observe(duration.Seconds())
 	
 	if status == "success" {
-		intakeFieldsExtracted.WithLabelValues(sessionID).Set(float64(fieldsCount))
-		intakeCompletionScore.WithLabelValues(sessionID).Set(completionScore)
+		intakeFieldsExtracted.Observe(float64(fieldsCount))
+		intakeCompletionScore.Observe(completionScore)
 	}
 }
 
@@ -240,8 +299,8 @@ func UpdateMessageProcessingMetrics(role string, stage string, processingType st
 }
 
 // UpdateIntakeMetrics updates intake completion metrics
-func UpdateIntakeMetrics(sessionID string, percentage float64) {
-	intakeCompletionPercentage.WithLabelValues(sessionID).Set(percentage)
+func UpdateIntakeMetrics(percentage float64) {
+	intakeCompletionPercentage.Observe(percentage)
 }
 
 // UpdateDatabaseMetrics updates database table row counts
@@ -249,6 +308,38 @@ func UpdateDatabaseMetrics(table string, count int) {
 	databaseTableRows.WithLabelValues(table).Set(float64(count))
 }
 
+// RecordToolCallCap records a turn that hit the tool-call cap and the calls it dropped
+func RecordToolCallCap(phase string, dropped []string) {
+	toolCallsCapped.WithLabelValues(phase).Inc()
+	for _, toolName := range dropped {
+		toolCallsDropped.WithLabelValues(phase, toolName).Inc()
+	}
+}
+
+// UpdateDatabasePoolMetrics records connection pool usage and saturation
+func UpdateDatabasePoolMetrics(stats sql.DBStats) {
+	databasePoolConnections.WithLabelValues("open").Set(float64(stats.OpenConnections))
+	databasePoolConnections.WithLabelValues("in_use").Set(float64(stats.InUse))
+	databasePoolConnections.WithLabelValues("idle").Set(float64(stats.Idle))
+	databasePoolConnections.WithLabelValues("max_open").Set(float64(stats.MaxOpenConnections))
+	databasePoolWaitCount.Set(float64(stats.WaitCount))
+	databasePoolWaitSeconds.Set(stats.WaitDuration.Seconds())
+	if stats.MaxOpenConnections > 0 {
+		databasePoolSaturation.Set(float64(stats.InUse) / float64(stats.MaxOpenConnections))
+	}
+}
+
+// StartDatabasePoolMetrics periodically samples connection pool stats
+func StartDatabasePoolMetrics(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			UpdateDatabasePoolMetrics(db.Stats())
+		}
+	}()
+}
+
 // UpdateSessionActiveMetrics sets the active sessions count
 func UpdateSessionActiveMetrics(count int) {
 	sessionsActive.Set(float64(count))
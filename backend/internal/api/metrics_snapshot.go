@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+)
+
+// MetricsSnapshot is a Prometheus-independent view of the current metric values
+type MetricsSnapshot struct {
+	ActiveSessions int64                 `json:"active_sessions"`
+	TotalSessions  int64                 `json:"total_sessions"`
+	TokensByAgent  map[string]AgentStats `json:"tokens_by_agent"`
+	TableRows      map[string]int64      `json:"table_rows"`
+	GeneratedAt    time.Time             `json:"generated_at"`
+}
+
+// snapshotTables are the tables whose row counts are reported
+var snapshotTables = []string{
+	"clients",
+	"therapists",
+	"sessions",
+	"messages",
+	"session_field_values",
+	"phases",
+	"phase_data",
+	"prompts",
+	"tools",
+}
+
+var (
+	snapshotMutex  sync.Mutex
+	cachedSnapshot *MetricsSnapshot
+
+	// sessionCounterSeeded guards the one-time Add so the counter isn't seeded twice
+	sessionCounterSeeded sync.Once
+)
+
+// SeedSessionMetrics seeds the session counter and active gauge from the database so
+// they survive restarts. The counter is only seeded once per process.
+func SeedSessionMetrics() error {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var total, active int64
+	if err := db.Model(&repository.Session{}).Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count sessions: %w", err)
+	}
+	if err := db.Model(&repository.Session{}).Where("status = ?", "active").Count(&active).Error; err != nil {
+		return fmt.Errorf("failed to count active sessions: %w", err)
+	}
+
+	sessionCounterSeeded.Do(func() {
+		InitializeSessionCounter(int(total))
+	})
+	UpdateSessionActiveMetrics(int(active))
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"total_sessions":  total,
+		"active_sessions": active,
+	}).Info("📊 Seeded session metrics from database")
+	return nil
+}
+
+// CollectMetricsSnapshot queries the metric sources and refreshes the matching Prometheus gauges
+func CollectMetricsSnapshot() (*MetricsSnapshot, error) {
+	snapshot := &MetricsSnapshot{
+		TokensByAgent: make(map[string]AgentStats),
+		TableRows:     make(map[string]int64),
+		GeneratedAt:   time.Now(),
+	}
+
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	if err := db.Model(&repository.Session{}).Where("status = ?", "active").Count(&snapshot.ActiveSessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count active sessions: %w", err)
+	}
+	UpdateSessionActiveMetrics(int(snapshot.ActiveSessions))
+
+	if err := db.Model(&repository.Session{}).Count(&snapshot.TotalSessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	for _, table := range snapshotTables {
+		var count int64
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		snapshot.TableRows[table] = count
+		UpdateDatabaseMetrics(table, int(count))
+	}
+
+	// Prompt logging is optional, so the table may not exist
+	if db.Migrator().HasTable("prompt_logs") {
+		var usages []struct {
+			AgentType   string
+			PromptCount int
+			TotalTokens int
+		}
+		if err := db.Table("prompt_logs").
+			Select("agent_type, COUNT(*) as prompt_count, SUM(token_count) as total_tokens").
+			Group("agent_type").
+			Scan(&usages).Error; err != nil {
+			return nil, fmt.Errorf("failed to aggregate token usage: %w", err)
+		}
+		for _, usage := range usages {
+			avgTokens := 0
+			if usage.PromptCount > 0 {
+				avgTokens = usage.TotalTokens / usage.PromptCount
+			}
+			snapshot.TokensByAgent[usage.AgentType] = AgentStats{
+				PromptCount: usage.PromptCount,
+				TotalTokens: usage.TotalTokens,
+				AvgTokens:   avgTokens,
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+// GetMetricsSnapshotHandler returns the current metrics as plain JSON, cached for the configured TTL
+func GetMetricsSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ttl := 30 * time.Second
+	if Services != nil && Services.Config != nil {
+		ttl = Services.Config.MetricsSnapshotTTL
+	}
+
+	snapshotMutex.Lock()
+	snapshot := cachedSnapshot
+	if snapshot == nil || time.Since(snapshot.GeneratedAt) >= ttl {
+		fresh, err := CollectMetricsSnapshot()
+		if err != nil {
+			snapshotMutex.Unlock()
+			logger.AppLogger.WithError(err).Error("Failed to collect metrics snapshot")
+			http.Error(w, "Failed to collect metrics snapshot", http.StatusInternalServerError)
+			return
+		}
+		cachedSnapshot = fresh
+		snapshot = fresh
+	}
+	snapshotMutex.Unlock()
+
+	maxAge := ttl - time.Since(snapshot.GeneratedAt)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("Last-Modified", snapshot.GeneratedAt.UTC().Format(http.TimeFormat))
+	json.NewEncoder(w).Encode(snapshot)
+}
@@ -2,11 +2,18 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
 	"strings"
 
 	"therapy-navigation-system/internal/auth"
 	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
 )
 
 // Global Firebase auth instance
@@ -32,12 +39,6 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// Skip auth for WebSocket endpoint (uses its own auth)
-		if strings.Contains(r.URL.Path, "/ws") {
-			next(w, r)
-			return
-		}
-
 		// Skip auth for docs
 		if strings.HasPrefix(r.URL.Path, "/docs") {
 			next(w, r)
@@ -47,12 +48,19 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		// Check if Firebase auth is initialized
 		if firebaseAuth == nil {
 			logger.AppLogger.Error("Firebase auth not initialized - allowing request for development")
-			next(w, r)
+			ctx := context.WithValue(r.Context(), "user_admin", true)
+			next(w, r.WithContext(repository.WithOrganization(ctx, defaultOrganization())))
 			return
 		}
 
-		// Get Authorization header
+		// Get Authorization header; browsers can't set headers on a WebSocket handshake, so
+		// the session socket passes the token as a query parameter instead
 		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" && strings.HasSuffix(r.URL.Path, "/ws") {
+			if queryToken := r.URL.Query().Get("token"); queryToken != "" {
+				authHeader = "Bearer " + queryToken
+			}
+		}
 		if authHeader == "" {
 			logger.AppLogger.WithField("path", r.URL.Path).Warn("Request with no Authorization header")
 			http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
@@ -70,9 +78,19 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Resolve the caller's organization so all tenant queries are scoped to it
+		orgID, err := organizationFromClaims(firebaseToken.Claims)
+		if err != nil {
+			logger.AppLogger.WithError(err).WithField("email", firebaseToken.Claims["email"]).Warn("Rejecting request without organization")
+			http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+			return
+		}
+
 		// Add user info to context
 		ctx := context.WithValue(r.Context(), "user_email", firebaseToken.Claims["email"])
 		ctx = context.WithValue(ctx, "user_uid", firebaseToken.UID)
+		ctx = context.WithValue(ctx, "user_admin", adminFromClaims(firebaseToken.Claims))
+		ctx = repository.WithOrganization(ctx, orgID)
 
 		// Log successful auth
 		logger.AppLogger.WithField("email", firebaseToken.Claims["email"]).Debug("Request authenticated")
@@ -85,4 +103,81 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 // RequireAuth wraps a handler with authentication middleware
 func RequireAuth(handler http.HandlerFunc) http.HandlerFunc {
 	return AuthMiddleware(handler)
-}
\ No newline at end of file
+}
+
+// RequireAdmin restricts a handler to admins, for deployment-wide settings that affect
+// every organization. It runs behind AuthMiddleware, which records the admin claim.
+func RequireAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requestIsAdmin(r) {
+			logger.AppLogger.WithFields(map[string]interface{}{
+				"path":  r.URL.Path,
+				"email": r.Context().Value("user_email"),
+			}).Warn("Rejecting non-admin request to an admin endpoint")
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, map[string]string{"error": "Admin role required"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// requestIsAdmin reports whether the authenticated user holds the admin role
+func requestIsAdmin(r *http.Request) bool {
+	admin, _ := r.Context().Value("user_admin").(bool)
+	return admin
+}
+
+// adminFromClaims reads the admin custom claim
+func adminFromClaims(claims map[string]interface{}) bool {
+	admin, _ := claims["admin"].(bool)
+	return admin
+}
+
+// defaultOrganization is the organization used in single-tenant mode
+func defaultOrganization() string {
+	if Services != nil && Services.Config != nil && Services.Config.DefaultTenantID != "" {
+		return Services.Config.DefaultTenantID
+	}
+	return "default"
+}
+
+// organizationFromClaims reads the organization_id custom claim; single-tenant
+// deployments fall back to the default organization
+func organizationFromClaims(claims map[string]interface{}) (string, error) {
+	if orgID, ok := claims["organization_id"].(string); ok && orgID != "" {
+		return orgID, nil
+	}
+	if repository.MultiTenant() {
+		return "", fmt.Errorf("no organization_id claim on token")
+	}
+	return defaultOrganization(), nil
+}
+
+// RequestLogger logs requests like chi's middleware.Logger, with the session socket's
+// token query parameter redacted so ID tokens don't end up in access logs
+func RequestLogger() func(http.Handler) http.Handler {
+	return middleware.RequestLogger(tokenRedactingFormatter{
+		LogFormatter: &middleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+	})
+}
+
+// tokenRedactingFormatter formats log entries from a copy of the request without its token
+type tokenRedactingFormatter struct {
+	middleware.LogFormatter
+}
+
+func (f tokenRedactingFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	query := r.URL.Query()
+	if !query.Has("token") {
+		return f.LogFormatter.NewLogEntry(r)
+	}
+	query.Set("token", "REDACTED")
+
+	logged := r.WithContext(r.Context())
+	redactedURL := *r.URL
+	redactedURL.RawQuery = query.Encode()
+	logged.URL = &redactedURL
+	logged.RequestURI = redactedURL.RequestURI()
+	return f.LogFormatter.NewLogEntry(logged)
+}
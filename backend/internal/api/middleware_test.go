@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRequestLoggerRedactsToken(t *testing.T) {
+	var logged bytes.Buffer
+	logRequests := middleware.RequestLogger(tokenRedactingFormatter{
+		LogFormatter: &middleware.DefaultLogFormatter{Logger: log.New(&logged, "", 0), NoColor: true},
+	})
+
+	var seenToken string
+	handler := logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenToken = r.URL.Query().Get("token")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/sessions/s1/ws?token=secret-id-token&v=2", nil))
+
+	if seenToken != "secret-id-token" {
+		t.Errorf("handler saw token %q, want the original", seenToken)
+	}
+	if strings.Contains(logged.String(), "secret-id-token") {
+		t.Errorf("access log contains the token: %s", logged.String())
+	}
+	if !strings.Contains(logged.String(), "token=REDACTED") {
+		t.Errorf("access log = %q, want the token redacted", logged.String())
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	newTestEnv(t)
+	handler := RequireAdmin(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, tc := range []struct {
+		name  string
+		admin interface{}
+		want  int
+	}{
+		{"admin", true, http.StatusNoContent},
+		{"not admin", false, http.StatusForbidden},
+		{"no claim", nil, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/intake/rubric", nil)
+			if tc.admin != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "user_admin", tc.admin))
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
@@ -31,6 +31,8 @@ type PromptRecord struct {
 	ID          string    `json:"id"`
 	SessionID   string    `json:"session_id"`
 	AgentType   string    `json:"agent_type"`
+	TurnType    string    `json:"turn_type"`
+	Phase       string    `json:"phase"`
 	Prompt      string    `json:"prompt"`
 	Response    string    `json:"response"`
 	TokenCount  int       `json:"token_count"`
@@ -80,7 +82,11 @@ func GetPromptsHandler(w http.ResponseWriter, r *http.Request) {
 		query = query.Where("agent_type = ?", agentType)
 	}
 	
-	query.Order("timestamp DESC").Limit(100).Find(&prompts)
+	if err := query.Order("timestamp DESC").Limit(100).Find(&prompts).Error; err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to query prompt logs")
+		http.Error(w, "Failed to read prompts", http.StatusInternalServerError)
+		return
+	}
 	
 	// Get total count
 	var totalCount int64
@@ -148,6 +154,7 @@ func GetUsageStatsHandler(w http.ResponseWriter, r *http.Request) {
 	var agentUsages []AgentUsage
 	repository.DB.Table("prompt_logs").
 		Select("agent_type, COUNT(*) as prompt_count, SUM(token_count) as total_tokens").
+		Where("turn_type = ?", repository.PromptTurnRequest). // responses carry no token count
 		Group("agent_type").
 		Scan(&agentUsages)
 	
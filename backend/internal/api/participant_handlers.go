@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"gorm.io/gorm"
+)
+
+// SessionParticipantRequest adds a person to a session
+type SessionParticipantRequest struct {
+	PersonID    string `json:"person_id"`
+	Role        string `json:"role"` // supervisor, co_therapist, observer, or another configured role
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// GetSessionParticipantsHandler lists everyone taking part in a session
+// @Summary Get session participants
+// @Description The primary client and therapist followed by any additional participants, such as a supervisor or co-therapist
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {array} repository.SessionParticipant
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/participants [get]
+func GetSessionParticipantsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	participants, err := repository.SessionParticipants(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to fetch session participants")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch participants"})
+		return
+	}
+
+	render.JSON(w, r, participants)
+}
+
+// AddSessionParticipantHandler adds a person to a session, or changes their role. Only the
+// session's clinicians may.
+// @Summary Add session participant
+// @Description Add a supervisor, co-therapist or other configured role to a session. The primary client and therapist are set on the session itself.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param request body SessionParticipantRequest true "Participant"
+// @Success 200 {object} repository.SessionParticipant
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/participants [post]
+func AddSessionParticipantHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var req SessionParticipantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	if !requireClinician(w, r, sessionID) {
+		return
+	}
+
+	participant, err := repository.AddSessionParticipant(repository.DB, sessionID, req.PersonID, req.Role, req.DisplayName)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"person_id":  participant.PersonID,
+		"role":       participant.Role,
+	}).Info("👥 Session participant added")
+	broadcastParticipantsUpdate(sessionID)
+
+	render.JSON(w, r, participant)
+}
+
+// RemoveSessionParticipantHandler removes a person from a session. Only the session's
+// clinicians may.
+// @Summary Remove session participant
+// @Tags sessions
+// @Param sessionId path string true "Session ID"
+// @Param personId path string true "Participant person ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/sessions/{sessionId}/participants/{personId} [delete]
+func RemoveSessionParticipantHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+	personID := chi.URLParam(r, "personId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	if !requireClinician(w, r, sessionID) {
+		return
+	}
+
+	if err := repository.RemoveSessionParticipant(repository.DB, sessionID, personID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPrimaryParticipant):
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "Participant not found"})
+		default:
+			logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to remove session participant")
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to remove participant"})
+		}
+		return
+	}
+
+	broadcastParticipantsUpdate(sessionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireClinician checks that the authenticated user is one of the session's clinicians,
+// writing a 403 when they aren't. Only clinicians decide who else takes part in a session.
+func requireClinician(w http.ResponseWriter, r *http.Request, sessionID string) bool {
+	participant, err := requestParticipant(r, sessionID)
+	if err != nil || (participant != nil && !repository.ParticipantIsClinician(participant.Role)) {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": "Only the session's clinicians can change its participants"})
+		return false
+	}
+	return true
+}
+
+// broadcastParticipantsUpdate sends the session's current participants to its clients
+func broadcastParticipantsUpdate(sessionID string) {
+	participants, err := repository.SessionParticipants(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to load participants for broadcast")
+		return
+	}
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type: shared.MessageTypeParticipantsUpdated,
+		Metadata: map[string]interface{}{
+			"participants": participants,
+		},
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAddSessionParticipantRequiresClinician(t *testing.T) {
+	db := newTestEnv(t)
+	if err := db.AutoMigrate(&repository.SessionParticipant{}); err != nil {
+		t.Fatalf("failed to migrate participants: %v", err)
+	}
+	session := createTestSession(t, db, "intake")
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{sessionId}/participants", AddSessionParticipantHandler)
+
+	for _, tc := range []struct {
+		name   string
+		caller string
+		want   int
+	}{
+		{"client", session.ClientID, http.StatusForbidden},
+		{"not a participant", "stranger", http.StatusForbidden},
+		{"therapist", session.TherapistID, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body := strings.NewReader(`{"person_id":"supervisor-1","role":"supervisor"}`)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost,
+				"/api/sessions/"+session.ID+"/participants?participant_id="+tc.caller, body))
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBroadcastReachesEverySessionConnection(t *testing.T) {
+	db := newTestEnv(t)
+	session := createTestSession(t, db, "intake")
+
+	client := connectTestSocket(t, session.ID)
+	supervisor := connectTestSocket(t, session.ID)
+
+	broadcastSessionUpdate(session.ID, shared.TherapySessionUpdate{Type: "message", Timestamp: time.Now()})
+
+	for name, socket := range map[string]*testSocket{"client": client, "supervisor": supervisor} {
+		if got := len(socket.drain(200 * time.Millisecond)); got != 1 {
+			t.Errorf("%s socket received %d updates, want 1", name, got)
+		}
+	}
+}
+
+func TestSessionTimerStopsWithLastConnection(t *testing.T) {
+	first, second := &safeConn{}, &safeConn{}
+	if !addSessionConnection("timer-session", first) {
+		t.Fatal("first connection not reported as first")
+	}
+	if addSessionConnection("timer-session", second) {
+		t.Fatal("second connection reported as first")
+	}
+	if removeSessionConnection("timer-session", first) {
+		t.Error("removing one of two connections reported as the last")
+	}
+	if !removeSessionConnection("timer-session", second) {
+		t.Error("removing the remaining connection not reported as the last")
+	}
+	if removeSessionConnection("timer-session", second) {
+		t.Error("removing an already removed connection reported as the last")
+	}
+}
+
+func TestPauseControlsRecordTheSocketParticipant(t *testing.T) {
+	db := newTestEnv(t)
+	if err := db.AutoMigrate(&repository.SessionPauseEvent{}); err != nil {
+		t.Fatalf("failed to migrate pause events: %v", err)
+	}
+	Services.Config.PauseHistory = true
+	session := createTestSession(t, db, "intake")
+	t.Cleanup(func() {
+		sessionPausedMutex.Lock()
+		delete(sessionPaused, session.ID)
+		sessionPausedMutex.Unlock()
+	})
+
+	therapist := &repository.SessionParticipant{SessionID: session.ID, PersonID: session.TherapistID, Role: repository.ParticipantRoleTherapist}
+	handleSessionMessage(withSocketParticipant(context.Background(), therapist), session.ID, []byte(`{"type":"pause_session"}`), false)
+	handleSessionMessage(context.Background(), session.ID, []byte(`{"type":"resume_session"}`), false)
+
+	events, err := repository.PauseHistory(db, session.ID)
+	if err != nil {
+		t.Fatalf("PauseHistory: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d pause events, want 2", len(events))
+	}
+	if events[0].Actor != repository.ParticipantRoleTherapist || events[0].ActorID != session.TherapistID {
+		t.Errorf("therapist's pause recorded as %q/%q", events[0].Actor, events[0].ActorID)
+	}
+	if events[1].Actor != repository.PauseActorUnknown {
+		t.Errorf("unbound socket's resume recorded as %q, want %q", events[1].Actor, repository.PauseActorUnknown)
+	}
+}
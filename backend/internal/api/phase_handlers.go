@@ -1,11 +1,17 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
+	contextbuilder "therapy-navigation-system/internal/context"
 	"therapy-navigation-system/internal/logger"
 	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/state"
 	"therapy-navigation-system/shared"
 
 	"github.com/go-chi/chi/v5"
@@ -35,13 +41,20 @@ func GetPhasesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	phaseIDs := make([]string, len(phases))
+	for i := range phases {
+		phaseIDs[i] = phases[i].ID
+	}
+	tools, transitions, err := loadPhaseGraph(phaseIDs)
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch phase graph"})
+		return
+	}
+
 	responses := make([]PhaseResponse, len(phases))
-	for i, phase := range phases {
-		responses[i] = PhaseResponse{
-			Phase:       &phase,
-			Tools:       []string{}, // TODO: Implement database-driven tool lookup
-			Transitions: []repository.PhaseTransition{}, // TODO: Implement database-driven transitions
-		}
+	for i := range phases {
+		responses[i] = newPhaseResponse(&phases[i], tools, transitions)
 	}
 
 	render.JSON(w, r, responses)
@@ -66,13 +79,69 @@ func GetPhaseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := PhaseResponse{
-		Phase:       &phase,
-		Tools:       []string{}, // TODO: Implement database-driven tool lookup
-		Transitions: []repository.PhaseTransition{}, // TODO: Implement database-driven transitions
+	tools, transitions, err := loadPhaseGraph([]string{phase.ID})
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch phase graph"})
+		return
+	}
+
+	render.JSON(w, r, newPhaseResponse(&phase, tools, transitions))
+}
+
+// loadPhaseGraph fetches tool names and outgoing transitions for the given phases in two
+// queries, keyed by phase ID. Transitions keep their priority order (highest first).
+func loadPhaseGraph(phaseIDs []string) (map[string][]string, map[string][]repository.PhaseTransition, error) {
+	tools := make(map[string][]string)
+	transitions := make(map[string][]repository.PhaseTransition)
+	if len(phaseIDs) == 0 {
+		return tools, transitions, nil
+	}
+
+	var toolRows []struct {
+		PhaseID string
+		Name    string
+	}
+	if err := repository.DB.Table("phase_tools").
+		Select("phase_tools.phase_id, tools.name").
+		Joins("JOIN tools ON tools.id = phase_tools.tool_id").
+		Where("phase_tools.phase_id IN ? AND phase_tools.is_active = ? AND tools.is_active = ?", phaseIDs, true, true).
+		Order("tools.name ASC").
+		Scan(&toolRows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, row := range toolRows {
+		tools[row.PhaseID] = append(tools[row.PhaseID], row.Name)
+	}
+
+	var rows []repository.PhaseTransition
+	if err := repository.DB.
+		Where("from_phase_id IN ?", phaseIDs).
+		Order("priority DESC, created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, t := range rows {
+		transitions[t.FromPhaseID] = append(transitions[t.FromPhaseID], t)
 	}
 
-	render.JSON(w, r, response)
+	return tools, transitions, nil
+}
+
+// newPhaseResponse assembles a PhaseResponse from a loadPhaseGraph result
+func newPhaseResponse(phase *repository.Phase, tools map[string][]string, transitions map[string][]repository.PhaseTransition) PhaseResponse {
+	response := PhaseResponse{
+		Phase:       phase,
+		Tools:       tools[phase.ID],
+		Transitions: transitions[phase.ID],
+	}
+	if response.Tools == nil {
+		response.Tools = []string{}
+	}
+	if response.Transitions == nil {
+		response.Transitions = []repository.PhaseTransition{}
+	}
+	return response
 }
 
 // PhaseTransitionRequest represents a request to transition phases
@@ -80,16 +149,54 @@ type PhaseTransitionRequest struct {
 	SessionID   string                 `json:"session_id"`
 	ToPhaseID   string                 `json:"to_phase_id"`
 	SessionData map[string]interface{} `json:"session_data,omitempty"`
+	DryRun      bool                   `json:"dry_run,omitempty"` // Preview the transition without applying anything
+}
+
+var errTargetPhaseNotFound = errors.New("target phase not found")
+
+// manualTransition is a validated transition TransitionPhaseHandler is about to apply
+type manualTransition struct {
+	session     repository.Session
+	fromPhase   string
+	targetPhase repository.Phase
+	startsTimer bool // Leaving pre-session starts the session timer
+	stopsTimer  bool // Returning to pre-session or entering a terminal phase stops it
+}
+
+// planManualTransition runs TransitionPhaseHandler's checks without changing anything. A
+// transition through the API is a clinician's manual override, so unlike the coach's
+// transition tool it only requires the session and the target phase to exist.
+func planManualTransition(ctx context.Context, req PhaseTransitionRequest) (*manualTransition, error) {
+	var session repository.Session
+	if err := repository.Scoped(ctx).First(&session, "id = ?", req.SessionID).Error; err != nil {
+		return nil, err
+	}
+
+	var targetPhase repository.Phase
+	if err := repository.DB.First(&targetPhase, "id = ?", req.ToPhaseID).Error; err != nil {
+		return &manualTransition{session: session, fromPhase: session.Phase}, errTargetPhaseNotFound
+	}
+
+	return &manualTransition{
+		session:     session,
+		fromPhase:   session.Phase,
+		targetPhase: targetPhase,
+		startsTimer: session.Phase == "pre_session" && targetPhase.ID != "pre_session",
+		stopsTimer:  (session.Phase != "pre_session" && targetPhase.ID == "pre_session") || targetPhase.IsTerminal,
+	}, nil
 }
 
 // TransitionPhaseHandler handles phase transitions
 // @Summary Transition to a new phase
-// @Description Request a phase transition for a session
+// @Description Move a session to a phase as a manual override, which only requires the target phase to exist. A dry run returns the would-be result, including the current phase's missing fields, without updating the session or broadcasting.
 // @Tags phases
 // @Accept json
 // @Produce json
 // @Param request body PhaseTransitionRequest true "Transition request"
+// @Param dry_run query bool false "Preview the transition without applying it"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
 // @Router /api/phases/transition [post]
 func TransitionPhaseHandler(w http.ResponseWriter, r *http.Request) {
 	var req PhaseTransitionRequest
@@ -98,31 +205,51 @@ func TransitionPhaseHandler(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, map[string]string{"error": "Invalid request"})
 		return
 	}
+	dryRun := req.DryRun || r.URL.Query().Get("dry_run") == "true"
 
-	// Get current session
-	var session repository.Session
-	if err := repository.DB.First(&session, "id = ?", req.SessionID).Error; err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, map[string]string{"error": "Session not found"})
-		return
-	}
-
-	// Check if target phase exists
-	var targetPhase repository.Phase
-	if err := repository.DB.First(&targetPhase, "id = ?", req.ToPhaseID).Error; err != nil {
+	plan, err := planManualTransition(r.Context(), req)
+	if errors.Is(err, errTargetPhaseNotFound) {
 		render.Status(r, http.StatusBadRequest)
 		render.JSON(w, r, map[string]interface{}{
 			"error":   "Invalid transition",
 			"reason":  "Target phase not found",
-			"current": session.Phase,
+			"current": plan.fromPhase,
 			"target":  req.ToPhaseID,
+			"dry_run": dryRun,
+		})
+		return
+	}
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+	session := plan.session
+	oldPhase := plan.fromPhase
+	targetPhase := plan.targetPhase
+
+	if dryRun {
+		// The override doesn't need the current phase complete, but the preview shows what it skips
+		missingFields, err := state.New(session.ID).GetMissingFields(oldPhase)
+		if err != nil {
+			logger.AppLogger.WithError(err).WithField("session_id", session.ID).Warn("Failed to load missing fields for transition preview")
+			missingFields = []string{}
+		}
+		render.JSON(w, r, map[string]interface{}{
+			"success":        true,
+			"dry_run":        true,
+			"from":           oldPhase,
+			"to":             targetPhase.ID,
+			"phase":          targetPhase,
+			"missing_fields": missingFields,
+			"starts_timer":   plan.startsTimer,
+			"stops_timer":    plan.stopsTimer,
 		})
 		return
 	}
 
 	// Update session phase
-	oldPhase := session.Phase
-	session.Phase = req.ToPhaseID
+	session.Phase = targetPhase.ID
 	if err := repository.DB.Save(&session).Error; err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to update session phase")
 		render.Status(r, http.StatusInternalServerError)
@@ -130,23 +257,26 @@ func TransitionPhaseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Append to the session's phase timeline
+	if err := repository.RecordPhaseTransition(repository.DB, session.ID, oldPhase, targetPhase.ID, time.Now()); err != nil {
+		logger.AppLogger.WithError(err).Warn("Failed to record phase history")
+	}
+
 	// Log the transition
 	logger.AppLogger.WithFields(map[string]interface{}{
 		"session_id": session.ID,
 		"from_phase": oldPhase,
-		"to_phase":   req.ToPhaseID,
+		"to_phase":   targetPhase.ID,
 	}).Info("Phase transition completed")
 
 	// State machine manages timer: Start timer when leaving pre-session
-	if oldPhase == "pre_session" && req.ToPhaseID != "pre_session" {
-		// Starting active session phases - start the timer
+	if plan.startsTimer {
 		logger.AppLogger.WithField("session_id", session.ID).Info("Starting session timer - transitioning out of pre-session")
-		go startSessionTimer(session.ID, session.StartTime)
+		go startSessionTimer(session.ID, session.StartTime, time.Now())
 	}
 
 	// Stop timer when returning to pre-session or completing
-	if (oldPhase != "pre_session" && req.ToPhaseID == "pre_session") || req.ToPhaseID == "complete" {
-		// Stop the timer
+	if plan.stopsTimer {
 		stopSessionTimer(session.ID)
 		logger.AppLogger.WithField("session_id", session.ID).Info("Stopping session timer")
 	}
@@ -154,24 +284,140 @@ func TransitionPhaseHandler(w http.ResponseWriter, r *http.Request) {
 	// Broadcast the update via WebSocket
 	broadcastSessionUpdate(session.ID, shared.TherapySessionUpdate{
 		Type:      "phase_transition",
-		Phase:     req.ToPhaseID,
+		Phase:     targetPhase.ID,
 		Metadata: map[string]interface{}{
 			"from_phase": oldPhase,
-			"to_phase":   req.ToPhaseID,
+			"to_phase":   targetPhase.ID,
 		},
 		Timestamp: session.UpdatedAt,
 	})
 
+	broadcastPhasePreview(session.ID)
+	broadcastPhaseVisualization(session.ID)
+
 	// Return success with new phase info
 	render.JSON(w, r, map[string]interface{}{
 		"success":   true,
 		"from":      oldPhase,
-		"to":        req.ToPhaseID,
+		"to":        targetPhase.ID,
 		"phase":     targetPhase,
 		"tools":     []string{}, // TODO: Implement database-driven tool lookup
 	})
 }
 
+// PhaseRollbackRequest optionally explains why a session is rolled back
+type PhaseRollbackRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// RollbackPhaseHandler moves a session back to the phase before its current one
+// @Summary Roll back a session phase
+// @Description Move a session to the immediately prior phase when it advanced prematurely. Data collected in the phase being left is kept.
+// @Tags phases
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param request body PhaseRollbackRequest false "Rollback reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]string
+// @Router /api/sessions/{sessionId}/rollback [post]
+func RollbackPhaseHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var req PhaseRollbackRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid request"})
+			return
+		}
+	}
+
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).First(&session, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+	if session.Status == repository.SessionStatusCompleted || session.Status == repository.SessionStatusCancelled {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Session has ended and can't be rolled back"})
+		return
+	}
+
+	var currentPhase repository.Phase
+	if err := repository.DB.First(&currentPhase, "id = ?", session.Phase).Error; err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Current phase not found"})
+		return
+	}
+	previousPhase, err := repository.PreviousEnabledPhase(repository.DB, &session, currentPhase.Position)
+	if err != nil {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Session is already in the first phase"})
+		return
+	}
+
+	// Only roll back from the phase we looked at - a concurrent transition wins
+	now := time.Now()
+	result := repository.DB.Model(&repository.Session{}).
+		Where("id = ? AND phase = ?", session.ID, currentPhase.ID).
+		Updates(map[string]interface{}{
+			"phase":            previousPhase.ID,
+			"phase_start_time": now,
+		})
+	if result.Error != nil {
+		logger.AppLogger.WithError(result.Error).Error("Failed to roll back session phase")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to update session"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Session changed phase during the rollback"})
+		return
+	}
+
+	// SessionFieldValues collected in the phase being left are deliberately kept
+	if err := repository.RecordPhaseRollback(repository.DB, session.ID, currentPhase.ID, previousPhase.ID, now); err != nil {
+		logger.AppLogger.WithError(err).Warn("Failed to record phase history")
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": session.ID,
+		"from_phase": currentPhase.ID,
+		"to_phase":   previousPhase.ID,
+		"reason":     req.Reason,
+	}).Info("⏪ Phase rolled back")
+
+	// Back in pre-session the clock stops, as with a forward transition into it
+	if previousPhase.ID == "pre_session" {
+		stopSessionTimer(session.ID)
+	} else {
+		resetPhaseTimer(session.ID)
+	}
+
+	broadcastSessionUpdate(session.ID, shared.TherapySessionUpdate{
+		Type:  shared.MessageTypePhaseRollback,
+		Phase: previousPhase.ID,
+		Metadata: map[string]interface{}{
+			"from_phase": currentPhase.ID,
+			"to_phase":   previousPhase.ID,
+			"reason":     req.Reason,
+		},
+		Timestamp: now,
+	})
+	broadcastPhasePreview(session.ID)
+	broadcastPhaseVisualization(session.ID)
+
+	render.JSON(w, r, map[string]interface{}{
+		"success": true,
+		"from":    currentPhase.ID,
+		"to":      previousPhase.ID,
+		"phase":   previousPhase,
+	})
+}
+
 // GetPhaseToolsHandler returns tools available for a phase
 // @Summary Get phase tools
 // @Description Retrieve MCP tools available for a specific phase
@@ -236,6 +482,59 @@ func GetPhaseRequirementsHandler(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, phaseDataItems)
 }
 
+// GetEffectivePromptHandler returns the merged system+phase+addendum prompt for a phase
+// @Summary Get effective prompt
+// @Description Assemble a phase's prompt from the given system and phase prompt versions (active when omitted), with placeholder session variables and per-section token counts
+// @Tags prompts
+// @Produce json
+// @Param id path string true "Phase ID"
+// @Param system_version query int false "System prompt version"
+// @Param phase_version query int false "Phase prompt version"
+// @Success 200 {object} contextbuilder.EffectivePrompt
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/phases/{id}/effective-prompt [get]
+func GetEffectivePromptHandler(w http.ResponseWriter, r *http.Request) {
+	phaseID := chi.URLParam(r, "id")
+
+	versions := map[string]int{}
+	for _, param := range []string{"system_version", "phase_version"} {
+		raw := r.URL.Query().Get(param)
+		if raw == "" {
+			continue
+		}
+		version, err := strconv.Atoi(raw)
+		if err != nil || version < 1 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": param + " must be a positive integer"})
+			return
+		}
+		versions[param] = version
+	}
+
+	if err := repository.DB.Select("id").First(&repository.Phase{}, "id = ?", phaseID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Phase not found"})
+		return
+	}
+
+	prompt, err := contextbuilder.AssembleEffectivePrompt(phaseID, versions["system_version"], versions["phase_version"])
+	if errors.Is(err, contextbuilder.ErrPromptVersionNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Prompt version not found"})
+		return
+	}
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("phase_id", phaseID).Error("Failed to assemble effective prompt")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to assemble effective prompt"})
+		return
+	}
+
+	render.JSON(w, r, prompt)
+}
+
 // CheckAutoAdvanceHandler checks if a phase should auto-advance
 // @Summary Check auto-advance
 // @Description Check if a session should auto-advance to next phase
@@ -250,7 +549,7 @@ func CheckAutoAdvanceHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get session
 	var session repository.Session
-	if err := repository.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+	if err := repository.Scoped(r.Context()).First(&session, "id = ?", sessionID).Error; err != nil {
 		render.Status(r, http.StatusNotFound)
 		render.JSON(w, r, map[string]string{"error": "Session not found"})
 		return
@@ -265,4 +564,138 @@ func CheckAutoAdvanceHandler(w http.ResponseWriter, r *http.Request) {
 		"current_phase":  session.Phase,
 		"next_phase":     nextPhase,
 	})
-}
\ No newline at end of file
+}
+// PhasePreview is the client-facing summary of an upcoming phase
+type PhasePreview struct {
+	PhaseID                 string `json:"phase_id"`
+	DisplayName             string `json:"display_name"`
+	ClientDescription       string `json:"client_description"`
+	ExpectedDurationSeconds int    `json:"expected_duration_seconds"`
+	Icon                    string `json:"icon,omitempty"`
+	Color                   string `json:"color,omitempty"`
+}
+
+// buildPhasePreview returns the session's current phase and the phases reachable from it.
+// The session is looked up in ctx's organization.
+func buildPhasePreview(ctx context.Context, sessionID string) (string, []PhasePreview, error) {
+	var session repository.Session
+	if err := repository.Scoped(ctx).First(&session, "id = ?", sessionID).Error; err != nil {
+		return "", nil, err
+	}
+
+	var transitions []repository.PhaseTransition
+	if err := repository.DB.Preload("ToPhase").
+		Where("from_phase_id = ? AND is_active = ?", session.Phase, true).
+		Order("priority DESC").
+		Find(&transitions).Error; err != nil {
+		return session.Phase, nil, err
+	}
+
+	previews := []PhasePreview{}
+	for _, t := range transitions {
+		next := t.ToPhase
+		if next.ID == "" || !session.FeatureEnabled(next.FeatureFlag) {
+			continue
+		}
+		previews = append(previews, PhasePreview{
+			PhaseID:                 next.ID,
+			DisplayName:             next.DisplayName,
+			ClientDescription:       next.ClientDescription,
+			ExpectedDurationSeconds: next.RecommendedDurationSeconds,
+			Icon:                    next.Icon,
+			Color:                   next.Color,
+		})
+	}
+
+	return session.Phase, previews, nil
+}
+
+// broadcastPhasePreview pushes the "what's next" preview to the session's client
+func broadcastPhasePreview(sessionID string) {
+	currentPhase, previews, err := buildPhasePreview(repository.WithSystemScope(context.Background()), sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to build phase preview")
+		return
+	}
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:  shared.MessageTypePhasePreview,
+		Phase: currentPhase,
+		Metadata: map[string]interface{}{
+			"next_phases": previews,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// GetPhasePreviewHandler returns client-friendly previews of the phases that can follow the current one
+// @Summary Preview upcoming phases
+// @Description Get client-facing descriptions and expected durations of the phases reachable from the session's current phase
+// @Tags phases
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/sessions/{sessionId}/phase-preview [get]
+func GetPhasePreviewHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	currentPhase, previews, err := buildPhasePreview(r.Context(), sessionID)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"session_id":    sessionID,
+		"current_phase": currentPhase,
+		"next_phases":   previews,
+	})
+}
+
+// ReviewTransitionLoopHandler releases a session held as a runaway transition loop
+// @Summary Review a transition loop
+// @Description Release coach transitions held after the session exceeded MAX_PHASE_TRANSITIONS, restarting the count from its current value
+// @Tags phases
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/transition-review [post]
+func ReviewTransitionLoopHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	count, err := repository.ReviewTransitionLoop(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to release held transitions")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to release held transitions"})
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id":             sessionID,
+		"phase_transition_count": count,
+	}).Info("✅ Transition loop reviewed - coach transitions released")
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type: shared.MessageTypeTransitionLoopReviewed,
+		Metadata: map[string]interface{}{
+			"phase_transition_count": count,
+			"auto_transitions_held":  false,
+		},
+		Timestamp: time.Now(),
+	})
+
+	render.JSON(w, r, map[string]interface{}{
+		"session_id":             sessionID,
+		"phase_transition_count": count,
+		"auto_transitions_held":  false,
+	})
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+func TestEffectivePromptReportsAMissingVersionWithAFixedMessage(t *testing.T) {
+	db := newTestEnv(t)
+	createTestPhases(t, db, "intake")
+	if err := db.Create(&repository.Prompt{Name: "system_v1", Category: "system", Content: "You are a coach."}).Error; err != nil {
+		t.Fatalf("failed to create system prompt: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Get("/api/phases/{id}/effective-prompt", GetEffectivePromptHandler)
+	for _, query := range []string{"system_version=99", "phase_version=7"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/phases/intake/effective-prompt?"+query, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404: %s", query, rec.Code, rec.Body.String())
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != `{"error":"Prompt version not found"}` {
+			t.Errorf("%s: body = %s, want the fixed not-found message", query, body)
+		}
+	}
+}
+
+func TestTransitionDryRunChangesNothing(t *testing.T) {
+	db := newTestEnv(t)
+	createTestPhases(t, db, "intake", "status_check")
+	if err := db.Create(&repository.Phase{ID: "complete", DisplayName: "Complete", Position: 3, IsTerminal: true}).Error; err != nil {
+		t.Fatalf("failed to create terminal phase: %v", err)
+	}
+	if err := db.Create(&repository.PhaseData{
+		ID: "intake_presenting_problem", PhaseID: "intake", Name: "presenting_problem", Requirement: repository.PhaseDataRequired,
+	}).Error; err != nil {
+		t.Fatalf("failed to create phase data: %v", err)
+	}
+	session := createTestSession(t, db, "intake")
+	var before repository.Session
+	db.First(&before, "id = ?", session.ID)
+	socket := connectTestSocket(t, session.ID)
+
+	router := chi.NewRouter()
+	router.Post("/api/phases/transition", TransitionPhaseHandler)
+	transition := func(target string, dryRun bool) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, _ := json.Marshal(PhaseTransitionRequest{SessionID: session.ID, ToPhaseID: target, DryRun: dryRun})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/phases/transition", bytes.NewReader(body)))
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response
+	}
+
+	for _, tc := range []struct {
+		target     string
+		wantStatus int
+		stopsTimer bool
+	}{
+		{"status_check", http.StatusOK, false},
+		{"no_such_phase", http.StatusBadRequest, false},
+		{"complete", http.StatusOK, true},
+	} {
+		rec, response := transition(tc.target, true)
+		if rec.Code != tc.wantStatus || response["dry_run"] != true {
+			t.Errorf("dry run to %s: status %d, want %d as a dry run: %s", tc.target, rec.Code, tc.wantStatus, rec.Body.String())
+			continue
+		}
+		if tc.wantStatus == http.StatusOK {
+			missing, _ := response["missing_fields"].([]interface{})
+			if response["to"] != tc.target || response["stops_timer"] != tc.stopsTimer || len(missing) != 1 || missing[0] != "presenting_problem" {
+				t.Errorf("dry run to %s = %v, want the target, stops_timer %t and the missing presenting_problem", tc.target, response, tc.stopsTimer)
+			}
+		}
+
+		var after repository.Session
+		db.First(&after, "id = ?", session.ID)
+		if after.Phase != before.Phase || after.PhaseHistory != before.PhaseHistory || !after.UpdatedAt.Equal(before.UpdatedAt) {
+			t.Errorf("dry run to %s changed the session: phase %s, history %q", tc.target, after.Phase, after.PhaseHistory)
+		}
+	}
+	if updates := socket.drain(100 * time.Millisecond); len(updates) != 0 {
+		t.Errorf("dry runs broadcast %d updates, want none", len(updates))
+	}
+
+	// The real transition goes through the same checks and applies them
+	if rec, _ := transition("no_such_phase", false); rec.Code != http.StatusBadRequest {
+		t.Errorf("transition to a missing phase: status %d, want 400", rec.Code)
+	}
+	if rec, response := transition("status_check", false); rec.Code != http.StatusOK || response["to"] != "status_check" {
+		t.Fatalf("transition to status_check: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var after repository.Session
+	db.First(&after, "id = ?", session.ID)
+	if after.Phase != "status_check" {
+		t.Errorf("session phase = %s after the transition, want status_check", after.Phase)
+	}
+	broadcast := false
+	for _, update := range socket.drain(100 * time.Millisecond) {
+		broadcast = broadcast || (update.Type == "phase_transition" && update.Phase == "status_check")
+	}
+	if !broadcast {
+		t.Error("the transition wasn't broadcast")
+	}
+}
+
+func TestRollbackPhaseReturnsToThePreviousPhase(t *testing.T) {
+	db := newTestEnv(t)
+	createTestPhases(t, db, "intake", "status_check", "body_scan")
+	session := createTestSession(t, db, "intake")
+	if err := repository.RecordPhaseTransition(db, session.ID, "intake", "status_check", time.Now()); err != nil {
+		t.Fatalf("failed to record transition: %v", err)
+	}
+	db.Model(session).Update("phase", "status_check")
+	if err := repository.UpsertSessionFieldValue(db, &repository.SessionFieldValue{
+		SessionID: session.ID, PhaseID: "status_check", FieldName: "suds_current", FieldValue: "6", FieldType: "integer",
+	}); err != nil {
+		t.Fatalf("failed to store field value: %v", err)
+	}
+	socket := connectTestSocket(t, session.ID)
+
+	router := chi.NewRouter()
+	router.Post("/api/sessions/{sessionId}/rollback", RollbackPhaseHandler)
+	rollback := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/sessions/"+session.ID+"/rollback",
+			strings.NewReader(`{"reason":"advanced before the SUDS was confirmed"}`)))
+		return rec
+	}
+
+	if rec := rollback(); rec.Code != http.StatusOK {
+		t.Fatalf("rollback: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var stored repository.Session
+	db.First(&stored, "id = ?", session.ID)
+	if stored.Phase != "intake" {
+		t.Errorf("phase = %s after the rollback, want intake", stored.Phase)
+	}
+	history, err := repository.ParsePhaseHistory(stored.PhaseHistory)
+	if err != nil || len(history) == 0 {
+		t.Fatalf("phase history = %q (%v)", stored.PhaseHistory, err)
+	}
+	if last := history[len(history)-1]; last.PhaseID != "intake" || !last.Rollback || last.LeftAt != nil {
+		t.Errorf("last history entry = %+v, want an open rollback into intake", last)
+	}
+	var kept int64
+	db.Model(&repository.SessionFieldValue{}).Where("session_id = ? AND field_name = ?", session.ID, "suds_current").Count(&kept)
+	if kept != 1 {
+		t.Error("the rollback discarded the field collected in the phase it left")
+	}
+	var rolledBack *shared.TherapySessionUpdate
+	for _, update := range socket.drain(100 * time.Millisecond) {
+		if update.Type == shared.MessageTypePhaseRollback {
+			update := update
+			rolledBack = &update
+		}
+	}
+	if rolledBack == nil || rolledBack.Phase != "intake" || rolledBack.Metadata["from_phase"] != "status_check" ||
+		rolledBack.Metadata["reason"] != "advanced before the SUDS was confirmed" {
+		t.Errorf("phase_rollback broadcast = %+v, want the move from status_check to intake with its reason", rolledBack)
+	}
+
+	// A transition that lands between the handler reading the phase and updating it wins
+	db.Model(session).Update("phase", "body_scan")
+	raced := false
+	if err := db.Callback().Update().Before("gorm:begin_transaction").Register("test:concurrent_transition", func(tx *gorm.DB) {
+		if !raced && tx.Statement.Table == "sessions" {
+			raced = true
+			db.Exec("UPDATE sessions SET phase = ? WHERE id = ?", "intake", session.ID)
+		}
+	}); err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	rec := rollback()
+	if !raced {
+		t.Fatal("the concurrent transition never ran")
+	}
+	if rec.Code != http.StatusConflict {
+		t.Errorf("rollback racing a transition: status %d, want 409: %s", rec.Code, rec.Body.String())
+	}
+	db.First(&stored, "id = ?", session.ID)
+	if stored.Phase != "intake" {
+		t.Errorf("phase = %s, want the concurrent transition's intake", stored.Phase)
+	}
+	var rollbacks int
+	for _, update := range socket.drain(100 * time.Millisecond) {
+		if update.Type == shared.MessageTypePhaseRollback {
+			rollbacks++
+		}
+	}
+	if rollbacks != 0 {
+		t.Errorf("a failed rollback broadcast %d phase_rollback events", rollbacks)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+// phaseVisualization returns the phase's client-side visualization, or nil when it declares none
+func phaseVisualization(phase repository.Phase) *shared.PhaseVisualization {
+	if phase.VisualizationType == "" {
+		return nil
+	}
+
+	visualization := &shared.PhaseVisualization{Type: phase.VisualizationType}
+	if phase.VisualizationConfig != "" {
+		if err := json.Unmarshal([]byte(phase.VisualizationConfig), &visualization.Config); err != nil {
+			logger.AppLogger.WithError(err).WithField("phase", phase.ID).Warn("Invalid visualization config, sending type only")
+		}
+	}
+	return visualization
+}
+
+// broadcastPhaseVisualization tells the client which visualization to render for the session's
+// current phase. Phases without one are broadcast too, so the UI clears the previous ambiance.
+func broadcastPhaseVisualization(sessionID string) {
+	var session repository.Session
+	if err := repository.DB.Select("id", "phase").First(&session, "id = ?", sessionID).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to load session for phase visualization")
+		return
+	}
+
+	var phase repository.Phase
+	if err := repository.DB.Select("id", "visualization_type", "visualization_config").First(&phase, "id = ?", session.Phase).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("phase", session.Phase).Warn("Failed to load phase visualization")
+		return
+	}
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:  shared.MessageTypePhaseVisualization,
+		Phase: phase.ID,
+		Metadata: map[string]interface{}{
+			"visualization": phaseVisualization(phase),
+		},
+		Timestamp: time.Now(),
+	})
+}
@@ -1,54 +1,48 @@
 package api
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/services"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"gorm.io/gorm"
 )
 
+// sessionPromptLogs loads a session's prompt log rows oldest first, after checking the
+// session belongs to the requesting organization
+func sessionPromptLogs(r *http.Request, sessionID string) ([]repository.PromptLog, error) {
+	db := repository.Scoped(r.Context())
+	if err := db.Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		return nil, err
+	}
+	return repository.SessionPromptLogs(db, sessionID)
+}
+
 // GetSessionPrompts returns all prompt logs for a specific session
 func GetSessionPrompts(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 
-	// Read the prompts.jsonl file
-	// Note: In production, this should be from database
-	promptsFile := "/Users/acadiaai/projects/therapy-navigation-system/backend/logs/prompts.jsonl"
-	file, err := os.Open(promptsFile)
+	promptLogs, err := sessionPromptLogs(r, sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		logger.AppLogger.WithError(err).Error("Failed to open prompts file")
+		logger.AppLogger.WithError(err).Error("Failed to load prompt logs")
 		http.Error(w, "Failed to read prompts", http.StatusInternalServerError)
 		return
 	}
-	defer file.Close()
-
-	// Parse JSONL and filter for this session
-	var sessionPrompts []map[string]interface{}
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
-		var promptLog map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &promptLog); err != nil {
-			continue
-		}
-
-		// Filter for this session
-		if sid, ok := promptLog["session_id"].(string); ok && sid == sessionID {
-			sessionPrompts = append(sessionPrompts, promptLog)
-		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		logger.AppLogger.WithError(err).Error("Error reading prompts file")
+	sessionPrompts := make([]map[string]interface{}, len(promptLogs))
+	for i, promptLog := range promptLogs {
+		sessionPrompts[i] = promptLog.Entry()
 	}
 
 	// Return as JSON
@@ -60,89 +54,64 @@ func GetSessionPrompts(w http.ResponseWriter, r *http.Request) {
 func GetSessionPromptsRawText(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 
-	// Read the prompts.jsonl file
-	promptsFile := "/Users/acadiaai/projects/therapy-navigation-system/backend/logs/prompts.jsonl"
-	file, err := os.Open(promptsFile)
+	promptLogs, err := sessionPromptLogs(r, sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		logger.AppLogger.WithError(err).Error("Failed to open prompts file")
+		logger.AppLogger.WithError(err).Error("Failed to load prompt logs")
 		http.Error(w, "Failed to read prompts", http.StatusInternalServerError)
 		return
 	}
-	defer file.Close()
 
-	// Parse JSONL and filter for this session
 	var output string
 	output += "=== RAW PROMPT LOG FOR SESSION " + sessionID + " ===\n\n"
 
-	scanner := bufio.NewScanner(file)
 	turnCount := 0
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
-		var promptLog map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &promptLog); err != nil {
-			continue
+	for _, promptLog := range promptLogs {
+		turnCount++
+
+		// Format the output
+		output += "========================================\n"
+		output += fmt.Sprintf("TURN %d\n", turnCount)
+		output += "Time: " + promptLog.Timestamp.Format(time.RFC3339Nano) + "\n"
+		output += "Phase: " + promptLog.Phase + "\n"
+		output += "Type: " + promptLog.TurnType + "\n"
+		if promptLog.Model != "" {
+			output += "Model: " + promptLog.Model + "\n"
 		}
 
-		// Filter for this session
-		if sid, ok := promptLog["session_id"].(string); ok && sid == sessionID {
-			turnCount++
-
-			// Format the output
-			output += "========================================\n"
-			output += fmt.Sprintf("TURN %d\n", turnCount)
-
-			if timestamp, ok := promptLog["timestamp"].(string); ok {
-				output += "Time: " + timestamp + "\n"
-			}
-			if phase, ok := promptLog["phase"].(string); ok {
-				output += "Phase: " + phase + "\n"
-			}
-			if turnType, ok := promptLog["turn_type"].(string); ok {
-				output += "Type: " + turnType + "\n"
-			}
+		output += "\n"
 
-			output += "\n"
+		// Include user message
+		if promptLog.TurnType == repository.PromptTurnRequest && promptLog.ContentLogged {
+			output += "USER MESSAGE:\n"
+			output += promptLog.UserMessage + "\n\n"
 
-			// Include user message
-			if turnType, ok := promptLog["turn_type"].(string); ok && turnType == "REQUEST" {
-				if userMsg, ok := promptLog["user_message"].(string); ok {
-					output += "USER MESSAGE:\n"
-					output += userMsg + "\n\n"
-				}
+			output += "FULL PROMPT SENT TO AI:\n"
+			output += promptLog.Prompt + "\n\n"
+		}
 
-				if prompt, ok := promptLog["prompt"].(string); ok {
-					output += "FULL PROMPT SENT TO AI:\n"
-					output += prompt + "\n\n"
-				}
+		// Include response
+		if promptLog.TurnType == repository.PromptTurnResponse {
+			if promptLog.ContentLogged {
+				output += "AI RESPONSE:\n"
+				output += promptLog.Response + "\n\n"
 			}
 
-			// Include response
-			if turnType, ok := promptLog["turn_type"].(string); ok && turnType == "RESPONSE" {
-				if responseText, ok := promptLog["response_text"].(string); ok {
-					output += "AI RESPONSE:\n"
-					output += responseText + "\n\n"
-				}
-
-				if functionCalls, ok := promptLog["function_calls"].([]interface{}); ok && len(functionCalls) > 0 {
-					output += "TOOL CALLS:\n"
-					toolJSON, _ := json.MarshalIndent(functionCalls, "", "  ")
-					output += string(toolJSON) + "\n\n"
-				}
-
-				if tokenTotal, ok := promptLog["token_total"].(float64); ok {
-					output += fmt.Sprintf("Tokens: %d\n", int(tokenTotal))
-				}
+			var functionCalls []interface{}
+			if json.Unmarshal([]byte(promptLog.FunctionCalls), &functionCalls) == nil && len(functionCalls) > 0 {
+				output += "TOOL CALLS:\n"
+				toolJSON, _ := json.MarshalIndent(functionCalls, "", "  ")
+				output += string(toolJSON) + "\n\n"
 			}
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		logger.AppLogger.WithError(err).Error("Error reading prompts file")
+		if promptLog.TurnType == repository.PromptTurnRequest {
+			output += fmt.Sprintf("Tokens: %d\n", promptLog.TokenCount)
+		}
 	}
 
 	output += "\n=== END OF SESSION LOG ===\n"
@@ -151,4 +120,102 @@ func GetSessionPromptsRawText(w http.ResponseWriter, r *http.Request) {
 	// Return as plain text
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.Write([]byte(output))
-}
\ No newline at end of file
+}
+
+// PromptLoggingRequest toggles full prompt/response logging
+type PromptLoggingRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// GetPromptLoggingHandler returns the prompt content logging settings
+// @Summary Get prompt logging settings
+// @Description Whether full prompt/response content is logged globally, and which of the caller's organization's sessions have it enabled individually. Requires the admin role.
+// @Tags prompts
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /api/prompt-logging [get]
+func GetPromptLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	// Only list the sessions in the caller's organization
+	sessions := []string{}
+	if enabled := services.PromptLoggingSessions(); len(enabled) > 0 {
+		if err := repository.Scoped(r.Context()).Model(&repository.Session{}).
+			Where("id IN ?", enabled).Order("id ASC").Pluck("id", &sessions).Error; err != nil {
+			logger.AppLogger.WithError(err).Error("Failed to fetch prompt logging sessions")
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to fetch prompt logging settings"})
+			return
+		}
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"enabled":  services.PromptContentLogging(),
+		"sessions": sessions,
+	})
+}
+
+// UpdatePromptLoggingHandler turns full prompt/response logging on or off for all sessions,
+// across the deployment
+// @Summary Toggle prompt logging
+// @Description When off, only metadata (hashes, token counts, timing) is written to the prompt log. Requires the admin role.
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param request body PromptLoggingRequest true "Logging setting"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /api/prompt-logging [put]
+func UpdatePromptLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	var req PromptLoggingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	services.SetPromptContentLogging(req.Enabled)
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"enabled": req.Enabled,
+		"email":   r.Context().Value("user_email"),
+	}).Info("📝 Prompt content logging updated")
+
+	GetPromptLoggingHandler(w, r)
+}
+
+// UpdateSessionPromptLoggingHandler turns full prompt/response logging on or off for one session
+// @Summary Toggle prompt logging for a session
+// @Description Log full prompts and responses for just this session, e.g. while debugging it
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body PromptLoggingRequest true "Logging setting"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/sessions/{id}/prompt-logging [put]
+func UpdateSessionPromptLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+
+	var req PromptLoggingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	services.SetSessionPromptLogging(sessionID, req.Enabled)
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"enabled":    req.Enabled,
+	}).Info("📝 Session prompt content logging updated")
+
+	render.JSON(w, r, map[string]interface{}{
+		"session_id": sessionID,
+		"enabled":    req.Enabled,
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+// holdCoachResponse delays the coach's reply until the phase's minimum response delay has
+// passed since the client's message, showing a typing indicator in the meantime
+func holdCoachResponse(sessionID string, phaseID string, receivedAt time.Time) {
+	if Services == nil || Services.Config == nil || !Services.Config.CoachResponsePacing {
+		return
+	}
+
+	var phase repository.Phase
+	if err := repository.DB.Select("id", "min_response_delay_ms").First(&phase, "id = ?", phaseID).Error; err != nil {
+		return
+	}
+
+	hold := time.Duration(phase.MinResponseDelayMs)*time.Millisecond - time.Since(receivedAt)
+	if hold <= 0 {
+		return
+	}
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:  shared.MessageTypeCoachTyping,
+		Phase: phaseID,
+		Metadata: map[string]interface{}{
+			"hold_ms": hold.Milliseconds(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"phase":      phaseID,
+		"hold_ms":    hold.Milliseconds(),
+	}).Debug("Pacing coach response")
+
+	time.Sleep(hold)
+}
@@ -22,7 +22,7 @@ func NewRouter() *chi.Mux {
 	}))
 
 	// Standard middleware
-	r.Use(middleware.Logger)
+	r.Use(RequestLogger())
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
@@ -40,25 +40,66 @@ func NewRouter() *chi.Mux {
 			})
 		})
 
+		// Bulk intake re-extraction for analytics backfills
+		r.Post("/admin/extract", RequireAdmin(BulkExtractHandler))
+		r.Get("/admin/extract/{jobId}", RequireAdmin(GetExtractionJobHandler))
+
+		// JSON metrics snapshot for deployments without Prometheus
+		r.Get("/metrics/snapshot", GetMetricsSnapshotHandler)
+
 		// Basic entities for UI
 		r.Get("/therapists", GetTherapistsHandler)
 		r.Get("/clients", GetClientsHandler)
 		r.Get("/patients", GetClientsHandler) // Alias for frontend compatibility
+		r.Get("/clients/{clientId}/goals", GetClientGoalsHandler)
 		r.Get("/sessions", GetSessionsHandler)
 		r.Post("/sessions", CreateSessionHandler)
+		r.Post("/sessions/from-template/{templateId}", CreateSessionFromTemplateHandler)
+
+		// Session templates (scoped to the requesting therapist)
+		r.Get("/session-templates", GetSessionTemplatesHandler)
+		r.Post("/session-templates", CreateSessionTemplateHandler)
+		r.Get("/session-templates/{templateId}", GetSessionTemplateHandler)
+		r.Put("/session-templates/{templateId}", UpdateSessionTemplateHandler)
+		r.Delete("/session-templates/{templateId}", DeleteSessionTemplateHandler)
 
 		// Session specific
 		r.Route("/sessions/{sessionId}", func(r chi.Router) {
 			r.Get("/", GetSessionHandler)
 			r.Get("/messages", GetMessagesHandler)
+			r.Get("/transcript", GetSessionTranscriptHandler)
+			r.Get("/phase-history", GetPhaseHistoryHandler)
+			r.Get("/timeline", GetSessionTimelineHandler)
+			r.Post("/rollback", RollbackPhaseHandler)
+			r.Post("/transition-review", ReviewTransitionLoopHandler)
+			r.Get("/phase-preview", GetPhasePreviewHandler)
+			r.Get("/intake/completion", GetIntakeCompletionHandler)
+			r.Get("/goals", GetSessionGoalsHandler)
+			r.Get("/suds", GetSessionSudsHandler)
+			r.Get("/usage", GetSessionUsageHandler)
+			r.Get("/participants", GetSessionParticipantsHandler)
+			r.Post("/participants", AddSessionParticipantHandler)
+			r.Delete("/participants/{personId}", RemoveSessionParticipantHandler)
+			r.Get("/plan", GetSessionPlanHandler)
+			r.Post("/plan", GenerateSessionPlanHandler)
+			r.Put("/plan", ReviewSessionPlanHandler)
+			r.Put("/status", UpdateSessionStatusHandler)
+			r.Get("/completion-readiness", GetCompletionReadinessHandler)
+			r.Get("/attachments", GetSessionAttachmentsHandler)
+			r.Post("/attachments", UploadAttachmentHandler)
+			r.Get("/attachments/{attachmentId}", GetAttachmentContentHandler)
 		})
 
 		// Session prompts endpoint
 		r.Get("/sessions/{id}/prompts", GetSessionPrompts)
 		r.Get("/sessions/{id}/prompts/raw", GetSessionPromptsRawText)
+		r.Get("/prompt-logging", RequireAdmin(GetPromptLoggingHandler))
+		r.Put("/prompt-logging", RequireAdmin(UpdatePromptLoggingHandler))
+		r.Put("/sessions/{id}/prompt-logging", UpdateSessionPromptLoggingHandler)
 
 		// MCP (Model Context Protocol) endpoint
 		r.Post("/mcp", MCPHTTPHandler)
+		r.Get("/mcp/ws", MCPWebSocketHandler)
 
 		// Messages
 		r.Post("/messages", CreateMessageHandler)
@@ -69,9 +110,20 @@ func NewRouter() *chi.Mux {
 		// Phase handlers for database-driven workflow
 		r.Get("/phases", GetPhasesHandler)
 		r.Get("/phases/{id}", GetPhaseHandler)
+		r.Post("/phases/transition", TransitionPhaseHandler)
 		r.Put("/phases/{id}", UpdatePhaseHandler)
 		r.Get("/phases/{id}/requirements", GetPhaseRequirementsHandler)
 		r.Get("/phases/{id}/tools", GetPhaseToolsHandler)
+		r.Get("/phases/{id}/effective-prompt", GetEffectivePromptHandler)
+
+		// Phase transition editing for the Workflow Studio; the graph is shared by every organization
+		r.Post("/transitions", RequireAdmin(CreateTransitionHandler))
+		r.Put("/transitions/{id}", RequireAdmin(UpdateTransitionHandler))
+		r.Delete("/transitions/{id}", RequireAdmin(DeleteTransitionHandler))
+
+		// Intake scoring rubric, shared by every organization
+		r.Get("/intake/rubric", GetIntakeRubricHandler)
+		r.Put("/intake/rubric", RequireAdmin(UpdateIntakeRubricHandler))
 
 		// Workflow Studio endpoints
 		r.Get("/phase-data", GetAllPhaseDataHandler)
@@ -79,8 +131,10 @@ func NewRouter() *chi.Mux {
 
 		// Prompt management with versioning
 		r.Get("/workflow/prompts", GetWorkflowPromptsHandler)
+		r.Get("/workflow/diagnostics", GetWorkflowDiagnosticsHandler)
 		r.Post("/prompts", CreatePromptHandler)
 		r.Put("/prompts/{id}", UpdatePromptHandler)
+		r.Put("/prompts/{id}/primary", SetPrimaryPromptHandler)
 		r.Get("/prompts/history/{phaseId}", GetPromptHistoryHandler)
 		r.Put("/prompts/{id}/revert/{versionId}", RevertPromptVersionHandler)
 
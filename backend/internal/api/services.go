@@ -1,16 +1,66 @@
 package api
 
 import (
+	"context"
+	"time"
+
+	"therapy-navigation-system/internal/config"
+	"therapy-navigation-system/internal/repository"
 	"therapy-navigation-system/internal/services"
+	"therapy-navigation-system/shared"
 )
 
 // ServiceContainer holds all services used by handlers
 type ServiceContainer struct {
+	Config            *config.Config
 	GeminiService     *services.GeminiService
 	MonitoringService *services.MonitoringService
+	AttachmentStore   services.AttachmentStore
 }
 
 // Global service container (initialized at startup)
 var Services *ServiceContainer
 
+// assistantInitError records why the AI assistant failed to initialize, for the health check
+var assistantInitError error
+
+// assistantAvailable reports whether the AI assistant can generate responses
+func assistantAvailable() bool {
+	return Services != nil && Services.GeminiService != nil
+}
+
+// coachResponder generates the coach's reply to a turn
+type coachResponder interface {
+	GenerateResponse(ctx context.Context, sessionID string, userMessage string, currentPhase string) (*services.CoachResponse, error)
+	GenerateResponseStream(ctx context.Context, sessionID string, userMessage string, currentPhase string, onText func(text string)) (*services.CoachResponse, error)
+}
+
+// newCoachResponder builds a turn's coach on the Gemini service; tests replace it
+var newCoachResponder = func() coachResponder {
+	return services.NewCoachService(Services.GeminiService)
+}
+
+// intakeExtractor finds uncollected intake fields in a session's transcript
+type intakeExtractor interface {
+	ExtractIntake(ctx context.Context, sessionID string, fields []repository.IntakeFieldCompletion) (map[string]string, error)
+}
+
+// newIntakeExtractor builds the bulk extraction's extractor on the Gemini service; tests replace it
+var newIntakeExtractor = func() intakeExtractor {
+	return Services.GeminiService
+}
+
+// broadcastServiceUnavailable tells the client the assistant can't respond right now, instead
+// of leaving their message unanswered
+func broadcastServiceUnavailable(sessionID string) {
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type: shared.MessageTypeServiceUnavailable,
+		Metadata: map[string]interface{}{
+			"service": "assistant",
+			"message": "The assistant is temporarily unavailable. Your message was saved - please try again shortly.",
+		},
+		Timestamp: time.Now(),
+	})
+}
+
 // InitializeServices is defined in services_init.go
\ No newline at end of file
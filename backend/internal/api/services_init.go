@@ -2,10 +2,15 @@ package api
 
 import (
 	"fmt"
+	"strings"
 	"therapy-navigation-system/internal/config"
+	contextbuilder "therapy-navigation-system/internal/context"
 	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/mcp"
 	"therapy-navigation-system/internal/repository"
 	"therapy-navigation-system/internal/services"
+	"therapy-navigation-system/internal/state"
+	"therapy-navigation-system/internal/tokenizer"
 	"therapy-navigation-system/shared"
 	"time"
 )
@@ -17,6 +22,7 @@ func InitializeServices(cfg *config.Config) error {
 	// Initialize Gemini service with new Google GenAI SDK
 	geminiService, err := services.NewGeminiService(cfg)
 	if err != nil {
+		assistantInitError = err
 		return fmt.Errorf("failed to initialize Gemini service: %w", err)
 	}
 
@@ -25,6 +31,7 @@ func InitializeServices(cfg *config.Config) error {
 
 	// Create service container
 	Services = &ServiceContainer{
+		Config:            cfg,
 		GeminiService:     geminiService,
 		MonitoringService: monitoringService,
 	}
@@ -64,6 +71,11 @@ func InitializeServices(cfg *config.Config) error {
 
 				broadcastSessionUpdate(sid, update)
 
+				// Collected data changes intake completeness
+				if typ == "workflow_update" {
+					refreshIntakeMetrics(sid)
+				}
+
 				// Reset phase timer on phase transitions
 				if typ == "phase_transition" {
 					// Reset phase accumulated time for this session
@@ -78,6 +90,10 @@ func InitializeServices(cfg *config.Config) error {
 					phaseStartMutex.Unlock()
 
 					logger.AppLogger.WithField("session_id", sid).Info("✅ Reset phase timer after auto-transition")
+
+					// Let the client show what's coming next
+					broadcastPhasePreview(sid)
+					broadcastPhaseVisualization(sid)
 				}
 			} else {
 				logger.AppLogger.WithField("event", ev).Debug("MCP event (no session routing)")
@@ -95,11 +111,109 @@ func InitializeServices(cfg *config.Config) error {
 		// Conductor system removed - no autonomous AI
 	}
 
+	// Load and validate the configured protocol definition. The database drives sessions;
+	// the protocol only fills phase timing it lacks.
+	protocolSource := mcp.ResolveProtocolSource(cfg.ProtocolSource, cfg.ProtocolPath)
+	if protocol, err := mcp.NewProtocolService(protocolSource, cfg.ProtocolPath); err != nil {
+		logger.AppLogger.WithError(err).WithField("source", protocolSource).Warn("⚠️ Failed to load protocol definition")
+	} else {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"protocol": protocol.GetProtocolID(),
+			"source":   protocolSource,
+			"phases":   len(protocol.GetAllPhases()),
+		}).Info("✅ Protocol definition loaded")
+
+		if cfg.ProtocolBackfill {
+			if updated, err := protocol.BackfillPhaseDefaults(repository.DB); err != nil {
+				logger.AppLogger.WithError(err).Warn("⚠️ Failed to backfill phase defaults from protocol")
+			} else if updated > 0 {
+				logger.AppLogger.WithField("phases", updated).Info("✅ Backfilled phase defaults from protocol")
+			}
+		}
+	}
+
+	// Hard context limit for prompt assembly, sized per model unless overridden
+	contextbuilder.SetContextWindowOverride(cfg.AIContextWindowTokens)
+	if !contextbuilder.KnownModel(cfg.CoachModel) {
+		logger.AppLogger.WithField("model", cfg.CoachModel).Warn("⚠️ COACH_MODEL is not a known coach model - using gemini-2.0-flash")
+	} else {
+		contextbuilder.SetDefaultModel(cfg.CoachModel)
+	}
+	contextbuilder.SetSUDSPromptCadence(cfg.SUDSPromptCadence)
+	contextbuilder.SetRepetitionThreshold(float64(cfg.RepetitionThreshold))
+	contextbuilder.SetSystemMessageMode(cfg.WorkingMemorySystemMessages)
+	contextbuilder.SetLongMessageHandling(cfg.LongMessageMode, cfg.LongMessageChars)
+	contextbuilder.SetToolResultFeedback(cfg.ToolResultFeedback)
+	contextbuilder.SetPhasePromptSelection(cfg.PhasePromptSelection)
+	contextbuilder.SetContextTokenBudget(cfg.ContextTokenBudget, cfg.ContextSectionShares)
+
+	// Count prompt tokens with a real BPE vocabulary
+	if err := tokenizer.Load(cfg.TokenizerVocab); err != nil {
+		logger.AppLogger.WithError(err).WithField("vocab", cfg.TokenizerVocab).Warn("⚠️ Tokenizer vocabulary unavailable - estimating 4 chars/token (run make tokenizer-vocab)")
+	} else {
+		logger.AppLogger.WithField("vocab", cfg.TokenizerVocab).Info("✅ Tokenizer vocabulary loaded")
+	}
+
+	// Reject data the model collects on the client's behalf
+	mcp.SetClientDataGuard(cfg.ClientDataGuardEnabled, cfg.ClientDataGuardWindow)
+	mcp.SetToolCallRetention(cfg.ToolCallRetention)
+	mcp.SetDisabledTools(strings.Split(cfg.MCPDisabledTools, ","))
+	mcp.SetConsolidatedBroadcasts(cfg.ConsolidateWorkflowBroadcasts)
+
+	// Server-side status-check decision criteria
+	state.SetStatusDecisionPolicy(cfg.StatusDecisionMode, cfg.StatusProcessingLimit)
+
+	// Minimum-duration constraints count the WebSocket timer's unpaused phase time
+	state.SetPhaseElapsedSource(livePhaseElapsed)
+
+	// Check the model's function-call arguments before executing them
+	services.SetToolArgValidation(cfg.ToolArgValidation)
+
+	// Pre-session plans are opt-in per protocol
+	services.SetSessionPlanProtocols(cfg.SessionPlanProtocols)
+
+	// Transient Gemini failures (429, 5xx, timeouts) are retried with backoff
+	services.SetGeminiRetry(cfg.GeminiRetryAttempts, cfg.GeminiRetryBaseDelay)
+
+	// Fallback coach reply length for phases without their own limit
+	services.SetDefaultMaxOutputTokens(cfg.AIMaxTokens)
+
+	// Full prompt logging; individual sessions can be enabled via the API
+	services.SetPromptContentLogging(cfg.PromptContentLogging)
+	services.SetPromptLogFile(cfg.PromptLogFile)
+	services.SetPromptLogMaxSize(int64(cfg.PromptLogMaxSizeMB) << 20)
+	services.SetTokenUsageAudit(cfg.TokenUsageAudit)
+
+	// Attachment storage is optional - uploads are refused without it
+	if store, err := services.NewAttachmentStore(cfg); err != nil {
+		logger.AppLogger.WithError(err).Warn("⚠️ Attachment storage unavailable - uploads disabled")
+	} else {
+		Services.AttachmentStore = store
+		logger.AppLogger.WithField("backend", store.Backend()).Info("✅ Attachment storage initialized")
+	}
+
 	// Set up metrics callbacks to avoid circular imports
 	services.SetMetricsCallbacks(
 		UpdateGeminiMetrics,
 		UpdateChromaDBMetrics,
+		RecordGeminiRetry,
 	)
+	repository.SetSessionStatusChangedCallback(RecordSessionStatusChange)
+
+	// Bulk extraction jobs don't survive a restart; leave them resumable
+	markInterruptedExtractionJobs()
+
+	// Session metrics reset on restart; start them from what the database already holds
+	if err := SeedSessionMetrics(); err != nil {
+		logger.AppLogger.WithError(err).Warn("Failed to seed session metrics")
+	}
+
+	// Sample connection pool usage so saturation shows up in metrics
+	if sqlDB, err := repository.DB.DB(); err == nil {
+		StartDatabasePoolMetrics(sqlDB, 15*time.Second)
+	} else {
+		logger.AppLogger.WithError(err).Warn("Failed to access database pool for metrics")
+	}
 
 	// Start background embedding processor
 	// embeddingProcessor.Start()
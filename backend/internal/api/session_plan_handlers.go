@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/services"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// SessionPlanReviewRequest approves or rejects a proposed plan, optionally adjusting its
+// phases first
+type SessionPlanReviewRequest struct {
+	Status string                    `json:"status"` // approved or rejected
+	Phases []repository.PlannedPhase `json:"phases,omitempty"`
+}
+
+// GetSessionPlanHandler returns the session's plan
+// @Summary Get session plan
+// @Description The proposed or reviewed plan for a session
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} repository.SessionPlan
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/plan [get]
+func GetSessionPlanHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id", "plan").First(&session, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	plan, err := repository.ParseSessionPlan(session.Plan)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to read session plan")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to read session plan"})
+		return
+	}
+	if plan == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session has no plan"})
+		return
+	}
+
+	render.JSON(w, r, plan)
+}
+
+// GenerateSessionPlanHandler proposes a plan for a session that hasn't started
+// @Summary Generate session plan
+// @Description Propose phases, durations and focus areas from the client's intake and history. Replaces any earlier plan; it applies only once approved.
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} repository.SessionPlan
+// @Failure 409 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/sessions/{sessionId}/plan [post]
+func GenerateSessionPlanHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id", "status", "template_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	protocol := repository.SessionProtocol(repository.DB, &session)
+	if !services.SessionPlanEnabled(protocol) {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Session plans are not enabled for protocol " + protocol})
+		return
+	}
+	if session.Status != repository.SessionStatusScheduled {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Session has already started"})
+		return
+	}
+	if !assistantAvailable() {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, map[string]string{"error": "The assistant is unavailable"})
+		return
+	}
+
+	plan, err := Services.GeminiService.GenerateSessionPlan(r.Context(), sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to generate session plan")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to generate session plan"})
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"phases":     len(plan.Phases),
+	}).Info("🗺️ Session plan proposed")
+
+	render.JSON(w, r, plan)
+}
+
+// ReviewSessionPlanHandler records the therapist's decision on a proposed plan
+// @Summary Review session plan
+// @Description Approve or reject the plan before the session starts. Phases, when given, replace the proposed ones. An approved plan's durations override the phases' timed durations for this session.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param request body SessionPlanReviewRequest true "Review decision"
+// @Success 200 {object} repository.SessionPlan
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/sessions/{sessionId}/plan [put]
+func ReviewSessionPlanHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var req SessionPlanReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if req.Status != repository.PlanApproved && req.Status != repository.PlanRejected {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "status must be approved or rejected"})
+		return
+	}
+
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id", "status", "plan").First(&session, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	plan, err := repository.ParseSessionPlan(session.Plan)
+	if err != nil || plan == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session has no plan"})
+		return
+	}
+	if session.Status != repository.SessionStatusScheduled {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": "Session has already started"})
+		return
+	}
+
+	if req.Phases != nil {
+		for _, phase := range req.Phases {
+			if phase.DurationSeconds < 0 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, map[string]string{"error": "duration_seconds must not be negative"})
+				return
+			}
+		}
+		plan.Phases = req.Phases
+	}
+	now := time.Now()
+	plan.Status = req.Status
+	plan.ReviewedAt = &now
+
+	if err := repository.SaveSessionPlan(repository.DB, sessionID, plan); err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to save session plan")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to save session plan"})
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"status":     plan.Status,
+	}).Info("🗺️ Session plan reviewed")
+
+	render.JSON(w, r, plan)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/state"
+	"therapy-navigation-system/shared"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// SessionStatusRequest changes a session's lifecycle status
+type SessionStatusRequest struct {
+	Status string `json:"status"` // active, completed or cancelled
+	Reason string `json:"reason,omitempty"`
+}
+
+// UpdateSessionStatusHandler moves a session through its lifecycle
+// @Summary Update session status
+// @Description Change a session's status. Allowed: scheduled→active→completed, and scheduled or active→cancelled. Completing requires the session to be in a terminal phase with its requirements met.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param request body SessionStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]string
+// @Router /api/sessions/{sessionId}/status [put]
+func UpdateSessionStatusHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	var req SessionStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	// Completion goes through the state machine so the terminal phase's requirements are checked
+	var err error
+	if req.Status == repository.SessionStatusCompleted {
+		err = state.New(sessionID).CompleteSession()
+	} else {
+		err = repository.ChangeSessionStatus(repository.DB, sessionID, req.Status, req.Reason)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidStatusTransition) || errors.Is(err, state.ErrCannotCompleteSession) {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]string{"error": err.Error()})
+			return
+		}
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to update session status")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to update session status"})
+		return
+	}
+
+	// A finished session's clock stops even while clients are still connected
+	if req.Status == repository.SessionStatusCompleted || req.Status == repository.SessionStatusCancelled {
+		stopSessionTimer(sessionID)
+	}
+
+	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		Type:          "session_status",
+		SessionStatus: req.Status,
+		Timestamp:     time.Now(),
+	})
+
+	render.JSON(w, r, map[string]interface{}{
+		"session_id": sessionID,
+		"status":     req.Status,
+	})
+}
+
+// GetCompletionReadinessHandler reports whether the session could be completed now
+// @Summary Get session completion readiness
+// @Description Runs the completion checks for the terminal phase without completing the session, listing missing completion fields and every other blocker
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} state.CompletionReadiness
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/completion-readiness [get]
+func GetCompletionReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	readiness, err := state.New(sessionID).CompletionReadiness()
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to check completion readiness")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to check completion readiness"})
+		return
+	}
+
+	render.JSON(w, r, readiness)
+}
+
+// activateSession marks a scheduled session active; the status change counts it in the
+// active gauge once
+func activateSession(sessionID string, reason string) {
+	if Services != nil && Services.Config != nil && !Services.Config.AutoActivateSessions {
+		return
+	}
+	changed, err := repository.ActivateSession(repository.DB, sessionID, reason)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to mark session active")
+		return
+	}
+	if changed {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"session_id": sessionID,
+			"reason":     reason,
+		}).Info("▶️ Session marked active")
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCompletingThroughTheStatusEndpointChecksRequirementsAndStopsTheTimer(t *testing.T) {
+	db := newTestEnv(t)
+	if err := db.AutoMigrate(&repository.PhaseConstraint{}); err != nil {
+		t.Fatalf("failed to migrate phase constraints: %v", err)
+	}
+	for _, phase := range []repository.Phase{
+		{ID: "status_check", DisplayName: "Status Check", Position: 1},
+		{ID: "complete", DisplayName: "Complete", Position: 2, IsTerminal: true},
+	} {
+		if err := db.Create(&phase).Error; err != nil {
+			t.Fatalf("failed to create phase: %v", err)
+		}
+	}
+	if err := db.Create(&repository.PhaseData{
+		ID: "complete_final_suds", PhaseID: "complete", Name: "final_suds", Requirement: repository.PhaseDataRequired,
+	}).Error; err != nil {
+		t.Fatalf("failed to create phase data: %v", err)
+	}
+	session := createTestSession(t, db, "status_check")
+	if err := db.Model(session).Update("status", repository.SessionStatusActive).Error; err != nil {
+		t.Fatalf("failed to activate session: %v", err)
+	}
+	for _, role := range []string{"client", "coach"} {
+		if err := db.Create(&repository.Message{SessionID: session.ID, Role: role, Content: "hello"}).Error; err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Put("/api/sessions/{sessionId}/status", UpdateSessionStatusHandler)
+	complete := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/sessions/"+session.ID+"/status",
+			strings.NewReader(`{"status":"completed"}`)))
+		return rec
+	}
+	status := func() string {
+		var stored repository.Session
+		db.Select("status").First(&stored, "id = ?", session.ID)
+		return stored.Status
+	}
+
+	go startSessionTimer(session.ID, time.Now(), time.Now())
+	t.Cleanup(func() {
+		stopSessionTimer(session.ID)
+		waitForTimerExit(t, session.ID)
+	})
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, exists := runningTimer(session.ID); exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("session timer never started")
+		}
+	}
+
+	if rec := complete(); rec.Code != http.StatusConflict || status() != repository.SessionStatusActive {
+		t.Errorf("completing outside the terminal phase: status %d, session %s; want 409 and still active", rec.Code, status())
+	}
+
+	db.Model(session).Update("phase", "complete")
+	if rec := complete(); rec.Code != http.StatusConflict || status() != repository.SessionStatusActive {
+		t.Errorf("completing without final_suds: status %d, session %s; want 409 and still active", rec.Code, status())
+	}
+	if _, exists := runningTimer(session.ID); !exists {
+		t.Error("a rejected completion stopped the session timer")
+	}
+
+	if err := db.Create(&repository.SessionFieldValue{
+		SessionID: session.ID, PhaseID: "complete", FieldName: "final_suds", FieldValue: "1", FieldType: "integer",
+	}).Error; err != nil {
+		t.Fatalf("failed to store final_suds: %v", err)
+	}
+	if rec := complete(); rec.Code != http.StatusOK || status() != repository.SessionStatusCompleted {
+		t.Fatalf("completing with requirements met: status %d, session %s: %s", rec.Code, status(), rec.Body.String())
+	}
+	waitForTimerExit(t, session.ID)
+}
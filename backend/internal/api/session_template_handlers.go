@@ -0,0 +1,324 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"gorm.io/gorm"
+)
+
+// SessionTemplateRequest represents the request body for creating or updating a session template
+type SessionTemplateRequest struct {
+	Name        string          `json:"name"`
+	Protocol    string          `json:"protocol,omitempty"`
+	StartPhase  string          `json:"start_phase,omitempty"`
+	Locale      string          `json:"locale,omitempty"` // BCP 47 language tag, defaults to en
+	ModelConfig json.RawMessage `json:"model_config,omitempty"`
+	Notes       string          `json:"notes,omitempty"`
+	Features    []string        `json:"features,omitempty"`
+}
+
+// CreateSessionFromTemplateRequest represents the request body for instantiating a template
+type CreateSessionFromTemplateRequest struct {
+	ClientID  string `json:"client_id"`
+	StartTime string `json:"start_time,omitempty"` // RFC3339, defaults to now
+}
+
+// requestTherapist resolves the therapist making the request from the authenticated email.
+// Without Firebase (development) the therapist is named by the therapist_id query param.
+func requestTherapist(r *http.Request) (*repository.Therapist, error) {
+	db := repository.Scoped(r.Context())
+
+	var therapist repository.Therapist
+	if email, ok := r.Context().Value("user_email").(string); ok && email != "" {
+		if err := db.First(&therapist, "email = ?", email).Error; err != nil {
+			return nil, fmt.Errorf("no therapist for %s", email)
+		}
+		return &therapist, nil
+	}
+	if firebaseAuth == nil {
+		if therapistID := r.URL.Query().Get("therapist_id"); therapistID != "" {
+			if err := db.First(&therapist, "id = ?", therapistID).Error; err != nil {
+				return nil, fmt.Errorf("therapist %s not found", therapistID)
+			}
+			return &therapist, nil
+		}
+	}
+	return nil, fmt.Errorf("no therapist associated with this request")
+}
+
+// applySessionTemplateRequest validates req and copies it onto template
+func applySessionTemplateRequest(db *gorm.DB, template *repository.SessionTemplate, req SessionTemplateRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if req.StartPhase != "" {
+		if err := db.Select("id").First(&repository.Phase{}, "id = ?", req.StartPhase).Error; err != nil {
+			return fmt.Errorf("unknown start phase %q", req.StartPhase)
+		}
+	}
+	locale, err := repository.NormalizeLocale(req.Locale)
+	if err != nil {
+		return err
+	}
+	if _, err := repository.ParseSessionModelConfig(string(req.ModelConfig)); err != nil {
+		return err
+	}
+	features, err := repository.EncodeSessionFeatures(req.Features)
+	if err != nil {
+		return err
+	}
+
+	template.Name = req.Name
+	template.Protocol = req.Protocol
+	template.StartPhase = req.StartPhase
+	template.Locale = locale
+	template.ModelConfig = string(req.ModelConfig)
+	template.Notes = req.Notes
+	template.Features = features
+	return nil
+}
+
+// findTemplate loads a template owned by the requesting therapist, writing the error response on failure
+func findTemplate(w http.ResponseWriter, r *http.Request) (*repository.SessionTemplate, bool) {
+	therapist, err := requestTherapist(r)
+	if err != nil {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+
+	var template repository.SessionTemplate
+	if err := repository.Scoped(r.Context()).
+		First(&template, "id = ? AND therapist_id = ?", chi.URLParam(r, "templateId"), therapist.ID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session template not found"})
+		return nil, false
+	}
+	return &template, true
+}
+
+// GetSessionTemplatesHandler lists the requesting therapist's session templates
+// @Summary List session templates
+// @Description List the session templates owned by the requesting therapist
+// @Tags session-templates
+// @Produce json
+// @Success 200 {array} repository.SessionTemplate
+// @Router /api/session-templates [get]
+func GetSessionTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	therapist, err := requestTherapist(r)
+	if err != nil {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var templates []repository.SessionTemplate
+	if err := repository.Scoped(r.Context()).
+		Where("therapist_id = ?", therapist.ID).
+		Order("name ASC").
+		Find(&templates).Error; err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to fetch session templates")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch session templates"})
+		return
+	}
+
+	render.JSON(w, r, templates)
+}
+
+// GetSessionTemplateHandler returns a single session template
+// @Summary Get session template
+// @Tags session-templates
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Success 200 {object} repository.SessionTemplate
+// @Router /api/session-templates/{templateId} [get]
+func GetSessionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	template, ok := findTemplate(w, r)
+	if !ok {
+		return
+	}
+	render.JSON(w, r, template)
+}
+
+// CreateSessionTemplateHandler creates a session template for the requesting therapist
+// @Summary Create session template
+// @Tags session-templates
+// @Accept json
+// @Produce json
+// @Param template body SessionTemplateRequest true "Session template"
+// @Success 201 {object} repository.SessionTemplate
+// @Router /api/session-templates [post]
+func CreateSessionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	therapist, err := requestTherapist(r)
+	if err != nil {
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req SessionTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	db := repository.Scoped(r.Context())
+	template := repository.SessionTemplate{TherapistID: therapist.ID}
+	if err := applySessionTemplateRequest(db, &template, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := db.Create(&template).Error; err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to create session template")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to create session template"})
+		return
+	}
+
+	logger.AppLogger.WithField("template_id", template.ID).Info("Session template created")
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, template)
+}
+
+// UpdateSessionTemplateHandler replaces a session template's parameters
+// @Summary Update session template
+// @Tags session-templates
+// @Accept json
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Param template body SessionTemplateRequest true "Session template"
+// @Success 200 {object} repository.SessionTemplate
+// @Router /api/session-templates/{templateId} [put]
+func UpdateSessionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	template, ok := findTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	var req SessionTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	db := repository.Scoped(r.Context())
+	if err := applySessionTemplateRequest(db, template, req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := db.Save(template).Error; err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to update session template")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to update session template"})
+		return
+	}
+
+	render.JSON(w, r, template)
+}
+
+// DeleteSessionTemplateHandler deletes a session template
+// @Summary Delete session template
+// @Tags session-templates
+// @Param templateId path string true "Template ID"
+// @Success 204
+// @Router /api/session-templates/{templateId} [delete]
+func DeleteSessionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	template, ok := findTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := repository.Scoped(r.Context()).Delete(template).Error; err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to delete session template")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to delete session template"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateSessionFromTemplateHandler instantiates a new session from a template
+// @Summary Create session from template
+// @Description Create a session for a client using a saved template's protocol, start phase, locale, notes, feature flags and model settings
+// @Tags session-templates
+// @Accept json
+// @Produce json
+// @Param templateId path string true "Template ID"
+// @Param session body CreateSessionFromTemplateRequest true "Client and start time"
+// @Success 201 {object} repository.Session
+// @Router /api/sessions/from-template/{templateId} [post]
+func CreateSessionFromTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	template, ok := findTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateSessionFromTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	startTime := time.Now()
+	if req.StartTime != "" {
+		parsed, err := time.Parse(time.RFC3339, req.StartTime)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid start time format"})
+			return
+		}
+		startTime = parsed
+	}
+
+	db := repository.Scoped(r.Context())
+	if err := db.Select("id").First(&repository.Client{}, "id = ?", req.ClientID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Client not found"})
+		return
+	}
+
+	session := repository.Session{
+		ClientID:    req.ClientID,
+		TherapistID: template.TherapistID,
+		Status:      repository.SessionStatusScheduled,
+		Phase:       template.StartPhase,
+		StartTime:   startTime,
+		Notes:       template.Notes,
+		Features:    template.Features,
+		TemplateID:  template.ID,
+		Locale:      template.Locale,
+	}
+	if session.Phase == "" {
+		session.Phase = "pre_session"
+	}
+
+	if err := createSession(db, &session); err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to create session from template")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to create session"})
+		return
+	}
+
+	db.Preload("Client").Preload("Therapist").First(&session, "id = ?", session.ID)
+
+	logger.AppLogger.WithField("session_id", session.ID).WithField("template_id", template.ID).Info("Session created from template")
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, session)
+}
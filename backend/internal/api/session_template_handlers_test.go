@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSessionFromTemplateCarriesTheTemplateLocale(t *testing.T) {
+	db := newTestEnv(t)
+	if err := db.AutoMigrate(&repository.SessionTemplate{}); err != nil {
+		t.Fatalf("failed to migrate templates: %v", err)
+	}
+	therapist := repository.Therapist{Name: "Dr. Rivera", Email: "rivera@example.com"}
+	client := repository.Client{Name: "Ana"}
+	for _, row := range []interface{}{&therapist, &client} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", row, err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Post("/api/session-templates", CreateSessionTemplateHandler)
+	router.Post("/api/sessions/from-template/{templateId}", CreateSessionFromTemplateHandler)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path+"?therapist_id="+therapist.ID, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := post("/api/session-templates", `{"name":"Spanish intake","locale":"xx-invalid-tag-"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid locale: status = %d, want 400: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := post("/api/session-templates", `{"name":"Spanish intake","locale":"es-mx"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create template: status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var template repository.SessionTemplate
+	json.Unmarshal(rec.Body.Bytes(), &template)
+	if template.Locale != "es-MX" {
+		t.Errorf("template locale = %q, want the canonical es-MX", template.Locale)
+	}
+
+	rec = post("/api/sessions/from-template/"+template.ID, `{"client_id":"`+client.ID+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create session: status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created repository.Session
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	var stored repository.Session
+	if err := db.First(&stored, "id = ?", created.ID).Error; err != nil {
+		t.Fatalf("session not stored: %v", err)
+	}
+	if stored.Locale != "es-MX" || stored.TemplateID != template.ID {
+		t.Errorf("session locale = %q from template %q, want es-MX from %s", stored.Locale, stored.TemplateID, template.ID)
+	}
+}
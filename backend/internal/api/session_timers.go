@@ -0,0 +1,86 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+)
+
+// sessionTimer is the lifecycle of one session's timer goroutine. It stays registered in
+// sessionTimers until the goroutine has exited, so there is never more than one per session.
+type sessionTimer struct {
+	stop     chan struct{} // Closed to ask the goroutine to exit
+	done     chan struct{} // Closed once it has exited
+	stopOnce sync.Once
+}
+
+// Stop asks the timer goroutine to exit; safe to call any number of times from anywhere
+func (t *sessionTimer) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
+
+func (t *sessionTimer) stopping() bool {
+	select {
+	case <-t.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// timerRestartWait returns how long a timer start waits for a stopping timer to exit
+func timerRestartWait() time.Duration {
+	if Services != nil && Services.Config != nil && Services.Config.TimerRestartWait > 0 {
+		return Services.Config.TimerRestartWait
+	}
+	return 5 * time.Second
+}
+
+// claimSessionTimer registers a new timer for the session, or returns false when one is
+// already running. A timer still shutting down (a reconnect right after a disconnect) is
+// waited out so the restart isn't lost.
+func claimSessionTimer(sessionID string) (*sessionTimer, bool) {
+	for {
+		sessionTimerMutex.Lock()
+		existing, exists := sessionTimers[sessionID]
+		if !exists {
+			timer := &sessionTimer{stop: make(chan struct{}), done: make(chan struct{})}
+			sessionTimers[sessionID] = timer
+			sessionTimerMutex.Unlock()
+			return timer, true
+		}
+		sessionTimerMutex.Unlock()
+
+		if !existing.stopping() {
+			return nil, false
+		}
+		select {
+		case <-existing.done:
+		case <-time.After(timerRestartWait()):
+			logger.AppLogger.WithField("session_id", sessionID).Error("⏱️ Previous session timer didn't exit - not starting another")
+			return nil, false
+		}
+	}
+}
+
+// releaseSessionTimer unregisters an exited timer, unless a newer one has replaced it
+func releaseSessionTimer(sessionID string, timer *sessionTimer) {
+	sessionTimerMutex.Lock()
+	if sessionTimers[sessionID] == timer {
+		delete(sessionTimers, sessionID)
+	}
+	sessionTimerMutex.Unlock()
+	close(timer.done)
+}
+
+// stopSessionTimer stops the timer for a session, if one is running
+func stopSessionTimer(sessionID string) {
+	sessionTimerMutex.RLock()
+	timer, exists := sessionTimers[sessionID]
+	sessionTimerMutex.RUnlock()
+
+	if exists {
+		timer.Stop()
+	}
+}
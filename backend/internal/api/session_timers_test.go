@@ -0,0 +1,103 @@
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// runningTimer returns the session's registered timer, if any
+func runningTimer(sessionID string) (*sessionTimer, bool) {
+	sessionTimerMutex.RLock()
+	defer sessionTimerMutex.RUnlock()
+	timer, exists := sessionTimers[sessionID]
+	return timer, exists
+}
+
+// waitForTimerExit waits for the session's timer goroutine to exit and unregister
+func waitForTimerExit(t *testing.T, sessionID string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, exists := runningTimer(sessionID); !exists {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("session timer for %s never exited", sessionID)
+}
+
+func TestConcurrentSessionTimerStartsRunOneTimer(t *testing.T) {
+	db := newTestEnv(t)
+	session := createTestSession(t, db, "intake")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			startSessionTimer(session.ID, time.Now(), time.Now())
+		}()
+	}
+
+	// Every start but one returns straight away; the one left is the running timer
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if _, exists := runningTimer(session.ID); !exists {
+		t.Fatal("expected a running session timer")
+	}
+	select {
+	case <-finished:
+		t.Fatal("expected one start to still be running its timer")
+	default:
+	}
+
+	stopSessionTimer(session.ID)
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("session timer didn't stop")
+	}
+	waitForTimerExit(t, session.ID)
+}
+
+func TestConcurrentSessionTimerStartStopLeavesNoTimer(t *testing.T) {
+	db := newTestEnv(t)
+	session := createTestSession(t, db, "intake")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			startSessionTimer(session.ID, time.Now(), time.Now())
+		}()
+		go func() {
+			defer wg.Done()
+			stopSessionTimer(session.ID)
+		}()
+	}
+
+	// A start that raced past every stop may still be running; stop it until all have returned
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	deadline := time.After(10 * time.Second)
+	for {
+		stopSessionTimer(session.ID)
+		select {
+		case <-finished:
+			waitForTimerExit(t, session.ID)
+			return
+		case <-deadline:
+			t.Fatal("session timer starts and stops didn't all return")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -25,7 +26,8 @@ func int64Ptr(i int64) *int64 {
 	return &i
 }
 
-// Converter functions to map GORM repository types to shared WebSocket types
+// Converter functions to map GORM repository types to shared WebSocket types.
+// Phase data comes from the preloaded PhaseData relation (empty when not preloaded).
 func convertPhases(repoPhases []repository.Phase) []shared.Phase {
 	phases := make([]shared.Phase, len(repoPhases))
 	for i, p := range repoPhases {
@@ -35,7 +37,9 @@ func convertPhases(repoPhases []repository.Phase) []shared.Phase {
 			Description: p.Description,
 			Color:       p.Color,
 			Icon:        p.Icon,
-			PhaseData:   convertPhaseData(nil), // Will be populated separately if needed
+			PhaseData:   convertPhaseData(p.PhaseData),
+
+			Visualization: phaseVisualization(p),
 		}
 	}
 	return phases
@@ -58,7 +62,7 @@ func convertPhaseData(repoData []repository.PhaseData) []shared.PhaseDataField {
 		fields[i] = shared.PhaseDataField{
 			Name:        pd.Name,
 			Description: pd.Description,
-			Required:    pd.Required,
+			Required:    pd.IsRequired(),
 			DataType:    dataType,
 		}
 	}
@@ -69,14 +73,15 @@ func convertMessages(repoMessages []repository.Message) []shared.Message {
 	messages := make([]shared.Message, len(repoMessages))
 	for i, m := range repoMessages {
 		messages[i] = shared.Message{
-			ID:          m.ID,
-			SessionID:   m.SessionID,
-			Content:     m.Content,
-			Role:        m.Role,
-			MessageType: m.MessageType,
-			Metadata:    m.Metadata,
-			CreatedAt:   m.CreatedAt,
-			UpdatedAt:   m.UpdatedAt,
+			ID:            m.ID,
+			SessionID:     m.SessionID,
+			Content:       m.Content,
+			Role:          m.Role,
+			MessageType:   m.MessageType,
+			Metadata:      m.Metadata,
+			ParticipantID: m.ParticipantID,
+			CreatedAt:     m.CreatedAt,
+			UpdatedAt:     m.UpdatedAt,
 		}
 	}
 	return messages
@@ -87,28 +92,43 @@ func convertMessage(m *repository.Message) *shared.Message {
 		return nil
 	}
 	return &shared.Message{
-		ID:          m.ID,
-		SessionID:   m.SessionID,
-		Content:     m.Content,
-		Role:        m.Role,
-		MessageType: m.MessageType,
-		Metadata:    m.Metadata,
-		CreatedAt:   m.CreatedAt,
-		UpdatedAt:   m.UpdatedAt,
+		ID:            m.ID,
+		SessionID:     m.SessionID,
+		Content:       m.Content,
+		Role:          m.Role,
+		MessageType:   m.MessageType,
+		Metadata:      m.Metadata,
+		ParticipantID: m.ParticipantID,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
 	}
 }
 
 // MCP client for WebSocket tool execution
 // safeConn wraps websocket.Conn with a mutex for thread-safe writes
 type safeConn struct {
-	conn *websocket.Conn
-	mu   sync.Mutex
+	conn            *websocket.Conn
+	mu              sync.Mutex
+	closed          bool   // Set once the connection is closed or a write fails; guarded by mu
+	protocolVersion int    // Negotiated WebSocket protocol version for this client
+	participantRole string // Role of the participant the socket is bound to; empty when unbound
 }
 
+// errConnClosed is returned by writes to a connection that has already closed
+var errConnClosed = errors.New("websocket connection closed")
+
 func (s *safeConn) WriteJSON(v interface{}) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.conn.WriteJSON(v)
+	if s.closed {
+		return errConnClosed
+	}
+	if err := s.conn.WriteJSON(v); err != nil {
+		// A failed write leaves the connection unusable; later writes fail fast
+		s.closed = true
+		return err
+	}
+	return nil
 }
 
 func (s *safeConn) ReadMessage() (messageType int, p []byte, err error) {
@@ -118,9 +138,58 @@ func (s *safeConn) ReadMessage() (messageType int, p []byte, err error) {
 func (s *safeConn) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
 	return s.conn.Close()
 }
 
+// addSessionConnection registers one of a session's connections - each participant (client,
+// therapist, supervisor, ...) has their own. first reports whether no other was connected.
+func addSessionConnection(sessionID string, conn *safeConn) (first bool) {
+	sessionConnMutex.Lock()
+	defer sessionConnMutex.Unlock()
+	conns := sessionConnections[sessionID]
+	if conns == nil {
+		conns = make(map[*safeConn]struct{})
+		sessionConnections[sessionID] = conns
+	}
+	conns[conn] = struct{}{}
+	return len(conns) == 1
+}
+
+// removeSessionConnection drops one of a session's connections. last reports whether it was
+// the session's last, i.e. nobody is connected any more.
+func removeSessionConnection(sessionID string, conn *safeConn) (last bool) {
+	sessionConnMutex.Lock()
+	defer sessionConnMutex.Unlock()
+	conns, ok := sessionConnections[sessionID]
+	if !ok {
+		return false
+	}
+	if _, ok := conns[conn]; !ok {
+		return false
+	}
+	delete(conns, conn)
+	if len(conns) > 0 {
+		return false
+	}
+	delete(sessionConnections, sessionID)
+	return true
+}
+
+// sessionConnectionList returns a snapshot of a session's connections
+func sessionConnectionList(sessionID string) []*safeConn {
+	sessionConnMutex.RLock()
+	defer sessionConnMutex.RUnlock()
+	conns := make([]*safeConn, 0, len(sessionConnections[sessionID]))
+	for conn := range sessionConnections[sessionID] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
 var wsMCPClient *mcp.MCPClient
 
 func getWSMCPClient() *mcp.MCPClient {
@@ -139,8 +208,9 @@ var (
 		EnableCompression: false,
 	}
 
-	// WebSocket connection with mutex for thread-safe writes
-	sessionConnections = make(map[string]*safeConn)
+	// WebSocket connections with mutex for thread-safe writes; a session has one per
+	// connected participant
+	sessionConnections = make(map[string]map[*safeConn]struct{})
 	sessionConnMutex   sync.RWMutex
 
 	// Track active conversations to prevent duplicates
@@ -154,7 +224,7 @@ var (
 	sessionPausedMutex sync.RWMutex
 
 	// Track session timers
-	sessionTimers = make(map[string]chan bool)
+	sessionTimers = make(map[string]*sessionTimer)
 	sessionTimerMutex sync.RWMutex
 
 	// Track phase start times for phase duration
@@ -172,13 +242,29 @@ var (
 func SessionWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 
-	// Verify session exists
+	// Verify session exists in the caller's organization
 	var session repository.Session
-	if err := repository.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+	if err := repository.Scoped(r.Context()).First(&session, "id = ?", sessionID).Error; err != nil {
 		http.Error(w, "Session not found", http.StatusNotFound)
 		return
 	}
 
+	// Bind the socket to the authenticated user's place in the session; its messages can
+	// only speak as that participant
+	participant, err := requestParticipant(r, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Rejecting WebSocket from someone who isn't a session participant")
+		http.Error(w, "Forbidden: not a participant in this session", http.StatusForbidden)
+		return
+	}
+
+	// Messages are handled after the handshake returns; keep its organization for their queries
+	connCtx := repository.WithOrganization(context.Background(), repository.OrganizationFromContext(r.Context()))
+	connCtx = withSocketParticipant(connCtx, participant)
+
+	// Negotiate protocol version before upgrading
+	protocolVersion, protocolErr := negotiateProtocolVersion(r)
+
 	// Upgrade connection
 	conn, err := sessionWebSocketUpgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -187,142 +273,77 @@ func SessionWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	// Store connection with thread-safe wrapper
-	sessionConnMutex.Lock()
-	sessionConnections[sessionID] = &safeConn{conn: conn}
-	sessionConnMutex.Unlock()
+	// Tell incompatible clients why before closing so they don't reconnect in a loop
+	if protocolErr != nil {
+		logger.AppLogger.WithError(protocolErr).WithField("session_id", sessionID).Warn("Rejecting WebSocket client with incompatible protocol version")
+		_ = conn.WriteJSON(shared.TherapySessionUpdate{
+			Type:            shared.MessageTypeProtocolIncompatible,
+			Metadata:        protocolHandshakeMetadata(0),
+			ProtocolVersion: shared.ProtocolVersion,
+			Timestamp:       time.Now(),
+		})
+		_ = conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseProtocolError, protocolErr.Error()),
+			time.Now().Add(time.Second))
+		return
+	}
+
+	// Store connection with thread-safe wrapper, alongside the other participants' connections
+	sc := &safeConn{conn: conn, protocolVersion: protocolVersion}
+	if participant != nil {
+		sc.participantRole = participant.Role
+	}
+	firstConnection := addSessionConnection(sessionID, sc)
 
 	defer func() {
-		sessionConnMutex.Lock()
-		delete(sessionConnections, sessionID)
-		sessionConnMutex.Unlock()
+		// Mark closed before unregistering so in-flight broadcasts fail fast
+		sc.Close()
 
-		// Stop the session timer
-		stopSessionTimer(sessionID)
+		// The session timer runs while anyone is connected
+		if removeSessionConnection(sessionID, sc) {
+			stopSessionTimer(sessionID)
+		}
 	}()
 
-	logger.AppLogger.WithField("session_id", sessionID).Info("WebSocket connection established")
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id":       sessionID,
+		"protocol_version": protocolVersion,
+	}).Info("WebSocket connection established")
 
 	// Send initial session state immediately to eliminate shimmer
 	go func() {
-		// Get session with current phase
-		var session repository.Session
-		if err := repository.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		initial, err := loadInitialState(connCtx, sessionID)
+		if err != nil {
 			logger.AppLogger.WithError(err).Error("Failed to get session for initial state")
 			return
 		}
+		session, currentPhase := initial.Session, initial.CurrentPhase
 
-		// Get current phase info
-		var currentPhase repository.Phase
-		if err := repository.DB.First(&currentPhase, "id = ?", session.Phase).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get current phase")
+		// Timed phases: include the remaining time so a reconnecting client resumes its countdown
+		var initialMetadata map[string]interface{}
+		if timer := phaseTimerStatus(sessionID, &session, &currentPhase); timer != nil {
+			initialMetadata = map[string]interface{}{"phase_timer": timer}
 		}
 
-		// Get phase data for current phase only
-		var phaseData []repository.PhaseData
-		if err := repository.DB.Where("phase_id = ?", session.Phase).Find(&phaseData).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get phase data")
-		}
-
-		// Get only transitions from current phase (what's possible next)
-		var availableTransitions []repository.PhaseTransition
-		if err := repository.DB.Where("from_phase_id = ?", session.Phase).Find(&availableTransitions).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get available transitions")
-		}
-
-		// Get all messages for session (enterprise chatbot experience)
-		var messages []repository.Message
-		if err := repository.DB.Where("session_id = ?", sessionID).
-			Order("created_at DESC").
-			Find(&messages).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get messages")
-		}
-
-		// Get phase data values from SessionFieldValue table
-		phaseDataValues := make(map[string]interface{})
-		// Get stored field values for this session
-		var storedValues []repository.SessionFieldValue
-		if err := repository.DB.Where("session_id = ?", sessionID).Find(&storedValues).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get stored field values")
-		}
-
-		// Map ALL stored values, not just current phase
-		for _, sv := range storedValues {
-			// Parse JSON value
-			var parsedValue interface{}
-			if err := json.Unmarshal([]byte(sv.FieldValue), &parsedValue); err != nil {
-				logger.AppLogger.WithError(err).WithField("field_value", sv.FieldValue).Error("Failed to parse stored field value as JSON")
-				continue // Skip invalid values
-			}
-			phaseDataValues[sv.FieldName] = parsedValue
-		}
-
-		// Also include null for current phase fields that don't have values yet
-		for _, pd := range phaseData {
-			if _, exists := phaseDataValues[pd.Name]; !exists {
-				phaseDataValues[pd.Name] = nil
-			}
-		}
-
-		// Log exactly what we're sending
-		logger.AppLogger.WithFields(map[string]interface{}{
-			"session_id": sessionID,
-			"phase_data_count": len(phaseData),
-			"phase_data": phaseData,
-			"phase_data_values": phaseDataValues,
-			"current_phase": currentPhase,
-		}).Info("📊 INITIAL STATE DATA")
-
-		// Get all phases with their phase_data for the complete schema
-		var allPhases []repository.Phase
-		if err := repository.DB.Order("position ASC").Find(&allPhases).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get all phases")
-		}
-
-		// Convert phases and attach phase_data to each
-		sharedPhases := make([]shared.Phase, len(allPhases))
-		for i, phase := range allPhases {
-			var phaseFields []repository.PhaseData
-			if err := repository.DB.Where("phase_id = ?", phase.ID).Find(&phaseFields).Error; err != nil {
-				logger.AppLogger.WithError(err).Error("Failed to get phase data for phase")
-			}
-			sharedPhases[i] = shared.Phase{
-				ID:          phase.ID,
-				DisplayName: phase.DisplayName,
-				Description: phase.Description,
-				Color:       phase.Color,
-				Icon:        phase.Icon,
-				PhaseData:   convertPhaseData(phaseFields),
-			}
-		}
-
-		// Send initial state - clean structure
-		broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
+		// Send initial state - clean structure; only the connecting client needs it
+		sendSessionUpdate(sessionID, sc, shared.TherapySessionUpdate{
 			Type:                 "initial_state",
 			Phase:                session.Phase,
 			SessionStatus:        session.Status,
-			PhaseDataValues:      phaseDataValues,
-			Phases:               sharedPhases,
-			RecentMessages:       convertMessages(messages),
+			PhaseDataValues:      initial.PhaseDataValues,
+			Phases:               initial.Phases,
+			RecentMessages:       convertMessages(initial.Messages),
+			Metadata:             initialMetadata,
 			Timestamp:            time.Now(),
 		})
 
 		logger.AppLogger.WithField("session_id", sessionID).Info("✅ Sent initial session state to eliminate shimmer")
 	}()
 
-	// Initialize MCP session state
-	mcpClient := getWSMCPClient()
-	if mcpClient != nil {
-		args := json.RawMessage(fmt.Sprintf(`{"session_id": "%s", "enabled": true}`, sessionID))
-		_, err := mcpClient.ToolsCall(context.Background(), "therapy_session_enable_auto_mode", args)
-		if err != nil {
-			logger.AppLogger.WithError(err).Warn("Failed to initialize MCP session state")
-		}
-	}
-
-	// Send initial status
-	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
-		Type:      "connected",
+	// Send initial status with the negotiated protocol so the client can detect an incompatible server
+	sendSessionUpdate(sessionID, sc, shared.TherapySessionUpdate{
+		Type:      shared.MessageTypeConnected,
+		Metadata:  protocolHandshakeMetadata(protocolVersion),
 		Timestamp: time.Now(),
 	})
 
@@ -357,13 +378,23 @@ func SessionWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	sessionLastActivity[sessionID] = time.Now()
 	sessionActivityMutex.Unlock()
 
-	// Start auto-pause monitor
-	go monitorSessionActivity(sessionID)
+	// Start auto-pause monitor; one per session, running until the last connection leaves
+	if firstConnection {
+		go monitorSessionActivity(sessionID)
+	}
+
+	// A session paused before the disconnect stays paused
+	restorePauseState(&session)
+
+	// Reconnecting to a session already past pre-session means it's under way
+	if session.Phase != "pre_session" {
+		activateSession(sessionID, "connected after pre-session")
+	}
 
 	// Only start session timer if not in pre-session phase
 	// Timer should be managed by state machine when transitioning out of pre-session
 	if session.Phase != "pre_session" {
-		go startSessionTimer(sessionID, session.StartTime)
+		go startSessionTimer(sessionID, session.StartTime, session.PhaseStartTime)
 	}
 
 	// Handle incoming messages
@@ -386,9 +417,7 @@ func SessionWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 
 		if isPaused {
 			// If paused and receiving message, unpause
-			sessionPausedMutex.Lock()
-			sessionPaused[sessionID] = false
-			sessionPausedMutex.Unlock()
+			setSessionPaused(sessionID, false, repository.PauseReasonClientMessage, socketParticipant(connCtx))
 
 			broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
 				Type:      "session_resumed",
@@ -397,47 +426,44 @@ func SessionWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Process the message
-		go handlePatientMessage(sessionID, messageData)
+		go handlePatientMessage(connCtx, sessionID, messageData)
 	}
 }
 
-// startSessionTimer sends timer updates every second via WebSocket
-func startSessionTimer(sessionID string, startTime time.Time) {
-	// Check if timer already exists
-	sessionTimerMutex.RLock()
-	if _, exists := sessionTimers[sessionID]; exists {
-		sessionTimerMutex.RUnlock()
+// startSessionTimer sends timer updates every second via WebSocket. Only one timer runs per
+// session: a start while one is running returns immediately.
+// phaseStartTime is the persisted phase start, so a restart after reconnect resumes the phase clock.
+func startSessionTimer(sessionID string, startTime time.Time, phaseStartTime time.Time) {
+	timer, claimed := claimSessionTimer(sessionID)
+	if !claimed {
 		return
 	}
-	sessionTimerMutex.RUnlock()
-
-	// Create stop channel
-	stopChan := make(chan bool, 1)
-	sessionTimerMutex.Lock()
-	sessionTimers[sessionID] = stopChan
-	sessionTimerMutex.Unlock()
+	defer releaseSessionTimer(sessionID, timer)
 
 	// Initialize tracking
 	phaseStartMutex.Lock()
 	phaseStartTimes[sessionID] = startTime
 	phaseStartMutex.Unlock()
 
+	// Resume from the persisted time rather than zero
+	persistedSession, persistedPhase := loadTimerState(sessionID, phaseStartTime)
 	accumulatedMutex.Lock()
-	sessionAccumulatedTime[sessionID] = 0
-	phaseAccumulatedTime[sessionID] = 0
+	sessionAccumulatedTime[sessionID] = persistedSession
+	phaseAccumulatedTime[sessionID] = persistedPhase
 	lastUpdateTime[sessionID] = time.Now()
 	accumulatedMutex.Unlock()
 
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
+	lastFlush := time.Now()
+	lastCheckInPoll := time.Now()
 
 	for {
 		select {
-		case <-stopChan:
+		case <-timer.stop:
 			// Timer stopped
-			sessionTimerMutex.Lock()
-			delete(sessionTimers, sessionID)
-			sessionTimerMutex.Unlock()
+			persistTimerState(sessionID)
+			clearCheckIns(sessionID)
 			return
 		case <-ticker.C:
 			// Check if session is paused
@@ -464,6 +490,17 @@ func startSessionTimer(sessionID string, startTime time.Time) {
 			lastUpdateTime[sessionID] = time.Now()
 			accumulatedMutex.Unlock()
 
+			if time.Since(lastFlush) >= timerFlushInterval() {
+				persistTimerState(sessionID)
+				lastFlush = time.Now()
+			}
+
+			// Paused time doesn't count toward check-ins
+			if !isPaused && time.Since(lastCheckInPoll) >= checkInPollInterval {
+				checkInIfDue(sessionID, phaseAccum)
+				lastCheckInPoll = time.Now()
+			}
+
 			// Send timer update with accumulated time
 			timerUpdate := shared.TherapySessionUpdate{
 				Type: "timer_update",
@@ -487,15 +524,46 @@ func startSessionTimer(sessionID string, startTime time.Time) {
 	}
 }
 
-// stopSessionTimer stops the timer for a session
-func stopSessionTimer(sessionID string) {
-	sessionTimerMutex.RLock()
-	stopChan, exists := sessionTimers[sessionID]
-	sessionTimerMutex.RUnlock()
+// phaseTimerStatus reports progress through a timed phase (one with a configured duration,
+// which an approved session plan may override). Elapsed time comes from the running timer
+// when there is one, otherwise from the persisted phase start.
+func phaseTimerStatus(sessionID string, session *repository.Session, phase *repository.Phase) *shared.TimerStatus {
+	total := phase.DurationSeconds
+	if planned := session.PlannedPhaseDuration(phase.ID); planned > 0 && total > 0 {
+		total = planned
+	}
+	if total <= 0 || session.PhaseStartTime.IsZero() {
+		return nil
+	}
+
+	accumulatedMutex.RLock()
+	elapsed, running := phaseAccumulatedTime[sessionID]
+	accumulatedMutex.RUnlock()
+	if !running {
+		elapsed = time.Since(session.PhaseStartTime)
+	}
+
+	sessionPausedMutex.RLock()
+	isPaused := sessionPaused[sessionID]
+	sessionPausedMutex.RUnlock()
 
-	if exists {
-		stopChan <- true
+	status := &shared.TimerStatus{
+		Phase:   phase.ID,
+		State:   shared.TimerStateRunning,
+		Elapsed: int(elapsed.Seconds()),
+		Total:   total,
+		Active:  true,
+	}
+	status.Remaining = status.Total - status.Elapsed
+	switch {
+	case status.Remaining <= 0:
+		status.Remaining = 0
+		status.State = shared.TimerStateCompleted
+		status.Active = false
+	case isPaused:
+		status.State = shared.TimerStatePaused
 	}
+	return status
 }
 
 // monitorSessionActivity checks for inactivity and auto-pauses the session
@@ -534,11 +602,10 @@ func monitorSessionActivity(sessionID string) {
 				continue
 			}
 
-			// If more than 2 minutes of inactivity, pause the session
-			if time.Since(lastActivity) > 2*time.Minute {
-				sessionPausedMutex.Lock()
-				sessionPaused[sessionID] = true
-				sessionPausedMutex.Unlock()
+			// Pause once the current phase's inactivity threshold is exceeded
+			threshold, enabled := autoPauseThreshold(sessionID)
+			if enabled && time.Since(lastActivity) > threshold {
+				setSessionPaused(sessionID, true, repository.PauseReasonAutoInactivity, nil)
 
 				logger.AppLogger.WithFields(map[string]interface{}{
 					"session_id": sessionID,
@@ -550,7 +617,7 @@ func monitorSessionActivity(sessionID string) {
 				broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
 					Type: "session_paused",
 					Metadata: map[string]interface{}{
-						"reason": "Auto-paused due to 2 minutes of inactivity",
+						"reason": fmt.Sprintf("Auto-paused due to %s of inactivity", threshold),
 						"inactivity_seconds": int(time.Since(lastActivity).Seconds()),
 						"is_paused": true,
 					},
@@ -564,9 +631,36 @@ func monitorSessionActivity(sessionID string) {
 	}
 }
 
+// autoPauseThreshold returns the inactivity window for the session's current phase. Timed
+// phases never auto-pause - the client is meant to sit quietly until the timer ends.
+func autoPauseThreshold(sessionID string) (time.Duration, bool) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var session repository.Session
+	if err := db.Select("id", "phase").First(&session, "id = ?", sessionID).Error; err != nil {
+		return 0, false
+	}
+	var phase repository.Phase
+	if err := db.Select("id", "auto_pause_seconds", "duration_seconds").First(&phase, "id = ?", session.Phase).Error; err != nil {
+		// Unknown phase: keep the default window rather than never pausing
+		return 2 * time.Minute, true
+	}
+	if phase.DurationSeconds > 0 || phase.AutoPauseSeconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(phase.AutoPauseSeconds) * time.Second, true
+}
+
 // handlePatientMessage processes incoming patient messages via Conductor
-func handlePatientMessage(sessionID string, messageData []byte) {
-	ctx := context.Background()
+func handlePatientMessage(ctx context.Context, sessionID string, messageData []byte) {
+	handleSessionMessage(ctx, sessionID, messageData, false)
+}
+
+// handleSessionMessage processes a session message. ctx carries the socket's organization
+// (internal messages are system-scoped). Only internal messages (timer triggers) may carry
+// the system role; anything from the client is stored as the client's.
+func handleSessionMessage(ctx context.Context, sessionID string, messageData []byte, internal bool) {
 	
 	logger.AppLogger.WithFields(map[string]interface{}{
 		"session_id":    sessionID,
@@ -578,6 +672,14 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		Type    string `json:"type"`
 		Content string `json:"content"`
 		Role    string `json:"role"`
+		Stream  bool   `json:"stream,omitempty"` // Opt-in: receive the coach reply as message_chunk events
+
+		// Sender in a group or supervised session: a SessionParticipant person ID
+		ParticipantID string `json:"participant_id,omitempty"`
+
+		// correct_field: a clinician's fix of a collected value
+		FieldName  string      `json:"field_name,omitempty"`
+		FieldValue interface{} `json:"field_value,omitempty"`
 	}
 
 	if err := json.Unmarshal(messageData, &wsMessage); err != nil {
@@ -596,15 +698,13 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 	if wsMessage.Type == "trigger_checkin" {
 		logger.AppLogger.WithField("session_id", sessionID).Info("Triggering check-in after mindfulness timer")
 		// Handle timer-triggered check-ins via Conductor
-		go handlePatientMessage(sessionID, []byte(`{"type":"message","role":"system","content":"[5 minutes elapsed - trigger check-in]"}`))
+		go handleSessionMessage(repository.WithSystemScope(context.Background()), sessionID, []byte(`{"type":"message","role":"system","content":"[5 minutes elapsed - trigger check-in]"}`), true)
 		return
 	}
 
 	// Handle pause/resume/stop controls
 	if wsMessage.Type == "pause_session" {
-		sessionPausedMutex.Lock()
-		sessionPaused[sessionID] = true
-		sessionPausedMutex.Unlock()
+		setSessionPaused(sessionID, true, repository.PauseReasonManual, socketParticipant(ctx))
 
 		logger.AppLogger.WithField("session_id", sessionID).Info("Session manually paused")
 
@@ -636,9 +736,7 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 	}
 
 	if wsMessage.Type == "resume_session" {
-		sessionPausedMutex.Lock()
-		sessionPaused[sessionID] = false
-		sessionPausedMutex.Unlock()
+		setSessionPaused(sessionID, false, repository.PauseReasonManual, socketParticipant(ctx))
 
 		// Update last activity to prevent auto-pause
 		sessionActivityMutex.Lock()
@@ -671,17 +769,10 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		logger.AppLogger.WithField("session_id", sessionID).Info("Session stop requested")
 
 		// Stop the timer
-		sessionTimerMutex.Lock()
-		if timerChan, exists := sessionTimers[sessionID]; exists {
-			close(timerChan)
-			delete(sessionTimers, sessionID)
-		}
-		sessionTimerMutex.Unlock()
+		stopSessionTimer(sessionID)
 
 		// Mark session as stopped
-		sessionPausedMutex.Lock()
-		sessionPaused[sessionID] = true
-		sessionPausedMutex.Unlock()
+		setSessionPaused(sessionID, true, repository.PauseReasonStopped, socketParticipant(ctx))
 
 		broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
 			Type: "session_stopped",
@@ -693,20 +784,25 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		return
 	}
 
+	if wsMessage.Type == shared.MessageTypeCorrectField {
+		handleFieldCorrection(ctx, sessionID, wsMessage.FieldName, wsMessage.FieldValue)
+		return
+	}
+
 	// Handle workflow status requests
 	if wsMessage.Type == "get_workflow_status" {
 		logger.AppLogger.WithField("session_id", sessionID).Info("Frontend requested workflow status")
 
 		// Get current session to find phase
 		var session repository.Session
-		if err := repository.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		if err := repository.Scoped(ctx).First(&session, "id = ?", sessionID).Error; err != nil {
 			logger.AppLogger.WithError(err).Error("Failed to get session")
 			return
 		}
 
-		// Get ALL phases for the complete state machine
+		// Get ALL phases for the complete state machine, with their phase data in one query
 		var allPhases []repository.Phase
-		if err := repository.DB.Order("\"order\"").Find(&allPhases).Error; err != nil {
+		if err := repository.DB.Preload("PhaseData").Order("position ASC").Find(&allPhases).Error; err != nil {
 			logger.AppLogger.WithError(err).Error("Failed to get phases")
 		}
 
@@ -716,10 +812,12 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 			logger.AppLogger.WithError(err).Error("Failed to get transitions")
 		}
 
-		// Get phase data for current phase
+		// Phase data for current phase
 		var phaseData []repository.PhaseData
-		if err := repository.DB.Where("phase_id = ?", session.Phase).Find(&phaseData).Error; err != nil {
-			logger.AppLogger.WithError(err).Error("Failed to get phase data")
+		for _, phase := range allPhases {
+			if phase.ID == session.Phase {
+				phaseData = phase.PhaseData
+			}
 		}
 
 		// Get available transitions from current phase
@@ -729,7 +827,6 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		}
 
 		// Get phase data values from SessionFieldValue table
-		phaseDataValues := make(map[string]interface{})
 		// Get stored field values for this session
 		var storedValues []repository.SessionFieldValue
 		if err := repository.DB.Where("session_id = ?", sessionID).Find(&storedValues).Error; err != nil {
@@ -737,14 +834,9 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		}
 
 		// Map ALL stored values, not just current phase
-		for _, sv := range storedValues {
-			// Parse JSON value
-			var parsedValue interface{}
-			if err := json.Unmarshal([]byte(sv.FieldValue), &parsedValue); err != nil {
-				logger.AppLogger.WithError(err).WithField("field_value", sv.FieldValue).Error("Failed to parse stored field value as JSON")
-				continue // Skip invalid values
-			}
-			phaseDataValues[sv.FieldName] = parsedValue
+		phaseDataValues, parseErrs := repository.ParsedFieldValues(storedValues)
+		for _, err := range parseErrs {
+			logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to parse stored field value")
 		}
 
 		// Also include null for current phase fields that don't have values yet
@@ -755,21 +847,7 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		}
 
 		// Convert all phases with their phase_data for clean structure
-		sharedPhases := make([]shared.Phase, len(allPhases))
-		for i, phase := range allPhases {
-			var phaseFields []repository.PhaseData
-			if err := repository.DB.Where("phase_id = ?", phase.ID).Find(&phaseFields).Error; err != nil {
-				logger.AppLogger.WithError(err).Error("Failed to get phase data for phase")
-			}
-			sharedPhases[i] = shared.Phase{
-				ID:          phase.ID,
-				DisplayName: phase.DisplayName,
-				Description: phase.Description,
-				Color:       phase.Color,
-				Icon:        phase.Icon,
-				PhaseData:   convertPhaseData(phaseFields),
-			}
-		}
+		sharedPhases := convertPhases(allPhases)
 
 		// Send complete state - clean structure
 		broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
@@ -778,20 +856,49 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 			SessionStatus:   session.Status,
 			PhaseDataValues: phaseDataValues,
 			Phases:          sharedPhases,
-			Timestamp:       time.Now(),
+			Metadata: map[string]interface{}{
+				"phase_transition_count": session.PhaseTransitionCount,
+				"max_phase_transitions":  repository.MaxPhaseTransitions(),
+				"auto_transitions_held":  session.AutoTransitionsHeld,
+			},
+			Timestamp: time.Now(),
 		})
 		logger.AppLogger.WithField("session_id", sessionID).Info("✅ Sent complete state machine representation to frontend")
 		return
 	}
 
-	// Create patient message record
+	// Create patient message record (system annotations keep their role so they're never read as the client's words)
+	messageRole := "client"
+	if internal && wsMessage.Role == "system" {
+		messageRole = "system"
+	}
+	// The sender is the participant the socket was bound to at upgrade; a participant_id in
+	// the message is only accepted when it names that participant
+	var participantID string
+	if !internal {
+		bound := socketParticipant(ctx)
+		if wsMessage.ParticipantID != "" && (bound == nil || bound.PersonID != wsMessage.ParticipantID) {
+			logger.AppLogger.WithFields(map[string]interface{}{
+				"session_id":     sessionID,
+				"participant_id": wsMessage.ParticipantID,
+			}).Warn("⚠️ Ignoring message claiming a participant other than the socket's")
+			return
+		}
+		// Anyone but the primary therapist speaks under their own role; the primary
+		// therapist's socket drives the session on the client's behalf
+		if bound != nil && !(bound.Primary && bound.Role == repository.ParticipantRoleTherapist) {
+			messageRole = bound.Role
+			participantID = bound.PersonID
+		}
+	}
 	patientMsg := &repository.Message{
-		ID:        fmt.Sprintf("msg_%d", time.Now().UnixNano()),
-		SessionID: sessionID,
-		Role:      "client",
-		Content:   wsMessage.Content,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            fmt.Sprintf("msg_%d", time.Now().UnixNano()),
+		SessionID:     sessionID,
+		Role:          messageRole,
+		Content:       wsMessage.Content,
+		ParticipantID: participantID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Save to database
@@ -800,6 +907,11 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		return
 	}
 
+	// The client's first real message starts the session
+	if messageRole == "client" {
+		activateSession(sessionID, "first client message")
+	}
+
 	// Broadcast patient message
 	broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
 		Type:      "message",
@@ -807,6 +919,11 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		Timestamp: time.Now(),
 	})
 
+	// Other participants' messages are part of the record, but only the client prompts a coach reply
+	if messageRole != "client" && messageRole != "system" {
+		return
+	}
+
 	// TODO: Replace with state machine call
 	// GlobalWorkflowManager.ProcessPatientMessage(sessionID, patientMsg) // REMOVED: workflow manager deleted
 
@@ -837,23 +954,44 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 	
 	logger.AppLogger.WithFields(map[string]interface{}{
 		"session_id":        sessionID,
-		"gemini_service_nil": Services.GeminiService == nil,
+		"gemini_service_nil": !assistantAvailable(),
 	}).Info("[DEBUG] Checking Services.GeminiService before creating coach")
 	
-	if Services.GeminiService == nil {
+	if !assistantAvailable() {
 		logger.AppLogger.WithField("session_id", sessionID).Error("[DEBUG] Services.GeminiService is NIL - cannot create coach")
+		broadcastServiceUnavailable(sessionID)
 		return
 	}
 	
 	logger.AppLogger.WithField("session_id", sessionID).Info("[DEBUG] Services.GeminiService is good, creating coach service")
 	
 	// Generate response using Context Builder + phase-specific prompts
-	coachService := services.NewCoachService(Services.GeminiService)
+	coachService := newCoachResponder()
 	
 	logger.AppLogger.WithField("session_id", sessionID).Info("[DEBUG] Coach service created, calling GenerateResponse") 
-	coachResponse, err := coachService.GenerateResponse(ctx, sessionID, wsMessage.Content, currentPhase)
+
+	// A streamed reply's chunks and its saved message share this ID
+	therapistMsgID := fmt.Sprintf("msg_%d", time.Now().UnixNano())
+	var stream *coachStream
+	var coachResponse *services.CoachResponse
+	var err error
+	if wsMessage.Stream {
+		stream = &coachStream{
+			sessionID:  sessionID,
+			phaseID:    currentPhase,
+			messageID:  therapistMsgID,
+			receivedAt: patientMsg.CreatedAt,
+		}
+		coachResponse, err = coachService.GenerateResponseStream(ctx, sessionID, wsMessage.Content, currentPhase, stream.send)
+	} else {
+		coachResponse, err = coachService.GenerateResponse(ctx, sessionID, wsMessage.Content, currentPhase)
+	}
 	if err != nil {
 		logger.AppLogger.WithError(err).Error("Coach service failed to generate response")
+		if stream != nil && stream.chunks > 0 {
+			stream.complete(nil, true)
+		}
+		broadcastServiceUnavailable(sessionID)
 		return
 	}
 	
@@ -871,9 +1009,11 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		"tool_calls_count": len(coachResponse.ToolCalls),
 	}).Info("[MESSAGE_DEBUG] Processing coach response")
 
+	// The persisted message is the one broadcast, so the client sees a single bubble with a stable ID
+	var therapistMsg *repository.Message
 	if responseText != "" {
-		therapistMsg := &repository.Message{
-			ID:        fmt.Sprintf("msg_%d", time.Now().UnixNano()),
+		therapistMsg = &repository.Message{
+			ID:        therapistMsgID,
 			SessionID: sessionID,
 			Role:      "coach",
 			Content:   responseText,
@@ -883,6 +1023,9 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 
 		if err := repository.DB.Create(therapistMsg).Error; err != nil {
 			logger.AppLogger.WithError(err).Error("Failed to save therapist message")
+			if stream != nil && stream.chunks > 0 {
+				stream.complete(nil, true)
+			}
 			return
 		}
 
@@ -891,6 +1034,29 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		logger.AppLogger.WithField("session_id", sessionID).Info("[MESSAGE_DEBUG] No response text, skipping conversation message")
 	}
 
+	// Bound the blast radius of a runaway generation
+	if maxToolCalls := Services.Config.MaxToolCallsPerTurn; maxToolCalls > 0 && len(coachResponse.ToolCalls) > maxToolCalls {
+		dropped := make([]string, 0, len(coachResponse.ToolCalls)-maxToolCalls)
+		for _, tc := range coachResponse.ToolCalls[maxToolCalls:] {
+			dropped = append(dropped, tc.Name)
+		}
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"session_id":     sessionID,
+			"current_phase":  currentPhase,
+			"tool_calls":     len(coachResponse.ToolCalls),
+			"max_tool_calls": maxToolCalls,
+			"dropped_tools":  dropped,
+		}).Warn("⚠️ Tool call cap exceeded - dropping extra tool calls")
+		RecordToolCallCap(currentPhase, dropped)
+		coachResponse.ToolCalls = coachResponse.ToolCalls[:maxToolCalls]
+	}
+
+	// The model writes session_id itself; pin every call to this connection's session so
+	// a hallucinated or stale ID can't write to another session
+	for i := range coachResponse.ToolCalls {
+		bindToolCallSession(sessionID, &coachResponse.ToolCalls[i])
+	}
+
 	// Create initial "executing" tool call messages and execute async
 	mcpClient := getWSMCPClient()
 	hasTransitionTool := false
@@ -904,6 +1070,7 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 			// 1. Create initial "executing" tool call message
 			initialMetadata, _ := json.Marshal(map[string]interface{}{
 				"tool_name":    toolCall.Name,
+				"tool_call_id": toolCall.ID, // Matches the tool's mcp_activity progress events
 				"arguments":    toolCall.Arguments,
 				"executed_at":  time.Now(),
 				"status":       "executing",
@@ -914,7 +1081,7 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 			switch toolCall.Name {
 			case "therapy_session_transition":
 				toolMessage = "Starting formal brainspotting session"
-			case "therapy_session_record_suds":
+			case "record_suds":
 				toolMessage = "Recording stress level"
 			case "collect_structured_data":
 				toolMessage = "Collecting therapeutic data"
@@ -947,12 +1114,19 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 			})
 
 			// 3. Execute tool asynchronously and update message
-			go func(tCall services.ToolCall, msgID string, coach *services.CoachService) {
+			go func(tCall services.ToolCall, msgID string, coach coachResponder) {
 				var toolResult interface{}
 				var executionError error
-				if mcpClient != nil {
+				if tCall.Correction != "" {
+					// Malformed arguments: report back so the model retries with valid ones
+					executionError = errors.New("invalid arguments")
+					toolResult = map[string]interface{}{
+						"success":      false,
+						"instructions": tCall.Correction,
+					}
+				} else if mcpClient != nil {
 					argsJSON, _ := json.Marshal(tCall.Arguments)
-					toolResult, executionError = mcpClient.ToolsCall(ctx, tCall.Name, argsJSON)
+					toolResult, executionError = mcpClient.ToolsCall(ctx, tCall.Name, argsJSON, tCall.ID)
 				}
 
 				// Check if tool result contains a continuation prompt first
@@ -1049,18 +1223,15 @@ func handlePatientMessage(sessionID string, messageData []byte) {
 		// }
 	}
 
-	// Broadcast the response (if there was conversation text)
-	if responseText != "" {
+	// Broadcast the response (if there was conversation text), paced for the phase. A
+	// streamed reply was already paced and shown; it just needs closing with the saved message.
+	if stream != nil {
+		stream.complete(therapistMsg, false)
+	} else if therapistMsg != nil {
+		holdCoachResponse(sessionID, currentPhase, patientMsg.CreatedAt)
 		broadcastSessionUpdate(sessionID, shared.TherapySessionUpdate{
 			Type:      "message",
-			Message:   &shared.Message{
-				ID:        fmt.Sprintf("msg_%d", time.Now().UnixNano()),
-				SessionID: sessionID,
-				Role:      "coach",
-				Content:   responseText,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			},
+			Message:   convertMessage(therapistMsg),
 			Timestamp: time.Now(),
 		})
 	}
@@ -1091,18 +1262,20 @@ func generateInitialGreeting(sessionID string) {
 		"current_phase": currentPhase,
 	}).Info("🤖 GENERATING INITIAL GREETING")
 
-	if Services.GeminiService == nil {
+	if !assistantAvailable() {
 		logger.AppLogger.WithField("session_id", sessionID).Error("[DEBUG] Services.GeminiService is NIL - cannot create coach for greeting")
+		broadcastServiceUnavailable(sessionID)
 		return
 	}
 
 	// Generate greeting using Context Builder + phase-specific prompts
-	coachService := services.NewCoachService(Services.GeminiService)
+	coachService := newCoachResponder()
 
 	// Pass empty string as user message to indicate this is an initial greeting
 	coachResponse, err := coachService.GenerateResponse(ctx, sessionID, "", currentPhase)
 	if err != nil {
 		logger.AppLogger.WithError(err).Error("Coach service failed to generate initial greeting")
+		broadcastServiceUnavailable(sessionID)
 		return
 	}
 
@@ -1140,29 +1313,54 @@ func generateInitialGreeting(sessionID string) {
 	}
 }
 
-// broadcastSessionUpdate sends updates to connected WebSocket clients
-func broadcastSessionUpdate(sessionID string, update shared.TherapySessionUpdate) {
-	sessionConnMutex.RLock()
-	conn, exists := sessionConnections[sessionID]
-	sessionConnMutex.RUnlock()
+// bindToolCallSession sets a tool call's session_id argument to the connection's session,
+// warning when the model supplied a different one
+func bindToolCallSession(sessionID string, call *services.ToolCall) {
+	if call.Arguments == nil {
+		call.Arguments = make(map[string]interface{})
+	}
+	if provided, ok := call.Arguments["session_id"]; ok && provided != sessionID {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"session_id":          sessionID,
+			"provided_session_id": provided,
+			"tool":                call.Name,
+		}).Warn("⚠️ Tool call named a different session - correcting session_id")
+	}
+	call.Arguments["session_id"] = sessionID
+}
 
-	if !exists {
+// broadcastSessionUpdate sends updates to every WebSocket client connected to the session
+func broadcastSessionUpdate(sessionID string, update shared.TherapySessionUpdate) {
+	conns := sessionConnectionList(sessionID)
+	if len(conns) == 0 {
 		logger.AppLogger.WithField("session_id", sessionID).Debug("No WebSocket connection found for session")
 		return
 	}
 
-	// Calculate total connections for this session
-	sessionConnMutex.RLock()
-	totalConnections := len(sessionConnections)
-	sessionConnMutex.RUnlock()
-
 	logger.AppLogger.WithFields(map[string]interface{}{
-		"session_id":         sessionID,
-		"update_type":        update.Type,
-		"connection_exists":  exists,
-		"total_connections": totalConnections,
+		"session_id":          sessionID,
+		"update_type":         update.Type,
+		"session_connections": len(conns),
 	}).Info("Broadcasting session update")
 
+	for _, conn := range conns {
+		sendSessionUpdate(sessionID, conn, update)
+	}
+}
+
+// sendSessionUpdate sends an update to one of the session's WebSocket clients
+func sendSessionUpdate(sessionID string, conn *safeConn, update shared.TherapySessionUpdate) {
+	// Tailor the event to what this client understands
+	update, ok := adaptUpdateForProtocol(update, conn.protocolVersion)
+	if !ok {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"session_id":       sessionID,
+			"update_type":      update.Type,
+			"protocol_version": conn.protocolVersion,
+		}).Debug("Skipped update the client's protocol version doesn't support")
+		return
+	}
+
 	// Log WebSocket message content to dedicated file
 	wsLogEntry := map[string]interface{}{
 		"timestamp":   time.Now().Format(time.RFC3339),
@@ -1179,7 +1377,20 @@ func broadcastSessionUpdate(sessionID string, update shared.TherapySessionUpdate
 
 	// Send update to WebSocket
 	if err := conn.WriteJSON(update); err != nil {
-		logger.AppLogger.WithError(err).Error("Failed to send WebSocket update")
+		if errors.Is(err, errConnClosed) {
+			logger.AppLogger.WithFields(map[string]interface{}{
+				"session_id":  sessionID,
+				"update_type": update.Type,
+			}).Debug("Dropped update for closed WebSocket connection")
+		} else {
+			logger.AppLogger.WithError(err).Error("Failed to send WebSocket update")
+		}
+		// Unregister so later broadcasts don't retry a dead connection; its reader then
+		// finds it already gone, so stop the timer here if it was the last
+		conn.Close()
+		if removeSessionConnection(sessionID, conn) {
+			stopSessionTimer(sessionID)
+		}
 		return
 	}
 
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"therapy-navigation-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// errNotSessionParticipant is returned when an authenticated user takes no part in the session
+var errNotSessionParticipant = errors.New("not a participant in this session")
+
+// socketParticipantKey holds the participant a session socket is bound to
+type socketParticipantKey struct{}
+
+// withSocketParticipant binds a socket's message context to its participant
+func withSocketParticipant(ctx context.Context, participant *repository.SessionParticipant) context.Context {
+	return context.WithValue(ctx, socketParticipantKey{}, participant)
+}
+
+// socketParticipant returns the participant a socket was bound to at upgrade, if any
+func socketParticipant(ctx context.Context) *repository.SessionParticipant {
+	participant, _ := ctx.Value(socketParticipantKey{}).(*repository.SessionParticipant)
+	return participant
+}
+
+// requestParticipant resolves the authenticated user's place in a session from their email:
+// the therapist or client with that email, as a participant of the session. Without Firebase
+// (development) the participant is named by the participant_id query param, and a socket
+// without one is unbound (nil, nil).
+func requestParticipant(r *http.Request, sessionID string) (*repository.SessionParticipant, error) {
+	db := repository.Scoped(r.Context())
+
+	email, _ := r.Context().Value("user_email").(string)
+	if email == "" {
+		if firebaseAuth == nil {
+			if personID := r.URL.Query().Get("participant_id"); personID != "" {
+				return repository.FindSessionParticipant(db, sessionID, personID)
+			}
+			return nil, nil
+		}
+		return nil, errNotSessionParticipant
+	}
+
+	var therapistIDs, clientIDs []string
+	if err := db.Model(&repository.Therapist{}).Where("email = ?", email).Pluck("id", &therapistIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&repository.Client{}).Where("email = ?", email).Pluck("id", &clientIDs).Error; err != nil {
+		return nil, err
+	}
+	personIDs := append(therapistIDs, clientIDs...)
+
+	for _, personID := range personIDs {
+		participant, err := repository.FindSessionParticipant(db, sessionID, personID)
+		if err == nil {
+			return participant, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return nil, errNotSessionParticipant
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetSessionSudsHandler returns every SUDS reading recorded in a session
+// @Summary Get session SUDS readings
+// @Description The session's SUDS readings oldest first, with the overall trend (increasing, decreasing or stable)
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/suds [get]
+func GetSessionSudsHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	readings, err := repository.SessionSudsReadings(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to fetch SUDS readings")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch SUDS readings"})
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"session_id": sessionID,
+		"readings":   readings,
+		"trend":      repository.SudsTrend(readings),
+	})
+}
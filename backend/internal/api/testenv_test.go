@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/config"
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/services"
+	"therapy-navigation-system/shared"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestEnv installs a throwaway SQLite database as repository.DB and a service container
+// with an assistant, restoring both when the test ends
+func newTestEnv(t *testing.T) *gorm.DB {
+	t.Helper()
+	if logger.AppLogger == nil {
+		logger.AppLogger = logrus.New()
+		logger.AppLogger.SetLevel(logrus.WarnLevel)
+	}
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&repository.Client{},
+		&repository.Therapist{},
+		&repository.Session{},
+		&repository.SessionStatusChange{},
+		&repository.Message{},
+		&repository.Phase{},
+		&repository.PhaseData{},
+		&repository.PhaseTransition{},
+		&repository.SessionFieldValue{},
+		&repository.Prompt{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	// Field writes upsert against the unique index migration 023 adds
+	if err := db.Exec("CREATE UNIQUE INDEX idx_session_field_values_unique ON session_field_values (session_id, field_name)").Error; err != nil {
+		t.Fatalf("failed to index session field values: %v", err)
+	}
+
+	previousDB, previousServices := repository.DB, Services
+	repository.DB = db
+	Services = &ServiceContainer{Config: &config.Config{}, GeminiService: &services.GeminiService{}}
+	t.Cleanup(func() {
+		repository.DB, Services = previousDB, previousServices
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// createTestSession stores a scheduled session in the given phase
+func createTestSession(t *testing.T, db *gorm.DB, phase string) *repository.Session {
+	t.Helper()
+	session := &repository.Session{
+		ClientID:    "client-1",
+		TherapistID: "therapist-1",
+		Status:      repository.SessionStatusScheduled,
+		Phase:       phase,
+		StartTime:   time.Now(),
+	}
+	if err := db.Create(session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	return session
+}
+
+// createTestPhases stores phases with the given IDs, in order
+func createTestPhases(t *testing.T, db *gorm.DB, phaseIDs ...string) {
+	t.Helper()
+	for i, id := range phaseIDs {
+		if err := db.Create(&repository.Phase{ID: id, DisplayName: id, Position: i + 1}).Error; err != nil {
+			t.Fatalf("failed to create phase %s: %v", id, err)
+		}
+	}
+}
+
+// testSocket is a client connected as a session's WebSocket, recording every update
+// broadcast to the session
+type testSocket struct {
+	updates chan shared.TherapySessionUpdate
+}
+
+// connectTestSocket registers a live WebSocket connection for the session
+func connectTestSocket(t *testing.T, sessionID string) *testSocket {
+	t.Helper()
+	return connectTestSocketAt(t, sessionID, shared.ProtocolVersion, "")
+}
+
+// connectTestSocketAt registers a live WebSocket connection that negotiated the given protocol
+// version, bound to a participant with the given role (unbound when empty)
+func connectTestSocketAt(t *testing.T, sessionID string, protocolVersion int, participantRole string) *testSocket {
+	t.Helper()
+	registered := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := sessionWebSocketUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		sc := &safeConn{conn: conn, protocolVersion: protocolVersion, participantRole: participantRole}
+		addSessionConnection(sessionID, sc)
+		t.Cleanup(func() {
+			sc.Close()
+			removeSessionConnection(sessionID, sc)
+		})
+		close(registered)
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to connect test socket: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	<-registered
+
+	socket := &testSocket{updates: make(chan shared.TherapySessionUpdate, 256)}
+	go func() {
+		for {
+			_, data, err := client.ReadMessage()
+			if err != nil {
+				return
+			}
+			var update shared.TherapySessionUpdate
+			if json.Unmarshal(data, &update) == nil {
+				socket.updates <- update
+			}
+		}
+	}()
+	return socket
+}
+
+// drain returns the updates received until none arrive for quiet
+func (s *testSocket) drain(quiet time.Duration) []shared.TherapySessionUpdate {
+	var received []shared.TherapySessionUpdate
+	for {
+		select {
+		case update := <-s.updates:
+			received = append(received, update)
+		case <-time.After(quiet):
+			return received
+		}
+	}
+}
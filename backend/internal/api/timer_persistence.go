@@ -0,0 +1,118 @@
+package api
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// timerFlushInterval returns how often running timers are persisted to the session
+func timerFlushInterval() time.Duration {
+	if Services != nil && Services.Config != nil && Services.Config.TimerFlushInterval > 0 {
+		return Services.Config.TimerFlushInterval
+	}
+	return 15 * time.Second
+}
+
+// setSessionPaused records the pause state in memory and on the session, so a paused
+// session that reconnects stays paused instead of silently accumulating again. Changes are
+// added to the session's pause history with their reason and the participant who made them
+// (nil for the system).
+func setSessionPaused(sessionID string, paused bool, reason string, participant *repository.SessionParticipant) {
+	sessionPausedMutex.Lock()
+	wasPaused := sessionPaused[sessionID]
+	sessionPaused[sessionID] = paused
+	sessionPausedMutex.Unlock()
+
+	if err := repository.DB.Model(&repository.Session{}).Where("id = ?", sessionID).Update("timer_paused", paused).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to persist pause state")
+	}
+
+	// Stopping an already-paused session is still worth recording
+	if pauseHistoryEnabled() && (wasPaused != paused || reason == repository.PauseReasonStopped) {
+		if err := repository.RecordPauseEvent(repository.DB, sessionID, paused, reason, participant); err != nil {
+			logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to record pause event")
+		}
+	}
+}
+
+// pauseHistoryEnabled reports whether pauses and resumes are recorded
+func pauseHistoryEnabled() bool {
+	return Services == nil || Services.Config == nil || Services.Config.PauseHistory
+}
+
+// restorePauseState reloads a persisted pause when no in-memory state exists (e.g. after a restart)
+func restorePauseState(session *repository.Session) {
+	sessionPausedMutex.Lock()
+	defer sessionPausedMutex.Unlock()
+	if _, known := sessionPaused[session.ID]; !known && session.TimerPaused {
+		sessionPaused[session.ID] = true
+	}
+}
+
+// persistTimerState writes the accumulated session and phase time to the session
+func persistTimerState(sessionID string) {
+	accumulatedMutex.RLock()
+	sessionAccum, running := sessionAccumulatedTime[sessionID]
+	phaseAccum := phaseAccumulatedTime[sessionID]
+	accumulatedMutex.RUnlock()
+	if !running {
+		return
+	}
+
+	// timer_phase takes the session's current phase so a later reload can tell whether the
+	// phase time still applies
+	if err := repository.DB.Model(&repository.Session{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"accumulated_seconds":       int(sessionAccum.Seconds()),
+		"phase_accumulated_seconds": int(phaseAccum.Seconds()),
+		"timer_phase":               gorm.Expr("phase"),
+	}).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to persist session timer")
+	}
+}
+
+// loadTimerState returns the persisted session and phase time to resume from. Phase time
+// only carries over while the session is still in the phase it was measured in; otherwise
+// it falls back to the persisted phase start.
+func loadTimerState(sessionID string, phaseStartTime time.Time) (sessionAccum time.Duration, phaseAccum time.Duration) {
+	var session repository.Session
+	if err := repository.DB.Select("id", "phase", "accumulated_seconds", "phase_accumulated_seconds", "timer_phase").
+		First(&session, "id = ?", sessionID).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("Failed to load persisted session timer")
+	}
+
+	sessionAccum = time.Duration(session.AccumulatedSeconds) * time.Second
+	switch {
+	case session.TimerPhase != "" && session.TimerPhase == session.Phase:
+		phaseAccum = time.Duration(session.PhaseAccumulatedSeconds) * time.Second
+	case !phaseStartTime.IsZero() && phaseStartTime.Before(time.Now()):
+		phaseAccum = time.Since(phaseStartTime)
+	}
+	return sessionAccum, phaseAccum
+}
+
+// livePhaseElapsed returns the running timer's accumulated (non-paused) time in the
+// session's current phase
+func livePhaseElapsed(sessionID string) (time.Duration, bool) {
+	accumulatedMutex.RLock()
+	defer accumulatedMutex.RUnlock()
+	elapsed, running := phaseAccumulatedTime[sessionID]
+	return elapsed, running
+}
+
+// resetPhaseTimer restarts the running phase timer after the session changes phase
+func resetPhaseTimer(sessionID string) {
+	accumulatedMutex.Lock()
+	if _, running := phaseAccumulatedTime[sessionID]; running {
+		phaseAccumulatedTime[sessionID] = 0
+		lastUpdateTime[sessionID] = time.Now()
+	}
+	accumulatedMutex.Unlock()
+
+	phaseStartMutex.Lock()
+	phaseStartTimes[sessionID] = time.Now()
+	phaseStartMutex.Unlock()
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// transcriptRoleLabels are the display labels for message roles
+var transcriptRoleLabels = map[string]string{
+	"user":      "CLIENT",
+	"patient":   "CLIENT",
+	"client":    "CLIENT",
+	"coach":     "COACH",
+	"therapist": "COACH",
+	"system":    "SYSTEM",
+}
+
+// transcriptEntry is one message in the JSON transcript
+type transcriptEntry struct {
+	ID          string                 `json:"id"`
+	Role        string                 `json:"role"`
+	Label       string                 `json:"label"`
+	MessageType string                 `json:"message_type"`
+	Content     string                 `json:"content"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Tool        map[string]interface{} `json:"tool,omitempty"`
+}
+
+// GetSessionTranscriptHandler downloads a session's full message transcript
+// @Summary Download session transcript
+// @Description All messages in order with role labels, timestamps and tool-call details, as text, JSON or markdown
+// @Tags sessions
+// @Produce plain
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param format query string false "txt (default), json or md"
+// @Param include_tools query bool false "Include tool_call/tool_result messages (default true)"
+// @Success 200 {string} string
+// @Router /api/sessions/{sessionId}/transcript [get]
+func GetSessionTranscriptHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "txt"
+	}
+	if format != "txt" && format != "json" && format != "md" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "format must be txt, json or md"})
+		return
+	}
+	includeTools := r.URL.Query().Get("include_tools") != "false"
+
+	// Messages are scoped through their session
+	var session repository.Session
+	if err := repository.Scoped(r.Context()).Select("id").First(&session, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	query := repository.DB.Where("session_id = ?", sessionID)
+	if !includeTools {
+		query = query.Where("message_type NOT IN ?", []string{"tool_call", "tool_result"})
+	}
+	var messages []repository.Message
+	if err := query.Order("created_at ASC").Find(&messages).Error; err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to fetch transcript messages")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch messages"})
+		return
+	}
+
+	entries := make([]transcriptEntry, len(messages))
+	for i, msg := range messages {
+		entries[i] = transcriptEntry{
+			ID:          msg.ID,
+			Role:        msg.Role,
+			Label:       transcriptLabel(msg.Role),
+			MessageType: msg.MessageType,
+			Content:     msg.Content,
+			Timestamp:   msg.CreatedAt,
+		}
+		if isToolMessage(msg.MessageType) && msg.Metadata != "" {
+			var tool map[string]interface{}
+			if err := json.Unmarshal([]byte(msg.Metadata), &tool); err == nil {
+				entries[i].Tool = tool
+			}
+		}
+	}
+
+	var body []byte
+	contentType := "text/plain; charset=utf-8"
+	switch format {
+	case "json":
+		body, _ = json.MarshalIndent(map[string]interface{}{
+			"session_id": sessionID,
+			"messages":   entries,
+		}, "", "  ")
+		contentType = "application/json"
+	case "md":
+		body = []byte(renderTranscriptMarkdown(sessionID, entries))
+		contentType = "text/markdown; charset=utf-8"
+	default:
+		body = []byte(renderTranscriptText(sessionID, entries))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="session-%s-transcript.%s"`, sessionID, format))
+	w.Write(body)
+}
+
+// transcriptLabel returns the display label for a role
+func transcriptLabel(role string) string {
+	if label, ok := transcriptRoleLabels[role]; ok {
+		return label
+	}
+	return strings.ToUpper(role)
+}
+
+// isToolMessage reports whether a message type records a tool call or result
+func isToolMessage(messageType string) bool {
+	return messageType == "tool_call" || messageType == "tool_result"
+}
+
+// renderTranscriptText lays out a transcript like the raw prompt log
+func renderTranscriptText(sessionID string, entries []transcriptEntry) string {
+	var out strings.Builder
+	out.WriteString("=== TRANSCRIPT FOR SESSION " + sessionID + " ===\n\n")
+
+	for i, entry := range entries {
+		out.WriteString("========================================\n")
+		out.WriteString(fmt.Sprintf("MESSAGE %d\n", i+1))
+		out.WriteString("Time: " + entry.Timestamp.Format(time.RFC3339) + "\n")
+		out.WriteString("From: " + entry.Label + "\n")
+		if entry.MessageType != "" && entry.MessageType != "conversation" {
+			out.WriteString("Type: " + entry.MessageType + "\n")
+		}
+		out.WriteString("\n" + entry.Content + "\n\n")
+
+		if entry.Tool != nil {
+			out.WriteString("TOOL CALL:\n")
+			toolJSON, _ := json.MarshalIndent(entry.Tool, "", "  ")
+			out.WriteString(string(toolJSON) + "\n\n")
+		}
+	}
+
+	out.WriteString("\n=== END OF TRANSCRIPT ===\n")
+	out.WriteString(fmt.Sprintf("Total messages: %d\n", len(entries)))
+	return out.String()
+}
+
+// renderTranscriptMarkdown renders a transcript with tool calls in collapsible blocks
+func renderTranscriptMarkdown(sessionID string, entries []transcriptEntry) string {
+	var out strings.Builder
+	out.WriteString("# Session transcript\n\n")
+	out.WriteString("Session `" + sessionID + "`\n\n")
+
+	for _, entry := range entries {
+		if entry.Tool != nil {
+			toolName, _ := entry.Tool["tool_name"].(string)
+			if toolName == "" {
+				toolName = entry.MessageType
+			}
+			toolJSON, _ := json.MarshalIndent(entry.Tool, "", "  ")
+			out.WriteString(fmt.Sprintf("<details>\n<summary>🔧 %s · %s · %s</summary>\n\n", toolName, entry.Content, entry.Timestamp.Format("15:04:05")))
+			out.WriteString("```json\n" + string(toolJSON) + "\n```\n\n</details>\n\n")
+			continue
+		}
+
+		out.WriteString(fmt.Sprintf("**%s** · _%s_\n\n", entry.Label, entry.Timestamp.Format("15:04:05")))
+		for _, line := range strings.Split(entry.Content, "\n") {
+			out.WriteString("> " + line + "\n")
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// TransitionRequest is the body for creating or editing a phase transition. On update,
+// omitted fields keep their current values.
+type TransitionRequest struct {
+	FromPhaseID *string `json:"from_phase_id,omitempty"`
+	ToPhaseID   *string `json:"to_phase_id,omitempty"`
+	Condition   *string `json:"condition,omitempty"` // e.g. "next_action == 'squeeze_hug'"; empty always holds
+	Priority    *int    `json:"priority,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// apply copies the provided fields onto a transition
+func (req TransitionRequest) apply(t *repository.PhaseTransition) {
+	if req.FromPhaseID != nil {
+		t.FromPhaseID = *req.FromPhaseID
+	}
+	if req.ToPhaseID != nil {
+		t.ToPhaseID = *req.ToPhaseID
+	}
+	if req.Condition != nil {
+		t.Condition = *req.Condition
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+}
+
+// CreateTransitionHandler adds a transition to the workflow graph
+// @Summary Create phase transition
+// @Description Add a transition between two existing phases. Requires the admin role.
+// @Tags transitions
+// @Accept json
+// @Produce json
+// @Param transition body TransitionRequest true "Transition to create"
+// @Success 201 {object} repository.PhaseTransition
+// @Failure 403 {object} map[string]string
+// @Router /api/transitions [post]
+func CreateTransitionHandler(w http.ResponseWriter, r *http.Request) {
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	transition := repository.PhaseTransition{IsActive: true}
+	req.apply(&transition)
+
+	if err := repository.CreatePhaseTransition(repository.DB, &transition); err != nil {
+		renderTransitionError(w, r, err, "Failed to create transition")
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"transition_id": transition.ID,
+		"from_phase":    transition.FromPhaseID,
+		"to_phase":      transition.ToPhaseID,
+	}).Info("🔀 Phase transition created")
+	broadcastWorkflowGraphChanged("created", transition)
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, transition)
+}
+
+// UpdateTransitionHandler edits an existing transition
+// @Summary Update phase transition
+// @Description Change a transition's endpoints, condition, priority or active flag. Requires the admin role.
+// @Tags transitions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transition ID"
+// @Param transition body TransitionRequest true "Fields to change"
+// @Success 200 {object} repository.PhaseTransition
+// @Failure 403 {object} map[string]string
+// @Router /api/transitions/{id} [put]
+func UpdateTransitionHandler(w http.ResponseWriter, r *http.Request) {
+	transitionID := chi.URLParam(r, "id")
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	transition, err := repository.UpdatePhaseTransition(repository.DB, transitionID, req.apply)
+	if err != nil {
+		renderTransitionError(w, r, err, "Failed to update transition")
+		return
+	}
+
+	logger.AppLogger.WithField("transition_id", transitionID).Info("🔀 Phase transition updated")
+	broadcastWorkflowGraphChanged("updated", *transition)
+
+	render.JSON(w, r, transition)
+}
+
+// DeleteTransitionHandler removes a transition from the workflow graph
+// @Summary Delete phase transition
+// @Description Remove a transition; refused if it would leave a phase unreachable. Requires the admin role.
+// @Tags transitions
+// @Produce json
+// @Param id path string true "Transition ID"
+// @Success 200 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/transitions/{id} [delete]
+func DeleteTransitionHandler(w http.ResponseWriter, r *http.Request) {
+	transitionID := chi.URLParam(r, "id")
+
+	if err := repository.DeletePhaseTransition(repository.DB, transitionID); err != nil {
+		renderTransitionError(w, r, err, "Failed to delete transition")
+		return
+	}
+
+	logger.AppLogger.WithField("transition_id", transitionID).Info("🗑️ Phase transition deleted")
+	broadcastWorkflowGraphChanged("deleted", repository.PhaseTransition{ID: transitionID})
+
+	render.JSON(w, r, map[string]string{"status": "deleted", "id": transitionID})
+}
+
+// renderTransitionError maps transition repository errors onto HTTP statuses
+func renderTransitionError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrTransitionNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvalidTransition):
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+	case errors.Is(err, repository.ErrPhaseOrphaned):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+	default:
+		logger.AppLogger.WithError(err).Error(fallback)
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": fallback})
+	}
+}
+
+// broadcastWorkflowGraphChanged tells the clinicians connected to any session that the phase
+// graph changed so open studio views refetch it. The graph is global, so it reaches every
+// organization; client sockets have no studio view and aren't sent it.
+func broadcastWorkflowGraphChanged(action string, transition repository.PhaseTransition) {
+	update := shared.TherapySessionUpdate{
+		Type: shared.MessageTypeWorkflowGraphChanged,
+		Metadata: map[string]interface{}{
+			"action":        action,
+			"transition_id": transition.ID,
+			"from_phase_id": transition.FromPhaseID,
+			"to_phase_id":   transition.ToPhaseID,
+		},
+		Timestamp: time.Now(),
+	}
+
+	sessionConnMutex.RLock()
+	sessionIDs := make([]string, 0, len(sessionConnections))
+	for sessionID := range sessionConnections {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sessionConnMutex.RUnlock()
+
+	for _, sessionID := range sessionIDs {
+		for _, conn := range sessionConnectionList(sessionID) {
+			if conn.participantRole != repository.ParticipantRoleClient {
+				sendSessionUpdate(sessionID, conn, update)
+			}
+		}
+	}
+}
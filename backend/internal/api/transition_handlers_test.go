@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/shared"
+)
+
+func TestCreateTransitionNotifiesEveryOrganizationsClinicians(t *testing.T) {
+	db := newTestEnv(t)
+	createTestPhases(t, db, "intake", "closing")
+	editing := createTestSession(t, db, "intake")
+	other := createTestSession(t, db, "intake")
+	if err := db.Model(other).Update("organization_id", "other-org").Error; err != nil {
+		t.Fatalf("failed to move session: %v", err)
+	}
+	sockets := map[string]*testSocket{
+		"editing organization": connectTestSocketAt(t, editing.ID, shared.ProtocolVersion, repository.ParticipantRoleTherapist),
+		"other organization":   connectTestSocket(t, other.ID),
+	}
+	// Clients have no studio view to refresh
+	client := connectTestSocketAt(t, editing.ID, shared.ProtocolVersion, repository.ParticipantRoleClient)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/transitions",
+		strings.NewReader(`{"from_phase_id":"intake","to_phase_id":"closing"}`))
+	req = req.WithContext(repository.WithOrganization(req.Context(), editing.OrganizationID))
+	rec := httptest.NewRecorder()
+	CreateTransitionHandler(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for name, socket := range sockets {
+		notified := false
+		for _, update := range socket.drain(200 * time.Millisecond) {
+			if update.Type == shared.MessageTypeWorkflowGraphChanged && update.Metadata["action"] == "created" {
+				notified = true
+			}
+		}
+		if !notified {
+			t.Errorf("session in the %s was not told the graph changed", name)
+		}
+	}
+	for _, update := range client.drain(100 * time.Millisecond) {
+		if update.Type == shared.MessageTypeWorkflowGraphChanged {
+			t.Error("the client's socket was sent workflow_graph_changed")
+		}
+	}
+}
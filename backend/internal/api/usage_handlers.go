@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetSessionUsageHandler returns the Gemini tokens a session has used
+// @Summary Get session token usage
+// @Description The session's Gemini token totals by agent and model, with an estimated cost in USD when every model used has a price configured in GEMINI_TOKEN_PRICES
+// @Tags sessions
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} repository.SessionUsage
+// @Failure 404 {object} map[string]string
+// @Router /api/sessions/{sessionId}/usage [get]
+func GetSessionUsageHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionId")
+
+	if err := repository.Scoped(r.Context()).Select("id").First(&repository.Session{}, "id = ?", sessionID).Error; err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Session not found"})
+		return
+	}
+
+	usage, err := repository.SessionTokenUsageReport(repository.DB, sessionID)
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Error("Failed to fetch token usage")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to fetch token usage"})
+		return
+	}
+	if usage.Breakdown == nil {
+		usage.Breakdown = []repository.SessionTokenUsage{}
+	}
+
+	render.JSON(w, r, usage)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"therapy-navigation-system/shared"
+)
+
+// eventVersions records the protocol version that introduced each event type. Types not
+// listed date from v1; a new event type goes here with a bump of shared.ProtocolVersion.
+var eventVersions = map[string]int{
+	shared.MessageTypePhasePreview:           3,
+	shared.MessageTypeCoachTyping:            3,
+	shared.MessageTypePhaseVisualization:     3,
+	shared.MessageTypeServiceUnavailable:     3,
+	shared.MessageTypeAttachment:             3,
+	shared.MessageTypeRequirementSatisfied:   3,
+	shared.MessageTypePhaseRollback:          3,
+	shared.MessageTypeWorkflowGraphChanged:   3,
+	shared.MessageTypeMessageChunk:           3,
+	shared.MessageTypeMessageComplete:        3,
+	shared.MessageTypeFieldCorrected:         3,
+	shared.MessageTypeTransitionLoopDetected: 3,
+	shared.MessageTypeTransitionLoopReviewed: 3,
+	shared.MessageTypeParticipantsUpdated:    3,
+	"session_status":                         3,
+}
+
+// fieldsVersion is the protocol version that added Phase.Visualization and Message.ParticipantID
+const fieldsVersion = 3
+
+// legacyEvents rewrite an event newer than the client's protocol version as an older event
+// with the same meaning. Newer events without one aren't sent to that client.
+var legacyEvents = map[string]func(shared.TherapySessionUpdate) (shared.TherapySessionUpdate, bool){
+	// A rollback is a phase transition to an earlier phase
+	shared.MessageTypePhaseRollback: func(update shared.TherapySessionUpdate) (shared.TherapySessionUpdate, bool) {
+		update.Type = "phase_transition"
+		return update, true
+	},
+	// Clients that don't stream get the saved reply as an ordinary message
+	shared.MessageTypeMessageComplete: func(update shared.TherapySessionUpdate) (shared.TherapySessionUpdate, bool) {
+		if update.Message == nil {
+			return update, false
+		}
+		return shared.TherapySessionUpdate{Type: "message", Message: update.Message, Timestamp: update.Timestamp}, true
+	},
+}
+
+// negotiateProtocolVersion picks the protocol version to speak with a connecting client
+func negotiateProtocolVersion(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("protocol_version")
+	if raw == "" {
+		// Clients built before versioning never send it
+		return shared.MinProtocolVersion, nil
+	}
+
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid protocol_version %q", raw)
+	}
+	if version < shared.MinProtocolVersion {
+		return 0, fmt.Errorf("client protocol version %d is older than the minimum supported version %d", version, shared.MinProtocolVersion)
+	}
+	if version > shared.ProtocolVersion {
+		// Newer clients are expected to speak down to the server's version
+		return shared.ProtocolVersion, nil
+	}
+
+	return version, nil
+}
+
+// adaptUpdateForProtocol tailors an outbound update to the client's protocol version. It
+// returns false when the client's version has no equivalent of the event.
+func adaptUpdateForProtocol(update shared.TherapySessionUpdate, version int) (shared.TherapySessionUpdate, bool) {
+	if introduced, ok := eventVersions[update.Type]; ok && version < introduced {
+		legacy, ok := legacyEvents[update.Type]
+		if !ok {
+			return update, false
+		}
+		if update, ok = legacy(update); !ok {
+			return update, false
+		}
+	}
+
+	if version < fieldsVersion {
+		update = withoutNewerFields(update)
+	}
+
+	if version < 2 {
+		// v1 clients predate the versioned envelope
+		update.ProtocolVersion = 0
+		return update, true
+	}
+
+	update.ProtocolVersion = version
+	return update, true
+}
+
+// withoutNewerFields clears the fields fieldsVersion added. The update's slices are shared
+// with the other connections it's broadcast to, so they're copied rather than edited.
+func withoutNewerFields(update shared.TherapySessionUpdate) shared.TherapySessionUpdate {
+	if update.Phases != nil {
+		phases := make([]shared.Phase, len(update.Phases))
+		for i, phase := range update.Phases {
+			phase.Visualization = nil
+			phases[i] = phase
+		}
+		update.Phases = phases
+	}
+	if update.RecentMessages != nil {
+		messages := make([]shared.Message, len(update.RecentMessages))
+		for i, message := range update.RecentMessages {
+			message.ParticipantID = ""
+			messages[i] = message
+		}
+		update.RecentMessages = messages
+	}
+	if update.Message != nil {
+		message := *update.Message
+		message.ParticipantID = ""
+		update.Message = &message
+	}
+	return update
+}
+
+// protocolHandshakeMetadata describes the negotiated protocol in the connected event
+func protocolHandshakeMetadata(version int) map[string]interface{} {
+	return map[string]interface{}{
+		"protocol_version":        version,
+		"server_protocol_version": shared.ProtocolVersion,
+		"min_protocol_version":    shared.MinProtocolVersion,
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"therapy-navigation-system/shared"
+)
+
+func TestOlderProtocolClientsOnlyReceiveEventsTheyUnderstand(t *testing.T) {
+	newTestEnv(t)
+	v1 := connectTestSocketAt(t, "session-1", 1, "")
+	current := connectTestSocket(t, "session-1")
+
+	broadcastSessionUpdate("session-1", shared.TherapySessionUpdate{
+		Type:      shared.MessageTypePhasePreview,
+		Metadata:  map[string]interface{}{"next_phase": "body_scan"},
+		Timestamp: time.Now(),
+	})
+	broadcastSessionUpdate("session-1", shared.TherapySessionUpdate{
+		Type:      shared.MessageTypePhaseRollback,
+		Phase:     "intake",
+		Metadata:  map[string]interface{}{"from_phase": "status_check", "to_phase": "intake"},
+		Timestamp: time.Now(),
+	})
+	broadcastSessionUpdate("session-1", shared.TherapySessionUpdate{
+		Type:      "message",
+		Message:   &shared.Message{ID: "message-1", Content: "hello", Role: "client", ParticipantID: "person-1"},
+		Phases:    []shared.Phase{{ID: "intake", Visualization: &shared.PhaseVisualization{Type: "ocean_waves"}}},
+		Timestamp: time.Now(),
+	})
+
+	var v1Types []string
+	for _, update := range v1.drain(200 * time.Millisecond) {
+		v1Types = append(v1Types, update.Type)
+		if update.ProtocolVersion != 0 {
+			t.Errorf("v1 %s carried protocol_version %d", update.Type, update.ProtocolVersion)
+		}
+		if update.Type == "phase_transition" && (update.Phase != "intake" || update.Metadata["from_phase"] != "status_check") {
+			t.Errorf("translated rollback = %+v, want a phase_transition back to intake", update)
+		}
+		if update.Type == "message" && (update.Message.ParticipantID != "" || update.Phases[0].Visualization != nil) {
+			t.Errorf("v1 message kept v3 fields: participant %q, visualization %+v", update.Message.ParticipantID, update.Phases[0].Visualization)
+		}
+	}
+	if len(v1Types) != 2 || v1Types[0] != "phase_transition" || v1Types[1] != "message" {
+		t.Errorf("v1 client received %v, want the rollback as phase_transition and the message, without phase_preview", v1Types)
+	}
+
+	var currentTypes []string
+	for _, update := range current.drain(200 * time.Millisecond) {
+		currentTypes = append(currentTypes, update.Type)
+		if update.ProtocolVersion != shared.ProtocolVersion {
+			t.Errorf("%s carried protocol_version %d, want %d", update.Type, update.ProtocolVersion, shared.ProtocolVersion)
+		}
+		if update.Type == "message" && (update.Message.ParticipantID != "person-1" || update.Phases[0].Visualization == nil) {
+			t.Errorf("current client's message lost its fields: %+v", update)
+		}
+	}
+	if len(currentTypes) != 3 || currentTypes[0] != shared.MessageTypePhasePreview || currentTypes[1] != shared.MessageTypePhaseRollback {
+		t.Errorf("current client received %v, want every event unchanged", currentTypes)
+	}
+}
+
+func TestStreamedRepliesReachClientsThatPredateStreaming(t *testing.T) {
+	complete := shared.TherapySessionUpdate{
+		Type:      shared.MessageTypeMessageComplete,
+		Metadata:  map[string]interface{}{"message_id": "message-1", "chunks": 4},
+		Message:   &shared.Message{ID: "message-1", Content: "Let's begin."},
+		Timestamp: time.Now(),
+	}
+	update, ok := adaptUpdateForProtocol(complete, 2)
+	if !ok || update.Type != "message" || update.Message.ID != "message-1" || update.Metadata != nil {
+		t.Errorf("v2 message_complete = %+v, %t; want the saved reply as a plain message", update, ok)
+	}
+
+	complete.Message = nil
+	if _, ok := adaptUpdateForProtocol(complete, 2); ok {
+		t.Error("a failed stream with no saved message was sent to a v2 client")
+	}
+	if _, ok := adaptUpdateForProtocol(shared.TherapySessionUpdate{Type: shared.MessageTypeMessageChunk}, 2); ok {
+		t.Error("message_chunk was sent to a v2 client")
+	}
+}
@@ -2,12 +2,18 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
+	contextbuilder "therapy-navigation-system/internal/context"
 	"therapy-navigation-system/internal/logger"
 	"therapy-navigation-system/internal/repository"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"gorm.io/gorm"
 )
 
 // UpdatePhaseRequest represents the request body for updating a phase
@@ -19,17 +25,19 @@ type UpdatePhaseRequest struct {
 	MinTurns             int    `json:"min_turns"`
 	RecommendedDuration  int    `json:"recommended_duration"`
 	MaxDuration          int    `json:"max_duration"`
+	ModelName            *string `json:"model_name,omitempty"` // Coach model for the phase; "" reverts to the default, omitted leaves it
 }
 
 // UpdatePhaseHandler updates phase configuration
 // @Summary Update phase configuration
-// @Description Update phase display name, description, colors, icons, and timing requirements
+// @Description Update phase display name, description, colors, icons, timing requirements and coach model
 // @Tags phases
 // @Accept json
 // @Produce json
 // @Param id path string true "Phase ID"
 // @Param phase body UpdatePhaseRequest true "Phase update request"
 // @Success 200 {object} repository.Phase
+// @Failure 400 {object} map[string]string
 // @Router /api/phases/{id} [put]
 func UpdatePhaseHandler(w http.ResponseWriter, r *http.Request) {
 	phaseID := chi.URLParam(r, "id")
@@ -61,6 +69,20 @@ func UpdatePhaseHandler(w http.ResponseWriter, r *http.Request) {
 	phase.MinimumTurns = req.MinTurns
 	phase.RecommendedDurationSeconds = req.RecommendedDuration
 	phase.DurationSeconds = req.MaxDuration
+	if req.ModelName != nil {
+		model := strings.TrimSpace(*req.ModelName)
+		if model != "" && !contextbuilder.KnownModel(model) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Unknown model_name: " + model})
+			return
+		}
+		phase.ModelName = model
+	}
+	// Timing set here is deliberate, even 0; keep the protocol backfill off it
+	if phase.ProtocolDefaultsAppliedAt == nil {
+		now := time.Now()
+		phase.ProtocolDefaultsAppliedAt = &now
+	}
 
 	// Save the updated phase
 	if err := repository.DB.Save(&phase).Error; err != nil {
@@ -115,6 +137,43 @@ func GetAllPhaseDataHandler(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, phaseData)
 }
 
+// GetWorkflowDiagnosticsHandler reports phases whose prompt and phase data disagree, and
+// phases with no prompt at all
+// @Summary Get workflow diagnostics
+// @Description Cross-check each phase's active prompt against its PhaseData: data keys the prompt asks for that aren't modeled, required fields the prompt never mentions, and phases that require data without a prompt. Also lists non-terminal phases with no active prompt.
+// @Tags phases
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/workflow/diagnostics [get]
+func GetWorkflowDiagnosticsHandler(w http.ResponseWriter, r *http.Request) {
+	misaligned, err := repository.CheckPhasePromptAlignment(repository.DB)
+	if err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to check phase prompts against phase data")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to run workflow diagnostics"})
+		return
+	}
+	if misaligned == nil {
+		misaligned = []repository.PhasePromptAlignment{}
+	}
+	uncovered, err := repository.PhasesWithoutPrompts(repository.DB)
+	if err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to check phase prompt coverage")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to run workflow diagnostics"})
+		return
+	}
+	if uncovered == nil {
+		uncovered = []string{}
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"aligned":                len(misaligned) == 0 && len(uncovered) == 0,
+		"prompt_data_issues":     misaligned,
+		"phases_without_prompts": uncovered,
+	})
+}
+
 // GetWorkflowPromptsHandler returns all active prompts for workflow studio
 // @Summary Get all active prompts
 // @Description Get all currently active prompts for all phases
@@ -163,7 +222,7 @@ func GetWorkflowPromptsHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetPromptHistoryHandler returns the version history for a phase's prompts
 // @Summary Get prompt version history
-// @Description Get all versions of prompts for a specific phase
+// @Description Get all versions of prompts for a specific phase, newest first
 // @Tags prompts
 // @Produce json
 // @Param phaseId path string true "Phase ID"
@@ -173,8 +232,8 @@ func GetPromptHistoryHandler(w http.ResponseWriter, r *http.Request) {
 	phaseID := chi.URLParam(r, "phaseId")
 
 	var versions []repository.Prompt
-	if err := repository.DB.Where("phase_id = ?", phaseID).
-		Order("version DESC").
+	if err := repository.DB.Where("workflow_phase = ?", phaseID).
+		Order("version DESC, created_at DESC").
 		Find(&versions).Error; err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to fetch prompt history")
 		render.Status(r, http.StatusInternalServerError)
@@ -191,9 +250,9 @@ type UpdatePromptRequest struct {
 	Content string `json:"content"`
 }
 
-// UpdatePromptHandler updates or creates a prompt for a phase
+// UpdatePromptHandler publishes new content for a prompt as its next version
 // @Summary Update prompt
-// @Description Create a new version of an existing prompt
+// @Description Create a new version of an existing prompt; the new version becomes the only active prompt for its phase and category
 // @Tags prompts
 // @Accept json
 // @Produce json
@@ -219,25 +278,21 @@ func UpdatePromptHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update prompt content and increment version
-	prompt.Content = req.Content
-	prompt.Version = prompt.Version + 1
-
-	// Save updated prompt
-	if err := repository.DB.Save(&prompt).Error; err != nil {
+	published, err := repository.PublishPromptVersion(repository.DB, prompt, req.Content, promptAuthor(r))
+	if err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to update prompt")
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Failed to create prompt version"})
 		return
 	}
 
-	logger.AppLogger.WithField("prompt_id", promptID).Info("Prompt updated with new version")
-	render.JSON(w, r, prompt)
+	logger.AppLogger.WithField("prompt_id", published.ID).WithField("version", published.Version).Info("Prompt updated with new version")
+	render.JSON(w, r, published)
 }
 
 // CreatePromptHandler creates a new prompt for a phase
 // @Summary Create prompt
-// @Description Create a new prompt for a phase
+// @Description Create a new prompt for a phase; any active phase prompt is superseded and kept as history
 // @Tags prompts
 // @Accept json
 // @Produce json
@@ -251,54 +306,53 @@ func CreatePromptHandler(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
 		return
 	}
-
-	// Check if a prompt already exists for this phase
-	var existingCount int64
-	repository.DB.Model(&repository.Prompt{}).
-		Where("phase_id = ? AND is_active = ?", req.PhaseID, true).
-		Count(&existingCount)
-
-	if existingCount > 0 {
-		// Deactivate existing prompts
-		repository.DB.Model(&repository.Prompt{}).
-			Where("phase_id = ? AND is_active = ?", req.PhaseID, true).
-			Update("is_active", false)
+	if req.PhaseID == "" {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "phase_id is required"})
+		return
+	}
+	req.PhaseID = repository.CanonicalPhaseID(req.PhaseID)
+	var phase repository.Phase
+	if err := repository.DB.First(&phase, "id = ?", req.PhaseID).Error; err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": fmt.Sprintf("phase_id %q does not name an existing phase", req.PhaseID)})
+		return
 	}
 
-	// Create new prompt
-	newPrompt := repository.Prompt{
-		ID:            "prompt_" + req.PhaseID,
-		Name:          "Phase " + req.PhaseID + " Prompt",
-		Category:      "phase",
-		Content:       req.Content,
+	// Continue the phase's existing version line if it has one
+	base := repository.Prompt{
+		Name:          req.PhaseID,
+		Category:      string(repository.PromptCategoryPhase),
 		WorkflowPhase: req.PhaseID,
-		Version:       1,
-		IsActive:      true,
 	}
+	repository.DB.
+		Where("workflow_phase = ? AND category = ? AND COALESCE(feature_flag, '') = ''", req.PhaseID, base.Category).
+		Order("version DESC").
+		First(&base)
 
-	if err := repository.DB.Create(&newPrompt).Error; err != nil {
+	newPrompt, err := repository.PublishPromptVersion(repository.DB, base, req.Content, promptAuthor(r))
+	if err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to create prompt")
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Failed to create prompt"})
 		return
 	}
 
-	logger.AppLogger.WithField("phase_id", req.PhaseID).Info("Prompt created successfully")
+	logger.AppLogger.WithField("phase_id", req.PhaseID).WithField("version", newPrompt.Version).Info("Prompt created successfully")
 	render.Status(r, http.StatusCreated)
 	render.JSON(w, r, newPrompt)
 }
 
 // RevertPromptVersionHandler reverts to a specific prompt version
 // @Summary Revert prompt version
-// @Description Revert a prompt to a previous version
+// @Description Publish an earlier version's content as a new active version
 // @Tags prompts
 // @Produce json
 // @Param id path string true "Prompt ID"
-// @Param versionId path string true "Version ID"
+// @Param versionId path string true "ID of the prompt version to restore"
 // @Success 200 {object} repository.Prompt
 // @Router /api/prompts/{id}/revert/{versionId} [put]
 func RevertPromptVersionHandler(w http.ResponseWriter, r *http.Request) {
-	_ = chi.URLParam(r, "id") // promptID - not used in this version
 	versionID := chi.URLParam(r, "versionId")
 
 	// Find the version to revert to
@@ -309,17 +363,52 @@ func RevertPromptVersionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Simply update the prompt with reverted content
-	targetVersion.Version++
-	targetVersion.IsActive = true
-
-	if err := repository.DB.Save(&targetVersion).Error; err != nil {
+	// Reverting is a new version with the old content, so history stays append-only
+	reverted, err := repository.PublishPromptVersion(repository.DB, targetVersion, targetVersion.Content, promptAuthor(r))
+	if err != nil {
 		logger.AppLogger.WithError(err).Error("Failed to revert prompt version")
 		render.Status(r, http.StatusInternalServerError)
 		render.JSON(w, r, map[string]string{"error": "Failed to revert version"})
 		return
 	}
 
-	logger.AppLogger.WithField("version_id", versionID).Info("Prompt reverted to previous version")
-	render.JSON(w, r, targetVersion)
-}
\ No newline at end of file
+	logger.AppLogger.WithField("version_id", versionID).WithField("version", reverted.Version).Info("Prompt reverted to previous version")
+	render.JSON(w, r, reverted)
+}
+
+// SetPrimaryPromptHandler flags a prompt as its phase's primary prompt
+// @Summary Set primary prompt
+// @Description Mark the prompt as primary for its phase, category and feature flag. With PHASE_PROMPT_SELECTION=primary it is used when a phase has several active prompts.
+// @Tags prompts
+// @Produce json
+// @Param id path string true "Prompt ID"
+// @Success 200 {object} repository.Prompt
+// @Failure 404 {object} map[string]string
+// @Router /api/prompts/{id}/primary [put]
+func SetPrimaryPromptHandler(w http.ResponseWriter, r *http.Request) {
+	promptID := chi.URLParam(r, "id")
+
+	prompt, err := repository.SetPrimaryPrompt(repository.Scoped(r.Context()), promptID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Prompt not found"})
+		return
+	}
+	if err != nil {
+		logger.AppLogger.WithError(err).WithField("prompt_id", promptID).Error("Failed to set primary prompt")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to set primary prompt"})
+		return
+	}
+
+	logger.AppLogger.WithField("prompt_id", prompt.ID).WithField("phase_id", prompt.WorkflowPhase).Info("Primary prompt set")
+	render.JSON(w, r, prompt)
+}
+
+// promptAuthor identifies who changed a prompt, from the authenticated email when there is one
+func promptAuthor(r *http.Request) string {
+	if email, ok := r.Context().Value("user_email").(string); ok {
+		return email
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func createTestPrompt(t *testing.T, phaseID string, content string) {
+	t.Helper()
+	body := `{"phase_id":"` + phaseID + `","content":"` + content + `"}`
+	rec := httptest.NewRecorder()
+	CreatePromptHandler(rec, httptest.NewRequest(http.MethodPost, "/api/prompts", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating prompt for %s: status %d: %s", phaseID, rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetPromptHistoryReturnsEveryVersionNewestFirst(t *testing.T) {
+	db := newTestEnv(t)
+	createTestPhases(t, db, "body_scan", "eye_position")
+	for _, content := range []string{"first", "second", "third"} {
+		createTestPrompt(t, "body_scan", content)
+	}
+	createTestPrompt(t, "eye_position", "another phase")
+
+	router := chi.NewRouter()
+	router.Get("/api/phases/{phaseId}/prompts/history", GetPromptHistoryHandler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/phases/body_scan/prompts/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var history []repository.Prompt
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("got %d versions, want 3", len(history))
+	}
+	for i, want := range []struct {
+		version int
+		content string
+		active  bool
+	}{{3, "third", true}, {2, "second", false}, {1, "first", false}} {
+		got := history[i]
+		if got.Version != want.version || got.Content != want.content || got.IsActive != want.active {
+			t.Errorf("history[%d] = v%d %q active=%t, want v%d %q active=%t",
+				i, got.Version, got.Content, got.IsActive, want.version, want.content, want.active)
+		}
+		if got.WorkflowPhase != "body_scan" {
+			t.Errorf("history[%d] belongs to phase %s", i, got.WorkflowPhase)
+		}
+	}
+}
+
+func TestUpdatePhaseRejectsUnknownModel(t *testing.T) {
+	db := newTestEnv(t)
+	createTestPhases(t, db, "intake")
+
+	router := chi.NewRouter()
+	router.Put("/api/phases/{id}", UpdatePhaseHandler)
+	for _, tc := range []struct {
+		model string
+		want  int
+	}{
+		{"gemini-9-ultra", http.StatusBadRequest},
+		{"gemini-2.5-pro", http.StatusOK},
+		{"", http.StatusOK},
+	} {
+		body := `{"display_name":"Intake","model_name":"` + tc.model + `"}`
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/phases/intake", strings.NewReader(body)))
+		if rec.Code != tc.want {
+			t.Errorf("model %q: status %d, want %d: %s", tc.model, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+
+	var phase repository.Phase
+	db.First(&phase, "id = ?", "intake")
+	if phase.ModelName != "" {
+		t.Errorf("phase model is %q after reverting to the default", phase.ModelName)
+	}
+}
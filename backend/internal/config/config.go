@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,7 +16,11 @@ type Config struct {
 	Environment string // dev, staging, prod
 
 	// Database
-	DatabaseURL string
+	DatabaseURL       string
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBQueryTimeout    time.Duration // Applied to hot-path queries and as the Postgres statement_timeout
 
 	// GCP Configuration
 	GCPProjectID string
@@ -35,6 +40,159 @@ type Config struct {
 	AITemperature float32
 	AIMaxTokens   int
 
+	// CoachModel generates coach replies in phases and templates that don't name a model
+	CoachModel string
+
+	// AIContextWindowTokens overrides the model's known context limit (0 = use the model's)
+	AIContextWindowTokens int
+
+	// Session Behavior
+	MaxToolCallsPerTurn int // Tool calls beyond this in a single model turn are dropped
+	SUDSPromptCadence   int // Client turns without a SUDS reading before the model is told to ask (0 = off)
+
+	// Similarity (0-1) at which consecutive coach messages count as repeats (0 = off)
+	RepetitionThreshold float32
+
+	// Client data guard: reject client-sourced fields no recent client message supports
+	ClientDataGuardEnabled bool
+	ClientDataGuardWindow  int // Most recent messages searched for supporting client text
+
+	// Tool calls are remembered by ID for this long so retried deliveries apply once
+	ToolCallRetention time.Duration
+
+	// Comma-separated MCP tools to withhold, e.g. therapy_session_transition
+	MCPDisabledTools string
+
+	// A collect's auto-transition sends its workflow_update instead of the collect sending one too
+	ConsolidateWorkflowBroadcasts bool
+
+	// Auto mode set on connect: phases advance as soon as their requirements are met
+	SessionAutoMode bool
+
+	// Prompt token budget and optional section shares, e.g. "working=0.5,awareness=0.1"
+	ContextTokenBudget   int
+	ContextSectionShares string
+
+	// Run the connect-time initial-state queries concurrently
+	InitialStateConcurrent bool
+
+	// tiktoken-format BPE vocabulary for token counts. The default is the cl100k_base file
+	// the image ships (make tokenizer-vocab fetches it locally); ~4 chars/token only if it
+	// fails to load.
+	TokenizerVocab string
+
+	// How system and tool-call messages appear in working memory: annotate, exclude
+	WorkingMemorySystemMessages string
+
+	// Feed the structured results of the model's tool calls into its next turn
+	ToolResultFeedback bool
+
+	// Phase prompt used when a phase has several active: highest_version, primary or concatenate
+	PhasePromptSelection string
+
+	// Client messages longer than this many characters are condensed in working memory
+	LongMessageChars int
+	LongMessageMode  string // summarize, truncate, off
+
+	// Hold coach replies for each phase's minimum response delay
+	CoachResponsePacing bool
+
+	// How often running session timers are persisted so reconnects resume them
+	TimerFlushInterval time.Duration
+
+	// How long a timer start waits for the session's stopping timer to exit before giving up
+	TimerRestartWait time.Duration
+
+	// Status-check decision criteria: off, advise (flag mismatches), enforce (reject mismatches)
+	StatusDecisionMode    string
+	StatusProcessingLimit time.Duration // Processing time after which residual SUDS is de-escalated
+
+	// Full prompt/response content in the prompt log; otherwise metadata only
+	PromptContentLogging bool
+
+	// Also write prompt log rows to logs/prompts.jsonl, for local debugging
+	PromptLogFile bool
+
+	// Rotate logs/prompts.jsonl once it reaches this size (MB); 0 disables rotation
+	PromptLogMaxSizeMB int
+
+	// Normalize new phase IDs and reject rows referencing phases that don't exist
+	PhaseIDValidation bool
+	PhaseAliases      string // Legacy phase IDs rewritten to canonical ones: "old=new,old2=new2"
+
+	// Pending migrations older than the schema version: enforce (refuse) or warn (apply)
+	MigrationOrdering string
+
+	// Duplicate session field values found when making them unique: merge (keep latest) or fail
+	FieldValueDuplicates string
+
+	// Stored field values that aren't JSON: lenient (read as text) or strict (skipped as errors)
+	FieldValueDecoding string
+
+	// Transitions since the last review before coach transitions are held as a runaway loop; 0 disables
+	MaxPhaseTransitions int
+
+	// Session status transitions: enforce, warn or off
+	SessionStatusEnforcement string
+
+	// Phase prompts checked against their PhaseData at startup: off, warn or strict
+	PhasePromptDataCheck string
+	// Extra data keys prompts may use that aren't fields, comma-separated
+	PhasePromptDataIgnore string
+	// Non-terminal phases without an active prompt at startup: off, warn or strict
+	PhasePromptCoverageCheck string
+
+	// Store per-session Gemini token totals from the API's usage metadata
+	TokenUsageAudit bool
+	// Gemini prices for session cost estimates: "model=input/output,...", USD per million tokens
+	GeminiTokenPrices string
+
+	// Roles a session participant may hold, comma-separated; client and therapist are always allowed
+	SessionParticipantRoles string
+
+	// Mark scheduled sessions active on the client's first message
+	AutoActivateSessions bool
+
+	// Prompt check-ins at each phase's CheckInIntervalSeconds while the timer runs
+	CheckInScheduling bool
+
+	// Fill phase timing missing from the database with the protocol definition's values
+	ProtocolBackfill bool
+	// Protocol definition source: embedded or file; empty uses the file when ProtocolPath
+	// is set, otherwise the embedded definition. The protocol only supplies timing defaults
+	// for ProtocolBackfill; phases and their data come from the database.
+	ProtocolSource string
+	// Protocol definition JSON file for the file source
+	ProtocolPath string
+
+	// Record each pause/resume and its reason in the session's pause history
+	PauseHistory bool
+
+	// Model function-call arguments vs tool declarations: off, warn, enforce (return a correction)
+	ToolArgValidation string
+
+	// Bulk intake re-extraction: parallel workers and sessions started per second
+	BulkExtractConcurrency   int
+	BulkExtractRatePerSecond float64
+
+	// Retries of Gemini calls failing with 429/5xx or timeouts: attempts (including the
+	// first) and the backoff delay before the first retry, doubling after each
+	GeminiRetryAttempts  int
+	GeminiRetryBaseDelay time.Duration
+
+	// Protocols whose sessions get an AI-proposed plan before starting: "brainspotting,..."
+	SessionPlanProtocols string
+
+	// Session attachments: "local" disk under AttachmentDir, or "gcs" in AttachmentBucket
+	AttachmentBackend      string
+	AttachmentDir          string
+	AttachmentBucket       string
+	AttachmentMaxBytes     int64
+	AttachmentContentTypes string // Comma-separated allowed MIME types
+
+	// Metrics
+	MetricsSnapshotTTL time.Duration // How long GET /api/metrics/snapshot serves a cached snapshot
+
 	// Tenant Configuration (for multi-tenant future)
 	TenantMode      string // single, multi
 	DefaultTenantID string
@@ -68,7 +226,11 @@ func Load() (*Config, error) {
 		Environment: getEnvOrDefault("ENVIRONMENT", "dev"),
 
 		// Database
-		DatabaseURL: getEnvOrDefault("DATABASE_URL", "sqlite://therapy.db"),
+		DatabaseURL:       getEnvOrDefault("DATABASE_URL", "sqlite://therapy.db"),
+		DBMaxOpenConns:    getIntEnvOrDefault("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getIntEnvOrDefault("DB_MAX_IDLE_CONNS", 5),
+		DBConnMaxLifetime: getDurationEnvOrDefault("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBQueryTimeout:    getDurationEnvOrDefault("DB_QUERY_TIMEOUT", 5*time.Second),
 
 		// GCP Configuration
 		GCPProjectID: getEnvOrDefault("GCP_PROJECT_ID", "therapy-nav-poc-quan"),
@@ -88,6 +250,102 @@ func Load() (*Config, error) {
 		AITemperature: getFloatEnvOrDefault("AI_TEMPERATURE", 0.7),
 		AIMaxTokens:   getIntEnvOrDefault("AI_MAX_TOKENS", 500),
 
+		CoachModel:            getEnvOrDefault("COACH_MODEL", "gemini-2.0-flash"),
+		AIContextWindowTokens: getIntEnvOrDefault("AI_CONTEXT_WINDOW_TOKENS", 0),
+
+		// Session Behavior
+		MaxToolCallsPerTurn: getIntEnvOrDefault("MAX_TOOL_CALLS_PER_TURN", 5),
+		SUDSPromptCadence:   getIntEnvOrDefault("SUDS_PROMPT_CADENCE", 3),
+
+		RepetitionThreshold: getFloatEnvOrDefault("REPETITION_THRESHOLD", 0.8),
+
+		ClientDataGuardEnabled: getBoolEnvOrDefault("CLIENT_DATA_GUARD_ENABLED", true),
+		ClientDataGuardWindow:  getIntEnvOrDefault("CLIENT_DATA_GUARD_WINDOW", 6),
+
+		ToolCallRetention: getDurationEnvOrDefault("TOOL_CALL_RETENTION", 24*time.Hour),
+
+		MCPDisabledTools: getEnvOrDefault("MCP_DISABLED_TOOLS", ""),
+
+		ConsolidateWorkflowBroadcasts: getBoolEnvOrDefault("CONSOLIDATE_WORKFLOW_BROADCASTS", true),
+
+		SessionAutoMode: getBoolEnvOrDefault("SESSION_AUTO_MODE", true),
+
+		ContextTokenBudget:   getIntEnvOrDefault("CONTEXT_TOKEN_BUDGET", 1500),
+		ContextSectionShares: getEnvOrDefault("CONTEXT_SECTION_SHARES", ""),
+
+		InitialStateConcurrent: getBoolEnvOrDefault("INITIAL_STATE_CONCURRENT", true),
+
+		TokenizerVocab: getEnvOrDefault("TOKENIZER_VOCAB", "assets/cl100k_base.tiktoken"),
+
+		WorkingMemorySystemMessages: getEnvOrDefault("WORKING_MEMORY_SYSTEM_MESSAGES", "annotate"),
+
+		ToolResultFeedback: getBoolEnvOrDefault("TOOL_RESULT_FEEDBACK", true),
+
+		PhasePromptSelection: getEnvOrDefault("PHASE_PROMPT_SELECTION", "highest_version"),
+
+		LongMessageChars: getIntEnvOrDefault("LONG_MESSAGE_CHARS", 600),
+		LongMessageMode:  getEnvOrDefault("LONG_MESSAGE_MODE", "summarize"),
+
+		CoachResponsePacing: getBoolEnvOrDefault("COACH_RESPONSE_PACING", true),
+
+		TimerFlushInterval: getDurationEnvOrDefault("TIMER_FLUSH_INTERVAL", 15*time.Second),
+
+		TimerRestartWait: getDurationEnvOrDefault("TIMER_RESTART_WAIT", 5*time.Second),
+
+		StatusDecisionMode:    getEnvOrDefault("STATUS_DECISION_MODE", "advise"),
+		StatusProcessingLimit: getDurationEnvOrDefault("STATUS_PROCESSING_LIMIT", 20*time.Minute),
+
+		PhaseIDValidation: getBoolEnvOrDefault("PHASE_ID_VALIDATION", true),
+		PhaseAliases:      getEnvOrDefault("PHASE_ALIASES", "completion=complete"),
+
+		MigrationOrdering: getEnvOrDefault("MIGRATION_ORDERING", "enforce"),
+
+		FieldValueDuplicates: getEnvOrDefault("FIELD_VALUE_DUPLICATES", "merge"),
+
+		FieldValueDecoding: getEnvOrDefault("FIELD_VALUE_DECODING", "lenient"),
+
+		MaxPhaseTransitions: getIntEnvOrDefault("MAX_PHASE_TRANSITIONS", 50),
+
+		SessionStatusEnforcement: getEnvOrDefault("SESSION_STATUS_ENFORCEMENT", "enforce"),
+
+		PhasePromptDataCheck:     getEnvOrDefault("PHASE_PROMPT_DATA_CHECK", "warn"),
+		PhasePromptDataIgnore:    getEnvOrDefault("PHASE_PROMPT_DATA_IGNORE", ""),
+		PhasePromptCoverageCheck: getEnvOrDefault("PHASE_PROMPT_COVERAGE_CHECK", "warn"),
+
+		TokenUsageAudit:   getBoolEnvOrDefault("TOKEN_USAGE_AUDIT", true),
+		GeminiTokenPrices: getEnvOrDefault("GEMINI_TOKEN_PRICES", ""),
+
+		SessionParticipantRoles: getEnvOrDefault("SESSION_PARTICIPANT_ROLES", "supervisor,co_therapist,observer"),
+
+		AutoActivateSessions: getBoolEnvOrDefault("AUTO_ACTIVATE_SESSIONS", true),
+
+		CheckInScheduling: getBoolEnvOrDefault("CHECK_IN_SCHEDULING", true),
+
+		ProtocolBackfill: getBoolEnvOrDefault("PROTOCOL_BACKFILL", true),
+		ProtocolSource:   getEnvOrDefault("PROTOCOL_SOURCE", ""),
+		ProtocolPath:     getEnvOrDefault("PROTOCOL_PATH", ""),
+
+		PauseHistory: getBoolEnvOrDefault("PAUSE_HISTORY", true),
+
+		ToolArgValidation: getEnvOrDefault("TOOL_ARG_VALIDATION", "enforce"),
+
+		BulkExtractConcurrency:   getIntEnvOrDefault("BULK_EXTRACT_CONCURRENCY", 4),
+		BulkExtractRatePerSecond: float64(getFloatEnvOrDefault("BULK_EXTRACT_RATE_PER_SECOND", 2)),
+
+		SessionPlanProtocols: getEnvOrDefault("SESSION_PLAN_PROTOCOLS", ""),
+
+		GeminiRetryAttempts:  getIntEnvOrDefault("GEMINI_RETRY_ATTEMPTS", 3),
+		GeminiRetryBaseDelay: getDurationEnvOrDefault("GEMINI_RETRY_BASE_DELAY", 500*time.Millisecond),
+
+		AttachmentBackend:      getEnvOrDefault("ATTACHMENT_BACKEND", "local"),
+		AttachmentDir:          getEnvOrDefault("ATTACHMENT_DIR", "data/attachments"),
+		AttachmentBucket:       os.Getenv("ATTACHMENT_BUCKET"),
+		AttachmentMaxBytes:     int64(getIntEnvOrDefault("ATTACHMENT_MAX_BYTES", 10<<20)),
+		AttachmentContentTypes: getEnvOrDefault("ATTACHMENT_CONTENT_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"),
+
+		// Metrics
+		MetricsSnapshotTTL: getDurationEnvOrDefault("METRICS_SNAPSHOT_TTL", 30*time.Second),
+
 		// Tenant Configuration
 		TenantMode:      getEnvOrDefault("TENANT_MODE", "single"),
 		DefaultTenantID: getEnvOrDefault("DEFAULT_TENANT_ID", "default"),
@@ -104,6 +362,11 @@ func Load() (*Config, error) {
 		OTelEndpoint: os.Getenv("OTEL_ENDPOINT"),
 	}
 
+	// Prompt content is privacy-sensitive: off in production unless explicitly enabled
+	cfg.PromptContentLogging = getBoolEnvOrDefault("PROMPT_CONTENT_LOGGING", cfg.Environment != "prod")
+	cfg.PromptLogMaxSizeMB = getIntEnvOrDefault("PROMPT_LOG_MAX_SIZE_MB", 100)
+	cfg.PromptLogFile = getBoolEnvOrDefault("PROMPT_LOG_FILE", false)
+
 	// Validate required fields based on environment
 	if cfg.Environment == "prod" {
 		if cfg.GeminiAPIKey == "" && cfg.OpenAIAPIKey == "" {
@@ -149,6 +412,15 @@ func getBoolEnvOrDefault(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getDurationEnvOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationVal, err := time.ParseDuration(value); err == nil {
+			return durationVal
+		}
+	}
+	return defaultValue
+}
+
 // Errors
 var (
 	ErrMissingAPIKey = fmt.Errorf("missing required API key for AI provider")
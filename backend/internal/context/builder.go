@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -14,6 +13,7 @@ import (
 	"therapy-navigation-system/internal/repository"
 	"therapy-navigation-system/internal/state"
 	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/tokenizer"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,6 +21,8 @@ import (
 type TokenReport struct {
 	Sections map[string]int `json:"sections"`
 	Total    int            `json:"total"`
+	Budget   int            `json:"budget"` // Effective token budget the sections were truncated to
+	Caps     map[string]int `json:"caps"`   // Per-section caps derived from Budget
 }
 
 
@@ -33,6 +35,8 @@ type ContextBundle struct {
 	Tools             []string        `json:"tools"`
 	Timestamp         time.Time       `json:"timestamp"`
 	PromptHash        string          `json:"prompt_hash"`
+	Model             string          `json:"model"` // Model the coach should generate this turn with
+	ToolExchanges     []ToolExchange  `json:"tool_exchanges,omitempty"` // Previous turn's function calls, replayed as call and response turns
 }
 
 var lastContexts sync.Map // sessionID -> *ContextBundle
@@ -49,6 +53,12 @@ func Last(sessionID string) (*ContextBundle, bool) {
 
 // BuildTurnContext builds the per-turn constructed prompt and stores it as last context
 func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	// Legacy phase IDs would silently miss the phase's prompts and requirements
+	phase = repository.CanonicalPhaseID(phase)
+
 	logger.AppLogger.WithFields(map[string]interface{}{
 		"session_id": sessionID,
 		"phase":      phase,
@@ -58,7 +68,7 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 	logger.AppLogger.WithField("session_id", sessionID).Info("[CONTEXT_DEBUG] Loading system prompt from database")
 	
 	var sp repository.Prompt
-	if err := repository.DB.Where("category = ? AND is_active = ?", "system", true).First(&sp).Error; err != nil {
+	if err := db.Where("category = ? AND is_active = ?", "system", true).First(&sp).Error; err != nil {
 		logger.AppLogger.WithFields(map[string]interface{}{
 			"session_id": sessionID,
 			"error":      err.Error(),
@@ -83,7 +93,7 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 	}).Info("[CONTEXT_DEBUG] Loading phase templates from database for phase")
 
 	var phasePrompts []repository.Prompt
-	if err := repository.DB.Where("workflow_phase = ? AND is_active = ?", phase, true).Order("created_at").Find(&phasePrompts).Error; err != nil {
+	if err := db.Where("workflow_phase = ? AND is_active = ?", phase, true).Order("created_at").Find(&phasePrompts).Error; err != nil {
 		logger.AppLogger.WithFields(map[string]interface{}{
 			"session_id": sessionID,
 			"phase": phase,
@@ -91,8 +101,19 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 		}).Warn("[CONTEXT_DEBUG] Failed to load phase prompts, using empty")
 	}
 
-	var phaseTemplates []string
+	// Experimental prompts only apply to sessions carrying their feature flag
+	var flagSession repository.Session
+	_ = db.Select("id", "features", "locale").First(&flagSession, "id = ?", sessionID).Error
+
+	var applicable []repository.Prompt
 	for _, prompt := range phasePrompts {
+		if flagSession.FeatureEnabled(prompt.FeatureFlag) {
+			applicable = append(applicable, prompt)
+		}
+	}
+
+	var phaseTemplates []string
+	for _, prompt := range selectPhasePrompts(sessionID, phase, applicable) {
 		phaseTemplates = append(phaseTemplates, prompt.Content)
 		logger.AppLogger.WithFields(map[string]interface{}{
 			"session_id": sessionID,
@@ -111,7 +132,7 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 	phaseAddendum := ""
 	{
 		var pa repository.PromptAddendum
-		_ = repository.DB.Where("session_id = '' AND phase = ?", phase).Order("version DESC").First(&pa).Error
+		_ = db.Where("session_id = '' AND phase = ?", phase).Order("version DESC").First(&pa).Error
 		phaseAddendum = pa.Content
 	}
 	logger.AppLogger.WithField("session_id", sessionID).Info("[CONTEXT_DEBUG] Phase addendum loaded")
@@ -121,7 +142,7 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 	vars := map[string]string{"session_id": sessionID}
 	{
 		var session repository.Session
-		if err := repository.DB.Preload("Therapist").Preload("Client").First(&session, "id = ?", sessionID).Error; err == nil {
+		if err := db.Preload("Therapist").Preload("Client").First(&session, "id = ?", sessionID).Error; err == nil {
 			if session.Therapist.Name != "" {
 				vars["therapist_name"] = session.Therapist.Name
 			}
@@ -135,15 +156,7 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 	logger.AppLogger.WithField("session_id", sessionID).Info("[CONTEXT_DEBUG] About to start variable substitution")
 	// Substitute known vars and remove any remaining {{var}} tokens
 	substitute := func(s string) string {
-		out := s
-		for k, v := range vars {
-			token := "{{" + k + "}}"
-			out = strings.ReplaceAll(out, token, v)
-		}
-		// Remove any remaining template tokens like {{anything}}
-		re := regexp.MustCompile(`\{\{[^}]+\}\}`)
-		out = re.ReplaceAllString(out, "")
-		return out
+		return substituteVars(s, vars)
 	}
 
 	// Apply substitution to prompts before assembly
@@ -167,19 +180,12 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 
 	// 5) Retrieval removed - ChromaDB integration deleted
 
-	// 6) Single universal MCP tool - handles everything
-	tools := []string{
-		"collect_structured_data(session_id, data) - Collect phase-required data and auto-transition when requirements are met",
-	}
+	// 6) Callable tools - the same list the phase workflow section advertises
+	tools := coachToolList()
 
-	// 7) Enforce a simple token budget per section (approx 4 chars/token)
-	const totalBudgetTokens = 1500
-	caps := map[string]int{
-		"system_phase": int(0.30 * float64(totalBudgetTokens)),
-		"awareness":    int(0.15 * float64(totalBudgetTokens)),
-		"working":      int(0.35 * float64(totalBudgetTokens)),
-		"tools":        int(0.05 * float64(totalBudgetTokens)),
-	}
+	// 7) Enforce a token budget per section, from config or the phase
+	totalBudgetTokens := resolveTokenBudget(phase)
+	caps := sectionCaps(totalBudgetTokens)
 
 	rawSystemPhase := systemPrompt + "\n\n" + strings.Join(phaseTemplates, "\n")
 	if phaseAddendum != "" {
@@ -190,14 +196,10 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 		if capTokens <= 0 {
 			return ""
 		}
-		maxChars := capTokens * 4
-		if len(s) <= maxChars {
+		cut := tokenizer.PrefixLen(s, capTokens)
+		if cut >= len(s) {
 			return s
 		}
-		cut := maxChars
-		if cut > len(s) {
-			cut = len(s)
-		}
 		if idx := strings.LastIndex(s[:cut], "\n"); idx > 0 && idx > cut-200 {
 			cut = idx
 		}
@@ -209,39 +211,84 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 	finalWorking := truncate(workingMemory, caps["working"])
 	finalTools := truncate(strings.Join(tools, ", "), caps["tools"])
 
-	// Assemble constructed prompt from truncated sections
-	var sb strings.Builder
-	sb.WriteString("SYSTEM PROMPT\n")
-	sb.WriteString(finalSystemPhase)
-	if finalAwareness != "" {
-		sb.WriteString("\n\nAWARENESS\n")
-		sb.WriteString(finalAwareness)
-	}
-	if finalWorking != "" {
-		sb.WriteString("\n\nWORKING MEMORY (recent dialogue)\n")
-		sb.WriteString(finalWorking)
-	}
-
 	// Add phase requirements and transitions from state machine
 	phaseContext := buildPhaseContextFromStateMachine(sessionID, phase)
-	if phaseContext != "" {
-		sb.WriteString("\n\nPHASE WORKFLOW\n")
-		sb.WriteString(phaseContext)
-	}
 
 	// Add phase requirements validation
 	requirementsStatus := buildPhaseRequirementsStatus(sessionID, phase)
-	if requirementsStatus != "" {
-		sb.WriteString("\n\nPHASE REQUIREMENTS STATUS\n")
-		sb.WriteString(requirementsStatus)
-	}
 
-	sb.WriteString("\n\nTOOLS\n")
-	sb.WriteString(finalTools)
-	sb.WriteString(fmt.Sprintf("\n\nSESSION INFO\nCurrent Session ID: %s (use this exact ID in all tool calls)\n", sessionID))
-	sb.WriteString("\n\nCONSTRAINTS\n- Be concise and professional.\n- When transitioning phases, provide a clear response that guides the user smoothly into the next phase.\n- Continue the conversation naturally after using tools - don't just say 'Okay'.\n")
+	// Re-ask for SUDS if the loop has gone too long without a reading
+	sudsDirective := buildSUDSDirective(sessionID, phase)
+
+	// The model's previous function calls and their results, so it knows what succeeded
+	toolExchanges := buildToolExchanges(sessionID)
+
+	// Break up near-identical replies in repetitive loops
+	variationDirective := buildVariationDirective(sessionID)
+
+	// Sessions created from a template with a non-English locale are conducted in that language
+	languageDirective := buildLanguageDirective(flagSession.Locale)
+
+	// Assemble constructed prompt from truncated sections
+	assemble := func(awareness, working string) string {
+		var sb strings.Builder
+		sb.WriteString("SYSTEM PROMPT\n")
+		sb.WriteString(finalSystemPhase)
+		if languageDirective != "" {
+			sb.WriteString("\n\nLANGUAGE\n")
+			sb.WriteString(languageDirective)
+		}
+		if awareness != "" {
+			sb.WriteString("\n\nAWARENESS\n")
+			sb.WriteString(awareness)
+		}
+		if working != "" {
+			sb.WriteString("\n\nWORKING MEMORY (recent dialogue)\n")
+			sb.WriteString(working)
+		}
+		if phaseContext != "" {
+			sb.WriteString("\n\nPHASE WORKFLOW\n")
+			sb.WriteString(phaseContext)
+		}
+		if requirementsStatus != "" {
+			sb.WriteString("\n\nPHASE REQUIREMENTS STATUS\n")
+			sb.WriteString(requirementsStatus)
+		}
+		if sudsDirective != "" {
+			sb.WriteString("\n\nSUDS CHECK REQUIRED\n")
+			sb.WriteString(sudsDirective)
+		}
+		if variationDirective != "" {
+			sb.WriteString("\n\nVARY YOUR PHRASING\n")
+			sb.WriteString(variationDirective)
+		}
+		sb.WriteString("\n\nTOOLS\n")
+		sb.WriteString(finalTools)
+		sb.WriteString(fmt.Sprintf("\n\nSESSION INFO\nCurrent Session ID: %s (use this exact ID in all tool calls)\n", sessionID))
+		sb.WriteString("\n\nCONSTRAINTS\n- Be concise and professional.\n- When transitioning phases, provide a clear response that guides the user smoothly into the next phase.\n- Continue the conversation naturally after using tools - don't just say 'Okay'.\n")
+		return sb.String()
+	}
 
-	constructed := sb.String()
+	// Hard check against the model's context window - the budget above is only a char heuristic
+	model := resolveModel(sessionID, phase)
+	contextWindow := contextWindowFor(model)
+	constructed, finalAwareness, finalWorking, dropped, err := fitContextWindow(assemble, finalAwareness, finalWorking, contextWindow)
+	if err != nil {
+		logger.AppLogger.WithFields(logrus.Fields{
+			"session_id":     sessionID,
+			"model":          model,
+			"context_window": contextWindow,
+		}).WithError(err).Error("[CONTEXT_DEBUG] Context window overflow")
+		return nil, err
+	}
+	if len(dropped) > 0 {
+		logger.AppLogger.WithFields(logrus.Fields{
+			"session_id":     sessionID,
+			"model":          model,
+			"context_window": contextWindow,
+			"dropped":        countDropped(dropped),
+		}).Warn("⚠️ [CONTEXT_DEBUG] Prompt exceeded context window - dropped low-priority sections")
+	}
 
 	// Compute prompt hash for audit/debugging
 	sum := sha256.Sum256([]byte(constructed))
@@ -254,9 +301,9 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 		"working":      finalWorking,
 		"tools":        finalTools,
 	}
-	tr := TokenReport{Sections: map[string]int{}, Total: 0}
+	tr := TokenReport{Sections: map[string]int{}, Total: 0, Budget: totalBudgetTokens, Caps: caps}
 	for k, v := range sections {
-		t := len(v) / 4
+		t := tokenizer.CountTokens(v)
 		tr.Sections[k] = t
 		tr.Total += t
 	}
@@ -271,6 +318,8 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 		Tools:             tools,
 		Timestamp:         time.Now(),
 		PromptHash:        promptHash,
+		Model:             model,
+		ToolExchanges:     toolExchanges,
 	}
 	
 	logger.AppLogger.WithFields(map[string]interface{}{
@@ -286,8 +335,11 @@ func BuildTurnContext(sessionID string, phase string) (*ContextBundle, error) {
 }
 
 func buildAwarenessSummary(sessionID string) string {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	var session repository.Session
-	if err := repository.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+	if err := db.First(&session, "id = ?", sessionID).Error; err != nil {
 		return ""
 	}
 	// Removed elapsed time calculation - was using incorrect wall clock time
@@ -298,26 +350,41 @@ func buildAwarenessSummary(sessionID string) string {
 
 	// Get dynamic field values
 	var fieldValues []repository.SessionFieldValue
-	repository.DB.Where("session_id = ?", session.ID).Find(&fieldValues)
+	db.Where("session_id = ?", session.ID).Find(&fieldValues)
+
+	if participants := participantSummary(db, session.ID); participants != "" {
+		lines = append(lines, participants)
+	}
+
+	// The SUDS series shows the trend; sessions without readings fall back to the field
+	sudsLine := sudsTrendLine(db, session.ID)
+	if sudsLine != "" {
+		lines = append(lines, sudsLine)
+	}
 
 	for _, fv := range fieldValues {
 		if fv.FieldName == "suds_level" || fv.FieldName == "suds_current" {
-			lines = append(lines, fmt.Sprintf("SUDS: %s", fv.FieldValue))
+			if sudsLine == "" {
+				lines = append(lines, fmt.Sprintf("SUDS: %s", repository.FieldValueText(fv)))
+			}
 		} else if fv.FieldName == "body_location" {
-			lines = append(lines, fmt.Sprintf("Body location: %s", fv.FieldValue))
+			lines = append(lines, fmt.Sprintf("Body location: %s", repository.FieldValueText(fv)))
 		} else if fv.FieldName == "eye_position" {
-			lines = append(lines, fmt.Sprintf("Eye position: %s", fv.FieldValue))
+			lines = append(lines, fmt.Sprintf("Eye position: %s", repository.FieldValueText(fv)))
 		}
 	}
 	return "- " + strings.Join(lines, "\n- ")
 }
 
 func buildWorkingMemory(sessionID string) string {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	logger.AppLogger.WithField("session_id", sessionID).Info("[CONTEXT_DEBUG] buildWorkingMemory: Starting function")
 	
 	var messages []repository.Message
 	logger.AppLogger.WithField("session_id", sessionID).Info("[CONTEXT_DEBUG] buildWorkingMemory: About to query database")
-	_ = repository.DB.Where("session_id = ?", sessionID).Order("created_at DESC").Limit(30).Find(&messages)
+	_ = db.Where("session_id = ?", sessionID).Order("created_at DESC").Limit(30).Find(&messages)
 	logger.AppLogger.WithFields(map[string]interface{}{
 		"session_id": sessionID,
 		"message_count": len(messages),
@@ -332,11 +399,10 @@ func buildWorkingMemory(sessionID string) string {
 	// cap roughly to ~1200 chars (~300 tokens) for POC
 	const capChars = 1200
 	for i := range messages {
-		role := "Patient"
-		if messages[i].Role == "therapist" || messages[i].Role == "coach" {
-			role = "Therapist"
+		line, ok := renderWorkingMemoryLine(&messages[i])
+		if !ok {
+			continue
 		}
-		line := fmt.Sprintf("%s: %s\n", role, messages[i].Content)
 		if sb.Len()+len(line) > capChars {
 			// stop if exceeding cap
 			break
@@ -349,6 +415,9 @@ func buildWorkingMemory(sessionID string) string {
 
 // buildPhaseContextFromStateMachine provides AI with current phase requirements and transitions
 func buildPhaseContextFromStateMachine(sessionID string, currentPhase string) string {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	logger.AppLogger.WithFields(logrus.Fields{
 		"session_id": sessionID,
 		"phase": currentPhase,
@@ -358,7 +427,7 @@ func buildPhaseContextFromStateMachine(sessionID string, currentPhase string) st
 
 	// Get phase data from database
 	var phaseData []repository.PhaseData
-	if err := repository.DB.Where("phase_id = ?", currentPhase).Find(&phaseData).Error; err != nil {
+	if err := db.Where("phase_id = ?", currentPhase).Find(&phaseData).Error; err != nil {
 		logger.AppLogger.WithFields(logrus.Fields{
 			"session_id": sessionID,
 			"phase": currentPhase,
@@ -373,6 +442,23 @@ func buildPhaseContextFromStateMachine(sessionID string, currentPhase string) st
 		"phase_data_count": len(phaseData),
 	}).Info("[PHASE_CONTEXT_DEBUG] Found phase data items")
 
+	if len(phaseData) == 0 {
+		var phase repository.Phase
+		if err := db.Select("expects_data").Where("id = ?", currentPhase).First(&phase).Error; err == nil && phase.ExpectsData {
+			logger.AppLogger.WithFields(logrus.Fields{
+				"session_id": sessionID,
+				"phase":      currentPhase,
+			}).Error("🚨 MISCONFIGURED PHASE: expects data but has no phase_data - model gets no data guidance")
+		} else {
+			sb.WriteString(fmt.Sprintf("CURRENT PHASE: %s\n", currentPhase))
+			if coachToolCallable("therapy_session_transition") {
+				sb.WriteString("This is a conversational phase with no data to collect. Focus on the conversation and use therapy_session_transition when the phase goals are met.\n")
+			} else {
+				sb.WriteString("This is a conversational phase with no data to collect. Focus on the conversation; there is nothing to submit with collect_structured_data.\n")
+			}
+		}
+	}
+
 	if len(phaseData) > 0 {
 		sb.WriteString(fmt.Sprintf("CURRENT PHASE: %s\n", currentPhase))
 		sb.WriteString("DATA YOU CAN CONTRIBUTE IN THIS PHASE:\n")
@@ -381,8 +467,11 @@ func buildPhaseContextFromStateMachine(sessionID string, currentPhase string) st
 		var schemaInfo []string
 		for _, item := range phaseData {
 			required := ""
-			if item.Required {
+			switch item.Requirement {
+			case repository.PhaseDataRequired:
 				required = " (REQUIRED)"
+			case repository.PhaseDataRecommended:
+				required = " (RECOMMENDED)"
 			}
 
 			// Parse schema to get field type and enum values if available
@@ -413,19 +502,12 @@ func buildPhaseContextFromStateMachine(sessionID string, currentPhase string) st
 			schemaInfo = append(schemaInfo, fmt.Sprintf("%s:%s", item.Name, fieldType))
 		}
 
-		sb.WriteString("\nTOOLS AVAILABLE (Universal MCP Tools):\n")
-		sb.WriteString("- get_phase_context(session_id) - Get current phase requirements and context\n")
-		sb.WriteString("- collect_structured_data(session_id, data) - Submit structured data including observations\n")
-		sb.WriteString(fmt.Sprintf("  Current phase expects: %s\n", strings.Join(schemaInfo, ", ")))
-		sb.WriteString("  Returns: ready_to_transition (bool) - if true, phase will auto-transition\n")
-		sb.WriteString("- therapy_session_transition(session_id, target_phase, reason) - Transition to next phase\n")
-		sb.WriteString("  Use 'next' as target_phase to move to the next phase in sequence\n")
-		sb.WriteString("\nIMPORTANT: Only use these exact tool names. Any other tool will fail immediately.\n")
+		writeAvailableTools(&sb, schemaInfo)
 	}
 
 	// Get possible transitions
 	var transitions []repository.PhaseTransition
-	if err := repository.DB.Where("from_phase_id = ?", currentPhase).Find(&transitions).Error; err == nil && len(transitions) > 0 {
+	if err := db.Where("from_phase_id = ?", currentPhase).Find(&transitions).Error; err == nil && len(transitions) > 0 {
 		sb.WriteString("\nNEXT PHASES AVAILABLE:\n")
 		for _, trans := range transitions {
 			sb.WriteString(fmt.Sprintf("- %s\n", trans.ToPhaseID))
@@ -445,6 +527,12 @@ func buildPhaseContextFromStateMachine(sessionID string, currentPhase string) st
 		sb.WriteString("- If patient requests more mindfulness, set next_action: 'focused_mindfulness'\n")
 		sb.WriteString("- Only use 'complete' if patient explicitly wants to end the session\n")
 		sb.WriteString("- DO NOT override patient preference based on time or other factors\n")
+		if state.StatusDecisionMode() != state.DecisionModeOff {
+			if decision, err := state.New(sessionID).RecommendStatusAction(nil); err == nil && decision != nil {
+				sb.WriteString(fmt.Sprintf("📐 DECISION CRITERIA RECOMMEND next_action: '%s' (%s: %s)\n", decision.Action, decision.Criterion, decision.Reason))
+				sb.WriteString("- Use this unless the patient explicitly asks for something else\n")
+			}
+		}
 	}
 
 	// Clean, simple reference - no random validation
@@ -486,8 +574,11 @@ func buildPhaseRequirementsStatus(sessionID string, currentPhase string) string
 
 // loadPhaseToolsFromDB loads tools for a phase using Phase -> PhaseTools -> Tools relationship
 func loadPhaseToolsFromDB(phaseID string) ([]string, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	var phaseTools []repository.PhaseTool
-	err := repository.DB.
+	err := db.
 		Preload("Tool").
 		Where("phase_id = ? AND is_active = ?", phaseID, true).
 		Find(&phaseTools).Error
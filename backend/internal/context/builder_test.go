@@ -0,0 +1,183 @@
+package contextbuilder
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestDB installs a throwaway SQLite database with the tables prompt assembly reads
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	if logger.AppLogger == nil {
+		logger.AppLogger = logrus.New()
+		logger.AppLogger.SetLevel(logrus.WarnLevel)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")),
+		&gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&repository.Client{},
+		&repository.Therapist{},
+		&repository.Session{},
+		&repository.Message{},
+		&repository.Phase{},
+		&repository.PhaseData{},
+		&repository.PhaseTransition{},
+		&repository.PhaseConstraint{},
+		&repository.Tool{},
+		&repository.PhaseTool{},
+		&repository.SessionFieldValue{},
+		&repository.SudsReading{},
+		&repository.Prompt{},
+		&repository.PromptAddendum{},
+		&repository.ToolCallRecord{},
+		&repository.SessionPhaseState{},
+		&repository.SessionState{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	if err := repository.AutoMigrateMonitoring(db); err != nil {
+		t.Fatalf("failed to migrate monitoring tables: %v", err)
+	}
+
+	previous := repository.DB
+	repository.DB = db
+	t.Cleanup(func() {
+		repository.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestBuildTurnContextLoadsCompletePhasePromptAndRequirements(t *testing.T) {
+	db := newTestDB(t)
+	seed := []interface{}{
+		&repository.Phase{ID: "complete", DisplayName: "Complete", Position: 10, IsTerminal: true, ExpectsData: true},
+		&repository.PhaseData{ID: "complete_final_suds", PhaseID: "complete", Name: "final_suds",
+			Requirement: repository.PhaseDataRequired, Schema: `{"type": "integer"}`},
+		&repository.Prompt{Name: "system", Category: "system", Content: "You are a brainspotting coach.", IsActive: true},
+		// Written under the legacy ID; the hook stores it under the canonical one
+		&repository.Prompt{Name: "completion", Category: "phase", Content: "Close the session gently.",
+			IsActive: true, WorkflowPhase: "completion"},
+		&repository.Session{ID: "session-1", ClientID: "client-1", TherapistID: "therapist-1",
+			Phase: "complete", StartTime: time.Now()},
+	}
+	for _, row := range seed {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", row, err)
+		}
+	}
+
+	// Both the canonical ID and the legacy alias reach the same phase
+	for _, phase := range []string{"complete", "completion"} {
+		bundle, err := BuildTurnContext("session-1", phase)
+		if err != nil {
+			t.Fatalf("BuildTurnContext(%q): %v", phase, err)
+		}
+		if bundle.Phase != "complete" {
+			t.Errorf("BuildTurnContext(%q) phase = %q, want complete", phase, bundle.Phase)
+		}
+		if !strings.Contains(bundle.ConstructedPrompt, "Close the session gently.") {
+			t.Errorf("BuildTurnContext(%q) didn't load the complete phase prompt", phase)
+		}
+		if !strings.Contains(bundle.ConstructedPrompt, "final_suds") {
+			t.Errorf("BuildTurnContext(%q) didn't list the complete phase's required final_suds", phase)
+		}
+	}
+}
+
+func TestBuildTurnContextReplaysPreviousToolCallsAsExchanges(t *testing.T) {
+	db := newTestDB(t)
+	start := time.Now().Add(-time.Minute)
+	seed := []interface{}{
+		&repository.Phase{ID: "intake", DisplayName: "Intake", Position: 1},
+		&repository.Prompt{Name: "system", Category: "system", Content: "You are a brainspotting coach.", IsActive: true},
+		&repository.Session{ID: "session-1", ClientID: "client-1", TherapistID: "therapist-1",
+			Phase: "intake", StartTime: start},
+		&repository.Message{ID: "message-1", SessionID: "session-1", Role: "patient", Content: "Work has been stressful",
+			CreatedAt: start},
+		&repository.Message{ID: "message-2", SessionID: "session-1", Role: "coach", Content: "Collecting data",
+			MessageType: "tool_call", CreatedAt: start.Add(time.Second),
+			Metadata: `{"tool_name": "collect_structured_data", "arguments": {"data": {"issue": "work stress"}}, "status": "completed", "success": true, "tool_result": {"missing_requirements": ["suds_initial"], "timestamp": "2026-01-01T00:00:00Z"}}`},
+		&repository.Message{ID: "message-3", SessionID: "session-1", Role: "patient", Content: "It's about a six",
+			CreatedAt: start.Add(2 * time.Second)},
+	}
+	for _, row := range seed {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", row, err)
+		}
+	}
+
+	bundle, err := BuildTurnContext("session-1", "intake")
+	if err != nil {
+		t.Fatalf("BuildTurnContext: %v", err)
+	}
+	if len(bundle.ToolExchanges) != 1 {
+		t.Fatalf("expected 1 tool exchange, got %d", len(bundle.ToolExchanges))
+	}
+	exchange := bundle.ToolExchanges[0]
+	if exchange.Name != "collect_structured_data" || exchange.Arguments["data"] == nil {
+		t.Errorf("expected the collect call with its arguments, got %+v", exchange)
+	}
+	if exchange.Response["success"] != true || exchange.Response["missing_requirements"] == nil {
+		t.Errorf("expected the actionable result, got %+v", exchange.Response)
+	}
+	if _, ok := exchange.Response["timestamp"]; ok {
+		t.Errorf("expected the timestamp to be left out, got %+v", exchange.Response)
+	}
+	if strings.Contains(bundle.ConstructedPrompt, "suds_initial") {
+		t.Error("expected tool results in the exchanges, not the prompt text")
+	}
+}
+
+func TestBuildTurnContextConductsTheSessionInItsLocale(t *testing.T) {
+	db := newTestDB(t)
+	seed := []interface{}{
+		&repository.Phase{ID: "intake", DisplayName: "Intake", Position: 1},
+		&repository.Prompt{Name: "system", Category: "system", Content: "You are a brainspotting coach.", IsActive: true},
+		&repository.Session{ID: "session-es", ClientID: "client-1", TherapistID: "therapist-1",
+			Phase: "intake", StartTime: time.Now(), Locale: "es-MX"},
+		&repository.Session{ID: "session-en", ClientID: "client-1", TherapistID: "therapist-1",
+			Phase: "intake", StartTime: time.Now(), Locale: "en-GB"},
+		&repository.Session{ID: "session-default", ClientID: "client-1", TherapistID: "therapist-1",
+			Phase: "intake", StartTime: time.Now()},
+	}
+	for _, row := range seed {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to seed %T: %v", row, err)
+		}
+	}
+
+	bundle, err := BuildTurnContext("session-es", "intake")
+	if err != nil {
+		t.Fatalf("BuildTurnContext: %v", err)
+	}
+	if !strings.Contains(bundle.ConstructedPrompt, "\n\nLANGUAGE\n") || !strings.Contains(bundle.ConstructedPrompt, "Spanish (es-MX)") {
+		t.Errorf("es-MX session prompt has no Spanish language directive:\n%s", bundle.ConstructedPrompt)
+	}
+
+	for _, sessionID := range []string{"session-en", "session-default"} {
+		bundle, err := BuildTurnContext(sessionID, "intake")
+		if err != nil {
+			t.Fatalf("BuildTurnContext(%s): %v", sessionID, err)
+		}
+		if strings.Contains(bundle.ConstructedPrompt, "\n\nLANGUAGE\n") {
+			t.Errorf("%s prompt has a language directive", sessionID)
+		}
+	}
+}
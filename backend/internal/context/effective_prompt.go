@@ -0,0 +1,123 @@
+package contextbuilder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/tokenizer"
+
+	"gorm.io/gorm"
+)
+
+// ErrPromptVersionNotFound is returned when no prompt exists at a requested version
+var ErrPromptVersionNotFound = errors.New("prompt version not found")
+
+// templateToken matches {{variable}} placeholders in prompt content
+var templateToken = regexp.MustCompile(`\{\{[^}]+\}\}`)
+
+// substituteVars replaces known {{variables}} and removes any that remain
+func substituteVars(s string, vars map[string]string) string {
+	out := s
+	for k, v := range vars {
+		out = strings.ReplaceAll(out, "{{"+k+"}}", v)
+	}
+	return templateToken.ReplaceAllString(out, "")
+}
+
+// placeholderVars stand in for session values when no session is involved
+var placeholderVars = map[string]string{
+	"session_id":     "[session_id]",
+	"therapist_name": "[therapist_name]",
+	"client_name":    "[client_name]",
+}
+
+// PromptSource identifies the prompt row a section of the effective prompt came from
+type PromptSource struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// EffectivePrompt is the system + phase + addendum assembly for a phase at chosen prompt versions
+type EffectivePrompt struct {
+	Phase         string         `json:"phase"`
+	System        PromptSource   `json:"system"`
+	PhasePrompts  []PromptSource `json:"phase_prompts"`
+	Text          string         `json:"text"`
+	SectionTokens map[string]int `json:"section_tokens"`
+	TotalTokens   int            `json:"total_tokens"`
+}
+
+// AssembleEffectivePrompt builds a phase's system prompt the way BuildTurnContext does, without
+// a session. A version of 0 selects the active prompt; otherwise the prompt row with that version
+// is used whether or not it's active. Session variables are filled with placeholders and
+// experimental (feature-flagged) prompts are left out.
+func AssembleEffectivePrompt(phase string, systemVersion int, phaseVersion int) (*EffectivePrompt, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var system repository.Prompt
+	systemQuery := db.Where("category = ?", "system")
+	if systemVersion > 0 {
+		systemQuery = systemQuery.Where("version = ?", systemVersion).Order("is_active DESC")
+	} else {
+		systemQuery = systemQuery.Where("is_active = ?", true)
+	}
+	if err := systemQuery.First(&system).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: system prompt version %d", ErrPromptVersionNotFound, systemVersion)
+		}
+		return nil, fmt.Errorf("failed to load system prompt: %w", err)
+	}
+
+	var phasePrompts []repository.Prompt
+	phaseQuery := db.Where("workflow_phase = ? AND (feature_flag = '' OR feature_flag IS NULL)", phase)
+	if phaseVersion > 0 {
+		phaseQuery = phaseQuery.Where("version = ?", phaseVersion)
+	} else {
+		phaseQuery = phaseQuery.Where("is_active = ?", true)
+	}
+	if err := phaseQuery.Order("created_at").Find(&phasePrompts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load phase prompts: %w", err)
+	}
+	if phaseVersion > 0 && len(phasePrompts) == 0 {
+		return nil, fmt.Errorf("%w: phase %s has no prompt at version %d", ErrPromptVersionNotFound, phase, phaseVersion)
+	}
+
+	var addendum repository.PromptAddendum
+	_ = db.Where("session_id = '' AND phase = ?", phase).Order("version DESC").First(&addendum).Error
+
+	result := &EffectivePrompt{
+		Phase:         phase,
+		System:        PromptSource{ID: system.ID, Name: system.Name, Version: system.Version},
+		PhasePrompts:  make([]PromptSource, 0, len(phasePrompts)),
+		SectionTokens: map[string]int{},
+	}
+
+	systemText := substituteVars(system.Content, placeholderVars)
+	var phaseTexts []string
+	for _, prompt := range phasePrompts {
+		result.PhasePrompts = append(result.PhasePrompts, PromptSource{ID: prompt.ID, Name: prompt.Name, Version: prompt.Version})
+		phaseTexts = append(phaseTexts, substituteVars(prompt.Content, placeholderVars))
+	}
+	phaseText := strings.Join(phaseTexts, "\n")
+	addendumText := substituteVars(addendum.Content, placeholderVars)
+
+	// Same joins as BuildTurnContext's system_phase section
+	result.Text = systemText + "\n\n" + phaseText
+	if addendumText != "" {
+		result.Text += "\n\n" + addendumText
+	}
+
+	// Same token count as TokenReport
+	for name, text := range map[string]string{"system": systemText, "phase": phaseText, "addendum": addendumText} {
+		tokens := tokenizer.CountTokens(text)
+		result.SectionTokens[name] = tokens
+		result.TotalTokens += tokens
+	}
+
+	return result, nil
+}
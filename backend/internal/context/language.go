@@ -0,0 +1,19 @@
+package contextbuilder
+
+import (
+	"fmt"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// buildLanguageDirective tells the coach to conduct the session in its locale's language.
+// English sessions need no directive. Tool arguments stay in English because conditions and
+// phase IDs are matched literally.
+func buildLanguageDirective(locale string) string {
+	name, nonEnglish := repository.LocaleLanguage(locale)
+	if !nonEnglish {
+		return ""
+	}
+	return fmt.Sprintf("Conduct this session in %s (%s): write every reply to the client in %s, even if the prompts above are in English. "+
+		"Keep tool call arguments, field names, phase IDs and enum values exactly as specified, in English.", name, locale, name)
+}
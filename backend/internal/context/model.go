@@ -0,0 +1,32 @@
+package contextbuilder
+
+import (
+	"therapy-navigation-system/internal/repository"
+)
+
+// defaultModel generates coach replies in phases without their own model; a session's
+// template can override it, and a phase with Phase.ModelName
+var defaultModel = "gemini-2.0-flash"
+
+// SetDefaultModel configures the model used when a phase doesn't name one
+func SetDefaultModel(model string) {
+	if model != "" {
+		defaultModel = model
+	}
+}
+
+// resolveModel returns the phase's model override, then the session template's, then the
+// configured default
+func resolveModel(sessionID string, phaseID string) string {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var phase repository.Phase
+	if err := db.Select("id", "model_name").First(&phase, "id = ?", phaseID).Error; err == nil && phase.ModelName != "" {
+		return phase.ModelName
+	}
+	if model := repository.LoadSessionModelConfig(db, sessionID).Model; model != "" {
+		return model
+	}
+	return defaultModel
+}
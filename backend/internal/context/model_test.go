@@ -0,0 +1,46 @@
+package contextbuilder
+
+import (
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+func TestResolveModelPrefersThePhaseModel(t *testing.T) {
+	db := newTestDB(t)
+	for _, phase := range []repository.Phase{
+		{ID: "intake", DisplayName: "Intake", Position: 1},
+		{ID: "processing", DisplayName: "Processing", Position: 2, ModelName: "gemini-2.5-pro"},
+	} {
+		if err := db.Create(&phase).Error; err != nil {
+			t.Fatalf("failed to create phase: %v", err)
+		}
+	}
+
+	if got := resolveModel("no-session", "intake"); got != "gemini-2.0-flash" {
+		t.Errorf("phase without a model resolved to %s, want the default gemini-2.0-flash", got)
+	}
+	if got := resolveModel("no-session", "processing"); got != "gemini-2.5-pro" {
+		t.Errorf("phase with a model resolved to %s, want gemini-2.5-pro", got)
+	}
+}
+
+func TestContextWindowIsSizedPerModel(t *testing.T) {
+	t.Cleanup(func() { SetContextWindowOverride(0) })
+
+	for _, tc := range []struct {
+		model    string
+		override int
+		want     int
+	}{
+		{"gemini-2.0-flash", 0, 1048576},
+		{"gemini-2.0-pro", 0, 2097152},
+		{"unlisted-model", 0, defaultContextWindowTokens},
+		{"gemini-2.0-pro", 32000, 32000},
+	} {
+		SetContextWindowOverride(tc.override)
+		if got := contextWindowFor(tc.model); got != tc.want {
+			t.Errorf("contextWindowFor(%s) with override %d = %d, want %d", tc.model, tc.override, got, tc.want)
+		}
+	}
+}
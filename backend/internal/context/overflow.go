@@ -0,0 +1,87 @@
+package contextbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// modelContextWindows are the input token limits of the models the coach can use
+var modelContextWindows = map[string]int{
+	"gemini-2.0-flash": 1048576,
+	"gemini-2.0-pro":   2097152,
+	"gemini-2.5-flash": 1048576,
+	"gemini-2.5-pro":   1048576,
+}
+
+// defaultContextWindowTokens is used for models missing from modelContextWindows
+const defaultContextWindowTokens = 1048576
+
+// contextWindowOverride replaces every model's limit when set
+var contextWindowOverride int
+
+// SetContextWindowOverride configures a hard context limit for every model; 0 uses each
+// model's own
+func SetContextWindowOverride(tokens int) {
+	contextWindowOverride = tokens
+}
+
+// KnownModel reports whether the coach can generate replies with model
+func KnownModel(model string) bool {
+	return modelContextWindows[model] > 0
+}
+
+// contextWindowFor returns the hard limit checked after assembling a prompt for model
+func contextWindowFor(model string) int {
+	switch {
+	case contextWindowOverride > 0:
+		return contextWindowOverride
+	case modelContextWindows[model] > 0:
+		return modelContextWindows[model]
+	default:
+		return defaultContextWindowTokens
+	}
+}
+
+// conservativeTokenCount over-estimates tokens (~3 chars/token) so the hard check
+// errs on the side of dropping context rather than failing the model call
+func conservativeTokenCount(s string) int {
+	return (len(s) + 2) / 3
+}
+
+// fitContextWindow re-assembles the prompt, dropping the lowest-priority sections
+// (oldest working memory first, then awareness) until it fits within windowTokens
+func fitContextWindow(assemble func(awareness, working string) string, awareness, working string, windowTokens int) (string, string, string, []string, error) {
+	var dropped []string
+	constructed := assemble(awareness, working)
+
+	for conservativeTokenCount(constructed) > windowTokens {
+		switch {
+		case working != "":
+			lines := strings.SplitN(working, "\n", 2)
+			if len(lines) == 2 {
+				working = lines[1]
+			} else {
+				working = ""
+			}
+			dropped = append(dropped, "working_memory_line")
+		case awareness != "":
+			awareness = ""
+			dropped = append(dropped, "awareness")
+		default:
+			return "", awareness, working, dropped, fmt.Errorf("constructed prompt (~%d tokens) exceeds context window of %d tokens even after dropping optional sections",
+				conservativeTokenCount(constructed), windowTokens)
+		}
+		constructed = assemble(awareness, working)
+	}
+
+	return constructed, awareness, working, dropped, nil
+}
+
+// countDropped summarizes dropped sections for logging
+func countDropped(dropped []string) map[string]int {
+	counts := make(map[string]int)
+	for _, section := range dropped {
+		counts[section]++
+	}
+	return counts
+}
@@ -0,0 +1,43 @@
+package contextbuilder
+
+import (
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// participantLabel renders a participant role for the prompt, e.g. co_therapist as Co-therapist
+func participantLabel(role string) string {
+	label := strings.ReplaceAll(role, "_", "-")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// participantSummary names the session's participants beyond the primary client and
+// therapist, e.g. "Participants: Supervisor (Dr. Lee), Observer". Sessions with only the
+// primary pair get no line.
+func participantSummary(db *gorm.DB, sessionID string) string {
+	participants, err := repository.SessionParticipants(db, sessionID)
+	if err != nil {
+		return ""
+	}
+	var names []string
+	for _, p := range participants {
+		if p.Primary {
+			continue
+		}
+		name := participantLabel(p.Role)
+		if p.DisplayName != "" {
+			name += " (" + p.DisplayName + ")"
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return "Participants: " + strings.Join(names, ", ")
+}
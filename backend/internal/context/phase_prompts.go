@@ -0,0 +1,75 @@
+package contextbuilder
+
+import (
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Phase prompt selection when a phase has more than one active prompt
+const (
+	PromptSelectionHighestVersion = "highest_version" // the highest version, most recently updated on ties
+	PromptSelectionPrimary        = "primary"         // the prompt flagged is_primary, else the highest version
+	PromptSelectionConcatenate    = "concatenate"     // every active prompt, in creation order
+)
+
+// phasePromptSelection is configured from PHASE_PROMPT_SELECTION
+var phasePromptSelection = PromptSelectionHighestVersion
+
+// SetPhasePromptSelection configures how a phase's active prompts are chosen
+func SetPhasePromptSelection(mode string) {
+	switch mode {
+	case PromptSelectionHighestVersion, PromptSelectionPrimary, PromptSelectionConcatenate:
+		phasePromptSelection = mode
+	}
+}
+
+// selectPhasePrompts picks one prompt per category and feature flag from a phase's active
+// prompts - the slot PublishPromptVersion keeps to one active prompt - so a version left
+// active alongside its successor isn't included twice. Experimental prompts keep their own
+// slot next to the unflagged one. Input and output are in creation order.
+func selectPhasePrompts(sessionID string, phase string, prompts []repository.Prompt) []repository.Prompt {
+	if phasePromptSelection == PromptSelectionConcatenate {
+		return prompts
+	}
+
+	chosen := make(map[string]int) // category|feature flag -> index into prompts
+	for i, prompt := range prompts {
+		slot := prompt.Category + "|" + prompt.FeatureFlag
+		j, seen := chosen[slot]
+		if !seen {
+			chosen[slot] = i
+			continue
+		}
+		logger.AppLogger.WithFields(logrus.Fields{
+			"session_id":   sessionID,
+			"phase":        phase,
+			"feature_flag": prompt.FeatureFlag,
+			"prompts":      []string{prompts[j].Name, prompt.Name},
+			"selection":    phasePromptSelection,
+		}).Warn("⚠️ Multiple active prompts for phase - using one")
+		if preferPrompt(prompt, prompts[j]) {
+			chosen[slot] = i
+		}
+	}
+
+	selected := make([]repository.Prompt, 0, len(chosen))
+	for i, prompt := range prompts {
+		if chosen[prompt.Category+"|"+prompt.FeatureFlag] == i {
+			selected = append(selected, prompt)
+		}
+	}
+	return selected
+}
+
+// preferPrompt reports whether candidate should replace current as the phase prompt
+func preferPrompt(candidate repository.Prompt, current repository.Prompt) bool {
+	if phasePromptSelection == PromptSelectionPrimary && candidate.IsPrimary != current.IsPrimary {
+		return candidate.IsPrimary
+	}
+	if candidate.Version != current.Version {
+		return candidate.Version > current.Version
+	}
+	return candidate.UpdatedAt.After(current.UpdatedAt)
+}
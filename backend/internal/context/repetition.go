@@ -0,0 +1,107 @@
+package contextbuilder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// repetitionThreshold is the similarity (0-1) at which consecutive coach messages count as
+// repeats and the model is told to vary its phrasing (0 disables the check)
+var repetitionThreshold = 0.8
+
+// repetitionWindow is how many recent coach messages are compared pairwise
+const repetitionWindow = 3
+
+// SetRepetitionThreshold configures the similarity at which coach messages count as repeats
+func SetRepetitionThreshold(threshold float64) {
+	if threshold >= 0 && threshold <= 1 {
+		repetitionThreshold = threshold
+	}
+}
+
+// buildVariationDirective returns an instruction to vary phrasing when the coach's recent
+// consecutive messages are near-identical, as happens in the mindfulness/check-in loop
+func buildVariationDirective(sessionID string) string {
+	if repetitionThreshold <= 0 {
+		return ""
+	}
+
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var messages []repository.Message
+	if err := db.Select("id", "content", "created_at").
+		Where("session_id = ? AND role = ? AND (message_type = ? OR message_type = '' OR message_type IS NULL)", sessionID, "coach", "conversation").
+		Order("created_at DESC").Limit(repetitionWindow).Find(&messages).Error; err != nil || len(messages) < 2 {
+		return ""
+	}
+
+	// Newest first: compare each message with the one before it
+	var best float64
+	for i := 0; i+1 < len(messages); i++ {
+		if similarity := messageSimilarity(messages[i].Content, messages[i+1].Content); similarity > best {
+			best = similarity
+		}
+	}
+	if best < repetitionThreshold {
+		return ""
+	}
+
+	logger.AppLogger.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"similarity": best,
+		"threshold":  repetitionThreshold,
+	}).Info("🔁 Near-identical coach messages - asking the model to vary phrasing")
+
+	return fmt.Sprintf("Your recent replies have been nearly identical (%.0f%% similar). Your last one was:\n\"%s\"\n"+
+		"Do not repeat it. Keep the same therapeutic intent but use different wording, imagery or a different invitation (e.g. a body sensation, an image, the breath).",
+		best*100, truncateAtWord(messages[0].Content, 300))
+}
+
+// messageSimilarity is 1 minus the normalized word-level Levenshtein distance, so word
+// order and small edits count but punctuation and case don't
+func messageSimilarity(a, b string) float64 {
+	wordsA, wordsB := similarityWords(a), similarityWords(b)
+	longest := len(wordsA)
+	if len(wordsB) > longest {
+		longest = len(wordsB)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(wordsA, wordsB))/float64(longest)
+}
+
+// similarityWords lowercases s and splits it into words, dropping punctuation
+func similarityWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// levenshtein is the edit distance between two word sequences
+func levenshtein(a, b []string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
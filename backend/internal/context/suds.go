@@ -0,0 +1,101 @@
+package contextbuilder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// sudsPromptCadence is the number of client turns without a SUDS reading before
+// the model is explicitly told to ask for one (0 disables the directive)
+var sudsPromptCadence = 3
+
+// SetSUDSPromptCadence configures how many turns may pass without a SUDS reading
+func SetSUDSPromptCadence(turns int) {
+	sudsPromptCadence = turns
+}
+
+// isSUDSField reports whether a phase_data field holds a SUDS reading
+func isSUDSField(name string) bool {
+	return strings.Contains(strings.ToLower(name), "suds")
+}
+
+// buildSUDSDirective returns an explicit instruction to ask for SUDS when the current
+// phase collects SUDS and the client has taken too many turns without a new reading
+func buildSUDSDirective(sessionID string, currentPhase string) string {
+	if sudsPromptCadence <= 0 {
+		return ""
+	}
+
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var phaseData []repository.PhaseData
+	if err := db.Where("phase_id = ?", currentPhase).Find(&phaseData).Error; err != nil {
+		return ""
+	}
+	var sudsFields []string
+	for _, pd := range phaseData {
+		if isSUDSField(pd.Name) {
+			sudsFields = append(sudsFields, pd.Name)
+		}
+	}
+	if len(sudsFields) == 0 {
+		return ""
+	}
+
+	var session repository.Session
+	if err := db.Select("id", "phase_start_time").First(&session, "id = ?", sessionID).Error; err != nil {
+		return ""
+	}
+
+	// Count turns since the later of phase start and the last SUDS reading this phase
+	since := session.PhaseStartTime
+	var lastReading repository.SessionFieldValue
+	if err := db.Where("session_id = ? AND field_name IN ?", sessionID, sudsFields).
+		Order("updated_at DESC").First(&lastReading).Error; err == nil && lastReading.UpdatedAt.After(since) {
+		since = lastReading.UpdatedAt
+	}
+
+	var turnsSinceSUDS int64
+	db.Model(&repository.Message{}).
+		Where("session_id = ? AND role = ? AND created_at > ?", sessionID, "client", since).
+		Count(&turnsSinceSUDS)
+
+	if int(turnsSinceSUDS) < sudsPromptCadence {
+		return ""
+	}
+
+	logger.AppLogger.WithFields(logrus.Fields{
+		"session_id":       sessionID,
+		"phase":            currentPhase,
+		"turns_since_suds": turnsSinceSUDS,
+		"cadence":          sudsPromptCadence,
+	}).Info("📏 Injecting SUDS prompt directive")
+
+	return fmt.Sprintf("It has been %d client turns without a SUDS reading. In this response, ask the client for their current SUDS level (0-10) and record it with record_suds.\n",
+		turnsSinceSUDS)
+}
+
+// sudsTrendLine summarizes the session's SUDS readings, e.g. "SUDS: 7→5→3, decreasing"
+func sudsTrendLine(db *gorm.DB, sessionID string) string {
+	readings, err := repository.SessionSudsReadings(db, sessionID)
+	if err != nil || len(readings) == 0 {
+		return ""
+	}
+	values := make([]string, 0, len(readings))
+	for _, reading := range readings {
+		values = append(values, strconv.FormatFloat(reading.Value, 'f', -1, 64))
+	}
+	line := "SUDS: " + strings.Join(values, "→")
+	if trend := repository.SudsTrend(readings); trend != "" {
+		line += ", " + trend
+	}
+	return line
+}
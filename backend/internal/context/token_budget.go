@@ -0,0 +1,62 @@
+package contextbuilder
+
+import (
+	"strconv"
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// contextTokenBudget is the default prompt budget in tokens (approx 4 chars/token); a phase
+// can override it with Phase.ContextTokenBudget
+var contextTokenBudget = 1500
+
+// sectionShares is each budgeted section's fraction of the total budget
+var sectionShares = map[string]float64{
+	"system_phase": 0.30,
+	"awareness":    0.15,
+	"working":      0.35,
+	"tools":        0.05,
+}
+
+// SetContextTokenBudget configures the default token budget and, optionally, the section
+// shares as "section=fraction" pairs, e.g. "working=0.5,awareness=0.1". Unknown sections and
+// malformed pairs are ignored.
+func SetContextTokenBudget(total int, shares string) {
+	if total > 0 {
+		contextTokenBudget = total
+	}
+	for _, pair := range strings.Split(shares, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		share, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if _, known := sectionShares[name]; !known || err != nil || share < 0 || share > 1 {
+			continue
+		}
+		sectionShares[name] = share
+	}
+}
+
+// resolveTokenBudget returns the phase's budget override, or the configured default
+func resolveTokenBudget(phaseID string) int {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var phase repository.Phase
+	if err := db.Select("id", "context_token_budget").First(&phase, "id = ?", phaseID).Error; err == nil && phase.ContextTokenBudget > 0 {
+		return phase.ContextTokenBudget
+	}
+	return contextTokenBudget
+}
+
+// sectionCaps splits a token budget into per-section caps
+func sectionCaps(budget int) map[string]int {
+	caps := make(map[string]int, len(sectionShares))
+	for name, share := range sectionShares {
+		caps[name] = int(share * float64(budget))
+	}
+	return caps
+}
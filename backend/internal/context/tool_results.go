@@ -0,0 +1,109 @@
+package contextbuilder
+
+import (
+	"encoding/json"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// toolResultFeedback feeds the results of the model's previous function calls into the
+// next turn's context, closing the function-calling loop
+var toolResultFeedback = true
+
+// SetToolResultFeedback enables or disables feeding tool results back to the model
+func SetToolResultFeedback(enabled bool) {
+	toolResultFeedback = enabled
+}
+
+// toolResultKeys are the parts of a tool result the model can act on; timestamps and
+// stored values are left out to keep the section small
+var toolResultKeys = []string{
+	"success",
+	"error",
+	"missing_requirements",
+	"requirements_satisfied",
+	"ready_to_transition",
+	"rejected_fields",
+	"unsupported_fields",
+	"guidance",
+	"instructions",
+	"decision_guidance",
+	"message",
+	"current_phase",
+}
+
+// ToolResultsDirective tells the model to act on the function responses of its previous turn
+const ToolResultsDirective = "Act on the results of your previous function calls: collect anything still missing and follow any guidance before calling the tool again."
+
+// ToolExchange is one of the model's previous function calls and its result, replayed as a
+// function call turn and a function response turn so the model sees its own calls answered
+type ToolExchange struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Response  map[string]interface{} `json:"response"`
+}
+
+// buildToolExchanges loads the tool calls made since the client's previous message with
+// the parts of their results the model can act on. Returns nil when there are none.
+func buildToolExchanges(sessionID string) []ToolExchange {
+	if !toolResultFeedback {
+		return nil
+	}
+
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	// The newest client message is the one being answered; tool calls from the previous
+	// coach turn come after the one before it
+	var clientMessages []repository.Message
+	_ = db.Select("id", "created_at").
+		Where("session_id = ? AND role IN ?", sessionID, []string{"client", "patient", "user"}).
+		Order("created_at DESC").Limit(2).Find(&clientMessages).Error
+
+	query := db.Where("session_id = ? AND message_type = ?", sessionID, "tool_call")
+	if len(clientMessages) == 2 {
+		query = query.Where("created_at > ?", clientMessages[1].CreatedAt)
+	}
+	var toolMessages []repository.Message
+	if err := query.Order("created_at ASC").Limit(5).Find(&toolMessages).Error; err != nil || len(toolMessages) == 0 {
+		return nil
+	}
+
+	var exchanges []ToolExchange
+	for _, msg := range toolMessages {
+		var metadata struct {
+			ToolName   string                 `json:"tool_name"`
+			Arguments  map[string]interface{} `json:"arguments"`
+			Status     string                 `json:"status"`
+			Success    bool                   `json:"success"`
+			Error      string                 `json:"error"`
+			ToolResult json.RawMessage        `json:"tool_result"`
+		}
+		if err := json.Unmarshal([]byte(msg.Metadata), &metadata); err != nil || metadata.ToolName == "" {
+			continue
+		}
+		exchange := ToolExchange{Name: metadata.ToolName, Arguments: metadata.Arguments}
+
+		if metadata.Status == "executing" {
+			exchange.Response = map[string]interface{}{"status": "still running"}
+			exchanges = append(exchanges, exchange)
+			continue
+		}
+
+		// Results that aren't objects only report success
+		var toolResult map[string]interface{}
+		_ = json.Unmarshal(metadata.ToolResult, &toolResult)
+
+		exchange.Response = map[string]interface{}{"success": metadata.Success}
+		if metadata.Error != "" {
+			exchange.Response["error"] = metadata.Error
+		}
+		for _, key := range toolResultKeys {
+			if value, ok := toolResult[key]; ok && value != nil {
+				exchange.Response[key] = value
+			}
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges
+}
@@ -0,0 +1,63 @@
+package contextbuilder
+
+import "strings"
+
+// coachTool is a function the coach model can actually call. Both the TOOLS section and the
+// phase workflow section are rendered from coachTools, and the coach declares each one by
+// name, so the prompt never advertises a tool the model can't call.
+type coachTool struct {
+	Name    string
+	Params  string
+	Summary string
+}
+
+var coachTools = []coachTool{
+	{
+		Name:    "collect_structured_data",
+		Params:  "session_id, data",
+		Summary: "Collect phase-required data and auto-transition when requirements are met",
+	},
+	{
+		Name:    "record_suds",
+		Params:  "session_id, value",
+		Summary: "Record a SUDS rating (0-10); every reading is kept as the session's distress trend",
+	},
+}
+
+// signature renders the tool as name(params)
+func (t coachTool) signature() string {
+	return t.Name + "(" + t.Params + ")"
+}
+
+// coachToolList returns the tool strings handed to the coach in the context bundle
+func coachToolList() []string {
+	tools := make([]string, 0, len(coachTools))
+	for _, t := range coachTools {
+		tools = append(tools, t.signature()+" - "+t.Summary)
+	}
+	return tools
+}
+
+// coachToolCallable reports whether the coach model can call the named tool
+func coachToolCallable(name string) bool {
+	for _, t := range coachTools {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAvailableTools renders the callable tools for the phase workflow section, adding the
+// current phase's expected fields under collect_structured_data
+func writeAvailableTools(sb *strings.Builder, schemaInfo []string) {
+	sb.WriteString("\nTOOLS AVAILABLE:\n")
+	for _, t := range coachTools {
+		sb.WriteString("- " + t.signature() + " - " + t.Summary + "\n")
+		if t.Name == "collect_structured_data" && len(schemaInfo) > 0 {
+			sb.WriteString("  Current phase expects: " + strings.Join(schemaInfo, ", ") + "\n")
+			sb.WriteString("  Returns: ready_to_transition (bool) - if true, phase will auto-transition\n")
+		}
+	}
+	sb.WriteString("\nIMPORTANT: Only use these exact tool names. Any other tool will fail immediately.\n")
+}
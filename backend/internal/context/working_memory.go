@@ -0,0 +1,154 @@
+package contextbuilder
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// systemMessageMode controls how non-conversational messages (system annotations
+// and tool calls) appear in working memory: "annotate" labels them, "exclude" drops them
+var systemMessageMode = "annotate"
+
+// SetSystemMessageMode configures how system and tool-call messages appear in working memory
+func SetSystemMessageMode(mode string) {
+	if mode == "annotate" || mode == "exclude" {
+		systemMessageMode = mode
+	}
+}
+
+// Oversized client messages are condensed before entering working memory so one pasted
+// journal entry can't crowd out the conversation. The stored message is never changed.
+const (
+	LongMessageOff       = "off"       // Use the message as-is
+	LongMessageTruncate  = "truncate"  // Keep the opening of the message
+	LongMessageSummarize = "summarize" // Keep the opening and closing sentences
+)
+
+var (
+	longMessageMode  = LongMessageSummarize
+	longMessageChars = 600
+)
+
+// SetLongMessageHandling configures how client messages over maxChars appear in working memory
+func SetLongMessageHandling(mode string, maxChars int) {
+	switch mode {
+	case LongMessageOff, LongMessageTruncate, LongMessageSummarize:
+		longMessageMode = mode
+	}
+	if maxChars > 0 {
+		longMessageChars = maxChars
+	}
+}
+
+// condenseLongMessage shortens an oversized client message for working memory
+func condenseLongMessage(content string) string {
+	if longMessageMode == LongMessageOff || len(content) <= longMessageChars {
+		return content
+	}
+	totalWords := len(strings.Fields(content))
+
+	var kept string
+	if longMessageMode == LongMessageSummarize {
+		kept = summarizeSentences(content, longMessageChars)
+	} else {
+		kept = truncateAtWord(content, longMessageChars)
+	}
+	omitted := totalWords - len(strings.Fields(kept))
+	return fmt.Sprintf("%s [long message condensed, %d of %d words omitted; full text in transcript]", kept, omitted, totalWords)
+}
+
+// summarizeSentences keeps the opening sentences and the closing one within budget chars,
+// since clients usually lead with context and end with what they want to talk about
+func summarizeSentences(content string, budget int) string {
+	sentences := splitSentences(content)
+	if len(sentences) < 2 {
+		return truncateAtWord(content, budget)
+	}
+
+	last := sentences[len(sentences)-1]
+	if len(last) > budget/2 {
+		last = truncateAtWord(last, budget/2)
+	}
+
+	var opening []string
+	used := len(last)
+	for _, sentence := range sentences[:len(sentences)-1] {
+		if used+len(sentence)+1 > budget {
+			break
+		}
+		opening = append(opening, sentence)
+		used += len(sentence) + 1
+	}
+	if len(opening) == 0 {
+		opening = append(opening, truncateAtWord(sentences[0], budget-len(last)))
+	}
+	return strings.Join(opening, " ") + " … " + last
+}
+
+// splitSentences splits on sentence-ending punctuation and line breaks
+func splitSentences(content string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range content {
+		if r == '.' || r == '!' || r == '?' || r == '\n' {
+			if sentence := strings.TrimSpace(content[start : i+1]); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(content[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// truncateAtWord cuts s to at most limit bytes without splitting a word
+func truncateAtWord(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := strings.LastIndex(s[:limit], " ")
+	if cut <= 0 {
+		cut = limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimSpace(s[:cut]) + "…"
+}
+
+// renderWorkingMemoryLine renders one message for working memory; ok is false when it's left out.
+// Only client messages are attributed to the patient.
+func renderWorkingMemoryLine(msg *repository.Message) (line string, ok bool) {
+	switch {
+	case msg.MessageType == "tool_call":
+		if systemMessageMode == "exclude" {
+			return "", false
+		}
+		return fmt.Sprintf("[Tool call: %s]\n", msg.Content), true
+	case msg.MessageType == "attachment":
+		// Only the reference - the file itself never enters the prompt
+		who := "Patient"
+		if msg.Role == "therapist" {
+			who = "Therapist"
+		}
+		return fmt.Sprintf("[%s attachment: %s]\n", who, msg.Content), true
+	case msg.Role == "therapist" || msg.Role == "coach":
+		return fmt.Sprintf("Therapist: %s\n", msg.Content), true
+	case msg.Role == "client" || msg.Role == "patient" || msg.Role == "user":
+		return fmt.Sprintf("Patient: %s\n", condenseLongMessage(msg.Content)), true
+	case repository.ParticipantRoleAllowed(msg.Role):
+		// Supervisors, co-therapists and other session participants
+		return fmt.Sprintf("%s: %s\n", participantLabel(msg.Role), condenseLongMessage(msg.Content)), true
+	default:
+		// System annotations (timer triggers etc.) are never the client's words
+		if systemMessageMode == "exclude" {
+			return "", false
+		}
+		return fmt.Sprintf("[System note, not said by the patient: %s]\n", msg.Content), true
+	}
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handleEnableAutoMode stores whether collect_structured_data may auto-transition the session
+func (s *MCPServer) handleEnableAutoMode(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		SessionID string `json:"session_id"`
+		Enabled   *bool  `json:"enabled"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.SessionID == "" || args.Enabled == nil {
+		return nil, fmt.Errorf("session_id and enabled are required")
+	}
+
+	result := repository.Scoped(ctx).Model(&repository.Session{}).Where("id = ?", args.SessionID).Update("auto_mode", *args.Enabled)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update auto mode: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("session not found: %s", args.SessionID)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id": args.SessionID,
+		"auto_mode":  *args.Enabled,
+	}).Info("Session auto mode updated")
+
+	return map[string]interface{}{
+		"success":    true,
+		"session_id": args.SessionID,
+		"auto_mode":  *args.Enabled,
+		"timestamp":  time.Now(),
+	}, nil
+}
@@ -91,10 +91,12 @@ func (c *MCPClient) ToolsList(ctx context.Context) (*json.RawMessage, error) {
 	return c.call(ctx, "tools/list", nil)
 }
 
-func (c *MCPClient) ToolsCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+// ToolsCall executes a tool; toolCallID makes retries of the same call idempotent
+func (c *MCPClient) ToolsCall(ctx context.Context, name string, arguments json.RawMessage, toolCallID string) (interface{}, error) {
 	result, err := c.call(ctx, "tools/call", map[string]interface{}{
-		"name":      name,
-		"arguments": arguments,
+		"name":         name,
+		"arguments":    arguments,
+		"tool_call_id": toolCallID,
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// clientDataGuard rejects client-sourced fields that no recent client message supports,
+// catching the model answering on the client's behalf
+var clientDataGuard = struct {
+	enabled bool
+	window  int // Number of most recent messages searched for evidence
+}{enabled: true, window: 6}
+
+// SetClientDataGuard configures the client-evidence check on collect_structured_data
+func SetClientDataGuard(enabled bool, window int) {
+	clientDataGuard.enabled = enabled
+	if window > 0 {
+		clientDataGuard.window = window
+	}
+}
+
+// numberWords lets "seven" count as evidence for a SUDS of 7
+var numberWords = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+
+// recentClientText returns the client's messages among the most recent messages of the session
+func recentClientText(sessionID string) ([]string, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var recent []repository.Message
+	if err := db.Where("session_id = ?", sessionID).
+		Order("created_at DESC").
+		Limit(clientDataGuard.window).
+		Find(&recent).Error; err != nil {
+		return nil, fmt.Errorf("failed to load recent messages: %w", err)
+	}
+
+	var texts []string
+	for _, msg := range recent {
+		if msg.Role == "client" && strings.TrimSpace(msg.Content) != "" {
+			texts = append(texts, strings.ToLower(msg.Content))
+		}
+	}
+	return texts, nil
+}
+
+// hasClientEvidence reports whether any of the client's recent messages plausibly contains value.
+// Booleans only need a client reply; numbers must be mentioned; text must share a content word.
+func hasClientEvidence(value interface{}, clientTexts []string) bool {
+	if len(clientTexts) == 0 {
+		return false
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return true
+	case float64:
+		number := strconv.FormatFloat(v, 'f', -1, 64)
+		for _, text := range clientTexts {
+			if containsWord(text, number) {
+				return true
+			}
+			if v == float64(int(v)) && int(v) >= 0 && int(v) < len(numberWords) && containsWord(text, numberWords[int(v)]) {
+				return true
+			}
+		}
+		return false
+	case string:
+		words := contentWords(v)
+		if len(words) == 0 {
+			return true
+		}
+		for _, text := range clientTexts {
+			for _, word := range words {
+				// Prefix match tolerates paraphrase ("anxious" vs "anxiety")
+				if strings.Contains(text, stem(word)) {
+					return true
+				}
+			}
+		}
+		return false
+	default:
+		// Structured values are assembled by the coach; a client reply is enough
+		return true
+	}
+}
+
+// containsWord reports whether word appears in text as a whole token
+func containsWord(text string, word string) bool {
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-'
+	}) {
+		if strings.Trim(token, ".") == word {
+			return true
+		}
+	}
+	return false
+}
+
+// contentWords returns the lowercase words of s long enough to be meaningful
+func contentWords(s string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) >= 4 {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// stem truncates word so different forms of it still match
+func stem(word string) string {
+	if len(word) > 4 {
+		return word[:4]
+	}
+	return word
+}
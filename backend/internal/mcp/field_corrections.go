@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/state"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CorrectField stores a clinician's correction of a collected field, bypassing the coach.
+// Only fields the current phase defines can be corrected. The value is checked against the
+// field's schema like a collected one (but not against the client's messages), recorded with
+// the manual source, and the phase's requirements are re-evaluated: an auto-mode session
+// that's now ready transitions as after a collect.
+func (s *MCPServer) CorrectField(ctx context.Context, sessionID string, fieldName string, value interface{}) (map[string]interface{}, error) {
+	if fieldName == "" {
+		return nil, fmt.Errorf("field_name is required")
+	}
+
+	var session repository.Session
+	if err := repository.Scoped(ctx).Where("id = ?", sessionID).First(&session).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	var phaseFields []repository.PhaseData
+	repository.DB.Where("phase_id = ?", session.Phase).Find(&phaseFields)
+	defined := false
+	for _, field := range phaseFields {
+		if field.Name == fieldName {
+			defined = true
+			break
+		}
+	}
+	if !defined {
+		return nil, fmt.Errorf("field %s is not defined for phase %s", fieldName, session.Phase)
+	}
+	schemas := schemaFields(phaseFields, map[string]interface{}{fieldName: value})
+
+	value, fieldType, adjustment := normalizeFieldValue(schemas[fieldName].DeclaredType(), value)
+	if field, ok := schemas[fieldName]; ok {
+		if err := field.ValidateValue(value); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", fieldName, err)
+		}
+	}
+
+	var previous repository.SessionFieldValue
+	hadPrevious := repository.DB.Where("session_id = ? AND field_name = ?", sessionID, fieldName).First(&previous).Error == nil
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", fieldName, err)
+	}
+	record := repository.SessionFieldValue{
+		SessionID:  sessionID,
+		PhaseID:    session.Phase,
+		FieldName:  fieldName,
+		FieldValue: string(encoded),
+		FieldType:  fieldType,
+		Source:     repository.FieldSourceManual,
+	}
+	if err := repository.UpsertSessionFieldValue(repository.DB, &record); err != nil {
+		return nil, err
+	}
+
+	fields := logrus.Fields{
+		"session_id": sessionID,
+		"phase":      session.Phase,
+		"field":      fieldName,
+		"value":      record.FieldValue,
+	}
+	if hadPrevious {
+		fields["previous_value"] = previous.FieldValue
+		fields["previous_source"] = previous.Source
+	}
+	s.logger.WithFields(fields).Info("✏️ Field value corrected manually")
+
+	stateMachine := state.New(sessionID)
+	readyToTransition := stateMachine.ValidatePhaseRequirements(session.Phase) == nil
+	missing, err := stateMachine.GetMissingFields(session.Phase)
+	if err != nil {
+		missing = []string{}
+	}
+
+	result := map[string]interface{}{
+		"success":              true,
+		"field_name":           fieldName,
+		"field_type":           fieldType,
+		"source":               repository.FieldSourceManual,
+		"missing_requirements": missing,
+		"ready_to_transition":  readyToTransition,
+		"timestamp":            time.Now(),
+	}
+	if adjustment != "" {
+		result["type_adjustment"] = adjustment
+	}
+
+	// A correction can complete the phase's requirements, or change where it branches
+	if readyToTransition && session.AutoMode {
+		var collected []repository.SessionFieldValue
+		repository.DB.Where("session_id = ?", sessionID).Find(&collected)
+		transitionArgs, _ := json.Marshal(map[string]string{
+			"session_id":   sessionID,
+			"target_phase": s.autoTransitionTarget(session.Phase, collected),
+			"reason":       fmt.Sprintf("Auto-transition: requirements satisfied after correcting %s", fieldName),
+			"from_phase":   session.Phase,
+		})
+		transition, err := s.handleTransition(ctx, transitionArgs)
+		result["auto_transition_attempted"] = true
+		if err != nil {
+			s.logger.WithError(err).WithField("session_id", sessionID).Error("❌ AUTO-TRANSITION after correction failed")
+			result["auto_transition_error"] = err.Error()
+		} else {
+			result["transition_result"] = transition
+		}
+	}
+
+	// A transition broadcasts the update itself, already carrying the correction
+	if !transitioned(result) {
+		s.broadcastWorkflowUpdate(sessionID, session.Phase)
+	}
+	return result, nil
+}
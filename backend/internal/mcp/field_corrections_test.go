@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCorrectField(t *testing.T) {
+	db := newTestDB(t)
+	for _, phase := range []repository.Phase{
+		{ID: "intake", DisplayName: "Intake", Position: 1},
+		{ID: "grounding", DisplayName: "Grounding", Position: 2},
+	} {
+		if err := db.Create(&phase).Error; err != nil {
+			t.Fatalf("failed to create phase: %v", err)
+		}
+	}
+	for _, field := range []repository.PhaseData{
+		{ID: "intake_issue", PhaseID: "intake", Name: "issue", Requirement: repository.PhaseDataRequired},
+		{ID: "intake_suds", PhaseID: "intake", Name: "suds", Requirement: repository.PhaseDataOptional,
+			Schema: `{"type": "integer", "minimum": 0, "maximum": 10}`},
+		{ID: "grounding_anchor", PhaseID: "grounding", Name: "anchor", Requirement: repository.PhaseDataRequired},
+	} {
+		if err := db.Create(&field).Error; err != nil {
+			t.Fatalf("failed to create phase data: %v", err)
+		}
+	}
+	if err := db.Create(&repository.PhaseTransition{ID: "intake_grounding", FromPhaseID: "intake", ToPhaseID: "grounding"}).Error; err != nil {
+		t.Fatalf("failed to create transition: %v", err)
+	}
+	session := repository.Session{
+		ClientID:       "client-1",
+		TherapistID:    "therapist-1",
+		Status:         repository.SessionStatusActive,
+		Phase:          "intake",
+		AutoMode:       true,
+		StartTime:      time.Now(),
+		PhaseStartTime: time.Now(),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	// One exchange meets the phase's default minimum turns
+	for _, role := range []string{"client", "coach"} {
+		if err := db.Create(&repository.Message{SessionID: session.ID, Role: role, Content: "hello"}).Error; err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+	// The coach collected a SUDS value the clinician will correct
+	if err := repository.UpsertSessionFieldValue(db, &repository.SessionFieldValue{
+		SessionID: session.ID, PhaseID: "intake", FieldName: "suds", FieldValue: "7", FieldType: "integer", Source: repository.FieldSourceAI,
+	}); err != nil {
+		t.Fatalf("failed to store suds: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	recorder := &broadcastRecorder{counts: make(map[string]int)}
+	server := NewMCPServer(logger, recorder.broadcast)
+	stored := func(name string) (repository.SessionFieldValue, bool) {
+		var value repository.SessionFieldValue
+		err := db.First(&value, "session_id = ? AND field_name = ?", session.ID, name).Error
+		return value, err == nil
+	}
+	phase := func() string {
+		var current repository.Session
+		db.Select("phase").First(&current, "id = ?", session.ID)
+		return current.Phase
+	}
+
+	if _, err := server.CorrectField(context.Background(), session.ID, "anchor", "breath"); err == nil {
+		t.Error("corrected a field the current phase doesn't define")
+	}
+	if _, ok := stored("anchor"); ok {
+		t.Error("a rejected correction stored a value")
+	}
+
+	if _, err := server.CorrectField(context.Background(), session.ID, "suds", float64(11)); err == nil {
+		t.Error("corrected suds to 11, outside its schema's 0-10")
+	}
+	if suds, _ := stored("suds"); suds.FieldValue != "7" {
+		t.Errorf("suds = %s after a rejected correction, want the coach's 7", suds.FieldValue)
+	}
+
+	result, err := server.CorrectField(context.Background(), session.ID, "suds", float64(4))
+	if err != nil {
+		t.Fatalf("correcting suds: %v", err)
+	}
+	if suds, _ := stored("suds"); suds.FieldValue != "4" || suds.Source != repository.FieldSourceManual {
+		t.Errorf("suds = %s from %q, want 4 from %q", suds.FieldValue, suds.Source, repository.FieldSourceManual)
+	}
+	if result["ready_to_transition"] != false || result["auto_transition_attempted"] != nil || phase() != "intake" {
+		t.Errorf("correcting an optional field = %v in phase %s; want no transition while issue is missing", result, phase())
+	}
+	if missing, _ := result["missing_requirements"].([]string); len(missing) != 1 || missing[0] != "issue" {
+		t.Errorf("missing_requirements = %v, want [issue]", result["missing_requirements"])
+	}
+
+	result, err = server.CorrectField(context.Background(), session.ID, "issue", "work stress")
+	if err != nil {
+		t.Fatalf("correcting issue: %v", err)
+	}
+	if issue, _ := stored("issue"); issue.Source != repository.FieldSourceManual {
+		t.Errorf("issue source = %q, want %q", issue.Source, repository.FieldSourceManual)
+	}
+	if result["ready_to_transition"] != true || !transitioned(result) || phase() != "grounding" {
+		t.Errorf("completing the requirements = %v in phase %s; want an auto-transition to grounding", result, phase())
+	}
+	if got := recorder.count("phase_transition"); got != 1 {
+		t.Errorf("%d phase_transition broadcasts, want 1", got)
+	}
+}
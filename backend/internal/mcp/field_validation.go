@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// schemaFields maps each submitted field to the PhaseData whose schema validates it. The
+// current phase's definition wins; fields defined only by another phase (e.g. a SUDS
+// reading recorded early) use that phase's definition.
+func schemaFields(phaseFields []repository.PhaseData, data map[string]interface{}) map[string]repository.PhaseData {
+	fields := make(map[string]repository.PhaseData, len(data))
+	for _, field := range phaseFields {
+		if _, submitted := data[field.Name]; submitted {
+			fields[field.Name] = field
+		}
+	}
+
+	var others []string
+	for key := range data {
+		if _, known := fields[key]; !known {
+			others = append(others, key)
+		}
+	}
+	if len(others) == 0 {
+		return fields
+	}
+
+	var defined []repository.PhaseData
+	if err := repository.DB.Where("name IN ?", others).Find(&defined).Error; err != nil {
+		return fields
+	}
+	for _, field := range defined {
+		if _, known := fields[field.Name]; !known {
+			fields[field.Name] = field
+		}
+	}
+	return fields
+}
+
+// normalizeFieldValue coerces a collected value to its field's declared numeric type
+// before validation: integer fields are rounded (SUDS 7.4 is stored as 7) and numeric
+// strings become numbers. Returns the value to validate and store, the resolved type
+// recorded as the field's FieldType, and a note describing any adjustment.
+func normalizeFieldValue(declared string, value interface{}) (interface{}, string, string) {
+	if declared == "integer" || declared == "number" {
+		if text, ok := value.(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+				value = n
+			}
+		}
+		if n, ok := value.(float64); ok {
+			if declared == "integer" && n != math.Trunc(n) {
+				rounded := math.Round(n)
+				return rounded, declared, fmt.Sprintf("rounded %v to %v: the field expects an integer", n, rounded)
+			}
+			return n, declared, ""
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) {
+			return value, "integer", ""
+		}
+		return value, "number", ""
+	case bool:
+		return value, "boolean", ""
+	case map[string]interface{}, []interface{}:
+		return value, "object", ""
+	}
+	return value, "string", ""
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+type toolCallIDContextKey struct{}
+
+// toolCallRetention is how long applied tool calls are remembered for duplicate detection
+var toolCallRetention = 24 * time.Hour
+
+// duplicateWait bounds how long a retried call waits for the original to finish
+const duplicateWait = 10 * time.Second
+
+// pendingClaimTimeout is how long a claimed call may stay unfinished before a retry takes
+// it over, assuming the process applying it died
+const pendingClaimTimeout = 2 * time.Minute
+
+// SetToolCallRetention configures how long applied tool calls are remembered
+func SetToolCallRetention(retention time.Duration) {
+	if retention > 0 {
+		toolCallRetention = retention
+	}
+}
+
+// WithToolCallID attaches the model's tool call ID so retries of the call are applied once
+func WithToolCallID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, toolCallIDContextKey{}, id)
+}
+
+// ToolCallIDFromContext returns the tool call ID attached to ctx, if any
+func ToolCallIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(toolCallIDContextKey{}).(string)
+	return id
+}
+
+// runOnce applies a tool call at most once per tool call ID. A duplicate delivery returns the
+// original result, waiting for it if the original is still running.
+func (s *MCPServer) runOnce(ctx context.Context, toolName string, arguments json.RawMessage, apply func() (interface{}, error)) (interface{}, error) {
+	id := ToolCallIDFromContext(ctx)
+	if id == "" {
+		return apply()
+	}
+
+	var args struct {
+		SessionID string `json:"session_id"`
+	}
+	json.Unmarshal(arguments, &args)
+
+	existing, claimed, err := repository.ClaimToolCall(repository.DB, id, args.SessionID, toolName, pendingClaimTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		s.logger.WithField("tool_call_id", id).WithField("tool", toolName).Warn("🔁 Duplicate tool call delivery - returning original result")
+		return s.awaitToolCall(ctx, existing)
+	}
+
+	result, err := apply()
+	if err != nil {
+		if releaseErr := repository.ReleaseToolCall(repository.DB, id, args.SessionID, toolName); releaseErr != nil {
+			s.logger.WithError(releaseErr).WithField("tool_call_id", id).Warn("Failed to release failed tool call")
+		}
+		return nil, err
+	}
+	if err := repository.CompleteToolCall(repository.DB, id, args.SessionID, toolName, result); err != nil {
+		s.logger.WithError(err).WithField("tool_call_id", id).Warn("Failed to record tool call result")
+	}
+
+	if err := repository.PurgeToolCalls(repository.DB, toolCallRetention); err != nil {
+		s.logger.WithError(err).Debug("Failed to purge old tool calls")
+	}
+
+	return result, nil
+}
+
+// awaitToolCall returns the result of an already-claimed tool call once it completes
+func (s *MCPServer) awaitToolCall(ctx context.Context, record *repository.ToolCallRecord) (interface{}, error) {
+	deadline := time.Now().Add(duplicateWait)
+	for record.Status != "completed" {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("tool call %s is still being applied", record.ID)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+		current, err := repository.FindToolCall(repository.DB, record.ID, record.SessionID, record.ToolName)
+		if err != nil {
+			// The original failed and released its claim
+			return nil, fmt.Errorf("tool call %s failed, retry it: %w", record.ID, err)
+		}
+		record = current
+	}
+	return record.DecodeResult()
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import "context"
+
+// ProgressFunc receives a tool call's progress (0.0-1.0) and the step it has reached
+type ProgressFunc func(progress float64, step string)
+
+type progressKey struct{}
+
+// withProgress attaches a progress reporter to ctx for the tool handlers below CallTool
+func withProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress reports a step of the running tool call; a no-op when nothing listens
+func reportProgress(ctx context.Context, progress float64, step string) {
+	if report, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && report != nil {
+		report(progress, step)
+	}
+}
+
+// progressRange scales progress reported under the returned ctx into [from, to], so a
+// handler called from another (collect's auto-transition) continues its caller's progress
+func progressRange(ctx context.Context, from float64, to float64) context.Context {
+	report, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || report == nil {
+		return ctx
+	}
+	return withProgress(ctx, func(progress float64, step string) {
+		report(from+progress*(to-from), step)
+	})
+}
@@ -2,7 +2,6 @@ package mcp
 
 import (
 	_ "embed"
-	"encoding/json"
 	"fmt"
 	"therapy-navigation-system/internal/repository"
 	"time"
@@ -198,6 +197,7 @@ var protocolJSON = []byte(`{
 // Phase represents a therapy session phase
 type Phase struct {
 	ID                  string            `json:"id"`
+	Type                string            `json:"type"`
 	DurationSec         int               `json:"duration_sec"`
 	RequiredFields      []string          `json:"required_fields"`
 	CheckInIntervalSec  int               `json:"check_in_interval_sec"`
@@ -211,17 +211,19 @@ type Protocol struct {
 	Phases []Phase `json:"phases"`
 }
 
-// ProtocolService manages therapy protocol configuration
+// ProtocolService manages therapy protocol configuration. Live sessions read phases from
+// the database; the protocol seeds defaults there via BackfillPhaseDefaults.
 type ProtocolService struct {
 	protocol *Protocol
 	phaseMap map[string]*Phase
 }
 
-// NewProtocolService creates and initializes a protocol service
-func NewProtocolService() (*ProtocolService, error) {
-	var protocol Protocol
-	if err := json.Unmarshal(protocolJSON, &protocol); err != nil {
-		return nil, fmt.Errorf("failed to parse protocol JSON: %w", err)
+// NewProtocolService creates and initializes a protocol service from source (see
+// ResolveProtocolSource); path is the protocol file for the file source
+func NewProtocolService(source string, path string) (*ProtocolService, error) {
+	protocol, err := loadProtocol(source, path)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate protocol
@@ -246,7 +248,7 @@ func NewProtocolService() (*ProtocolService, error) {
 	}
 
 	return &ProtocolService{
-		protocol: &protocol,
+		protocol: protocol,
 		phaseMap: phaseMap,
 	}, nil
 }
@@ -322,6 +324,11 @@ func (s *ProtocolService) GetNextPhase(currentPhaseID string) (string, error) {
 	return "", nil // No next phase (session complete)
 }
 
+// GetProtocolID returns the loaded protocol's ID
+func (s *ProtocolService) GetProtocolID() string {
+	return s.protocol.ID
+}
+
 // GetAllPhases returns all phases in order
 func (s *ProtocolService) GetAllPhases() []Phase {
 	return s.protocol.Phases
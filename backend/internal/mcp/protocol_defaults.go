@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// protocolPhaseIDs maps protocol phase IDs onto the database workflow's where they differ
+var protocolPhaseIDs = map[string]string{"status_check_loop": "status_check"}
+
+// BackfillPhaseDefaults copies protocol timing into database phases that haven't set it.
+// The database is the source of truth for live sessions; the protocol only supplies
+// defaults, so values edited in the Workflow Studio are never overwritten. Each phase is
+// backfilled once: after that a 0 is taken as intentional. Protocol phases
+// without a database counterpart (e.g. activation_and_setup, whose steps the database
+// models as body_scan and eye_position) are skipped.
+func (s *ProtocolService) BackfillPhaseDefaults(db *gorm.DB) (int, error) {
+	updated := 0
+	for _, p := range s.protocol.Phases {
+		phaseID := repository.CanonicalPhaseID(p.ID)
+		if mapped, ok := protocolPhaseIDs[phaseID]; ok {
+			phaseID = mapped
+		}
+
+		var phase repository.Phase
+		if err := db.Where("id = ? AND protocol_defaults_applied_at IS NULL", phaseID).Limit(1).Find(&phase).Error; err != nil {
+			return updated, err
+		}
+		if phase.ID == "" {
+			continue
+		}
+
+		changes := map[string]interface{}{"protocol_defaults_applied_at": time.Now()}
+		if phase.CheckInIntervalSeconds == 0 && p.CheckInIntervalSec > 0 {
+			changes["check_in_interval_seconds"] = p.CheckInIntervalSec
+		}
+		// Only timed protocol phases carry a hard duration; setting one elsewhere would
+		// turn a conversational phase into a timed one
+		if phase.DurationSeconds == 0 && p.Type == "timed_with_checkins" {
+			changes["duration_seconds"] = p.DurationSec
+		}
+		backfilled := len(changes) > 1
+
+		if err := db.Model(&repository.Phase{}).Where("id = ?", phaseID).Updates(changes).Error; err != nil {
+			return updated, err
+		}
+		if backfilled {
+			updated++
+		}
+	}
+	return updated, nil
+}
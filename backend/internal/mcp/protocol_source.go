@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Where the protocol definition comes from: the embedded brainspotting JSON or a JSON file
+// (PROTOCOL_PATH). Either way it only supplies timing defaults; the phases table drives
+// sessions (see BackfillPhaseDefaults).
+const (
+	ProtocolSourceEmbedded = "embedded"
+	ProtocolSourceFile     = "file"
+)
+
+// ResolveProtocolSource picks the source when none is configured: the file when a path is
+// set, otherwise the embedded definition
+func ResolveProtocolSource(source string, path string) string {
+	if source != "" {
+		return source
+	}
+	if path != "" {
+		return ProtocolSourceFile
+	}
+	return ProtocolSourceEmbedded
+}
+
+// loadProtocol reads the protocol definition from source; it is validated by the caller
+func loadProtocol(source string, path string) (*Protocol, error) {
+	switch ResolveProtocolSource(source, path) {
+	case ProtocolSourceEmbedded:
+		return parseProtocol(protocolJSON)
+	case ProtocolSourceFile:
+		if path == "" {
+			return nil, fmt.Errorf("protocol source %q requires PROTOCOL_PATH", ProtocolSourceFile)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read protocol file: %w", err)
+		}
+		return parseProtocol(data)
+	default:
+		return nil, fmt.Errorf("unknown protocol source %q: use %s or %s", source, ProtocolSourceEmbedded, ProtocolSourceFile)
+	}
+}
+
+// parseProtocol decodes a protocol definition from JSON
+func parseProtocol(data []byte) (*Protocol, error) {
+	var protocol Protocol
+	if err := json.Unmarshal(data, &protocol); err != nil {
+		return nil, fmt.Errorf("failed to parse protocol JSON: %w", err)
+	}
+	return &protocol, nil
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+func writeProtocolFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "protocol.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write protocol file: %v", err)
+	}
+	return path
+}
+
+func TestNewProtocolServiceSources(t *testing.T) {
+	valid := writeProtocolFile(t, `{"id":"emdr","phases":[{"id":"processing","type":"timed_with_checkins","duration_sec":600}]}`)
+	zeroDuration := writeProtocolFile(t, `{"id":"emdr","phases":[{"id":"processing","duration_sec":0}]}`)
+
+	for _, tc := range []struct {
+		name    string
+		source  string
+		path    string
+		wantID  string
+		wantErr string
+	}{
+		{"embedded by default", "", "", "brainspotting_8_stage_v1", ""},
+		{"file when a path is set", "", valid, "emdr", ""},
+		{"file without a path", ProtocolSourceFile, "", "", "requires PROTOCOL_PATH"},
+		{"invalid duration", "", zeroDuration, "", "invalid duration"},
+		{"database is not a source", "database", "", "", "unknown protocol source"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			protocol, err := NewProtocolService(tc.source, tc.path)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewProtocolService: %v", err)
+			}
+			if protocol.GetProtocolID() != tc.wantID {
+				t.Errorf("loaded protocol %s, want %s", protocol.GetProtocolID(), tc.wantID)
+			}
+		})
+	}
+}
+
+func TestBackfillPhaseDefaultsOnlyFillsUnsetTiming(t *testing.T) {
+	db := newTestDB(t)
+	for _, phase := range []repository.Phase{
+		{ID: "processing", DisplayName: "Processing", Position: 1},
+		{ID: "closing", DisplayName: "Closing", Position: 2, DurationSeconds: 90},
+	} {
+		if err := db.Create(&phase).Error; err != nil {
+			t.Fatalf("failed to create phase: %v", err)
+		}
+	}
+	path := writeProtocolFile(t, `{"id":"emdr","phases":[
+		{"id":"processing","type":"timed_with_checkins","duration_sec":600,"check_in_interval_sec":60},
+		{"id":"closing","type":"timed_with_checkins","duration_sec":300},
+		{"id":"unknown_phase","type":"timed_with_checkins","duration_sec":300}]}`)
+	protocol, err := NewProtocolService("", path)
+	if err != nil {
+		t.Fatalf("NewProtocolService: %v", err)
+	}
+
+	updated, err := protocol.BackfillPhaseDefaults(db)
+	if err != nil {
+		t.Fatalf("BackfillPhaseDefaults: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("backfilled %d phases, want 1", updated)
+	}
+
+	var processing, closing repository.Phase
+	db.First(&processing, "id = ?", "processing")
+	db.First(&closing, "id = ?", "closing")
+	if processing.DurationSeconds != 600 || processing.CheckInIntervalSeconds != 60 {
+		t.Errorf("processing timing = %ds every %ds, want the protocol's 600s every 60s",
+			processing.DurationSeconds, processing.CheckInIntervalSeconds)
+	}
+	if closing.DurationSeconds != 90 {
+		t.Errorf("closing duration = %ds, want its own 90s kept", closing.DurationSeconds)
+	}
+
+	var phases int64
+	db.Model(&repository.Phase{}).Count(&phases)
+	if phases != 2 {
+		t.Errorf("backfill created phases: %d in the table, want 2", phases)
+	}
+}
@@ -0,0 +1,278 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ToolHandler executes a tool call with its raw JSON arguments
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+
+// registeredTool pairs a tool definition with the handler CallTool dispatches to
+type registeredTool struct {
+	definition Tool
+	handler    ToolHandler
+}
+
+// disabledTools are registered but refused by CallTool and hidden from tools/list
+var (
+	disabledToolsMutex sync.RWMutex
+	disabledTools      = map[string]bool{}
+)
+
+// SetDisabledTools configures tools to withhold, e.g. to run with only collect_structured_data
+func SetDisabledTools(names []string) {
+	disabledToolsMutex.Lock()
+	defer disabledToolsMutex.Unlock()
+	disabledTools = map[string]bool{}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			disabledTools[name] = true
+		}
+	}
+}
+
+func toolDisabled(name string) bool {
+	disabledToolsMutex.RLock()
+	defer disabledToolsMutex.RUnlock()
+	return disabledTools[name]
+}
+
+// RegisterTool adds a tool to the server, replacing any tool already registered under the
+// same name. Calls are applied at most once per tool call ID.
+func (s *MCPServer) RegisterTool(definition Tool, handler ToolHandler) {
+	s.toolsMutex.Lock()
+	defer s.toolsMutex.Unlock()
+	if _, exists := s.tools[definition.Name]; !exists {
+		s.toolOrder = append(s.toolOrder, definition.Name)
+	}
+	s.tools[definition.Name] = &registeredTool{definition: definition, handler: handler}
+}
+
+// lookupTool returns the enabled tool registered under name
+func (s *MCPServer) lookupTool(name string) (*registeredTool, bool) {
+	if toolDisabled(name) {
+		return nil, false
+	}
+	s.toolsMutex.RLock()
+	defer s.toolsMutex.RUnlock()
+	tool, ok := s.tools[name]
+	return tool, ok
+}
+
+// availableToolNames lists the enabled tools in registration order
+func (s *MCPServer) availableToolNames() []string {
+	s.toolsMutex.RLock()
+	defer s.toolsMutex.RUnlock()
+	names := make([]string, 0, len(s.toolOrder))
+	for _, name := range s.toolOrder {
+		if !toolDisabled(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// unknownToolError names the tools that are actually available: those assigned to the
+// session's current phase when it has any, otherwise every enabled tool
+func (s *MCPServer) unknownToolError(name string, sessionID string) error {
+	if phase, tools := phaseToolNames(sessionID); len(tools) > 0 {
+		return fmt.Errorf("CRITICAL: Unknown tool '%s'. Tools available in phase %s: %s", name, phase, strings.Join(tools, ", "))
+	}
+	return fmt.Errorf("CRITICAL: Unknown tool '%s'. Available tools: %s", name, strings.Join(s.availableToolNames(), ", "))
+}
+
+// phaseToolNames returns the session's phase and the active tools its PhaseTool rows assign
+func phaseToolNames(sessionID string) (string, []string) {
+	if sessionID == "" {
+		return "", nil
+	}
+	var session repository.Session
+	if err := repository.DB.Select("phase").First(&session, "id = ?", sessionID).Error; err != nil {
+		return "", nil
+	}
+
+	var names []string
+	repository.DB.Model(&repository.Tool{}).
+		Joins("JOIN phase_tools ON tools.id = phase_tools.tool_id").
+		Where("phase_tools.phase_id = ? AND phase_tools.is_active = ? AND tools.is_active = ?", session.Phase, true, true).
+		Order("tools.name ASC").
+		Pluck("tools.name", &names)
+
+	available := names[:0]
+	for _, name := range names {
+		if !toolDisabled(name) {
+			available = append(available, name)
+		}
+	}
+	return session.Phase, available
+}
+
+// RegisterHandler names a tool implementation that Tool rows can select with handler_func
+func (s *MCPServer) RegisterHandler(name string, handler ToolHandler) {
+	s.toolsMutex.Lock()
+	defer s.toolsMutex.Unlock()
+	s.handlers[name] = handler
+}
+
+// LoadDatabaseTools registers the active rows of the tools table, each dispatching to the
+// handler its handler_func names, e.g. a check-in that collects with its own schema. Rows
+// without a handler_func or naming an unknown one are skipped, as are rows named after a
+// built-in tool, so a row can't silently replace a tool the coach relies on.
+func (s *MCPServer) LoadDatabaseTools(db *gorm.DB) error {
+	var rows []repository.Tool
+	if err := db.Where("is_active = ?", true).Order("name ASC").Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load tools: %w", err)
+	}
+
+	loaded := 0
+	for _, row := range rows {
+		fields := logrus.Fields{
+			"tool":         row.Name,
+			"handler_func": row.HandlerFunc,
+		}
+		if row.HandlerFunc == "" {
+			s.logger.WithFields(fields).Warn("⚠️ Skipping database tool without a handler_func")
+			continue
+		}
+
+		// Built-in tools register their handlers under their own names
+		s.toolsMutex.RLock()
+		handler, ok := s.handlers[row.HandlerFunc]
+		_, builtin := s.handlers[row.Name]
+		s.toolsMutex.RUnlock()
+		if builtin {
+			s.logger.WithFields(fields).Warn("⚠️ Skipping database tool that would override a built-in tool")
+			continue
+		}
+		if !ok {
+			s.logger.WithFields(fields).Warn("⚠️ Skipping database tool with an unknown handler_func")
+			continue
+		}
+
+		schema := map[string]interface{}{"type": "object"}
+		if row.InputSchema != "" {
+			if err := json.Unmarshal([]byte(row.InputSchema), &schema); err != nil {
+				s.logger.WithError(err).WithField("tool", row.Name).Warn("⚠️ Skipping database tool with an invalid input_schema")
+				continue
+			}
+		}
+
+		s.RegisterTool(Tool{Name: row.Name, Description: row.Description, InputSchema: schema}, handler)
+		loaded++
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"tools":  loaded,
+		"rows":   len(rows),
+		"active": s.availableToolNames(),
+	}).Info("🔧 Loaded database tools")
+	return nil
+}
+
+// registerBuiltinTools registers the tools the coach and WebSocket handler call by name,
+// and their handlers under the same names for database tools to reuse
+func (s *MCPServer) registerBuiltinTools() {
+	s.RegisterHandler("collect_structured_data", s.handleCollectStructuredData)
+	s.RegisterHandler("therapy_session_transition", s.handleTransition)
+	s.RegisterHandler("therapy_session_enable_auto_mode", s.handleEnableAutoMode)
+	s.RegisterHandler("record_suds", s.handleRecordSuds)
+
+	s.RegisterTool(Tool{
+		Name:        "collect_structured_data",
+		Description: "Collect and store data as defined by the current phase requirements. Only collect data that has been explicitly provided in the conversation. The required fields and their schemas are defined in the phase_data table for each workflow phase.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The session ID",
+				},
+				"data": map[string]interface{}{
+					"type":        "object",
+					"description": "Key-value pairs of data collected based on phase requirements. Each key should match field names defined in phase_data table. Values must reflect actual user responses from the conversation.",
+				},
+			},
+			"required": []string{"session_id", "data"},
+		},
+	}, s.handleCollectStructuredData)
+
+	s.RegisterTool(Tool{
+		Name:        "therapy_session_transition",
+		Description: "Transition the session to another phase. Use 'next' as target_phase to move to the next phase in sequence.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The session ID",
+				},
+				"target_phase": map[string]interface{}{
+					"type":        "string",
+					"description": "Phase ID to move to, or 'next'",
+				},
+				"reason": map[string]interface{}{
+					"type":        "string",
+					"description": "Why the session is moving on",
+				},
+				"from_phase": map[string]interface{}{
+					"type":        "string",
+					"description": "Only transition if the session is still in this phase",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Validate the transition and report its target without applying it",
+				},
+			},
+			"required": []string{"session_id", "target_phase"},
+		},
+	}, s.handleTransition)
+
+	s.RegisterTool(Tool{
+		Name:        "therapy_session_enable_auto_mode",
+		Description: "Turn auto mode on or off for a session. With auto mode on, collect_structured_data transitions to the next phase once the current phase's requirements are met.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The session ID",
+				},
+				"enabled": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether auto mode is on",
+				},
+			},
+			"required": []string{"session_id", "enabled"},
+		},
+	}, s.handleEnableAutoMode)
+
+	s.RegisterTool(Tool{
+		Name:        "record_suds",
+		Description: "Record the client's SUDS (subjective units of distress, 0-10) rating. Every reading is kept so the session's distress trend can be followed; the latest reading also counts as the current phase's SUDS field.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The session ID",
+				},
+				"value": map[string]interface{}{
+					"type":        "number",
+					"description": "The SUDS rating the client gave, from 0 (no distress) to 10 (worst imaginable)",
+					"minimum":     0,
+					"maximum":     10,
+				},
+			},
+			"required": []string{"session_id", "value"},
+		},
+	}, s.handleRecordSuds)
+}
@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLoadDatabaseToolsSkipsRowsWithoutHandlersAndBuiltinOverrides(t *testing.T) {
+	db := newTestDB(t)
+	for _, row := range []repository.Tool{
+		{ID: "tool-1", Name: "check_in", Description: "Check in with the client", HandlerFunc: "collect_structured_data"},
+		{ID: "tool-2", Name: "note_observation", Description: "No handler named"},
+		{ID: "tool-3", Name: "therapy_session_transition", Description: "Replaced transition", HandlerFunc: "collect_structured_data"},
+		{ID: "tool-4", Name: "summarize", Description: "Unknown handler", HandlerFunc: "summarize_session"},
+	} {
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatalf("failed to create tool: %v", err)
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	server := NewMCPServer(logger, func(event interface{}) {})
+	if err := server.LoadDatabaseTools(db); err != nil {
+		t.Fatalf("failed to load tools: %v", err)
+	}
+
+	if _, ok := server.lookupTool("check_in"); !ok {
+		t.Error("expected check_in to be registered")
+	}
+	for _, name := range []string{"note_observation", "summarize"} {
+		if _, ok := server.lookupTool(name); ok {
+			t.Errorf("expected %s to be skipped", name)
+		}
+	}
+	transition, ok := server.lookupTool("therapy_session_transition")
+	if !ok || transition.definition.Description == "Replaced transition" {
+		t.Errorf("expected the built-in therapy_session_transition to be kept, got %+v", transition)
+	}
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// newlySatisfiedRequirements returns the required fields collected now that weren't before
+// this call, so re-submitting an already-satisfied field reports nothing
+func newlySatisfiedRequirements(requiredFields []repository.PhaseData, before []repository.SessionFieldValue, collected map[string]bool) []string {
+	previously := make(map[string]bool, len(before))
+	for _, field := range before {
+		previously[field.FieldName] = true
+	}
+
+	var satisfied []string
+	for _, field := range requiredFields {
+		if collected[field.Name] && !previously[field.Name] {
+			satisfied = append(satisfied, field.Name)
+		}
+	}
+	return satisfied
+}
+
+// broadcastRequirementsSatisfied emits one requirement_satisfied event per newly met field
+func (s *MCPServer) broadcastRequirementsSatisfied(sessionID string, phase string, fields []string, phaseSatisfied bool) {
+	for _, field := range fields {
+		s.broadcast(map[string]interface{}{
+			"type":            "requirement_satisfied",
+			"session_id":      sessionID,
+			"phase":           phase,
+			"field":           field,
+			"phase_satisfied": phaseSatisfied,
+			"timestamp":       time.Now(),
+		})
+	}
+}
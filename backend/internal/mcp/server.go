@@ -3,9 +3,10 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"therapy-navigation-system/internal/repository"
@@ -17,61 +18,108 @@ import (
 type MCPServer struct {
 	logger    *logrus.Logger
 	broadcast func(event interface{})
+
+	// Registered tools, dispatched by name
+	toolsMutex sync.RWMutex
+	tools      map[string]*registeredTool
+	toolOrder  []string
+	handlers   map[string]ToolHandler // Implementations database tools select by handler_func
 }
 
-// NewMCPServer creates a new MCP server instance
+// NewMCPServer creates a new MCP server instance with the built-in tools registered
 func NewMCPServer(logger *logrus.Logger, broadcast func(event interface{})) *MCPServer {
-	return &MCPServer{
+	s := &MCPServer{
 		logger:    logger,
 		broadcast: broadcast,
+		tools:     make(map[string]*registeredTool),
+		handlers:  make(map[string]ToolHandler),
+	}
+	s.registerBuiltinTools()
+	return s
+}
+
+// addBroadcastListener passes every event the server broadcasts to listener as well
+func (s *MCPServer) addBroadcastListener(listener func(event interface{})) {
+	broadcast := s.broadcast
+	s.broadcast = func(event interface{}) {
+		broadcast(event)
+		listener(event)
 	}
 }
 
-// CallTool executes an MCP tool - only 3 essential tools
-func (s *MCPServer) CallTool(ctx context.Context, toolName string, arguments json.RawMessage) (interface{}, error) {
+// CallTool executes a registered MCP tool. Steps the tool reports are broadcast as
+// mcp_activity progress events and passed to onProgress, which may be nil.
+func (s *MCPServer) CallTool(ctx context.Context, toolName string, arguments json.RawMessage, onProgress ProgressFunc) (interface{}, error) {
 	s.logger.WithFields(logrus.Fields{
 		"tool": toolName,
 		"args": string(arguments),
 	}).Info("MCP tool called")
 
+	// Session and call IDs let the UI route events to the session and its tool message
+	var target struct {
+		SessionID string `json:"session_id"`
+	}
+	json.Unmarshal(arguments, &target)
+	toolCallID := ToolCallIDFromContext(ctx)
+
 	// Broadcast MCP activity event for UI
 	s.broadcast(map[string]interface{}{
-		"type":      "mcp_activity",
-		"tool":      toolName,
-		"timestamp": time.Now(),
-		"status":    "executing",
+		"type":         "mcp_activity",
+		"tool":         toolName,
+		"session_id":   target.SessionID,
+		"tool_call_id": toolCallID,
+		"timestamp":    time.Now(),
+		"status":       "executing",
+		"progress":     0.0,
 	})
 
-	var result interface{}
-	var err error
-
-	// Single universal tool
-	switch toolName {
-	case "collect_structured_data":
-		result, err = s.handleCollectStructuredData(ctx, arguments)
-	// REMOVED: therapy_session_transition is now handled automatically via collect_structured_data
-	// case "therapy_session_transition":
-	//	result, err = s.handleTransition(ctx, arguments)
-	default:
+	ctx = withProgress(ctx, func(progress float64, step string) {
+		s.broadcast(map[string]interface{}{
+			"type":         "mcp_activity",
+			"tool":         toolName,
+			"session_id":   target.SessionID,
+			"tool_call_id": toolCallID,
+			"timestamp":    time.Now(),
+			"status":       "executing",
+			"progress":     progress,
+			"step":         step,
+		})
+		if onProgress != nil {
+			onProgress(progress, step)
+		}
+	})
+
+	tool, ok := s.lookupTool(toolName)
+	if !ok {
 		// HARD ERROR - no silent failures
-		err = fmt.Errorf("CRITICAL: Unknown tool '%s'. Only available tool: collect_structured_data", toolName)
 		s.logger.WithField("tool", toolName).Error("Unknown tool called - failing hard")
-		return nil, err
+		return nil, s.unknownToolError(toolName, target.SessionID)
 	}
 
+	result, err := s.runOnce(ctx, toolName, arguments, func() (interface{}, error) {
+		return tool.handler(ctx, arguments)
+	})
+
 	// Broadcast completion event
 	status := "success"
 	if err != nil {
 		status = "error"
 	}
 
+	var errMessage interface{}
+	if err != nil {
+		errMessage = err.Error()
+	}
 	s.broadcast(map[string]interface{}{
-		"type":      "mcp_activity",
-		"tool":      toolName,
-		"timestamp": time.Now(),
-		"status":    status,
-		"result":    result,
-		"error":     err,
+		"type":         "mcp_activity",
+		"tool":         toolName,
+		"session_id":   target.SessionID,
+		"tool_call_id": toolCallID,
+		"timestamp":    time.Now(),
+		"status":       status,
+		"progress":     1.0,
+		"result":       result,
+		"error":        errMessage,
 	})
 
 	if err != nil {
@@ -94,46 +142,163 @@ type Tool struct {
 	InputSchema map[string]interface{} `json:"inputSchema"`
 }
 
-// GetTools returns the single universal MCP tool
+// GetTools returns the enabled registered tools
 func (s *MCPServer) GetTools() []Tool {
-	return []Tool{
-		{
-			Name:        "collect_structured_data",
-			Description: "Collect and store data as defined by the current phase requirements. Only collect data that has been explicitly provided in the conversation. The required fields and their schemas are defined in the phase_data table for each workflow phase.",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"session_id": map[string]interface{}{
-						"type":        "string",
-						"description": "The session ID",
-					},
-					"data": map[string]interface{}{
-						"type":        "object",
-						"description": "Key-value pairs of data collected based on phase requirements. Each key should match field names defined in phase_data table. Values must reflect actual user responses from the conversation.",
-					},
-				},
-				"required": []string{"session_id", "data"},
-			},
-		},
+	s.toolsMutex.RLock()
+	defer s.toolsMutex.RUnlock()
+	tools := make([]Tool, 0, len(s.toolOrder))
+	for _, name := range s.toolOrder {
+		if !toolDisabled(name) {
+			tools = append(tools, s.tools[name].definition)
+		}
 	}
+	return tools
 }
 
 // handleTransition processes therapy session phase transitions
 func (s *MCPServer) handleTransition(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
-	var args struct {
-		SessionID   string `json:"session_id"`
-		TargetPhase string `json:"target_phase"`
-		Reason      string `json:"reason"`
-	}
-
+	var args transitionArgs
 	if err := json.Unmarshal(arguments, &args); err != nil {
 		return nil, fmt.Errorf("invalid arguments: %w", err)
 	}
 
-	// Get current session
+	plan, response, err := s.planTransition(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if args.DryRun {
+		return dryRunResult(plan, response), nil
+	}
+	if response != nil {
+		return response, nil
+	}
+
+	// In the final phase "next" completes the session instead of transitioning
+	if plan.complete {
+		s.logger.WithField("session_id", args.SessionID).Info("🎉 COMPLETING SESSION - No next phase needed")
+
+		// Use state machine to complete the session
+		reportProgress(ctx, 0.5, "completing session")
+		if err := state.New(args.SessionID).CompleteSession(); err != nil {
+			return nil, fmt.Errorf("failed to complete session: %w", err)
+		}
+
+		// Compare the final state against the client's stated goals
+		goals, err := repository.EvaluateSessionGoals(repository.DB, args.SessionID)
+		if err != nil {
+			s.logger.WithError(err).WithField("session_id", args.SessionID).Warn("Failed to evaluate session goals")
+		}
+
+		// Broadcast session completion
+		s.broadcast(map[string]interface{}{
+			"type": "session_completed",
+			"session_id": args.SessionID,
+			"timestamp": time.Now(),
+			"message": "Session successfully completed!",
+			"goal_progress": goals,
+		})
+
+		return map[string]interface{}{
+			"success": true,
+			"message": "Session completed successfully",
+			"status": "completed",
+			"goal_progress": goals,
+			"timestamp": time.Now(),
+		}, nil
+	}
+
+	targetPhase := plan.targetPhase
+
+	// Store old phase for logging
+	oldPhase := plan.fromPhase
+	reportProgress(ctx, 0.6, "transitioning")
+
+	// Update session phase
+	updates := map[string]interface{}{
+		"phase":            targetPhase,
+		"phase_start_time": time.Now(),
+		"updated_at":       time.Now(),
+	}
+
+	// Conditional on the phase we validated against, so two racing transitions can't both apply
+	result := repository.Scoped(ctx).Model(&repository.Session{}).
+		Where("id = ? AND phase = ?", args.SessionID, oldPhase).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var current repository.Session
+		repository.Scoped(ctx).Select("phase").First(&current, "id = ?", args.SessionID)
+		return alreadyTransitioned(oldPhase, current.Phase), nil
+	}
+
+	// Append to the session's phase timeline
+	if err := repository.RecordPhaseTransition(repository.DB, args.SessionID, oldPhase, targetPhase, time.Now()); err != nil {
+		s.logger.WithError(err).WithField("session_id", args.SessionID).Warn("Failed to record phase history")
+	}
+	s.checkTransitionLoop(args.SessionID, targetPhase)
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":    args.SessionID,
+		"from_phase":    oldPhase,
+		"to_phase":      targetPhase,
+		"reason":        args.Reason,
+	}).Info("✅ Phase transition successful")
+
+	// Broadcast phase transition event for frontend
+	s.broadcast(map[string]interface{}{
+		"type": "phase_transition",
+		"session_id": args.SessionID,
+		"from_phase": oldPhase,
+		"to_phase": targetPhase,
+		"reason": args.Reason,
+		"timestamp": time.Now(),
+	})
+
+	// Broadcast workflow update for UI reactivity, with everything collected so far
+	s.broadcastWorkflowUpdate(args.SessionID, targetPhase)
+
+	return map[string]interface{}{
+		"success":     true,
+		"new_phase":   targetPhase,
+		"message":     fmt.Sprintf("Transitioned to %s", targetPhase),
+		"timestamp":   time.Now(),
+	}, nil
+}
+
+// transitionArgs are the therapy_session_transition arguments
+type transitionArgs struct {
+	SessionID   string `json:"session_id"`
+	TargetPhase string `json:"target_phase"`
+	Reason      string `json:"reason"`
+	FromPhase   string `json:"from_phase,omitempty"` // Only transition if the session is still in this phase
+	DryRun      bool   `json:"dry_run,omitempty"`    // Validate and resolve the target without transitioning
+}
+
+// transitionPlan is a validated transition handleTransition is about to apply
+type transitionPlan struct {
+	sessionID   string
+	fromPhase   string
+	targetPhase string
+	complete    bool                        // The session is in a terminal phase and "next" completes it
+	conditional *repository.PhaseTransition // The conditional transition "next" resolved to, if any
+}
+
+// planTransition runs handleTransition's checks - the from_phase guard, target resolution
+// (conditional transitions, "next", positions, aliases), the transition graph, the runaway
+// hold and the current phase's requirements - without changing anything. A non-nil response
+// is the structured result to return instead of transitioning.
+func (s *MCPServer) planTransition(ctx context.Context, args transitionArgs) (*transitionPlan, map[string]interface{}, error) {
+	// Get current session, in the caller's organization
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", args.SessionID).First(&session).Error; err != nil {
-		return nil, fmt.Errorf("session not found: %w", err)
+	if err := repository.Scoped(ctx).Where("id = ?", args.SessionID).First(&session).Error; err != nil {
+		return nil, nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	// A concurrent or retried call already moved the session on
+	if args.FromPhase != "" && session.Phase != args.FromPhase {
+		return nil, alreadyTransitioned(args.FromPhase, session.Phase), nil
 	}
 
 	// Use state machine for validation
@@ -145,10 +310,41 @@ func (s *MCPServer) handleTransition(ctx context.Context, arguments json.RawMess
 	// Get current phase position
 	var currentPhaseRecord repository.Phase
 	if err := repository.DB.Where("id = ?", session.Phase).First(&currentPhaseRecord).Error; err != nil {
-		return nil, fmt.Errorf("current phase not found: %w", err)
+		return nil, nil, fmt.Errorf("current phase not found: %w", err)
+	}
+
+	// Terminal phases only allow completing the session, never leaving the phase
+	if currentPhaseRecord.IsTerminal && args.TargetPhase != "next" {
+		return nil, nil, fmt.Errorf("phase %s is terminal; the session must be reopened before transitioning to %s", currentPhaseRecord.ID, args.TargetPhase)
+	}
+
+	// Phases whose transitions carry conditions branch on the collected data
+	var conditional *repository.PhaseTransition
+	if args.TargetPhase == "next" && !currentPhaseRecord.IsTerminal {
+		transition, ok, err := stateMachine.ConditionalTransition(session.Phase)
+		if ok && errors.Is(err, state.ErrTransitionConditionNotMet) {
+			return nil, map[string]interface{}{
+				"success":      false,
+				"error":        err.Error(),
+				"instructions": "Collect the data these conditions depend on before attempting transition.",
+			}, nil
+		}
+		if ok && err != nil {
+			return nil, nil, err
+		}
+		conditional = transition
 	}
 
-	if args.TargetPhase == "next" {
+	if conditional != nil {
+		targetPhase = conditional.ToPhaseID
+		s.logger.WithFields(logrus.Fields{
+			"session_id": args.SessionID,
+			"from_phase": session.Phase,
+			"to_phase":   targetPhase,
+			"condition":  conditional.Condition,
+			"priority":   conditional.Priority,
+		}).Info("🔀 Resolved conditional transition")
+	} else if args.TargetPhase == "next" {
 		// Find next phase by position
 		s.logger.WithFields(logrus.Fields{
 			"current_phase": currentPhaseRecord.ID,
@@ -156,34 +352,14 @@ func (s *MCPServer) handleTransition(ctx context.Context, arguments json.RawMess
 			"looking_for_position": currentPhaseRecord.Position + 1,
 		}).Debug("Looking for next phase")
 
-		var nextPhase repository.Phase
-		if err := repository.DB.Where("position = ?", currentPhaseRecord.Position+1).First(&nextPhase).Error; err != nil {
-			// Check if we're in the final phase - if so, complete the session instead of transitioning
-			if currentPhaseRecord.ID == "complete" {
-				s.logger.WithField("session_id", args.SessionID).Info("🎉 COMPLETING SESSION - No next phase needed")
-
-				// Use state machine to complete the session
-				stateMachine := state.New(args.SessionID)
-				if err := stateMachine.CompleteSession(); err != nil {
-					return nil, fmt.Errorf("failed to complete session: %w", err)
-				}
-
-				// Broadcast session completion
-				s.broadcast(map[string]interface{}{
-					"type": "session_completed",
-					"session_id": args.SessionID,
-					"timestamp": time.Now(),
-					"message": "Session successfully completed!",
-				})
-
-				return map[string]interface{}{
-					"success": true,
-					"message": "Session completed successfully",
-					"status": "completed",
-					"timestamp": time.Now(),
-				}, nil
+		// Skip experimental phases the session isn't flagged for
+		nextPhase, err := repository.NextEnabledPhase(repository.DB, &session, currentPhaseRecord.Position)
+		if err != nil || currentPhaseRecord.IsTerminal {
+			// In the final phase "next" completes the session instead of transitioning
+			if currentPhaseRecord.IsTerminal {
+				return &transitionPlan{sessionID: args.SessionID, fromPhase: session.Phase, complete: true}, nil, nil
 			}
-			return nil, fmt.Errorf("no next phase found after position %d", currentPhaseRecord.Position)
+			return nil, nil, fmt.Errorf("no next phase found after position %d", currentPhaseRecord.Position)
 		}
 		targetPhase = nextPhase.ID
 
@@ -196,19 +372,47 @@ func (s *MCPServer) handleTransition(ctx context.Context, arguments json.RawMess
 		if position := parsePosition(args.TargetPhase); position > 0 {
 			var targetPhaseRecord repository.Phase
 			if err := repository.DB.Where("position = ?", position).First(&targetPhaseRecord).Error; err != nil {
-				return nil, fmt.Errorf("no phase found at position %d", position)
+				return nil, nil, fmt.Errorf("no phase found at position %d", position)
 			}
 			targetPhase = targetPhaseRecord.ID
 		}
-		// Otherwise use the target as-is (assume it's a phase ID)
+		// Otherwise use the target as a phase ID, resolving legacy aliases
+		targetPhase = repository.CanonicalPhaseID(targetPhase)
+
+		// Experimental phases are only reachable by flagged sessions
+		var targetPhaseRecord repository.Phase
+		if err := repository.DB.Where("id = ?", targetPhase).First(&targetPhaseRecord).Error; err == nil && !session.FeatureEnabled(targetPhaseRecord.FeatureFlag) {
+			return nil, nil, fmt.Errorf("phase %s requires feature flag %s which is not enabled for this session", targetPhase, targetPhaseRecord.FeatureFlag)
+		}
+	}
+
+	// A session held as a runaway loop only moves on a clinician's transition
+	if session.AutoTransitionsHeld {
+		return nil, map[string]interface{}{
+			"success":               false,
+			"auto_transitions_held": true,
+			"error":                 fmt.Sprintf("transitions are held after %d phase transitions, pending clinician review", session.PhaseTransitionCount),
+			"instructions":          "Do not attempt further transitions. Continue supporting the client in the current phase until a clinician reviews the session.",
+		}, nil
 	}
 
 	// Validate transition
 	if !stateMachine.IsValidTransition(session.Phase, targetPhase) {
-		return nil, fmt.Errorf("invalid transition from %s to %s", session.Phase, targetPhase)
+		return nil, nil, fmt.Errorf("invalid transition from %s to %s", session.Phase, targetPhase)
+	}
+	if err := stateMachine.CheckTransitionCondition(session.Phase, targetPhase); err != nil {
+		if !errors.Is(err, state.ErrTransitionConditionNotMet) {
+			return nil, nil, err
+		}
+		return nil, map[string]interface{}{
+			"success":      false,
+			"error":        err.Error(),
+			"instructions": "Collect the data this transition's condition depends on, or choose another phase.",
+		}, nil
 	}
 
 	// Validate requirements and provide guidance if failed - check CURRENT phase completion
+	reportProgress(ctx, 0.3, "validating requirements")
 	if err := stateMachine.ValidatePhaseRequirements(session.Phase); err != nil {
 		// Get specific guidance for what's missing in CURRENT phase
 		guidance, guidanceErr := stateMachine.GetPhaseGuidance(session.Phase)
@@ -223,7 +427,7 @@ func (s *MCPServer) handleTransition(ctx context.Context, arguments json.RawMess
 		}
 
 		// Return structured response instead of error so AI can process it
-		return map[string]interface{}{
+		return nil, map[string]interface{}{
 			"success": false,
 			"error": fmt.Sprintf("phase requirements not met: %s", err.Error()),
 			"guidance": guidance,
@@ -232,81 +436,40 @@ func (s *MCPServer) handleTransition(ctx context.Context, arguments json.RawMess
 		}, nil
 	}
 
-	// Store old phase for logging
-	oldPhase := session.Phase
+	return &transitionPlan{
+		sessionID:   args.SessionID,
+		fromPhase:   session.Phase,
+		targetPhase: targetPhase,
+		conditional: conditional,
+	}, nil, nil
+}
 
-	// Update session phase
-	updates := map[string]interface{}{
-		"phase":            targetPhase,
-		"phase_start_time": time.Now(),
-		"updated_at":       time.Now(),
+// dryRunResult describes what handleTransition would have done with a plan
+func dryRunResult(plan *transitionPlan, response map[string]interface{}) map[string]interface{} {
+	if response != nil {
+		response["dry_run"] = true
+		return response
 	}
 
-	if err := repository.DB.Model(&session).Updates(updates).Error; err != nil {
-		return nil, fmt.Errorf("failed to update session: %w", err)
+	result := map[string]interface{}{
+		"success":        true,
+		"dry_run":        true,
+		"from_phase":     plan.fromPhase,
+		"would_complete": plan.complete,
+		"timestamp":      time.Now(),
 	}
-
-	s.logger.WithFields(logrus.Fields{
-		"session_id":    args.SessionID,
-		"from_phase":    oldPhase,
-		"to_phase":      targetPhase,
-		"reason":        args.Reason,
-	}).Info("✅ Phase transition successful")
-
-	// Broadcast phase transition event for frontend
-	s.broadcast(map[string]interface{}{
-		"type": "phase_transition",
-		"session_id": args.SessionID,
-		"from_phase": oldPhase,
-		"to_phase": targetPhase,
-		"reason": args.Reason,
-		"timestamp": time.Now(),
-	})
-
-	// Broadcast workflow update for UI reactivity
-	// Get all collected data for this session to include in broadcast
-	var allCollectedData []repository.SessionFieldValue
-	repository.DB.Where("session_id = ?", args.SessionID).Find(&allCollectedData)
-
-	// Get PhaseData records to map names to IDs
-	var phaseDataRecords []repository.PhaseData
-	repository.DB.Where("phase_id = ?", targetPhase).Find(&phaseDataRecords)
-
-	// Create name->ID mapping
-	nameToID := make(map[string]string)
-	for _, pd := range phaseDataRecords {
-		nameToID[pd.Name] = pd.ID
+	if plan.complete {
+		result["message"] = "Session would be completed"
+	} else {
+		result["target_phase"] = plan.targetPhase
+		result["message"] = fmt.Sprintf("Would transition from %s to %s", plan.fromPhase, plan.targetPhase)
 	}
-
-	phaseDataValues := make(map[string]interface{})
-	for _, field := range allCollectedData {
-		// Always use field name as key to match frontend expectations
-		phaseDataValues[field.FieldName] = field.FieldValue
+	if plan.conditional != nil {
+		result["condition"] = plan.conditional.Condition
 	}
-
-	// Get phase details for the broadcast
-	var newPhase repository.Phase
-	repository.DB.Where("id = ?", targetPhase).First(&newPhase)
-
-	s.broadcast(map[string]interface{}{
-		"type": "workflow_update",
-		"current_state": targetPhase,
-		"session_id": args.SessionID,
-		"phase": targetPhase,
-		"phase_description": newPhase.Description,
-		"phase_data_values": phaseDataValues,
-		"timestamp": time.Now(),
-	})
-
-	return map[string]interface{}{
-		"success":     true,
-		"new_phase":   targetPhase,
-		"message":     fmt.Sprintf("Transitioned to %s", targetPhase),
-		"timestamp":   time.Now(),
-	}, nil
+	return result
 }
 
-
 // handleCollectStructuredData stores phase-required data and handles auto-transitions
 func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
 	var args struct {
@@ -318,21 +481,130 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 		return nil, fmt.Errorf("invalid arguments: %w", err)
 	}
 
-	// Get current session and required fields
+	// Get current session (in the caller's organization) and required fields
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", args.SessionID).First(&session).Error; err != nil {
+	if err := repository.Scoped(ctx).Where("id = ?", args.SessionID).First(&session).Error; err != nil {
 		return nil, fmt.Errorf("session not found: %w", err)
 	}
 
 	// Get required fields for current phase
 	var requiredFields []repository.PhaseData
-	repository.DB.Where("phase_id = ? AND required = ?", session.Phase, true).Find(&requiredFields)
+	repository.DB.Where("phase_id = ? AND requirement = ?", session.Phase, repository.PhaseDataRequired).Find(&requiredFields)
+
+	// Status-check branching is checked against the protocol's decision criteria
+	var decision *state.StatusDecision
+	var mismatchedAction string
+	if session.Phase == state.StatusCheckPhase && state.StatusDecisionMode() != state.DecisionModeOff {
+		if proposed, ok := args.Data["next_action"].(string); ok {
+			var pendingSUDS *float64
+			if suds, ok := args.Data["suds_current"].(float64); ok {
+				pendingSUDS = &suds
+			}
+			recommended, err := state.New(args.SessionID).RecommendStatusAction(pendingSUDS)
+			if err != nil {
+				s.logger.WithError(err).WithField("session_id", args.SessionID).Warn("Failed to evaluate status-check decision criteria")
+			}
+			decision = recommended
+			// Ending the session is always the client's call
+			if decision != nil && proposed != decision.Action && proposed != "complete" {
+				mismatchedAction = proposed
+				s.logger.WithFields(logrus.Fields{
+					"session_id":  args.SessionID,
+					"next_action": proposed,
+					"recommended": decision.Action,
+					"criterion":   decision.Criterion,
+					"mode":        state.StatusDecisionMode(),
+				}).Warn("🧭 next_action contradicts the status-check decision criteria")
+				if state.StatusDecisionMode() == state.DecisionModeEnforce {
+					delete(args.Data, "next_action")
+				}
+			}
+		}
+	}
+
+	// Multi-step phases: fields from a later step can't be collected before the earlier
+	// step's fields (data supplied in this same call counts as collected)
+	var phaseFields []repository.PhaseData
+	repository.DB.Where("phase_id = ?", session.Phase).Find(&phaseFields)
+	var previouslyCollected []repository.SessionFieldValue
+	repository.DB.Where("session_id = ?", args.SessionID).Find(&previouslyCollected)
+	available := make(map[string]bool)
+	for _, field := range previouslyCollected {
+		if repository.FieldValuePresent(field) {
+			available[field.FieldName] = true
+		}
+	}
+	for key := range args.Data {
+		available[key] = true
+	}
+	blockedFields := repository.PendingPrerequisites(phaseFields, func(name string) bool { return available[name] })
 
 	// Check what requirements we satisfy (no mapping - use exact field names)
 	requirementsSatisfied := []string{}
 	extraDataStored := []string{}
+	rejectedFields := map[string][]string{}
+
+	// Client-sourced fields need a recent client message that plausibly contains them,
+	// otherwise the model is answering on the client's behalf
+	var clientTexts []string
+	unsupportedFields := []string{}
+	if clientDataGuard.enabled {
+		texts, err := recentClientText(args.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		clientTexts = texts
+	}
+	clientSourced := make(map[string]bool)
+	for _, field := range phaseFields {
+		clientSourced[field.Name] = field.Source == "" || field.Source == "client"
+	}
+
+	reportProgress(ctx, 0.2, "storing data")
+
+	// Values are checked against their field's schema before anything is stored
+	fieldSchemas := schemaFields(phaseFields, args.Data)
+	validationErrors := []map[string]interface{}{}
+	fieldTypes := make(map[string]string)
+	typeAdjustments := make(map[string]string)
 
 	for key, value := range args.Data {
+		if waitingOn, isBlocked := blockedFields[key]; isBlocked {
+			rejectedFields[key] = waitingOn
+			continue
+		}
+
+		// Coerce to the schema's declared type (e.g. integer SUDS) before validating
+		value, fieldType, adjustment := normalizeFieldValue(fieldSchemas[key].DeclaredType(), value)
+
+		if field, ok := fieldSchemas[key]; ok {
+			if err := field.ValidateValue(value); err != nil {
+				validationErrors = append(validationErrors, map[string]interface{}{
+					"field":  key,
+					"value":  value,
+					"error":  err.Error(),
+					"schema": json.RawMessage(field.Schema),
+				})
+				s.logger.WithFields(logrus.Fields{
+					"session_id":    args.SessionID,
+					"current_phase": session.Phase,
+					"field":         key,
+					"value":         value,
+				}).WithError(err).Warn("🚫 Rejected field that fails its schema")
+				continue
+			}
+		}
+		if clientDataGuard.enabled && clientSourced[key] && !hasClientEvidence(value, clientTexts) {
+			unsupportedFields = append(unsupportedFields, key)
+			s.logger.WithFields(logrus.Fields{
+				"session_id":    args.SessionID,
+				"current_phase": session.Phase,
+				"field":         key,
+				"value":         value,
+			}).Warn("🚫 Rejected client-sourced field with no supporting client message")
+			continue
+		}
+
 		// Check if this key matches a required field exactly
 		isRequired := false
 		for _, field := range requiredFields {
@@ -351,35 +623,31 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 		fieldValueBytes, _ := json.Marshal(value)
 		fieldValueStr := string(fieldValueBytes)
 
-		// Detect type
-		fieldType := "string"
-		switch value.(type) {
-		case float64:
-			fieldType = "number"
-		case bool:
-			fieldType = "boolean"
-		case map[string]interface{}, []interface{}:
-			fieldType = "object"
+		fieldTypes[key] = fieldType
+		if adjustment != "" {
+			typeAdjustments[key] = adjustment
 		}
 
-		// Store in SessionFieldValue
+		// Store in SessionFieldValue; concurrent collects of the same field coalesce
 		fieldValueRecord := repository.SessionFieldValue{
 			SessionID:  args.SessionID,
 			PhaseID:    session.Phase,
 			FieldName:  key, // Use original field name
 			FieldValue: fieldValueStr,
 			FieldType:  fieldType,
+			Source:     repository.FieldSourceAI,
+		}
+		if err := repository.UpsertSessionFieldValue(repository.DB, &fieldValueRecord); err != nil {
+			s.logger.WithError(err).WithField("session_id", args.SessionID).Error("Failed to store collected field")
+			continue
 		}
 
-		// Upsert the record
-		repository.DB.Where("session_id = ? AND field_name = ?", args.SessionID, key).
-			Assign(repository.SessionFieldValue{
-				FieldValue: fieldValueStr,
-				FieldType:  fieldType,
-				PhaseID:    session.Phase,
-				UpdatedAt:  time.Now(),
-			}).
-			FirstOrCreate(&fieldValueRecord)
+		// The client's desired outcome becomes a goal evaluated at completion
+		if repository.GoalFields[key] {
+			if _, err := repository.RecordSessionGoal(repository.DB, args.SessionID, fmt.Sprint(value)); err != nil {
+				s.logger.WithError(err).WithField("session_id", args.SessionID).Warn("Failed to record session goal")
+			}
+		}
 	}
 
 	// Check if all requirements are now satisfied by checking ALL collected data
@@ -400,7 +668,13 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 		}
 	}
 
+	// Let the UI check off each requirement this call met for the first time
+	s.broadcastRequirementsSatisfied(args.SessionID, session.Phase,
+		newlySatisfiedRequirements(requiredFields, previouslyCollected, collectedFieldNames),
+		len(missingRequirements) == 0)
+
 	// Use state machine to check if we can transition (includes timing constraints)
+	reportProgress(ctx, 0.5, "validating requirements")
 	stateMachine := state.New(args.SessionID)
 	readyToTransition := stateMachine.ValidatePhaseRequirements(session.Phase) == nil
 
@@ -450,73 +724,34 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 		"ready_to_transition": readyToTransition,
 	}).Info("✅ Structured data collected and requirements checked")
 
-	// Broadcast workflow update so UI refreshes with new data
-	// Get all collected data for this session
-	var allCollectedData []repository.SessionFieldValue
-	repository.DB.Where("session_id = ?", args.SessionID).Find(&allCollectedData)
-
-	// Get PhaseData records to map names to IDs
-	var phaseDataRecords []repository.PhaseData
-	repository.DB.Where("phase_id = ?", session.Phase).Find(&phaseDataRecords)
-
-	// Create name->ID mapping
-	nameToID := make(map[string]string)
-	for _, pd := range phaseDataRecords {
-		nameToID[pd.Name] = pd.ID
-	}
-
-	// Build phase_data_values map using field names as keys (frontend expects names, not IDs)
-	phaseDataValues := make(map[string]interface{})
-	for _, field := range allCollectedData {
-		// Always use field name as key to match frontend expectations
-		phaseDataValues[field.FieldName] = field.FieldValue
+	// Broadcast workflow update so UI refreshes with new data. When consolidating, a
+	// successful auto-transition sends it instead, already carrying this data.
+	if !consolidateBroadcasts {
+		s.broadcastWorkflowUpdate(args.SessionID, session.Phase)
 	}
 
-	// DEBUG: Log exactly what we're broadcasting
-	s.logger.WithFields(logrus.Fields{
-		"session_id": args.SessionID,
-		"phase_data_values_count": len(phaseDataValues),
-		"phase_data_values": phaseDataValues,
-		"all_collected_count": len(allCollectedData),
-	}).Info("🔍 DEBUG: About to broadcast workflow_update with phase data")
-
-	// Broadcast workflow status update
-	s.broadcast(map[string]interface{}{
-		"type": "workflow_update",
-		"current_state": session.Phase,
-		"session_id": args.SessionID,
-		"phase": session.Phase,
-		"phase_data_values": phaseDataValues,
-		"timestamp": time.Now(),
-	})
-
 	// AUTO-TRANSITION: If ready, automatically transition to next phase
 	transitionResult := map[string]interface{}{}
+	workflowPhase := session.Phase // Where the session is once any transition settles
 	s.logger.WithFields(logrus.Fields{
 		"session_id": args.SessionID,
 		"ready_to_transition": readyToTransition,
 		"current_phase": session.Phase,
 	}).Info("🔍 DEBUG: About to check auto-transition condition")
 
-	if readyToTransition {
-		// Check if we're in status_check phase and have a next_action field
-		targetPhase := "next" // default
-
-		if session.Phase == "status_check" {
-			// Look for next_action in collected data to determine branching
-			for _, field := range allCollectedData {
-				if field.FieldName == "next_action" {
-					// The value should be a phase ID directly from the enum
-					// Remove quotes if present (JSON string)
-					targetPhase = strings.Trim(field.FieldValue, "\"")
-					s.logger.WithFields(logrus.Fields{
-						"raw_value": field.FieldValue,
-						"cleaned_value": targetPhase,
-					}).Info("📍 Status check branching based on next_action")
-					break
-				}
-			}
+	if readyToTransition && !session.AutoMode {
+		// Requirements are met, but the session advances only on an explicit transition
+		s.logger.WithFields(logrus.Fields{
+			"session_id":    args.SessionID,
+			"current_phase": session.Phase,
+		}).Info("⏸️ AUTO-TRANSITION SKIPPED: Auto mode is off for this session")
+		transitionResult = map[string]interface{}{
+			"auto_transition_attempted": false,
+			"auto_mode":                 false,
 		}
+	} else if readyToTransition {
+		// status_check branches on next_action; other phases advance to the next one
+		targetPhase := s.autoTransitionTarget(session.Phase, allCollectedForSession)
 
 		s.logger.WithFields(logrus.Fields{
 			"session_id": args.SessionID,
@@ -529,15 +764,17 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 			SessionID   string `json:"session_id"`
 			TargetPhase string `json:"target_phase"`
 			Reason      string `json:"reason"`
+			FromPhase   string `json:"from_phase"`
 		}{
 			SessionID:   args.SessionID,
 			TargetPhase: targetPhase,
 			Reason:      "Auto-transition: All phase requirements satisfied",
+			FromPhase:   session.Phase,
 		}
 		transitionArgsBytes, _ := json.Marshal(transitionArgs)
 
-		// Execute transition
-		result, err := s.handleTransition(ctx, transitionArgsBytes)
+		// Execute transition, reporting its steps as the rest of this call's progress
+		result, err := s.handleTransition(progressRange(ctx, 0.7, 1.0), transitionArgsBytes)
 		if err != nil {
 			s.logger.WithError(err).Error("❌ AUTO-TRANSITION FAILED")
 			transitionResult = map[string]interface{}{
@@ -545,11 +782,35 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 				"auto_transition_success":   false,
 				"auto_transition_error":     err.Error(),
 			}
+		} else if resultMap, _ := result.(map[string]interface{}); resultMap["already_moved"] == true {
+			// A concurrent collect or transition advanced the phase first; the phase-guarded
+			// update in handleTransition kept this one from advancing it a second time
+			s.logger.WithFields(logrus.Fields{
+				"session_id":    args.SessionID,
+				"from_phase":    session.Phase,
+				"current_phase": resultMap["current_phase"],
+			}).Info("⏭️ AUTO-TRANSITION SKIPPED: Already performed by a concurrent call")
+			if current, ok := resultMap["current_phase"].(string); ok {
+				workflowPhase = current
+			}
+			transitionResult = map[string]interface{}{
+				"auto_transition_attempted":         true,
+				"auto_transition_success":           false,
+				"auto_transition_already_performed": true,
+				"current_phase":                     workflowPhase,
+				"transition_result":                 result,
+				"transition_guidance":               fmt.Sprintf("The session already moved from %s to %s in a concurrent call. Do not transition again; continue with %s.", session.Phase, workflowPhase, workflowPhase),
+			}
 		} else {
-			s.logger.WithField("session_id", args.SessionID).Info("✅ AUTO-TRANSITION SUCCESSFUL")
+			succeeded := resultMap["success"] != false
+			if succeeded {
+				s.logger.WithField("session_id", args.SessionID).Info("✅ AUTO-TRANSITION SUCCESSFUL")
+			} else {
+				s.logger.WithField("session_id", args.SessionID).Info("⏸️ AUTO-TRANSITION NOT APPLIED")
+			}
 			transitionResult = map[string]interface{}{
 				"auto_transition_attempted": true,
-				"auto_transition_success":   true,
+				"auto_transition_success":   succeeded,
 				"transition_result":         result,
 			}
 		}
@@ -574,6 +835,10 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 		}).Info("⏸️ AUTO-TRANSITION SKIPPED: Requirements not satisfied")
 	}
 
+	if consolidateBroadcasts && !transitioned(transitionResult) {
+		s.broadcastWorkflowUpdate(args.SessionID, workflowPhase)
+	}
+
 	// Merge results
 	response := map[string]interface{}{
 		"success": true,
@@ -581,8 +846,43 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 		"extra_data_stored": extraDataStored,
 		"missing_requirements": missingRequirements,
 		"ready_to_transition": readyToTransition,
+		"field_types": fieldTypes,
 		"timestamp": time.Now(),
 	}
+	if len(typeAdjustments) > 0 {
+		response["type_adjustments"] = typeAdjustments
+	}
+	if len(rejectedFields) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"session_id":      args.SessionID,
+			"current_phase":   session.Phase,
+			"rejected_fields": rejectedFields,
+		}).Warn("⚠️ Rejected fields from a later step before earlier steps were collected")
+		response["rejected_fields"] = rejectedFields
+		response["instructions"] = "Some fields belong to a later step. Collect the fields they wait on first, then ask for them again."
+	}
+	if len(validationErrors) > 0 {
+		response["validation_errors"] = validationErrors
+		response["validation_guidance"] = "These values weren't stored because they don't match the field's schema. Check the allowed type and range, and collect them again with a valid value."
+	}
+	if len(unsupportedFields) > 0 {
+		response["unsupported_fields"] = unsupportedFields
+		response["guidance"] = "The client hasn't said this yet. Ask the client and WAIT for their answer before collecting these fields - never answer for the client."
+	}
+
+	if decision != nil {
+		response["status_decision"] = decision
+	}
+	if mismatchedAction != "" {
+		response["decision_mismatch"] = mismatchedAction
+		if state.StatusDecisionMode() == state.DecisionModeEnforce {
+			response["decision_guidance"] = fmt.Sprintf("next_action %s was not stored: the decision criteria call for %s (%s). Collect next_action again as %s, or complete if the client wants to end.",
+				mismatchedAction, decision.Action, decision.Reason, decision.Action)
+		} else {
+			response["decision_guidance"] = fmt.Sprintf("next_action %s differs from the recommended %s (%s). Make sure the client explicitly asked for it.",
+				mismatchedAction, decision.Action, decision.Reason)
+		}
+	}
 
 	// Add transition results if any
 	for k, v := range transitionResult {
@@ -592,6 +892,50 @@ func (s *MCPServer) handleCollectStructuredData(ctx context.Context, arguments j
 	return response, nil
 }
 
+// autoTransitionTarget is where an auto-transition from phase goes. Phases with conditional
+// transitions leave it to handleTransition to resolve "next" from the collected data; without
+// them the status check branches to the phase its next_action names, and other phases
+// advance to the next one.
+func (s *MCPServer) autoTransitionTarget(phase string, collected []repository.SessionFieldValue) string {
+	if phase != state.StatusCheckPhase || repository.HasConditionalTransitions(repository.DB, phase) {
+		return "next"
+	}
+	for _, field := range collected {
+		if field.FieldName == "next_action" {
+			// The value should be a phase ID directly from the enum
+			target := repository.FieldValueText(field)
+			s.logger.WithFields(logrus.Fields{
+				"raw_value":     field.FieldValue,
+				"cleaned_value": target,
+			}).Info("📍 Status check branching based on next_action")
+			return target
+		}
+	}
+	return "next"
+}
+
+// transitioned reports whether a collect's auto-transition moved the session to a new phase
+// (and so broadcast its own workflow_update)
+func transitioned(transitionResult map[string]interface{}) bool {
+	result, ok := transitionResult["transition_result"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, moved := result["new_phase"]
+	return moved
+}
+
+// alreadyTransitioned is the no-op result when the session left fromPhase before this transition applied
+func alreadyTransitioned(fromPhase string, currentPhase string) map[string]interface{} {
+	return map[string]interface{}{
+		"success":       false,
+		"already_moved": true,
+		"current_phase": currentPhase,
+		"message":       fmt.Sprintf("Session already transitioned from %s to %s - nothing to do", fromPhase, currentPhase),
+		"timestamp":     time.Now(),
+	}
+}
+
 // parsePosition tries to parse a string as a position number
 func parsePosition(target string) int {
 	if position, err := strconv.Atoi(target); err == nil && position > 0 {
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSUDSField holds the latest reading when the current phase doesn't define a SUDS field
+const defaultSUDSField = "suds_current"
+
+// handleRecordSuds appends a SUDS reading to the session's time series. The reading is also
+// collected into the current phase's SUDS field, so phase requirements, the client data
+// guard and auto-transitions behave as if it came through collect_structured_data. The
+// reading is only appended when that field was stored.
+func (s *MCPServer) handleRecordSuds(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		SessionID string   `json:"session_id"`
+		Value     *float64 `json:"value"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.SessionID == "" || args.Value == nil {
+		return nil, fmt.Errorf("session_id and value are required")
+	}
+	if *args.Value < repository.MinSUDS || *args.Value > repository.MaxSUDS {
+		return nil, fmt.Errorf("value must be between %d and %d, got %v", repository.MinSUDS, repository.MaxSUDS, *args.Value)
+	}
+
+	var session repository.Session
+	if err := repository.Scoped(ctx).Select("id", "phase").First(&session, "id = ?", args.SessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	fieldName := phaseSUDSField(session.Phase)
+
+	collectArgs, _ := json.Marshal(map[string]interface{}{
+		"session_id": args.SessionID,
+		"data":       map[string]interface{}{fieldName: *args.Value},
+	})
+	result, err := s.handleCollectStructuredData(ctx, collectArgs)
+	if err != nil {
+		return nil, err
+	}
+	response, _ := result.(map[string]interface{})
+	if response == nil {
+		response = map[string]interface{}{}
+	}
+	response["suds_field"] = fieldName
+	if !fieldStored(response, fieldName) {
+		response["reading_recorded"] = false
+		return response, nil
+	}
+
+	reading, err := repository.RecordSudsReading(repository.DB, args.SessionID, session.Phase, *args.Value)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"session_id": args.SessionID,
+		"phase":      session.Phase,
+		"field":      fieldName,
+		"value":      reading.Value,
+	}).Info("📉 Recorded SUDS reading")
+
+	response["reading_recorded"] = true
+	response["reading"] = reading
+	return response, nil
+}
+
+// phaseSUDSField returns the phase's SUDS field, preferring a required one
+func phaseSUDSField(phaseID string) string {
+	var fields []repository.PhaseData
+	repository.DB.Where("phase_id = ?", phaseID).Order("name ASC").Find(&fields)
+	name := ""
+	for _, field := range fields {
+		if !strings.Contains(strings.ToLower(field.Name), "suds") {
+			continue
+		}
+		if field.IsRequired() {
+			return field.Name
+		}
+		if name == "" {
+			name = field.Name
+		}
+	}
+	if name == "" {
+		return defaultSUDSField
+	}
+	return name
+}
+
+// fieldStored reports whether a collect response lists the field as stored
+func fieldStored(response map[string]interface{}, fieldName string) bool {
+	for _, key := range []string{"requirements_satisfied", "extra_data_stored"} {
+		stored, _ := response[key].([]string)
+		for _, name := range stored {
+			if name == fieldName {
+				return true
+			}
+		}
+	}
+	return false
+}
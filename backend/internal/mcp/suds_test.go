@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecordSudsAppendsAReadingOnlyWhenTheFieldIsStored(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&repository.SudsReading{}); err != nil {
+		t.Fatalf("failed to migrate SUDS readings: %v", err)
+	}
+	if err := db.Create(&repository.Phase{ID: "status_check", DisplayName: "Status Check", Position: 1}).Error; err != nil {
+		t.Fatalf("failed to create phase: %v", err)
+	}
+	if err := db.Create(&repository.PhaseData{
+		ID:          "status_check_suds_current",
+		PhaseID:     "status_check",
+		Name:        "suds_current",
+		Requirement: repository.PhaseDataRequired,
+		Schema:      `{"type": "integer", "minimum": 0, "maximum": 10}`,
+	}).Error; err != nil {
+		t.Fatalf("failed to create phase data: %v", err)
+	}
+	session := repository.Session{
+		ClientID:       "client-1",
+		TherapistID:    "therapist-1",
+		Status:         repository.SessionStatusActive,
+		Phase:          "status_check",
+		StartTime:      time.Now(),
+		PhaseStartTime: time.Now(),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	// Keep the collect from transitioning out of the status check
+	if err := db.Model(&session).Update("auto_mode", false).Error; err != nil {
+		t.Fatalf("failed to disable auto mode: %v", err)
+	}
+	if err := db.Create(&repository.Message{SessionID: session.ID, Role: "client", Content: "It's about a six now"}).Error; err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	server := NewMCPServer(logger, func(interface{}) {})
+	record := func(value float64) (map[string]interface{}, error) {
+		arguments, _ := json.Marshal(map[string]interface{}{"session_id": session.ID, "value": value})
+		result, err := server.handleRecordSuds(context.Background(), arguments)
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+	readings := func() int64 {
+		var count int64
+		db.Model(&repository.SudsReading{}).Where("session_id = ?", session.ID).Count(&count)
+		return count
+	}
+
+	// Out of range: rejected before anything is collected
+	if _, err := record(11); err == nil {
+		t.Error("record_suds accepted 11")
+	}
+
+	// The client never said three, so the guard rejects the field and no reading is kept
+	response, err := record(3)
+	if err != nil {
+		t.Fatalf("record_suds(3): %v", err)
+	}
+	if response["reading_recorded"] != false || response["suds_field"] != "suds_current" {
+		t.Errorf("record_suds(3) = %v, want the reading not recorded", response)
+	}
+	if got := readings(); got != 0 {
+		t.Errorf("%d readings stored for a rejected field, want 0", got)
+	}
+
+	response, err = record(6)
+	if err != nil {
+		t.Fatalf("record_suds(6): %v", err)
+	}
+	if response["reading_recorded"] != true {
+		t.Errorf("record_suds(6) = %v, want the reading recorded", response)
+	}
+	if got := readings(); got != 1 {
+		t.Errorf("%d readings stored, want 1", got)
+	}
+	var field repository.SessionFieldValue
+	if err := db.First(&field, "session_id = ? AND field_name = ?", session.ID, "suds_current").Error; err != nil || field.FieldValue != "6" {
+		t.Errorf("suds_current = %q (%v), want 6", field.FieldValue, err)
+	}
+}
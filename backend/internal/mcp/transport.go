@@ -9,6 +9,8 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"therapy-navigation-system/internal/repository"
+
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 )
@@ -51,11 +53,40 @@ type MCPTransport struct {
 	sessionInfo   map[string]interface{}
 	mu            sync.RWMutex
 	requestID     atomic.Uint64
+
+	// Connected WebSocket clients, which receive notifications
+	clients   map[*wsClient]struct{}
+	clientsMu sync.Mutex
+}
+
+// wsClient wraps an MCP WebSocket connection with a mutex, since notifications are
+// written from other goroutines than the one answering the client's requests
+type wsClient struct {
+	conn   *websocket.Conn
+	mu     sync.Mutex
+	closed bool // Set once a write fails; guarded by mu
+
+	organizationID string // Organization the connection authenticated in; empty in single-tenant mode
+	sessionID      string // Session the connection watches; empty for all of its organization's
+}
+
+func (c *wsClient) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("websocket connection closed")
+	}
+	if err := c.conn.WriteJSON(v); err != nil {
+		// A failed write leaves the connection unusable; later writes fail fast
+		c.closed = true
+		return err
+	}
+	return nil
 }
 
 // NewMCPTransport creates a new MCP transport handler
 func NewMCPTransport(server *MCPServer, logger *logrus.Logger) *MCPTransport {
-	return &MCPTransport{
+	t := &MCPTransport{
 		server: server,
 		logger: logger,
 		capabilities: map[string]bool{
@@ -66,7 +97,11 @@ func NewMCPTransport(server *MCPServer, logger *logrus.Logger) *MCPTransport {
 			"experimental":  true,
 		},
 		sessionInfo: make(map[string]interface{}),
+		clients:     make(map[*wsClient]struct{}),
 	}
+	// Server events reach the WebSocket clients watching their session
+	server.addBroadcastListener(t.notifyEvent)
+	return t
 }
 
 // HandleRequest processes a JSON-RPC 2.0 request
@@ -161,8 +196,12 @@ func (t *MCPTransport) handleToolsList(ctx context.Context, req JSONRPCRequest)
 // handleToolsCall executes a tool
 func (t *MCPTransport) handleToolsCall(ctx context.Context, req JSONRPCRequest) JSONRPCResponse {
 	var params struct {
-		Name      string          `json:"name"`
-		Arguments json.RawMessage `json:"arguments"`
+		Name       string          `json:"name"`
+		Arguments  json.RawMessage `json:"arguments"`
+		ToolCallID string          `json:"tool_call_id,omitempty"` // Idempotency key for retried calls
+		Meta       struct {
+			ProgressToken interface{} `json:"progressToken,omitempty"`
+		} `json:"_meta"`
 	}
 
 	if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -178,7 +217,23 @@ func (t *MCPTransport) handleToolsCall(ctx context.Context, req JSONRPCRequest)
 	}
 
 	// Execute the tool
-	result, err := t.server.CallTool(ctx, params.Name, params.Arguments)
+	if params.ToolCallID != "" {
+		ctx = WithToolCallID(ctx, params.ToolCallID)
+	}
+	// Clients that send a progress token get notifications/progress as the tool runs, on
+	// their own connection only; plain HTTP calls have nowhere to receive them
+	var onProgress ProgressFunc
+	if client, token := requestingClient(ctx), params.Meta.ProgressToken; client != nil && token != nil {
+		onProgress = func(progress float64, step string) {
+			t.sendNotificationTo(client, "notifications/progress", map[string]interface{}{
+				"progressToken": token,
+				"progress":      progress,
+				"total":         1.0,
+				"message":       step,
+			})
+		}
+	}
+	result, err := t.server.CallTool(ctx, params.Name, params.Arguments, onProgress)
 	if err != nil {
 		return JSONRPCResponse{
 			JSONRPC: "2.0",
@@ -325,6 +380,14 @@ func (t *MCPTransport) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
+	// Requests are handled after the handshake returns; keep its organization for their queries
+	organizationID := repository.OrganizationFromContext(r.Context())
+	connCtx := repository.WithOrganization(context.Background(), organizationID)
+
+	client := &wsClient{conn: conn, organizationID: organizationID, sessionID: r.URL.Query().Get("session_id")}
+	t.addClient(client)
+	defer t.removeClient(client)
+
 	t.logger.Info("MCP WebSocket connection established")
 
 	// Send initialization notification
@@ -337,7 +400,7 @@ func (t *MCPTransport) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 			},
 		},
 	}
-	conn.WriteJSON(initNotification)
+	client.WriteJSON(initNotification)
 
 	// Handle messages
 	for {
@@ -349,12 +412,13 @@ func (t *MCPTransport) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
-		// Handle the request
-		resp := t.HandleRequest(context.Background(), req)
+		// Handle the request in the caller's organization; notifications it produces go
+		// back to this connection
+		resp := t.HandleRequest(withRequestingClient(connCtx, client), req)
 
 		// Send response if not a notification
 		if req.ID != nil {
-			if err := conn.WriteJSON(resp); err != nil {
+			if err := client.WriteJSON(resp); err != nil {
 				t.logger.WithError(err).Error("Failed to send response")
 				break
 			}
@@ -364,19 +428,115 @@ func (t *MCPTransport) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	t.logger.Info("MCP WebSocket connection closed")
 }
 
-// SendNotification sends a JSON-RPC notification to connected clients
-func (t *MCPTransport) SendNotification(method string, params interface{}) {
-	// This would be sent to all connected WebSocket clients
-	// For now, just log it
+// addClient registers a WebSocket connection for notifications
+func (t *MCPTransport) addClient(client *wsClient) {
+	t.clientsMu.Lock()
+	defer t.clientsMu.Unlock()
+	t.clients[client] = struct{}{}
+}
+
+// removeClient unregisters a WebSocket connection
+func (t *MCPTransport) removeClient(client *wsClient) {
+	t.clientsMu.Lock()
+	defer t.clientsMu.Unlock()
+	delete(t.clients, client)
+}
+
+// requestingClientKey holds the WebSocket connection a request arrived on
+type requestingClientKey struct{}
+
+// withRequestingClient attaches the connection a request arrived on
+func withRequestingClient(ctx context.Context, client *wsClient) context.Context {
+	return context.WithValue(ctx, requestingClientKey{}, client)
+}
+
+// requestingClient returns the connection a request arrived on, or nil over HTTP
+func requestingClient(ctx context.Context) *wsClient {
+	client, _ := ctx.Value(requestingClientKey{}).(*wsClient)
+	return client
+}
+
+// sendNotificationTo sends a JSON-RPC notification to one WebSocket client, dropping it
+// if the write fails
+func (t *MCPTransport) sendNotificationTo(client *wsClient, method string, params interface{}) {
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}
+	if err := client.WriteJSON(notification); err != nil {
+		t.logger.WithError(err).WithField("method", method).Warn("Dropping MCP WebSocket client after failed notification")
+		t.removeClient(client)
+		client.conn.Close()
+	}
+}
+
+// notifyEvent forwards a server event about a session to the WebSocket clients allowed to
+// see it as a notifications/message. Events without a session aren't forwarded.
+func (t *MCPTransport) notifyEvent(event interface{}) {
+	message, ok := event.(map[string]interface{})
+	if !ok {
+		return
+	}
+	sessionID, _ := message["session_id"].(string)
+	if sessionID == "" {
+		return
+	}
+	t.SendNotification(sessionID, "notifications/message", map[string]interface{}{
+		"level":  "info",
+		"logger": "therapy-navigation-system",
+		"data":   event,
+	})
+}
+
+// SendNotification sends a JSON-RPC notification about a session to the connected WebSocket
+// clients in the session's organization that watch it (or watch every session).
+// Clients whose write fails are dropped; their read loop ends when the connection closes.
+func (t *MCPTransport) SendNotification(sessionID string, method string, params interface{}) {
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}
+
+	t.clientsMu.Lock()
+	clients := make([]*wsClient, 0, len(t.clients))
+	for client := range t.clients {
+		if client.sessionID == "" || client.sessionID == sessionID {
+			clients = append(clients, client)
+		}
+	}
+	t.clientsMu.Unlock()
+	if len(clients) == 0 {
+		return
+	}
+
+	var session repository.Session
+	if err := repository.Scoped(repository.WithSystemScope(context.Background())).
+		Select("id", "organization_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		t.logger.WithError(err).WithField("session_id", sessionID).Debug("Not sending MCP notification for unknown session")
+		return
+	}
+
+	// Write outside the registry lock so one slow client doesn't block registration
+	sent, failed := 0, 0
+	for _, client := range clients {
+		if client.organizationID != "" && client.organizationID != session.OrganizationID {
+			continue
+		}
+		sent++
+		if err := client.WriteJSON(notification); err != nil {
+			t.logger.WithError(err).WithField("method", method).Warn("Dropping MCP WebSocket client after failed notification")
+			t.removeClient(client)
+			client.conn.Close()
+			failed++
+		}
+	}
+
 	t.logger.WithFields(logrus.Fields{
-		"method": method,
-		"params": params,
-	}).Debug("Sending MCP notification")
-	
-	// TODO: Implement actual WebSocket broadcast to connected clients
-	// notification := map[string]interface{}{
-	//     "jsonrpc": "2.0",
-	//     "method":  method,
-	//     "params":  params,
-	// }
-}
\ No newline at end of file
+		"method":      method,
+		"session_id":  sessionID,
+		"connections": sent,
+		"failed":      failed,
+	}).Debug("Sent MCP notification")
+}
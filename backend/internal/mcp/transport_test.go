@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// dialTransport opens an MCP WebSocket connection and reads its initialized notification
+func dialTransport(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var initialized map[string]interface{}
+	if err := conn.ReadJSON(&initialized); err != nil {
+		t.Fatalf("failed to read initialized notification: %v", err)
+	}
+	return conn
+}
+
+func TestToolProgressGoesOnlyToTheRequestingConnection(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	server := NewMCPServer(logger, func(event interface{}) {})
+	server.RegisterTool(Tool{Name: "report_step"}, func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+		reportProgress(ctx, 0.5, "halfway")
+		return map[string]interface{}{"success": true}, nil
+	})
+
+	transport := NewMCPTransport(server, logger)
+	httpServer := httptest.NewServer(http.HandlerFunc(transport.HandleWebSocket))
+	defer httpServer.Close()
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	caller := dialTransport(t, url)
+	bystander := dialTransport(t, url)
+
+	if err := caller.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "report_step",
+			"arguments": map[string]interface{}{},
+			"_meta":     map[string]interface{}{"progressToken": "call-1"},
+		},
+	}); err != nil {
+		t.Fatalf("failed to send tool call: %v", err)
+	}
+
+	var progress struct {
+		Method string                 `json:"method"`
+		Params map[string]interface{} `json:"params"`
+	}
+	if err := caller.ReadJSON(&progress); err != nil {
+		t.Fatalf("failed to read progress: %v", err)
+	}
+	if progress.Method != "notifications/progress" || progress.Params["progressToken"] != "call-1" {
+		t.Fatalf("expected the caller's progress notification, got %+v", progress)
+	}
+	var response JSONRPCResponse
+	if err := caller.ReadJSON(&response); err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if response.Error != nil {
+		t.Fatalf("tool call failed: %+v", response.Error)
+	}
+
+	bystander.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	var unexpected map[string]interface{}
+	if err := bystander.ReadJSON(&unexpected); err == nil {
+		t.Fatalf("expected no notification on another connection, got %v", unexpected)
+	}
+}
+
+func TestSessionEventsReachOnlyClientsAllowedToSeeThem(t *testing.T) {
+	db := newTestDB(t)
+	session := repository.Session{ID: "11111111-1111-1111-1111-111111111111", OrganizationID: "org-a", Phase: "intake"}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	server := NewMCPServer(logger, func(event interface{}) {})
+	transport := NewMCPTransport(server, logger)
+	// Connections authenticate in the organization their org query parameter names
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := repository.WithOrganization(r.Context(), r.URL.Query().Get("org"))
+		transport.HandleWebSocket(w, r.WithContext(ctx))
+	}))
+	defer httpServer.Close()
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	sameOrg := dialTransport(t, url+"?org=org-a")
+	watching := dialTransport(t, url+"?org=org-a&session_id="+session.ID)
+	otherSession := dialTransport(t, url+"?org=org-a&session_id=22222222-2222-2222-2222-222222222222")
+	otherOrg := dialTransport(t, url+"?org=org-b")
+
+	server.broadcast(map[string]interface{}{"type": "workflow_update", "session_id": session.ID})
+
+	for name, conn := range map[string]*websocket.Conn{"same organization": sameOrg, "watching the session": watching} {
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				Data map[string]interface{} `json:"data"`
+			} `json:"params"`
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if err := conn.ReadJSON(&notification); err != nil {
+			t.Fatalf("client %s got no notification: %v", name, err)
+		}
+		if notification.Method != "notifications/message" || notification.Params.Data["type"] != "workflow_update" {
+			t.Errorf("client %s got %+v", name, notification)
+		}
+	}
+	for name, conn := range map[string]*websocket.Conn{"watching another session": otherSession, "in another organization": otherOrg} {
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		var unexpected map[string]interface{}
+		if err := conn.ReadJSON(&unexpected); err == nil {
+			t.Errorf("client %s got %v", name, unexpected)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// consolidateBroadcasts makes collect_structured_data leave the workflow_update to an
+// auto-transition that follows it, so one collect produces one workflow_update. Off sends
+// the collect's own update as well, as before.
+var consolidateBroadcasts = true
+
+// SetConsolidatedBroadcasts configures whether a collect's auto-transition owns its workflow_update
+func SetConsolidatedBroadcasts(enabled bool) {
+	consolidateBroadcasts = enabled
+}
+
+// broadcastWorkflowUpdate sends the session's phase and every value collected so far,
+// keyed by field name as the frontend expects
+func (s *MCPServer) broadcastWorkflowUpdate(sessionID string, phase string) {
+	var collected []repository.SessionFieldValue
+	repository.DB.Where("session_id = ?", sessionID).Find(&collected)
+
+	phaseDataValues, errs := repository.ParsedFieldValues(collected)
+	for _, err := range errs {
+		s.logger.WithError(err).WithField("session_id", sessionID).Warn("Skipping undecodable field value")
+	}
+
+	var phaseRecord repository.Phase
+	repository.DB.Select("description").Where("id = ?", phase).First(&phaseRecord)
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":              sessionID,
+		"phase":                   phase,
+		"phase_data_values_count": len(phaseDataValues),
+	}).Debug("Broadcasting workflow_update")
+
+	s.broadcast(map[string]interface{}{
+		"type":              "workflow_update",
+		"current_state":     phase,
+		"session_id":        sessionID,
+		"phase":             phase,
+		"phase_description": phaseRecord.Description,
+		"phase_data_values": phaseDataValues,
+		"timestamp":         time.Now(),
+	})
+}
+
+// checkTransitionLoop holds further coach transitions once the session exceeds the
+// transition threshold, and tells the UI so a clinician can review it
+func (s *MCPServer) checkTransitionLoop(sessionID string, phase string) {
+	held, count, err := repository.CheckTransitionLoop(repository.DB, sessionID)
+	if err != nil {
+		s.logger.WithError(err).WithField("session_id", sessionID).Warn("Failed to check for a transition loop")
+		return
+	}
+	if !held {
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"session_id":             sessionID,
+		"phase":                  phase,
+		"phase_transition_count": count,
+		"threshold":              repository.MaxPhaseTransitions(),
+	}).Warn("🔁 TRANSITION LOOP DETECTED: Holding coach transitions pending review")
+
+	s.broadcast(map[string]interface{}{
+		"type":                   "transition_loop_detected",
+		"session_id":             sessionID,
+		"phase":                  phase,
+		"phase_transition_count": count,
+		"threshold":              repository.MaxPhaseTransitions(),
+		"timestamp":              time.Now(),
+	})
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestDB installs a throwaway SQLite database as repository.DB, restoring the previous
+// one when the test ends
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&repository.Session{},
+		&repository.Message{},
+		&repository.Phase{},
+		&repository.PhaseData{},
+		&repository.PhaseTransition{},
+		&repository.PhaseConstraint{},
+		&repository.SessionFieldValue{},
+		&repository.Tool{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	// Collected values are upserted on the index migration 023 adds
+	if err := db.Exec("CREATE UNIQUE INDEX idx_session_field_values_unique ON session_field_values (session_id, field_name)").Error; err != nil {
+		t.Fatalf("failed to add the unique index: %v", err)
+	}
+
+	previous := repository.DB
+	repository.DB = db
+	t.Cleanup(func() {
+		repository.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// broadcastRecorder counts the events an MCPServer broadcasts by type
+type broadcastRecorder struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (r *broadcastRecorder) broadcast(event interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if message, ok := event.(map[string]interface{}); ok {
+		eventType, _ := message["type"].(string)
+		r.counts[eventType]++
+	}
+}
+
+func (r *broadcastRecorder) count(eventType string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[eventType]
+}
+
+func TestCollectWithAutoTransitionBroadcastsOneWorkflowUpdate(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, phase := range []repository.Phase{
+		{ID: "intake", DisplayName: "Intake", Position: 1},
+		{ID: "grounding", DisplayName: "Grounding", Position: 2},
+	} {
+		if err := db.Create(&phase).Error; err != nil {
+			t.Fatalf("failed to create phase: %v", err)
+		}
+	}
+	if err := db.Create(&repository.PhaseData{
+		ID:          "intake_issue",
+		PhaseID:     "intake",
+		Name:        "issue",
+		Requirement: repository.PhaseDataRequired,
+		Source:      "coach",
+	}).Error; err != nil {
+		t.Fatalf("failed to create phase data: %v", err)
+	}
+	if err := db.Create(&repository.PhaseTransition{ID: "intake_grounding", FromPhaseID: "intake", ToPhaseID: "grounding"}).Error; err != nil {
+		t.Fatalf("failed to create transition: %v", err)
+	}
+	session := repository.Session{
+		ClientID:       "client-1",
+		TherapistID:    "therapist-1",
+		Status:         repository.SessionStatusActive,
+		Phase:          "intake",
+		AutoMode:       true,
+		StartTime:      time.Now(),
+		PhaseStartTime: time.Now(),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	// One exchange meets the phase's default minimum turns
+	for _, role := range []string{"patient", "coach"} {
+		if err := db.Create(&repository.Message{SessionID: session.ID, Role: role, Content: "hello"}).Error; err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	recorder := &broadcastRecorder{counts: make(map[string]int)}
+	server := NewMCPServer(logger, recorder.broadcast)
+
+	arguments, _ := json.Marshal(map[string]interface{}{
+		"session_id": session.ID,
+		"data":       map[string]interface{}{"issue": "work stress"},
+	})
+	result, err := server.handleCollectStructuredData(context.Background(), arguments)
+	if err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+	if response := result.(map[string]interface{}); response["auto_transition_success"] != true {
+		t.Fatalf("expected the collect to auto-transition, got %v", response)
+	}
+
+	if got := recorder.count("phase_transition"); got != 1 {
+		t.Errorf("expected 1 phase_transition broadcast, got %d", got)
+	}
+	if got := recorder.count("workflow_update"); got != 1 {
+		t.Errorf("expected 1 workflow_update broadcast, got %d", got)
+	}
+}
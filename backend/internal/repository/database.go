@@ -1,10 +1,16 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"time"
+
+	"therapy-navigation-system/internal/config"
 	"therapy-navigation-system/internal/logger"
 
+	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -21,16 +27,26 @@ type Database struct {
 // GlobalDB provides a Database wrapper for the global DB connection
 var GlobalDB *Database
 
+// tenantMode controls organization scoping ("multi" enforces it)
+var tenantMode = "single"
+
+// queryTimeout bounds hot-path queries (set from config in InitDatabase)
+var queryTimeout = 5 * time.Second
+
 // InitDatabase initializes the database connection and runs migrations
-func InitDatabase() error {
+func InitDatabase(cfg *config.Config) error {
 	var db *gorm.DB
 	var err error
 
+	if cfg.DBQueryTimeout > 0 {
+		queryTimeout = cfg.DBQueryTimeout
+	}
+
 	// Check if we're in production (Cloud SQL) or development (SQLite)
 	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
 		// Production: Use PostgreSQL via DATABASE_URL
 		logger.AppLogger.Info("Connecting to PostgreSQL database")
-		db, err = gorm.Open(postgres.Open(databaseURL), &gorm.Config{
+		db, err = gorm.Open(postgres.Open(withStatementTimeout(databaseURL, cfg.DBQueryTimeout)), &gorm.Config{
 			Logger: logger.NewGormLogger(),
 		})
 	} else {
@@ -45,6 +61,22 @@ func InitDatabase() error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Configure connection pool so concurrent sessions can't exhaust connections
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to access database pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+
+	logger.AppLogger.WithFields(logrus.Fields{
+		"max_open_conns":    cfg.DBMaxOpenConns,
+		"max_idle_conns":    cfg.DBMaxIdleConns,
+		"conn_max_lifetime": cfg.DBConnMaxLifetime.String(),
+		"query_timeout":     queryTimeout.String(),
+	}).Info("Database connection pool configured")
+
 	// Set global database instance (singleton)
 	DB = db
 
@@ -57,19 +89,32 @@ func InitDatabase() error {
 		&Client{},
 		&Therapist{},
 		&Session{},
+		&SessionStatusChange{},
+		&SessionPauseEvent{},
+		&ExtractionJob{},
 		&Message{},
+		&Attachment{},
+		&SessionTemplate{},
+		&SessionGoal{},
+		&SessionParticipant{},
 		// Phase system (database-driven)
 		&Phase{},
 		&PhaseData{},
 		&PhaseConstraint{},
 		&PhaseTransition{},
 		&SessionFieldValue{},
+		&SudsReading{},
+		&SessionTokenUsage{},
 		// Tool system
 		&Tool{},
 		&PhaseTool{},
 		// Content system
 		&Prompt{},
 		&PromptAddendum{},
+		// Tool call idempotency
+		&ToolCallRecord{},
+		// Intake scoring
+		&IntakeRubricField{},
 		// State tracking
 		&SessionState{},
 		&SessionPhaseState{},
@@ -77,15 +122,121 @@ func InitDatabase() error {
 		return fmt.Errorf("auto-migration failed: %w", err)
 	}
 
+	// Prompt logs and the other monitoring tables the monitoring endpoints query
+	if err := AutoMigrateMonitoring(db); err != nil {
+		return fmt.Errorf("monitoring auto-migration failed: %w", err)
+	}
+
 	// Run migrations to populate the database
+	phaseIDValidation = cfg.PhaseIDValidation
+	SetPhaseAliases(cfg.PhaseAliases)
+	SetSessionStatusMode(cfg.SessionStatusEnforcement)
+	SetMigrationOrdering(cfg.MigrationOrdering)
+	SetFieldValueDuplicates(cfg.FieldValueDuplicates)
+	SetFieldValueDecoding(cfg.FieldValueDecoding)
+	SetMaxPhaseTransitions(cfg.MaxPhaseTransitions)
+	SetParticipantRoles(cfg.SessionParticipantRoles)
+	SetPromptDataCheck(cfg.PhasePromptDataCheck, cfg.PhasePromptDataIgnore)
+	SetPromptCoverageCheck(cfg.PhasePromptCoverageCheck)
+	SetTokenPrices(cfg.GeminiTokenPrices)
 	if err := RunMigrations(db); err != nil {
 		return fmt.Errorf("running migrations: %w", err)
 	}
 
+	// Rows written before phase ID validation may point at phases that don't exist
+	if problems, err := CheckPhaseReferences(db); err != nil {
+		logger.AppLogger.WithError(err).Warn("Failed to check phase references")
+	} else if len(problems) > 0 {
+		logger.AppLogger.WithField("problems", problems).Warn("⚠️ Found references to unknown phase IDs")
+	}
+
+	// A phase prompt is found by exact workflow_phase match, so a phase without one
+	// silently runs on the system prompt alone
+	if promptCoverageCheckMode != PromptDataCheckOff {
+		uncovered, err := PhasesWithoutPrompts(db)
+		if err != nil {
+			logger.AppLogger.WithError(err).Warn("Failed to check phase prompt coverage")
+		}
+		for _, phaseID := range uncovered {
+			logger.AppLogger.WithField("phase", phaseID).Error("🚨 Phase has no active prompt - sessions in it get only the system prompt")
+		}
+		if len(uncovered) > 0 && promptCoverageCheckMode == PromptDataCheckStrict {
+			return fmt.Errorf("%d non-terminal phases have no active prompt: %v", len(uncovered), uncovered)
+		}
+	}
+
+	// Phase prompts drift from the fields their PhaseData requires
+	if promptDataCheckMode != PromptDataCheckOff {
+		misaligned, err := CheckPhasePromptAlignment(db)
+		if err != nil {
+			logger.AppLogger.WithError(err).Warn("Failed to check phase prompts against phase data")
+		}
+		for _, alignment := range misaligned {
+			logger.AppLogger.WithFields(logrus.Fields{
+				"phase":      alignment.PhaseID,
+				"unmodeled":  alignment.Unmodeled,
+				"unprompted": alignment.Unprompted,
+				"no_prompt":  alignment.NoPrompt,
+			}).Warn("⚠️ Phase prompt and phase data disagree")
+		}
+		if len(misaligned) > 0 && promptDataCheckMode == PromptDataCheckStrict {
+			return fmt.Errorf("%d phases have prompts that disagree with their phase data", len(misaligned))
+		}
+	}
+
+	// Scope tenant models to the request's organization; the global handle is
+	// internal-only so background workers keep working unscoped
+	tenantMode = cfg.TenantMode
+	if tenantMode == "multi" {
+		if err := registerTenantCallbacks(db); err != nil {
+			return err
+		}
+		DB = db.WithContext(WithSystemScope(context.Background()))
+		logger.AppLogger.Info("Multi-tenant organization scoping enabled")
+	}
+
+	// Verify hot query paths use the composite indexes (debug only)
+	if logger.AppLogger.IsLevelEnabled(logrus.DebugLevel) {
+		if plans, err := ExplainHotPaths(db); err != nil {
+			logger.AppLogger.WithError(err).Warn("Failed to explain hot query paths")
+		} else {
+			logger.AppLogger.WithField("plans", plans).Debug("Hot query path plans")
+		}
+	}
+
 	return nil
 }
 
 // Connection returns the underlying gorm.DB connection
 func (db *Database) Connection() *gorm.DB {
 	return db.conn
-}
\ No newline at end of file
+}
+
+// MultiTenant reports whether organization scoping is enforced
+func MultiTenant() bool {
+	return tenantMode == "multi"
+}
+
+// WithQueryTimeout returns a DB handle whose queries are cancelled after the configured timeout
+func WithQueryTimeout() (*gorm.DB, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(DB.Statement.Context, queryTimeout)
+	return DB.WithContext(ctx), cancel
+}
+
+// withStatementTimeout adds a server-side statement_timeout to a PostgreSQL URL
+func withStatementTimeout(databaseURL string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return databaseURL
+	}
+	u, err := url.Parse(databaseURL)
+	if err != nil || u.Scheme == "" {
+		// Key/value DSNs are left untouched
+		return databaseURL
+	}
+	q := u.Query()
+	if q.Get("statement_timeout") == "" {
+		q.Set("statement_timeout", fmt.Sprintf("%d", timeout.Milliseconds()))
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// IntakeFieldCompletion is the completion state of one rubric field for a session
+type IntakeFieldCompletion struct {
+	FieldName string  `json:"field_name"`
+	Label     string  `json:"label"`
+	Weight    float64 `json:"weight"`
+	Required  bool    `json:"required"`
+	Collected bool    `json:"collected"`
+}
+
+// IntakeCompletion scores how complete a session's intake is against the rubric
+type IntakeCompletion struct {
+	SessionID       string                  `json:"session_id"`
+	Percentage      float64                 `json:"percentage"` // Weighted share of the rubric collected (0-100)
+	RequiredMissing []string                `json:"required_missing"`
+	Complete        bool                    `json:"complete"` // All required fields collected
+	Fields          []IntakeFieldCompletion `json:"fields"`
+}
+
+// ComputeIntakeCompletion scores a session's collected fields against the intake rubric
+func ComputeIntakeCompletion(db *gorm.DB, sessionID string) (*IntakeCompletion, error) {
+	var rubric []IntakeRubricField
+	if err := db.Order("weight DESC, field_name ASC").Find(&rubric).Error; err != nil {
+		return nil, fmt.Errorf("failed to load intake rubric: %w", err)
+	}
+
+	var values []SessionFieldValue
+	if err := db.Where("session_id = ?", sessionID).Find(&values).Error; err != nil {
+		return nil, fmt.Errorf("failed to load session fields: %w", err)
+	}
+	collected := make(map[string]bool, len(values))
+	for _, value := range values {
+		collected[value.FieldName] = FieldValuePresent(value)
+	}
+
+	completion := &IntakeCompletion{
+		SessionID:       sessionID,
+		RequiredMissing: []string{},
+		Fields:          make([]IntakeFieldCompletion, 0, len(rubric)),
+	}
+	var totalWeight, collectedWeight float64
+	for _, field := range rubric {
+		state := IntakeFieldCompletion{
+			FieldName: field.FieldName,
+			Label:     field.Label,
+			Weight:    field.Weight,
+			Required:  field.Required,
+			Collected: collected[field.FieldName],
+		}
+		totalWeight += field.Weight
+		if state.Collected {
+			collectedWeight += field.Weight
+		} else if field.Required {
+			completion.RequiredMissing = append(completion.RequiredMissing, field.FieldName)
+		}
+		completion.Fields = append(completion.Fields, state)
+	}
+
+	if totalWeight > 0 {
+		completion.Percentage = collectedWeight / totalWeight * 100
+	}
+	completion.Complete = len(completion.RequiredMissing) == 0
+	return completion, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"fmt"
 	"time"
 	"gorm.io/gorm"
 )
@@ -117,13 +118,16 @@ func migrate002Phases(db *gorm.DB) error {
 			RecommendedDurationSeconds: 120, // 2 minutes
 			Icon:                       "CheckCircle2",
 			Color:                      "#10b981", // Bright green
+			IsTerminal:                 true,
 		},
 	}
 
 	for _, phase := range phases {
 		phase.CreatedAt = time.Now()
 		phase.UpdatedAt = time.Now()
-		db.FirstOrCreate(&phase, Phase{ID: phase.ID})
+		if err := db.FirstOrCreate(&phase, Phase{ID: phase.ID}).Error; err != nil {
+			return fmt.Errorf("failed to seed phase %s: %w", phase.ID, err)
+		}
 	}
 
 	return nil
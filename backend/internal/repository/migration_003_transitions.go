@@ -29,6 +29,14 @@ func migrate003PhaseTransitions(db *gorm.DB) error {
 		{FromPhaseID: "positive_installation", ToPhaseID: "complete"},
 	}
 
+	var phaseIDs []string
+	for _, trans := range transitions {
+		phaseIDs = append(phaseIDs, trans.FromPhaseID, trans.ToPhaseID)
+	}
+	if err := requireSeededPhases(db, phaseIDs); err != nil {
+		return err
+	}
+
 	for _, trans := range transitions {
 		// Create a copy to avoid modifying the loop variable
 		transition := PhaseTransition{
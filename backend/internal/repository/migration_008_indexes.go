@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// hotPathIndexes are composite indexes backing the queries run on every turn
+var hotPathIndexes = []struct {
+	Name    string
+	Model   interface{}
+	Columns string
+}{
+	// Field upserts and isFieldPopulated: WHERE session_id = ? AND field_name = ?
+	// (replaced by the unique idx_session_field_values_unique in migration 023)
+	{Name: "idx_session_field_values_session_field", Model: &SessionFieldValue{}, Columns: "session_id, field_name"},
+	// Working memory and turn counting: WHERE session_id = ? ORDER BY created_at
+	{Name: "idx_messages_session_created", Model: &Message{}, Columns: "session_id, created_at"},
+	// Requirement checks: WHERE phase_id = ? AND requirement = ?
+	{Name: "idx_phase_data_phase_requirement", Model: &PhaseData{}, Columns: "phase_id, requirement"},
+	// Transition lookups: WHERE from_phase_id = ? AND to_phase_id = ?
+	{Name: "idx_phase_transitions_from_to", Model: &PhaseTransition{}, Columns: "from_phase_id, to_phase_id"},
+}
+
+// tableName resolves the GORM table name for a model
+func tableName(db *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
+
+// migrate008HotPathIndexes creates composite indexes for the hot query paths
+func migrate008HotPathIndexes(db *gorm.DB) error {
+	for _, idx := range hotPathIndexes {
+		table, err := tableName(db, idx.Model)
+		if err != nil {
+			return fmt.Errorf("failed to resolve table for index %s: %w", idx.Name, err)
+		}
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", idx.Name, table, idx.Columns)
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create index %s: %w", idx.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ExplainHotPaths returns the query plan for each hot path so index usage can be verified
+func ExplainHotPaths(db *gorm.DB) (map[string][]string, error) {
+	explain := "EXPLAIN QUERY PLAN "
+	if db.Dialector.Name() == "postgres" {
+		explain = "EXPLAIN "
+	}
+
+	queries := map[string]string{
+		"session_field_values": "SELECT * FROM session_field_values WHERE session_id = 'x' AND field_name = 'y'",
+		"messages":             "SELECT * FROM messages WHERE session_id = 'x' ORDER BY created_at DESC LIMIT 30",
+		"phase_data":           "SELECT * FROM phase_data WHERE phase_id = 'x' AND requirement = 'required'",
+		"phase_transitions":    "SELECT * FROM phase_transitions WHERE from_phase_id = 'x' AND to_phase_id = 'y'",
+	}
+
+	plans := make(map[string][]string)
+	for name, query := range queries {
+		rows, err := db.Raw(explain + query).Rows()
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain %s: %w", name, err)
+		}
+
+		columns, _ := rows.Columns()
+		for rows.Next() {
+			values := make([]interface{}, len(columns))
+			pointers := make([]interface{}, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan plan for %s: %w", name, err)
+			}
+			// The plan text is the last column for both SQLite and PostgreSQL
+			detail := values[len(values)-1]
+			if b, ok := detail.([]byte); ok {
+				detail = string(b)
+			}
+			plans[name] = append(plans[name], fmt.Sprintf("%v", detail))
+		}
+		rows.Close()
+	}
+
+	return plans, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHotPathsUseTheirIndexes(t *testing.T) {
+	db := newTestDB(t,
+		&Client{}, &Therapist{}, &Session{}, &Message{},
+		&Phase{}, &PhaseData{}, &PhaseConstraint{}, &PhaseTransition{}, &SessionFieldValue{},
+		&Tool{}, &PhaseTool{}, &Prompt{}, &PromptAddendum{}, &ToolCallRecord{}, &IntakeRubricField{},
+	)
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("migrations failed: %v", err)
+	}
+
+	plans, err := ExplainHotPaths(db)
+	if err != nil {
+		t.Fatalf("ExplainHotPaths: %v", err)
+	}
+	for query, index := range map[string]string{
+		// Migration 023 replaced the session/field index with a unique one on the same columns
+		"session_field_values": "idx_session_field_values_unique",
+		"messages":             "idx_messages_session_created",
+		"phase_data":           "idx_phase_data_phase_requirement",
+		"phase_transitions":    "idx_phase_transitions_from_to",
+	} {
+		plan := strings.Join(plans[query], "\n")
+		if !strings.Contains(plan, index) {
+			t.Errorf("%s query plan doesn't use %s:\n%s", query, index, plan)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate009TerminalPhases marks the complete phase as terminal for databases seeded before IsTerminal existed
+func migrate009TerminalPhases(db *gorm.DB) error {
+	return db.Model(&Phase{}).Where("id = ?", "complete").Update("is_terminal", true).Error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate010ExpectsData flags every phase that currently has phase_data as expecting data,
+// so a phase whose requirements later go missing is reported as misconfigured
+func migrate010ExpectsData(db *gorm.DB) error {
+	return db.Model(&Phase{}).
+		Where("id IN (?)", db.Model(&PhaseData{}).Distinct("phase_id")).
+		Update("expects_data", true).Error
+}
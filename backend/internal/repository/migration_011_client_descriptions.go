@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate011ClientDescriptions seeds client-facing phase descriptions for the "what's next" preview
+func migrate011ClientDescriptions(db *gorm.DB) error {
+	descriptions := map[string]string{
+		"pre_session":           "We'll take a moment to settle in and get comfortable.",
+		"issue_decision":        "Next we'll choose what you'd like to focus on today.",
+		"information_gathering": "Next we'll talk a little more about what you chose to focus on.",
+		"body_scan":             "Next we'll gently notice where you feel this in your body.",
+		"eye_position":          "Next we'll find a spot for your eyes to rest that feels connected to this.",
+		"focused_mindfulness":   "Next we'll spend a few minutes just noticing whatever comes up.",
+		"status_check":          "Next we'll pause and check in on how you're feeling.",
+		"squeeze_hug":           "Next we'll try a simple, calming self-hug exercise.",
+		"positive_installation": "Next we'll focus on a positive thought or resource to take with you.",
+		"complete":              "Next we'll wrap up and reflect on today's session.",
+	}
+
+	for phaseID, description := range descriptions {
+		// Don't overwrite descriptions edited in the Workflow Studio
+		if err := db.Model(&Phase{}).
+			Where("id = ? AND (client_description IS NULL OR client_description = '')", phaseID).
+			Update("client_description", description).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate012PhaseDataSources marks fields the coach records from observation rather than
+// from something the client said, so they skip the client-evidence check on collection
+func migrate012PhaseDataSources(db *gorm.DB) error {
+	coachObserved := []string{
+		"eye_position_brainspot_x",
+		"eye_position_brainspot_y",
+		"eye_position_spot_type",
+		"focused_mindfulness_processing_time",
+		"status_check_next_action",
+		"squeeze_hug_bilateral_completed",
+		"complete_session_notes",
+	}
+
+	return db.Model(&PhaseData{}).
+		Where("id IN ?", coachObserved).
+		Update("source", "coach").Error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate013IntakeRubric seeds the default intake rubric: what the session needs to know
+// about the issue before processing starts, weighted by clinical importance
+func migrate013IntakeRubric(db *gorm.DB) error {
+	rubric := []IntakeRubricField{
+		{FieldName: "consent_given", Label: "Consent", Weight: 3, Required: true},
+		{FieldName: "selected_issue", Label: "Presenting issue", Weight: 3, Required: true},
+		{FieldName: "issue_intensity", Label: "Initial intensity", Weight: 2, Required: true},
+		{FieldName: "suds_level", Label: "SUDS baseline", Weight: 2, Required: true},
+		{FieldName: "history", Label: "Issue history", Weight: 1},
+		{FieldName: "negative_cognition", Label: "Negative cognition", Weight: 1},
+		{FieldName: "activation_present", Label: "Activation present", Weight: 0.5},
+	}
+
+	for _, field := range rubric {
+		if err := db.FirstOrCreate(&field, IntakeRubricField{FieldName: field.FieldName}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
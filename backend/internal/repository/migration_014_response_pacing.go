@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate014ResponsePacing seeds per-phase response pacing: conversational rapport
+// phases stay brisk, processing phases give the client more room between replies
+func migrate014ResponsePacing(db *gorm.DB) error {
+	delays := map[string]int{
+		"pre_session":           800,
+		"issue_decision":        1000,
+		"information_gathering": 1200,
+		"body_scan":             2000,
+		"eye_position":          2000,
+		"focused_mindfulness":   3000,
+		"status_check":          1500,
+		"squeeze_hug":           2500,
+		"positive_installation": 2000,
+		"complete":              1200,
+	}
+
+	for phaseID, delay := range delays {
+		// Don't overwrite pacing tuned in the Workflow Studio
+		if err := db.Model(&Phase{}).
+			Where("id = ? AND (min_response_delay_ms IS NULL OR min_response_delay_ms = 0)", phaseID).
+			Update("min_response_delay_ms", delay).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
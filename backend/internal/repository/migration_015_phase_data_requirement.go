@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// migrate015PhaseDataRequirement folds the redundant required/optional flags into the
+// single requirement column. Rows flagged both required and optional stay required;
+// rows flagged neither become recommended.
+func migrate015PhaseDataRequirement(db *gorm.DB) error {
+	table, err := tableName(db, &PhaseData{})
+	if err != nil {
+		return err
+	}
+
+	migrator := db.Migrator()
+	hasRequired := migrator.HasColumn(table, "required")
+	hasOptional := migrator.HasColumn(table, "optional")
+
+	switch {
+	case hasRequired && hasOptional:
+		err = db.Exec(fmt.Sprintf(`UPDATE %s SET requirement = CASE
+			WHEN required THEN 'required'
+			WHEN optional THEN 'optional'
+			ELSE 'recommended' END`, table)).Error
+	case hasRequired:
+		err = db.Exec(fmt.Sprintf(`UPDATE %s SET requirement = CASE
+			WHEN required THEN 'required'
+			ELSE 'optional' END`, table)).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reconcile phase data requirements: %w", err)
+	}
+
+	// The old index covers the column being dropped
+	if err := db.Exec("DROP INDEX IF EXISTS idx_phase_data_phase_required").Error; err != nil {
+		return fmt.Errorf("failed to drop index idx_phase_data_phase_required: %w", err)
+	}
+	for _, column := range []string{"required", "optional"} {
+		if !migrator.HasColumn(table, column) {
+			continue
+		}
+		if err := migrator.DropColumn(table, column); err != nil {
+			return fmt.Errorf("failed to drop phase_data.%s: %w", column, err)
+		}
+	}
+
+	stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_phase_data_phase_requirement ON %s (phase_id, requirement)", table)
+	return db.Exec(stmt).Error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// migrate016SingleActivePrompt leaves one active prompt per (phase, category, feature flag),
+// keeping the highest version. Earlier prompt handlers could activate a new version without
+// deactivating the old one, and every active phase prompt is concatenated into the context.
+func migrate016SingleActivePrompt(db *gorm.DB) error {
+	var active []Prompt
+	if err := db.Where("is_active = ?", true).
+		Order("version DESC, updated_at DESC").
+		Find(&active).Error; err != nil {
+		return fmt.Errorf("failed to load active prompts: %w", err)
+	}
+
+	kept := make(map[string]bool)
+	var superseded []string
+	for _, prompt := range active {
+		key := prompt.WorkflowPhase + "|" + prompt.Category + "|" + prompt.FeatureFlag
+		if kept[key] {
+			superseded = append(superseded, prompt.ID)
+			continue
+		}
+		kept[key] = true
+	}
+	if len(superseded) == 0 {
+		return nil
+	}
+
+	return db.Model(&Prompt{}).Where("id IN ?", superseded).Update("is_active", false).Error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrate017DesiredOutcome adds the client's desired outcome to information gathering.
+// Collecting it records a SessionGoal that is evaluated when the session completes.
+func migrate017DesiredOutcome(db *gorm.DB) error {
+	field := PhaseData{
+		ID:          "information_gathering_desired_outcome",
+		PhaseID:     "information_gathering",
+		Name:        "desired_outcome",
+		Requirement: PhaseDataRecommended,
+		Description: "What the client wants to be different if this issue were resolved, in their words",
+		Schema:      `{"type": "string", "description": "The client's desired outcome if the issue were resolved"}`,
+		Source:      "client",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := requireSeededPhases(db, []string{field.PhaseID}); err != nil {
+		return err
+	}
+	return db.FirstOrCreate(&field, PhaseData{ID: field.ID}).Error
+}
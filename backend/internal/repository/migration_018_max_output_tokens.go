@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate018MaxOutputTokens seeds per-phase output limits: assessment phases ask one
+// short question, rapport and closing phases get room for a fuller reply
+func migrate018MaxOutputTokens(db *gorm.DB) error {
+	limits := map[string]int{
+		"pre_session":           800,
+		"issue_decision":        600,
+		"information_gathering": 400,
+		"body_scan":             250,
+		"eye_position":          250,
+		"focused_mindfulness":   300,
+		"status_check":          200,
+		"squeeze_hug":           400,
+		"positive_installation": 500,
+		"complete":              800,
+	}
+
+	for phaseID, limit := range limits {
+		// Don't overwrite limits tuned in the Workflow Studio
+		if err := db.Model(&Phase{}).
+			Where("id = ? AND (max_output_tokens IS NULL OR max_output_tokens = 0)", phaseID).
+			Update("max_output_tokens", limit).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate019PhaseVisualization seeds client-side visualizations: calm ambiance for the
+// processing and resourcing phases, nothing for the conversational ones
+func migrate019PhaseVisualization(db *gorm.DB) error {
+	visualizations := map[string]struct {
+		Type   string
+		Config string
+	}{
+		"body_scan":             {"breathing_circle", `{"color":"#7fb3d5","cycle_seconds":8}`},
+		"eye_position":          {"focus_point", `{"color":"#f5cba7","pulse_seconds":4}`},
+		"focused_mindfulness":   {"breathing_circle", `{"color":"#a9cce3","cycle_seconds":10}`},
+		"squeeze_hug":           {"breathing_circle", `{"color":"#d2b4de","cycle_seconds":6}`},
+		"positive_installation": {"ocean_waves", `{"color":"#76d7c4","speed":0.5}`},
+	}
+
+	for phaseID, visualization := range visualizations {
+		// Don't overwrite visualizations configured in the Workflow Studio
+		if err := db.Model(&Phase{}).
+			Where("id = ? AND (visualization_type IS NULL OR visualization_type = '')", phaseID).
+			Updates(map[string]interface{}{
+				"visualization_type":   visualization.Type,
+				"visualization_config": visualization.Config,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
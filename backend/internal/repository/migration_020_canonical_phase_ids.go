@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// phaseReferenceColumns are the columns holding phase IDs
+var phaseReferenceColumns = []struct {
+	model  interface{}
+	column string
+}{
+	{&Session{}, "phase"},
+	{&SessionTemplate{}, "start_phase"},
+	{&SessionFieldValue{}, "phase_id"},
+	{&SessionPhaseState{}, "phase_id"},
+	{&Prompt{}, "workflow_phase"},
+	{&PromptAddendum{}, "phase"},
+	{&PhaseData{}, "phase_id"},
+	{&PhaseTransition{}, "from_phase_id"},
+	{&PhaseTransition{}, "to_phase_id"},
+	{&PhaseConstraint{}, "phase_id"},
+	{&PhaseTool{}, "phase_id"},
+}
+
+// migrate020CanonicalPhaseIDs rewrites legacy phase IDs (e.g. "completion") to their canonical
+// form so lookups by the session's phase find the phase's prompts and requirements
+func migrate020CanonicalPhaseIDs(db *gorm.DB) error {
+	for legacy, canonical := range phaseAliases {
+		for _, ref := range phaseReferenceColumns {
+			if !db.Migrator().HasTable(ref.model) {
+				continue
+			}
+			if err := db.Model(ref.model).Where(ref.column+" = ?", legacy).Update(ref.column, canonical).Error; err != nil {
+				return err
+			}
+		}
+
+		// Keep the canonical phase if both exist, otherwise rename the legacy one
+		var canonicalCount int64
+		if err := db.Model(&Phase{}).Where("id = ?", canonical).Count(&canonicalCount).Error; err != nil {
+			return err
+		}
+		if canonicalCount > 0 {
+			if err := db.Where("id = ?", legacy).Delete(&Phase{}).Error; err != nil {
+				return err
+			}
+		} else if err := db.Model(&Phase{}).Where("id = ?", legacy).Update("id", canonical).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
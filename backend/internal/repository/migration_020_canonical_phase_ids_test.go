@@ -0,0 +1,62 @@
+package repository
+
+import "testing"
+
+func TestMigrate020MovesLegacyCompletionRowsToComplete(t *testing.T) {
+	db := newTestDB(t, &Phase{}, &PhaseData{}, &Prompt{}, &Session{})
+
+	// Rows written under the legacy ID before the hooks canonicalized it
+	for _, stmt := range []string{
+		`INSERT INTO phases (id, display_name, position) VALUES ('completion', 'Completion', 10)`,
+		`INSERT INTO phase_data (id, phase_id, name, requirement) VALUES ('completion_final_suds', 'completion', 'final_suds', 'required')`,
+		`INSERT INTO prompts (id, name, category, content, is_active, workflow_phase) VALUES ('p1', 'completion', 'phase', 'Close the session gently.', true, 'completion')`,
+		`INSERT INTO sessions (id, client_id, therapist_id, phase) VALUES ('s1', 'c1', 't1', 'completion')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("failed to seed legacy rows: %v", err)
+		}
+	}
+
+	if err := migrate020CanonicalPhaseIDs(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	var phase Phase
+	if err := db.First(&phase, "id = ?", "complete").Error; err != nil {
+		t.Fatalf("complete phase missing after migration: %v", err)
+	}
+	var legacy int64
+	db.Model(&Phase{}).Where("id = ?", "completion").Count(&legacy)
+	if legacy != 0 {
+		t.Errorf("legacy completion phase still present")
+	}
+
+	var fields []PhaseData
+	db.Where("phase_id = ?", "complete").Find(&fields)
+	if len(fields) != 1 || fields[0].Name != "final_suds" {
+		t.Errorf("complete phase requirements = %+v, want final_suds", fields)
+	}
+	var prompts []Prompt
+	db.Where("workflow_phase = ? AND is_active = ?", "complete", true).Find(&prompts)
+	if len(prompts) != 1 {
+		t.Errorf("got %d active prompts for complete, want 1", len(prompts))
+	}
+	var session Session
+	db.First(&session, "id = ?", "s1")
+	if session.Phase != "complete" {
+		t.Errorf("session phase = %q, want complete", session.Phase)
+	}
+}
+
+func TestCanonicalPhaseIDResolvesCompletionAlias(t *testing.T) {
+	for input, want := range map[string]string{
+		"completion":   "complete",
+		" Completion ": "complete",
+		"complete":     "complete",
+		"Status Check": "status_check",
+	} {
+		if got := CanonicalPhaseID(input); got != want {
+			t.Errorf("CanonicalPhaseID(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
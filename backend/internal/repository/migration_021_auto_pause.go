@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate021AutoPause disables inactivity auto-pause for focused mindfulness, where the
+// client is supposed to sit silently for several minutes
+func migrate021AutoPause(db *gorm.DB) error {
+	// Only touch rows still on the column default, so Workflow Studio edits survive
+	return db.Model(&Phase{}).
+		Where("id = ? AND (auto_pause_seconds IS NULL OR auto_pause_seconds = ?)", "focused_mindfulness", 120).
+		Update("auto_pause_seconds", 0).Error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// migrate022CheckInIntervals seeds check-in intervals from the protocol's
+// check_in_interval_sec so the database-driven workflow schedules check-ins too
+func migrate022CheckInIntervals(db *gorm.DB) error {
+	intervals := map[string]int{
+		"focused_mindfulness": 180,
+		"status_check":        240,
+	}
+
+	for phaseID, seconds := range intervals {
+		// Don't overwrite intervals tuned in the Workflow Studio
+		if err := db.Model(&Phase{}).
+			Where("id = ? AND (check_in_interval_seconds IS NULL OR check_in_interval_seconds = 0)", phaseID).
+			Update("check_in_interval_seconds", seconds).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"therapy-navigation-system/internal/logger"
+
+	"gorm.io/gorm"
+)
+
+// Handling of duplicate (session_id, field_name) rows found when adding the unique index:
+// "merge" keeps the most recently updated value, "fail" stops the migration so the
+// duplicates can be reviewed first
+const (
+	FieldValueDuplicatesMerge = "merge"
+	FieldValueDuplicatesFail  = "fail"
+)
+
+// fieldValueDuplicates is configured from FIELD_VALUE_DUPLICATES
+var fieldValueDuplicates = FieldValueDuplicatesMerge
+
+// SetFieldValueDuplicates configures how migration 023 handles duplicate field values
+func SetFieldValueDuplicates(mode string) {
+	switch mode {
+	case FieldValueDuplicatesMerge, FieldValueDuplicatesFail:
+		fieldValueDuplicates = mode
+	}
+}
+
+// migrate023UniqueFieldValues makes (session_id, field_name) unique so field writes can
+// upsert atomically. Duplicates left by earlier concurrent writes are resolved first.
+func migrate023UniqueFieldValues(db *gorm.DB) error {
+	var groups []struct {
+		SessionID string
+		FieldName string
+	}
+	if err := db.Model(&SessionFieldValue{}).
+		Select("session_id, field_name").
+		Group("session_id, field_name").
+		Having("COUNT(*) > 1").
+		Scan(&groups).Error; err != nil {
+		return fmt.Errorf("failed to find duplicate field values: %w", err)
+	}
+
+	if len(groups) > 0 && fieldValueDuplicates == FieldValueDuplicatesFail {
+		names := make([]string, 0, len(groups))
+		for _, g := range groups {
+			names = append(names, g.SessionID+"/"+g.FieldName)
+		}
+		return fmt.Errorf("%d duplicated session fields (%s); resolve them or set FIELD_VALUE_DUPLICATES=merge",
+			len(groups), strings.Join(names, ", "))
+	}
+
+	removed := 0
+	for _, g := range groups {
+		var rows []SessionFieldValue
+		if err := db.Select("id").
+			Where("session_id = ? AND field_name = ?", g.SessionID, g.FieldName).
+			Order("updated_at DESC, created_at DESC").
+			Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to load duplicates of %s/%s: %w", g.SessionID, g.FieldName, err)
+		}
+		stale := make([]string, 0, len(rows)-1)
+		for _, row := range rows[1:] {
+			stale = append(stale, row.ID)
+		}
+		if err := db.Where("id IN ?", stale).Delete(&SessionFieldValue{}).Error; err != nil {
+			return fmt.Errorf("failed to remove duplicates of %s/%s: %w", g.SessionID, g.FieldName, err)
+		}
+		removed += len(stale)
+	}
+	if removed > 0 {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"fields": len(groups),
+			"rows":   removed,
+		}).Warn("⚠️ Removed duplicate session field values, keeping the latest of each")
+	}
+
+	table, err := tableName(db, &SessionFieldValue{})
+	if err != nil {
+		return err
+	}
+	// The unique index serves the same lookups as the plain one it replaces
+	if err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_session_field_values_unique ON %s (session_id, field_name)", table)).Error; err != nil {
+		return fmt.Errorf("failed to create unique field value index: %w", err)
+	}
+	return db.Exec("DROP INDEX IF EXISTS idx_session_field_values_session_field").Error
+}
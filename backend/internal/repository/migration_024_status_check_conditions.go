@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// migrate024StatusCheckConditions moves the status check's branching into its transitions:
+// each transition out of status_check holds when next_action names its target. It was
+// hardcoded in the collect handler. Conditions set in the Workflow Studio are left alone.
+func migrate024StatusCheckConditions(db *gorm.DB) error {
+	var transitions []PhaseTransition
+	if err := db.Where("from_phase_id = ? AND COALESCE(condition, '') = ''", "status_check").Find(&transitions).Error; err != nil {
+		return fmt.Errorf("failed to load status check transitions: %w", err)
+	}
+
+	for _, t := range transitions {
+		condition := fmt.Sprintf("next_action == '%s'", t.ToPhaseID)
+		if err := db.Model(&PhaseTransition{}).Where("id = ?", t.ID).Update("condition", condition).Error; err != nil {
+			return fmt.Errorf("failed to set condition on %s -> %s: %w", t.FromPhaseID, t.ToPhaseID, err)
+		}
+	}
+	return nil
+}
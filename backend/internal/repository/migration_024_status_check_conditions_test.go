@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"sort"
+	"testing"
+)
+
+// seedStatusCheckTransitions stores the phases and transitions migration 003 seeds
+func seedStatusCheckTransitions(t *testing.T) {
+	t.Helper()
+	db := newTestDB(t, &Phase{}, &PhaseTransition{})
+	for i, id := range []string{
+		"pre_session", "issue_decision", "information_gathering", "body_scan", "eye_position",
+		"focused_mindfulness", "status_check", "squeeze_hug", "positive_installation", "complete",
+	} {
+		if err := db.Create(&Phase{ID: id, DisplayName: id, Position: i + 1}).Error; err != nil {
+			t.Fatalf("failed to create phase %s: %v", id, err)
+		}
+	}
+	if err := migrate003PhaseTransitions(db); err != nil {
+		t.Fatalf("migration 003 failed: %v", err)
+	}
+}
+
+// conditionalTarget picks the transition "next" resolves to the way the state machine does:
+// the highest-priority active transition whose condition holds
+func conditionalTarget(t *testing.T, values map[string]interface{}) string {
+	t.Helper()
+	var transitions []PhaseTransition
+	DB.Where("from_phase_id = ? AND is_active = ?", "status_check", true).
+		Order("priority DESC, to_phase_id ASC").Find(&transitions)
+	for _, transition := range transitions {
+		condition, err := ParseTransitionCondition(transition.Condition)
+		if err != nil {
+			t.Fatalf("transition to %s has an invalid condition: %v", transition.ToPhaseID, err)
+		}
+		if condition.Evaluate(values) {
+			return transition.ToPhaseID
+		}
+	}
+	return ""
+}
+
+func TestMigrate024BranchesStatusCheckLikeNextAction(t *testing.T) {
+	seedStatusCheckTransitions(t)
+	if err := migrate024StatusCheckConditions(DB); err != nil {
+		t.Fatalf("migration 024 failed: %v", err)
+	}
+
+	var targets []string
+	DB.Model(&PhaseTransition{}).Where("from_phase_id = ?", "status_check").Pluck("to_phase_id", &targets)
+	sort.Strings(targets)
+	if len(targets) != 4 {
+		t.Fatalf("status check has transitions to %v, want 4", targets)
+	}
+
+	// Before the migration the collect handler auto-transitioned to FieldValueText(next_action);
+	// each way the value may be stored must now resolve to the same phase
+	for _, target := range targets {
+		for _, stored := range []SessionFieldValue{
+			{FieldName: "next_action", FieldType: "string", FieldValue: `"` + target + `"`},
+			{FieldName: "next_action", FieldType: "string", FieldValue: target},
+			{FieldName: "next_action", FieldType: "string", FieldValue: ` "` + target + `" `},
+		} {
+			old := FieldValueText(stored)
+			values, errs := ParsedFieldValues([]SessionFieldValue{stored})
+			if len(errs) > 0 {
+				t.Fatalf("failed to parse %q: %v", stored.FieldValue, errs)
+			}
+			if got := conditionalTarget(t, values); got != old {
+				t.Errorf("next_action stored as %q branches to %q, the old path went to %q", stored.FieldValue, got, old)
+			}
+		}
+	}
+
+	// The old path fell back to "next" without a next_action; no condition holds now either,
+	// so the transition reports what it's waiting for instead
+	if got := conditionalTarget(t, map[string]interface{}{}); got != "" {
+		t.Errorf("status check without next_action branches to %q, want no transition", got)
+	}
+
+	// Transitions out of other phases are left without conditions
+	var conditioned int64
+	DB.Model(&PhaseTransition{}).Where("from_phase_id <> ? AND COALESCE(condition, '') <> ''", "status_check").Count(&conditioned)
+	if conditioned != 0 {
+		t.Errorf("%d transitions outside the status check got conditions", conditioned)
+	}
+}
+
+func TestMigrate024KeepsStudioConditions(t *testing.T) {
+	seedStatusCheckTransitions(t)
+	custom := "suds_current <= 0 && next_action == 'complete'"
+	if err := DB.Model(&PhaseTransition{}).Where("id = ?", "status_check_to_complete").Update("condition", custom).Error; err != nil {
+		t.Fatalf("failed to set condition: %v", err)
+	}
+
+	for run := 0; run < 2; run++ {
+		if err := migrate024StatusCheckConditions(DB); err != nil {
+			t.Fatalf("migration 024 run %d failed: %v", run+1, err)
+		}
+	}
+
+	var transitions []PhaseTransition
+	DB.Where("from_phase_id = ?", "status_check").Find(&transitions)
+	for _, transition := range transitions {
+		want := "next_action == '" + transition.ToPhaseID + "'"
+		if transition.ID == "status_check_to_complete" {
+			want = custom
+		}
+		if transition.Condition != want {
+			t.Errorf("%s condition = %q, want %q", transition.ID, transition.Condition, want)
+		}
+	}
+}
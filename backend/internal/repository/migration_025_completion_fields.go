@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrate025CompletionFields adds the session summary and the client's feedback to the
+// complete phase. Along with final_suds they gate CompleteSession and the completion
+// readiness check, and the feedback is recorded against the client's goals.
+func migrate025CompletionFields(db *gorm.DB) error {
+	fields := []PhaseData{
+		{ID: "complete_session_summary", PhaseID: "complete", Name: "session_summary", Requirement: PhaseDataRequired,
+			Description: "Brief summary of what the session worked on and where it ended",
+			Schema:      `{"type": "string", "description": "Brief summary of the session"}`,
+			Source:      "coach"},
+		{ID: "complete_client_feedback", PhaseID: "complete", Name: "client_feedback", Requirement: PhaseDataRequired,
+			Description: "How the client says the session went for them, in their words",
+			Schema:      `{"type": "string", "description": "The client's feedback on the session"}`,
+			Source:      "client"},
+	}
+	if err := requireSeededPhases(db, []string{"complete"}); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		field.CreatedAt = time.Now()
+		field.UpdatedAt = time.Now()
+		if err := db.FirstOrCreate(&field, PhaseData{ID: field.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
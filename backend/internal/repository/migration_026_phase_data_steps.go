@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// migrate026PhaseDataSteps orders the brainspotting phases' fields so the coach can't rate
+// or branch on something it hasn't asked about yet: locate activation before rating it,
+// pick the issue before its intensity, take SUDS before choosing the next action, and so on.
+// Steps and dependencies set in the Workflow Studio are left alone.
+func migrate026PhaseDataSteps(db *gorm.DB) error {
+	steps := map[string]int{
+		"issue_decision_issue_intensity":    1,
+		"issue_decision_activation_present": 1,
+		"body_scan_activation_level":        1,
+		"body_scan_sensation_quality":       1,
+		"status_check_next_action":          1,
+		"squeeze_hug_bilateral_effect":      1,
+		"squeeze_hug_suds_after":            1,
+	}
+	dependsOn := map[string]string{
+		"eye_position_spot_type":   `["brainspot_x", "brainspot_y"]`,
+		"complete_client_feedback": `["final_suds"]`,
+	}
+
+	for id, step := range steps {
+		if err := db.Model(&PhaseData{}).
+			Where("id = ? AND COALESCE(step, 0) = 0", id).
+			Update("step", step).Error; err != nil {
+			return fmt.Errorf("failed to set step on %s: %w", id, err)
+		}
+	}
+	for id, names := range dependsOn {
+		if err := db.Model(&PhaseData{}).
+			Where("id = ? AND COALESCE(depends_on, '') = ''", id).
+			Update("depends_on", names).Error; err != nil {
+			return fmt.Errorf("failed to set dependencies on %s: %w", id, err)
+		}
+	}
+	return nil
+}
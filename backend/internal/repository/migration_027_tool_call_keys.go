@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// migrate027ToolCallKeys re-keys tool call records on (id, session_id, tool_name) so one
+// session's call can't be answered with another's result. The records only deduplicate
+// retries within TOOL_CALL_RETENTION, so the table is recreated rather than converted.
+func migrate027ToolCallKeys(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&ToolCallRecord{}); err != nil {
+		return fmt.Errorf("failed to drop tool call records: %w", err)
+	}
+	if err := db.Migrator().CreateTable(&ToolCallRecord{}); err != nil {
+		return fmt.Errorf("failed to create tool call records: %w", err)
+	}
+	return nil
+}
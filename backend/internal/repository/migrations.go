@@ -2,15 +2,18 @@ package repository
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 	"gorm.io/gorm"
 	"therapy-navigation-system/internal/logger"
 	"github.com/sirupsen/logrus"
 )
 
-// Migration tracks applied migrations
+// Migration records an applied migration; the highest applied ID is the schema version
 type Migration struct {
 	ID        string    `gorm:"primaryKey"`
+	Name      string
 	AppliedAt time.Time
 }
 
@@ -19,9 +22,95 @@ type MigrationFunc func(*gorm.DB) error
 
 // MigrationEntry represents a single migration
 type MigrationEntry struct {
-	ID   string
-	Name string
-	Func MigrationFunc
+	ID       string
+	Name     string
+	Func     MigrationFunc
+	Requires []string // Migrations that must be applied first, e.g. the one seeding the phases it references
+}
+
+// Out-of-order migrations (pending ones older than the schema version, e.g. merged from a
+// long-lived branch): "enforce" refuses to run them, "warn" logs and runs them
+const (
+	MigrationOrderEnforce = "enforce"
+	MigrationOrderWarn    = "warn"
+)
+
+// migrationOrdering is configured from MIGRATION_ORDERING
+var migrationOrdering = MigrationOrderEnforce
+
+// SetMigrationOrdering configures how out-of-order migrations are handled
+func SetMigrationOrdering(mode string) {
+	switch mode {
+	case MigrationOrderEnforce, MigrationOrderWarn:
+		migrationOrdering = mode
+	}
+}
+
+// SchemaVersion returns the ID of the latest applied migration, or "" on a fresh database
+func SchemaVersion(db *gorm.DB) (string, error) {
+	var ids []string
+	if err := db.Model(&Migration{}).Pluck("id", &ids).Error; err != nil {
+		return "", fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if len(ids) == 0 {
+		return "", nil
+	}
+	sort.Strings(ids)
+	return ids[len(ids)-1], nil
+}
+
+// validateMigrationList checks migration IDs are unique and ascending, and that every
+// migration's requirements come before it
+func validateMigrationList(migrations []MigrationEntry) error {
+	seen := make(map[string]bool, len(migrations))
+	for i, migration := range migrations {
+		if i > 0 && migration.ID <= migrations[i-1].ID {
+			return fmt.Errorf("migration %s (%s) is listed after %s; migrations must be in ascending ID order",
+				migration.ID, migration.Name, migrations[i-1].ID)
+		}
+		for _, required := range migration.Requires {
+			if !seen[required] {
+				return fmt.Errorf("migration %s (%s) requires migration %s, which is not listed before it",
+					migration.ID, migration.Name, required)
+			}
+		}
+		seen[migration.ID] = true
+	}
+	return nil
+}
+
+// requireSeededPhases fails when any of phaseIDs has no Phase row, so a migration inserting
+// rows that reference phases stops with a clear message instead of writing dangling rows
+func requireSeededPhases(db *gorm.DB, phaseIDs []string) error {
+	unique := make(map[string]bool, len(phaseIDs))
+	for _, id := range phaseIDs {
+		unique[id] = true
+	}
+	ids := make([]string, 0, len(unique))
+	for id := range unique {
+		ids = append(ids, id)
+	}
+
+	var existing []string
+	if err := db.Model(&Phase{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+		return fmt.Errorf("failed to check referenced phases: %w", err)
+	}
+	found := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("referenced phases do not exist: %s (they are seeded by migration 002)", strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 // RunMigrations runs all database migrations in order
@@ -35,18 +124,71 @@ func RunMigrations(db *gorm.DB) error {
 	migrations := []MigrationEntry{
 		{ID: "001", Name: "initial_users", Func: migrate001Users},
 		{ID: "002", Name: "brainspotting_phases", Func: migrate002Phases},
-		{ID: "003", Name: "phase_transitions", Func: migrate003PhaseTransitions},
-		{ID: "004", Name: "phase_data_requirements", Func: migrate004PhaseData},
+		{ID: "003", Name: "phase_transitions", Func: migrate003PhaseTransitions, Requires: []string{"002"}},
+		{ID: "004", Name: "phase_data_requirements", Func: migrate004PhaseData, Requires: []string{"002"}},
 		// NOTE: migrations 005 and 006 for dynamic MCP tools were removed - simplified MCP layer
 		{ID: "007", Name: "therapy_prompts", Func: migrate007Prompts},
+		{ID: "008", Name: "hot_path_indexes", Func: migrate008HotPathIndexes},
+		{ID: "009", Name: "terminal_phases", Func: migrate009TerminalPhases},
+		{ID: "010", Name: "phase_expects_data", Func: migrate010ExpectsData},
+		{ID: "011", Name: "phase_client_descriptions", Func: migrate011ClientDescriptions},
+		{ID: "012", Name: "phase_data_sources", Func: migrate012PhaseDataSources},
+		{ID: "013", Name: "intake_rubric", Func: migrate013IntakeRubric},
+		{ID: "014", Name: "phase_response_pacing", Func: migrate014ResponsePacing},
+		{ID: "015", Name: "phase_data_requirement", Func: migrate015PhaseDataRequirement},
+		{ID: "016", Name: "single_active_prompt", Func: migrate016SingleActivePrompt},
+		{ID: "017", Name: "desired_outcome", Func: migrate017DesiredOutcome, Requires: []string{"002"}},
+		{ID: "018", Name: "max_output_tokens", Func: migrate018MaxOutputTokens},
+		{ID: "019", Name: "phase_visualization", Func: migrate019PhaseVisualization},
+		{ID: "020", Name: "canonical_phase_ids", Func: migrate020CanonicalPhaseIDs},
+		{ID: "021", Name: "auto_pause", Func: migrate021AutoPause},
+		{ID: "022", Name: "check_in_intervals", Func: migrate022CheckInIntervals},
+		{ID: "023", Name: "unique_field_values", Func: migrate023UniqueFieldValues},
+		{ID: "024", Name: "status_check_conditions", Func: migrate024StatusCheckConditions, Requires: []string{"003"}},
+		{ID: "025", Name: "completion_fields", Func: migrate025CompletionFields, Requires: []string{"002"}},
+		{ID: "026", Name: "phase_data_steps", Func: migrate026PhaseDataSteps, Requires: []string{"004", "025"}},
+		{ID: "027", Name: "tool_call_keys", Func: migrate027ToolCallKeys},
+	}
+
+	if err := validateMigrationList(migrations); err != nil {
+		return err
+	}
+
+	var appliedIDs []string
+	if err := db.Model(&Migration{}).Pluck("id", &appliedIDs).Error; err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	applied := make(map[string]bool, len(appliedIDs))
+	for _, id := range appliedIDs {
+		applied[id] = true
+	}
+	version, err := SchemaVersion(db)
+	if err != nil {
+		return err
 	}
 
 	// Run each migration if not already applied
 	for _, migration := range migrations {
-		var applied Migration
-		result := db.Where("id = ?", migration.ID).First(&applied)
+		if !applied[migration.ID] {
+			for _, required := range migration.Requires {
+				if !applied[required] {
+					return fmt.Errorf("migration %s (%s) requires migration %s, which has not been applied",
+						migration.ID, migration.Name, required)
+				}
+			}
+
+			if migration.ID < version {
+				if migrationOrdering == MigrationOrderEnforce {
+					return fmt.Errorf("migration %s (%s) is older than schema version %s; refusing to apply it out of order (set MIGRATION_ORDERING=warn to allow)",
+						migration.ID, migration.Name, version)
+				}
+				logger.AppLogger.WithFields(logrus.Fields{
+					"migration_id":   migration.ID,
+					"migration_name": migration.Name,
+					"schema_version": version,
+				}).Warn("⚠️ Applying migration out of order")
+			}
 
-		if result.Error == gorm.ErrRecordNotFound {
 			// Migration not applied yet
 			logger.AppLogger.WithFields(logrus.Fields{
 				"migration_id":   migration.ID,
@@ -58,10 +200,17 @@ func RunMigrations(db *gorm.DB) error {
 			}
 
 			// Mark as applied
-			db.Create(&Migration{
+			if err := db.Create(&Migration{
 				ID:        migration.ID,
+				Name:      migration.Name,
 				AppliedAt: time.Now(),
-			})
+			}).Error; err != nil {
+				return fmt.Errorf("failed to record migration %s (%s): %w", migration.ID, migration.Name, err)
+			}
+			applied[migration.ID] = true
+			if migration.ID > version {
+				version = migration.ID
+			}
 
 			logger.AppLogger.WithFields(logrus.Fields{
 				"migration_id":   migration.ID,
@@ -70,5 +219,6 @@ func RunMigrations(db *gorm.DB) error {
 		}
 	}
 
+	logger.AppLogger.WithField("schema_version", version).Info("📦 Database schema up to date")
 	return nil
 }
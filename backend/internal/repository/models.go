@@ -18,6 +18,9 @@ type Client struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Tenancy
+	OrganizationID string `gorm:"index;not null;default:'default'" json:"organization_id"`
+
 	// Relationships
 	Sessions []Session `gorm:"foreignKey:ClientID" json:"sessions,omitempty"`
 }
@@ -30,6 +33,9 @@ type Therapist struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Tenancy
+	OrganizationID string `gorm:"index;not null;default:'default'" json:"organization_id"`
+
 	// Relationships
 	Sessions []Session `gorm:"foreignKey:TherapistID" json:"sessions,omitempty"`
 }
@@ -39,15 +45,46 @@ type Session struct {
 	ID          string    `gorm:"type:uuid;primary_key;" json:"id"`
 	ClientID    string    `gorm:"type:uuid;not null" json:"client_id"`
 	TherapistID string    `gorm:"type:uuid;not null" json:"therapist_id"`
-	Status      string    `gorm:"default:scheduled" json:"status"` // scheduled, active, completed
+	Status      string    `gorm:"default:scheduled" json:"status"` // scheduled, active, completed, cancelled - change via ChangeSessionStatus
 	Phase       string    `gorm:"default:pre_session" json:"phase"`
 	StartTime   time.Time `json:"start_time"`
 	EndTime     *time.Time `json:"end_time,omitempty"`
 	Notes       string    `gorm:"type:text" json:"notes,omitempty"`
 
+	// Tenancy
+	OrganizationID string `gorm:"index;not null;default:'default'" json:"organization_id"`
+
 	// Phase tracking
 	PhaseStartTime       time.Time `json:"phase_start_time"`
 	PhaseTransitionCount int       `json:"phase_transition_count" gorm:"default:0"`
+	PhaseHistory         string    `json:"phase_history,omitempty" gorm:"type:text"` // JSON array of PhaseTiming
+
+	// Timer state, flushed periodically so a reconnect resumes instead of restarting the clock
+	AccumulatedSeconds      int    `json:"accumulated_seconds" gorm:"default:0"`
+	PhaseAccumulatedSeconds int    `json:"phase_accumulated_seconds" gorm:"default:0"`
+	TimerPhase              string `json:"timer_phase,omitempty"` // Phase PhaseAccumulatedSeconds was measured in
+	TimerPaused             bool   `json:"timer_paused" gorm:"default:false"`
+
+	// Experiments
+	Features string `json:"features,omitempty" gorm:"type:text"` // JSON object of enabled feature flags
+
+	// Auto mode: collect_structured_data transitions on its own once a phase's requirements are met
+	AutoMode bool `json:"auto_mode" gorm:"default:true"`
+
+	// Runaway-loop guard: coach transitions are held once the session transitions more than
+	// MAX_PHASE_TRANSITIONS times since the count was last reviewed
+	AutoTransitionsHeld     bool `json:"auto_transitions_held" gorm:"default:false"`
+	TransitionCountReviewed int  `json:"transition_count_reviewed" gorm:"default:0"` // PhaseTransitionCount at the last review
+
+	// Fixed sampling seed for reproducible coach output in test sessions; unset in production
+	CoachSeed *int32 `json:"coach_seed,omitempty"`
+
+	// Origin
+	TemplateID string `json:"template_id,omitempty" gorm:"index"` // SessionTemplate the session was created from
+	Locale     string `json:"locale,omitempty"`                   // BCP 47 language the coach speaks; empty is English
+
+	// Proposed session plan (JSON SessionPlan); only applied once the therapist approves it
+	Plan string `json:"plan,omitempty" gorm:"type:text"`
 
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -60,16 +97,169 @@ type Session struct {
 	FieldValues  []SessionFieldValue   `json:"field_values,omitempty" gorm:"foreignKey:SessionID"`
 }
 
+// IntakeRubricField weights a collected field toward intake completeness. The rubric is
+// global: it scores every organization's sessions.
+type IntakeRubricField struct {
+	FieldName string    `gorm:"primaryKey" json:"field_name"` // SessionFieldValue.FieldName
+	Label     string    `json:"label"`
+	Weight    float64   `gorm:"default:1" json:"weight"`
+	Required  bool      `gorm:"default:false" json:"required"` // Intake is never complete while a required field is missing
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToolCallRecord records an applied MCP tool call so a retried delivery returns the original
+// result. Model tool call IDs aren't globally unique, so a call is keyed by its session and
+// tool as well.
+type ToolCallRecord struct {
+	ID        string    `gorm:"primaryKey" json:"id"` // Tool call ID from the model
+	SessionID string    `gorm:"primaryKey" json:"session_id"`
+	ToolName  string    `gorm:"primaryKey" json:"tool_name"`
+	Status    string    `gorm:"default:pending" json:"status"`     // pending, completed
+	Result    string    `gorm:"type:text" json:"result,omitempty"` // JSON-encoded tool result
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SessionStatusChange is the audit log of session lifecycle changes (see ChangeSessionStatus)
+type SessionStatusChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	SessionID  string    `gorm:"index" json:"session_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SessionPauseEvent records a session timer pausing or resuming and why (see RecordPauseEvent)
+type SessionPauseEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SessionID string    `gorm:"index" json:"session_id"`
+	Paused    bool      `json:"paused"`
+	Reason    string    `json:"reason"`             // auto_inactivity, manual, stopped, client_message
+	Actor     string    `json:"actor"`              // The participant's role (client, therapist, ...), system or unknown
+	ActorID   string    `json:"actor_id,omitempty"` // The participant's person ID
+	Phase     string    `json:"phase"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SudsReading is one SUDS (0-10) rating taken during a session. Readings are appended, never
+// overwritten, so the session keeps its full distress curve.
+type SudsReading struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	SessionID  string    `gorm:"type:uuid;index:idx_suds_readings_session" json:"session_id"`
+	PhaseID    string    `json:"phase_id"`
+	Value      float64   `gorm:"not null" json:"value"`
+	RecordedAt time.Time `gorm:"index:idx_suds_readings_session" json:"recorded_at"`
+}
+
+// SessionTokenUsage is a session's running Gemini token totals for one agent and model, as
+// reported by the API's usage metadata
+type SessionTokenUsage struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	SessionID       string    `gorm:"type:uuid;uniqueIndex:idx_session_token_usage" json:"session_id"`
+	AgentType       string    `gorm:"uniqueIndex:idx_session_token_usage" json:"agent_type"`
+	Model           string    `gorm:"uniqueIndex:idx_session_token_usage" json:"model"`
+	Requests        int       `json:"requests"`
+	PromptTokens    int64     `json:"prompt_tokens"`
+	CandidateTokens int64     `json:"candidate_tokens"`
+	ThoughtsTokens  int64     `json:"thoughts_tokens"`
+	TotalTokens     int64     `json:"total_tokens"`
+	CostUSD         float64   `gorm:"-" json:"cost_usd,omitempty"` // Estimated from the configured token prices
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ExtractionJob tracks a bulk intake re-extraction run. Sessions are processed in ID order
+// and Cursor holds the last ID completed, so a failed or interrupted job resumes after it.
+type ExtractionJob struct {
+	ID             string    `gorm:"primaryKey" json:"id"`
+	OrganizationID string    `gorm:"index" json:"organization_id,omitempty"`
+	Filters        string    `gorm:"type:text" json:"filters"`              // JSON-encoded BulkExtractFilters
+	Status         string    `gorm:"default:pending" json:"status"`          // pending, running, completed, failed, interrupted
+	Total          int       `json:"total"`
+	Processed      int       `json:"processed"`
+	Failed         int       `json:"failed"`
+	Cursor         string    `json:"cursor,omitempty"`
+	Error          string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SessionTemplate is a reusable set of session-creation parameters owned by a therapist
+type SessionTemplate struct {
+	ID             string    `gorm:"type:uuid;primary_key;" json:"id"`
+	TherapistID    string    `gorm:"type:uuid;not null;index" json:"therapist_id"`
+	OrganizationID string    `gorm:"index;not null;default:'default'" json:"organization_id"`
+	Name           string    `gorm:"not null" json:"name"`
+	Protocol       string    `gorm:"default:brainspotting" json:"protocol"`
+	StartPhase     string    `gorm:"default:pre_session" json:"start_phase"`
+	Locale         string    `gorm:"default:en" json:"locale"`                // BCP 47 language the coach conducts sessions in
+	ModelConfig    string    `gorm:"type:text" json:"model_config,omitempty"` // JSON object of model overrides (model, temperature, max_tokens)
+	Notes          string    `gorm:"type:text" json:"notes,omitempty"`        // Pre-filled session notes
+	Features       string    `gorm:"type:text" json:"features,omitempty"`     // JSON object of feature flags, same shape as Session.Features
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SessionParticipant is a person taking part in a session in a given role, such as a
+// supervisor or co-therapist. Session.ClientID and TherapistID remain the primary client and
+// therapist; SessionParticipants lists them alongside the stored rows.
+type SessionParticipant struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	SessionID   string    `gorm:"type:uuid;not null;uniqueIndex:idx_session_participants_person" json:"session_id"`
+	PersonID    string    `gorm:"not null;uniqueIndex:idx_session_participants_person" json:"person_id"`
+	Role        string    `gorm:"not null" json:"role"` // client, therapist, or a SESSION_PARTICIPANT_ROLES role
+	DisplayName string    `json:"display_name,omitempty"`
+	Primary     bool      `gorm:"-" json:"primary"` // Session.ClientID or TherapistID, not a stored row
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SessionGoal is the outcome a client says they want, evaluated when a session completes.
+// Goals belong to the client so they carry into later sessions.
+type SessionGoal struct {
+	ID             string     `gorm:"type:uuid;primary_key;" json:"id"`
+	ClientID       string     `gorm:"type:uuid;not null;index" json:"client_id"`
+	SessionID      string     `gorm:"type:uuid;index" json:"session_id"` // Session the goal was stated in
+	OrganizationID string     `gorm:"index;not null;default:'default'" json:"organization_id"`
+	Statement      string     `gorm:"type:text;not null" json:"statement"`
+	BaselineSUDS   *float64   `json:"baseline_suds,omitempty"`
+	FinalSUDS      *float64   `json:"final_suds,omitempty"`
+	ClientFeedback string     `gorm:"type:text" json:"client_feedback,omitempty"`
+	Progress       string     `gorm:"default:open" json:"progress"` // open, achieved, progressing, no_change, worse
+	EvaluatedAt    *time.Time `json:"evaluated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Attachment is a file (drawing, photo) shared during a session. The binary lives in the
+// attachment store; the coach only sees the textual reference in the linked message.
+type Attachment struct {
+	ID             string    `gorm:"type:uuid;primary_key;" json:"id"`
+	SessionID      string    `gorm:"type:uuid;not null;index" json:"session_id"`
+	MessageID      string    `gorm:"type:uuid;index" json:"message_id,omitempty"` // Message announcing the attachment in the conversation
+	OrganizationID string    `gorm:"index;not null;default:'default'" json:"organization_id"`
+	FileName       string    `gorm:"not null" json:"file_name"`
+	ContentType    string    `gorm:"not null" json:"content_type"`
+	SizeBytes      int64     `json:"size_bytes"`
+	Description    string    `gorm:"type:text" json:"description,omitempty"`
+	UploadedBy     string    `gorm:"default:client" json:"uploaded_by"` // Uploader's participant role: client, therapist, supervisor, ...
+	StorageBackend string    `gorm:"not null" json:"-"`                 // local, gcs
+	StorageKey     string    `gorm:"not null" json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
 // Message represents a chat message in a therapy session
 type Message struct {
-	ID          string    `json:"id" gorm:"type:uuid;primary_key;"`
-	SessionID   string    `json:"session_id" gorm:"type:uuid;not null"`
-	Role        string    `json:"role" gorm:"not null"` // patient, coach, system
-	Content     string    `json:"content" gorm:"type:text;not null"`
-	MessageType string    `json:"message_type" gorm:"default:conversation"` // conversation, tool_call, tool_result
-	Metadata    string    `json:"metadata,omitempty" gorm:"type:text"` // JSON string for tool calls/results
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            string    `json:"id" gorm:"type:uuid;primary_key;"`
+	SessionID     string    `json:"session_id" gorm:"type:uuid;not null"`
+	Role          string    `json:"role" gorm:"not null"` // patient, coach, system, or a participant role
+	Content       string    `json:"content" gorm:"type:text;not null"`
+	MessageType   string    `json:"message_type" gorm:"default:conversation"` // conversation, tool_call, tool_result
+	Metadata      string    `json:"metadata,omitempty" gorm:"type:text"`      // JSON string for tool calls/results
+	ParticipantID string    `json:"participant_id,omitempty"`                 // SessionParticipant.PersonID of the sender, when known
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 
 	// Relationships
 	Session Session `json:"session,omitempty" gorm:"foreignKey:SessionID"`
@@ -84,12 +274,32 @@ type Phase struct {
 	ID              string    `json:"id" gorm:"primaryKey"`
 	DisplayName     string    `json:"display_name" gorm:"not null"`
 	Description     string    `json:"description" gorm:"type:text"`
+	ClientDescription          string    `json:"client_description,omitempty" gorm:"type:text"` // Client-facing "what's next" text; never clinical internals
 	Position                   int       `json:"position" gorm:"not null"` // Order of phases
 	MinimumTurns               int       `json:"minimum_turns" gorm:"default:1"` // Required conversation exchanges
 	RecommendedDurationSeconds int       `json:"recommended_duration_seconds" gorm:"default:60"` // Recommended time for phase
 	Icon                       string    `json:"icon" gorm:"type:text"`
 	Color                      string    `json:"color" gorm:"type:text"`
 	DurationSeconds            int       `json:"duration_seconds"`
+	IsTerminal                 bool      `json:"is_terminal" gorm:"default:false"` // Absorbing phase: no transitions out without an explicit reopen
+	FeatureFlag                string    `json:"feature_flag,omitempty"` // Experimental phase: only active for sessions with this flag
+	ExpectsData                bool      `json:"expects_data" gorm:"default:false"` // False for conversational-only phases with no phase_data
+	MinResponseDelayMs         int       `json:"min_response_delay_ms" gorm:"default:0"` // Pacing: coach replies are held at least this long after the client's message
+	MaxOutputTokens            int       `json:"max_output_tokens" gorm:"default:0"`     // Cap on coach reply length; 0 uses the configured default
+	AutoPauseSeconds           int       `json:"auto_pause_seconds" gorm:"default:120"`  // Inactivity before the session auto-pauses; 0 disables
+	ContextTokenBudget         int       `json:"context_token_budget" gorm:"default:0"`  // Prompt token budget for this phase; 0 uses the configured default
+	CheckInIntervalSeconds     int       `json:"check_in_interval_seconds" gorm:"default:0"` // Timed phases: prompt a check-in this often; 0 disables
+	ModelName                  string    `json:"model_name,omitempty"`                       // Gemini model for coach replies; empty uses the template's, then COACH_MODEL
+
+	// Client-side ambiance rendered during the phase, e.g. breathing_circle, with JSON
+	// rendering options such as colors and animation speed
+	VisualizationType   string `json:"visualization_type,omitempty"`
+	VisualizationConfig string `json:"visualization_config,omitempty" gorm:"type:text"`
+
+	// Set once the protocol's timing defaults have been offered to the phase, so a later
+	// deliberate 0 isn't overwritten on the next startup
+	ProtocolDefaultsAppliedAt *time.Time `json:"-"`
+
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 
@@ -106,10 +316,12 @@ type PhaseData struct {
 	ID          string    `json:"id" gorm:"primaryKey"`
 	PhaseID     string    `json:"phase_id" gorm:"not null"`
 	Name        string    `json:"name" gorm:"not null"` // e.g., "selected_issue", "suds_level"
-	Required    bool      `json:"required" gorm:"default:false"`
-	Optional    bool      `json:"optional" gorm:"default:true"`
+	Requirement string    `json:"requirement" gorm:"default:optional"`
 	Schema      string    `json:"schema" gorm:"type:text"` // JSON Schema for validation
 	Description string    `json:"description" gorm:"type:text"`
+	Step        int       `json:"step" gorm:"default:0"`        // Multi-step phases: earlier steps' required fields must be collected first
+	DependsOn   string    `json:"depends_on" gorm:"type:text"`  // JSON array of field names that must be collected first
+	Source      string    `json:"source" gorm:"default:client"` // "client" (stated by the client) or "coach" (coach-observed)
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
@@ -117,6 +329,19 @@ type PhaseData struct {
 	Phase Phase `json:"phase,omitempty" gorm:"foreignKey:PhaseID"`
 }
 
+// PhaseData requirement levels. Only required fields block a phase transition;
+// recommended fields are called out to the model but never block.
+const (
+	PhaseDataRequired    = "required"
+	PhaseDataRecommended = "recommended"
+	PhaseDataOptional    = "optional"
+)
+
+// IsRequired reports whether the field must be collected before leaving its phase
+func (pd PhaseData) IsRequired() bool {
+	return pd.Requirement == PhaseDataRequired
+}
+
 // PhaseConstraint defines timing and engagement requirements for each phase
 type PhaseConstraint struct {
 	ID             string    `json:"id" gorm:"primaryKey"`
@@ -138,7 +363,7 @@ type PhaseTransition struct {
 	ID          string    `json:"id" gorm:"primaryKey"`
 	FromPhaseID string    `json:"from_phase_id" gorm:"not null"`
 	ToPhaseID   string    `json:"to_phase_id" gorm:"not null"`
-	Condition   string    `json:"condition,omitempty"` // Optional condition for this transition
+	Condition   string    `json:"condition,omitempty"` // Optional, e.g. "suds_current <= 0"; syntax in transition_conditions.go
 	Priority    int       `json:"priority" gorm:"default:0"`
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -161,6 +386,7 @@ type SessionFieldValue struct {
 	FieldName  string    `gorm:"not null;index" json:"field_name"`
 	FieldValue string    `gorm:"type:text" json:"field_value"`
 	FieldType  string    `json:"field_type"` // string, int, bool, json
+	Source     string    `gorm:"default:ai" json:"source"` // ai (collected by the coach), manual (corrected by a clinician), or extraction (found in the transcript by bulk extraction)
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 
@@ -217,7 +443,9 @@ type Prompt struct {
 	Parameters    string    `json:"parameters" gorm:"type:jsonb"` // JSON object for template vars
 	IsActive      bool      `gorm:"default:true" json:"is_active"`
 	IsSystem      bool      `gorm:"default:false" json:"is_system"`
+	IsPrimary     bool      `gorm:"default:false" json:"is_primary"` // Preferred phase prompt under PHASE_PROMPT_SELECTION=primary
 	WorkflowPhase string    `json:"workflow_phase,omitempty"` // Links to phases
+	FeatureFlag   string    `json:"feature_flag,omitempty"` // Experimental prompt: only used for sessions with this flag
 	UsageCount    int       `json:"usage_count" gorm:"default:0"`
 	CreatedBy     string    `json:"created_by" gorm:"type:text"`
 	UpdatedBy     string    `json:"updated_by" gorm:"type:text"`
@@ -322,6 +550,30 @@ func (s *Session) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeCreate hook for SessionTemplate
+func (t *SessionTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate hook for SessionGoal
+func (g *SessionGoal) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate hook for Attachment
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
 // BeforeCreate hook for Message
 func (m *Message) BeforeCreate(tx *gorm.DB) error {
 	if m.ID == "" {
@@ -351,15 +603,20 @@ func (pt *PhaseTool) BeforeCreate(tx *gorm.DB) error {
 	if pt.ID == "" {
 		pt.ID = uuid.New().String()
 	}
-	return nil
+	return requirePhase(tx, pt.PhaseID, "phase_id")
 }
 
-// BeforeCreate hook for Prompt
+// BeforeCreate hook for Prompt checks a phase prompt's workflow_phase names an existing
+// phase, since the context builder finds phase prompts by exact match on it
 func (p *Prompt) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == "" {
 		p.ID = uuid.New().String()
 	}
-	return nil
+	if p.WorkflowPhase == "" {
+		return nil
+	}
+	p.WorkflowPhase = CanonicalPhaseID(p.WorkflowPhase)
+	return requirePhase(tx, p.WorkflowPhase, "workflow_phase")
 }
 
 // BeforeCreate hook for PromptAddendum
@@ -6,7 +6,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// PromptLog stores prompt history for monitoring
+// PromptLog stores prompt history for monitoring. Each model call writes a REQUEST row
+// and a RESPONSE row; Prompt/Response/FunctionCalls are empty when content logging was off.
 type PromptLog struct {
 	ID         string    `gorm:"primaryKey" json:"id"`
 	SessionID  string    `gorm:"index" json:"session_id"`
@@ -17,6 +18,18 @@ type PromptLog struct {
 	Model      string    `json:"model"`
 	Timestamp  time.Time `gorm:"index" json:"timestamp"`
 	CreatedAt  time.Time `json:"created_at"`
+
+	TurnType        string `gorm:"index" json:"turn_type"` // REQUEST or RESPONSE
+	Phase           string `json:"phase"`
+	UserMessage     string `gorm:"type:text" json:"user_message,omitempty"`
+	FunctionCalls   string `gorm:"type:text" json:"function_calls,omitempty"` // JSON; just the names when content isn't logged
+	PromptHash      string `json:"prompt_hash,omitempty"`
+	ResponseHash    string `json:"response_hash,omitempty"`
+	Length          int    `json:"length"` // prompt or response length in characters
+	MaxOutputTokens int    `json:"max_output_tokens,omitempty"`
+	ToolCallsCount  int    `json:"tool_calls_count,omitempty"`
+	ResponseTimeMs  int64  `json:"response_time_ms,omitempty"`
+	ContentLogged   bool   `json:"content_logged"`
 }
 
 // EmbeddingLog stores embedding generation history
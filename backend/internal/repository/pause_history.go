@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Why a session's timer paused or resumed
+const (
+	PauseReasonAutoInactivity = "auto_inactivity" // No activity for the phase's auto-pause threshold
+	PauseReasonManual         = "manual"          // A participant pressed pause or resume
+	PauseReasonStopped        = "stopped"         // A participant stopped the session
+	PauseReasonClientMessage  = "client_message"  // A participant's message resumed the paused session
+
+	PauseActorSystem  = "system"  // Auto-pause; no participant acted
+	PauseActorUnknown = "unknown" // A socket bound to no participant (development without participant_id)
+)
+
+// RecordPauseEvent appends a pause or resume to the session's pause history, tagged with
+// the phase the session was in. participant is who paused or resumed; nil for the system's
+// auto-pause or an unbound socket.
+func RecordPauseEvent(db *gorm.DB, sessionID string, paused bool, reason string, participant *SessionParticipant) error {
+	var session Session
+	db.Select("id", "phase").Limit(1).Find(&session, "id = ?", sessionID)
+
+	event := SessionPauseEvent{
+		SessionID: sessionID,
+		Paused:    paused,
+		Reason:    reason,
+		Actor:     PauseActorUnknown,
+		Phase:     session.Phase,
+		CreatedAt: time.Now(),
+	}
+	switch {
+	case participant != nil:
+		event.Actor = participant.Role
+		event.ActorID = participant.PersonID
+	case reason == PauseReasonAutoInactivity:
+		event.Actor = PauseActorSystem
+	}
+	return db.Create(&event).Error
+}
+
+// PauseHistory returns a session's pause events, oldest first
+func PauseHistory(db *gorm.DB, sessionID string) ([]SessionPauseEvent, error) {
+	var events []SessionPauseEvent
+	err := db.Where("session_id = ?", sessionID).Order("created_at ASC, id ASC").Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordPauseEventRecordsWhoActed(t *testing.T) {
+	db := newTestDB(t, &Session{}, &SessionPauseEvent{})
+	session := Session{ID: "session-1", ClientID: "client-1", TherapistID: "therapist-1", Phase: "body_scan", StartTime: time.Now()}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	therapist := &SessionParticipant{SessionID: session.ID, PersonID: "therapist-1", Role: ParticipantRoleTherapist}
+
+	for _, tc := range []struct {
+		paused      bool
+		reason      string
+		participant *SessionParticipant
+		wantActor   string
+		wantActorID string
+	}{
+		{true, PauseReasonManual, therapist, ParticipantRoleTherapist, "therapist-1"},
+		{false, PauseReasonClientMessage, &SessionParticipant{PersonID: "client-1", Role: ParticipantRoleClient}, ParticipantRoleClient, "client-1"},
+		{true, PauseReasonAutoInactivity, nil, PauseActorSystem, ""},
+		{false, PauseReasonManual, nil, PauseActorUnknown, ""},
+	} {
+		if err := RecordPauseEvent(db, session.ID, tc.paused, tc.reason, tc.participant); err != nil {
+			t.Fatalf("RecordPauseEvent(%s): %v", tc.reason, err)
+		}
+	}
+
+	events, err := PauseHistory(db, session.ID)
+	if err != nil {
+		t.Fatalf("PauseHistory: %v", err)
+	}
+	want := []struct{ actor, actorID string }{
+		{ParticipantRoleTherapist, "therapist-1"},
+		{ParticipantRoleClient, "client-1"},
+		{PauseActorSystem, ""},
+		{PauseActorUnknown, ""},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Actor != want[i].actor || event.ActorID != want[i].actorID || event.Phase != "body_scan" {
+			t.Errorf("event %d (%s) = actor %q/%q in %q, want %q/%q in body_scan",
+				i, event.Reason, event.Actor, event.ActorID, event.Phase, want[i].actor, want[i].actorID)
+		}
+	}
+}
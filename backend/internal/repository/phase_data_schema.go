@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// fieldSchema is the subset of JSON Schema used by PhaseData.Schema. The seeded schemas
+// use min/max for numeric bounds; minimum/maximum are accepted too.
+type fieldSchema struct {
+	Type      string        `json:"type"`
+	Min       *float64      `json:"min"`
+	Max       *float64      `json:"max"`
+	Minimum   *float64      `json:"minimum"`
+	Maximum   *float64      `json:"maximum"`
+	Enum      []interface{} `json:"enum"`
+	MinLength *int          `json:"minLength"`
+	MaxLength *int          `json:"maxLength"`
+}
+
+// DeclaredType returns the JSON type the field's schema declares ("integer", "number",
+// "string", ...), or "" when the field has no parseable schema
+func (pd PhaseData) DeclaredType() string {
+	if strings.TrimSpace(pd.Schema) == "" {
+		return ""
+	}
+	var schema fieldSchema
+	if err := json.Unmarshal([]byte(pd.Schema), &schema); err != nil {
+		return ""
+	}
+	return schema.Type
+}
+
+// ValidateValue checks a collected value against the field's schema (type, numeric bounds,
+// enum membership, string length). A field without a parseable schema accepts anything.
+func (pd PhaseData) ValidateValue(value interface{}) error {
+	if strings.TrimSpace(pd.Schema) == "" {
+		return nil
+	}
+	var schema fieldSchema
+	if err := json.Unmarshal([]byte(pd.Schema), &schema); err != nil {
+		return nil
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("expected %s, got %s", schema.Type, jsonTypeName(value))
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("expected integer, got %v", n)
+		}
+		if low := firstBound(schema.Min, schema.Minimum); low != nil && n < *low {
+			return fmt.Errorf("%v is below the minimum of %v", n, *low)
+		}
+		if high := firstBound(schema.Max, schema.Maximum); high != nil && n > *high {
+			return fmt.Errorf("%v is above the maximum of %v", n, *high)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %s", jsonTypeName(value))
+		}
+		if schema.MinLength != nil && len([]rune(s)) < *schema.MinLength {
+			return fmt.Errorf("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && len([]rune(s)) > *schema.MaxLength {
+			return fmt.Errorf("must be at most %d characters", *schema.MaxLength)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected boolean, got %s", jsonTypeName(value))
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Errorf("expected array, got %s", jsonTypeName(value))
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("expected object, got %s", jsonTypeName(value))
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		for _, option := range schema.Enum {
+			if reflect.DeepEqual(option, value) {
+				return nil
+			}
+		}
+		options := make([]string, len(schema.Enum))
+		for i, option := range schema.Enum {
+			options[i] = fmt.Sprint(option)
+		}
+		return fmt.Errorf("%v is not one of: %s", value, strings.Join(options, ", "))
+	}
+
+	return nil
+}
+
+// firstBound returns whichever of the two bound spellings is set
+func firstBound(short *float64, long *float64) *float64 {
+	if short != nil {
+		return short
+	}
+	return long
+}
+
+// jsonTypeName names the JSON type of a decoded value for error messages
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PhaseTiming is one entry in Session.PhaseHistory
+type PhaseTiming struct {
+	PhaseID         string     `json:"phase_id"`
+	EnteredAt       time.Time  `json:"entered_at"`
+	LeftAt          *time.Time `json:"left_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	TurnCount       int        `json:"turn_count"`
+	MessageCount    int        `json:"message_count"`
+	Rollback        bool       `json:"rollback,omitempty"` // Entered by rolling back from a later phase
+}
+
+// maxPhaseHistoryRetries bounds compare-and-swap retries under concurrent transitions
+const maxPhaseHistoryRetries = 5
+
+// ParsePhaseHistory decodes the PhaseHistory column, treating empty as no history
+func ParsePhaseHistory(raw string) ([]PhaseTiming, error) {
+	if raw == "" {
+		return []PhaseTiming{}, nil
+	}
+
+	var history []PhaseTiming
+	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+		return nil, fmt.Errorf("invalid phase history: %w", err)
+	}
+	return history, nil
+}
+
+// RecordPhaseTransition closes the open timing for fromPhase and opens one for toPhase.
+// Updates are compare-and-swap on phase_transition_count so concurrent transitions never drop entries.
+func RecordPhaseTransition(db *gorm.DB, sessionID, fromPhase, toPhase string, at time.Time) error {
+	return recordPhaseChange(db, sessionID, fromPhase, toPhase, at, false)
+}
+
+// RecordPhaseRollback records a move back to an earlier phase, marking the new entry as a rollback
+func RecordPhaseRollback(db *gorm.DB, sessionID, fromPhase, toPhase string, at time.Time) error {
+	return recordPhaseChange(db, sessionID, fromPhase, toPhase, at, true)
+}
+
+func recordPhaseChange(db *gorm.DB, sessionID, fromPhase, toPhase string, at time.Time, rollback bool) error {
+	for attempt := 0; attempt < maxPhaseHistoryRetries; attempt++ {
+		var session Session
+		if err := db.Select("id", "phase_history", "phase_transition_count", "phase_start_time", "start_time").
+			First(&session, "id = ?", sessionID).Error; err != nil {
+			return fmt.Errorf("session not found: %w", err)
+		}
+
+		history, err := ParsePhaseHistory(session.PhaseHistory)
+		if err != nil {
+			// Don't let a corrupt column block transitions - start a fresh timeline
+			history = []PhaseTiming{}
+		}
+
+		// Sessions that predate history tracking get an entry for the phase being left
+		if len(history) == 0 || history[len(history)-1].LeftAt != nil {
+			enteredAt := session.PhaseStartTime
+			if enteredAt.IsZero() {
+				enteredAt = session.StartTime
+			}
+			history = append(history, PhaseTiming{PhaseID: fromPhase, EnteredAt: enteredAt})
+		}
+
+		// Close the open entry
+		open := &history[len(history)-1]
+		var messageCount int64
+		db.Model(&Message{}).
+			Where("session_id = ? AND created_at >= ? AND created_at < ?", sessionID, open.EnteredAt, at).
+			Count(&messageCount)
+		leftAt := at
+		open.LeftAt = &leftAt
+		open.DurationSeconds = at.Sub(open.EnteredAt).Seconds()
+		open.MessageCount = int(messageCount)
+		open.TurnCount = int(messageCount) / 2 // each turn = client + coach message
+
+		history = append(history, PhaseTiming{PhaseID: toPhase, EnteredAt: at, Rollback: rollback})
+
+		encoded, err := json.Marshal(history)
+		if err != nil {
+			return fmt.Errorf("failed to encode phase history: %w", err)
+		}
+
+		result := db.Model(&Session{}).
+			Where("id = ? AND phase_transition_count = ?", sessionID, session.PhaseTransitionCount).
+			Updates(map[string]interface{}{
+				"phase_history":          string(encoded),
+				"phase_transition_count": session.PhaseTransitionCount + 1,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update phase history: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+		// Another transition won the race - reload and retry
+	}
+
+	return fmt.Errorf("failed to record phase history for session %s: too many concurrent updates", sessionID)
+}
+
+// PhaseTimeline is a session's phase history with the phase it's in now
+type PhaseTimeline struct {
+	SessionID string         `json:"session_id"`
+	History   []PhaseTiming  `json:"history"`            // Completed phases, oldest first
+	Current   *PhaseTiming   `json:"current,omitempty"`  // In progress, timed up to now; nil once the session has ended
+	Revisits  map[string]int `json:"revisits,omitempty"` // Phases entered more than once, with how many times
+}
+
+// SessionTimeline returns the completed phases from PhaseHistory and the in-progress phase.
+// Sessions that haven't transitioned yet have no history and just the current phase.
+func SessionTimeline(db *gorm.DB, sessionID string) (*PhaseTimeline, error) {
+	var session Session
+	if err := db.Select("id", "phase", "status", "phase_history", "phase_start_time", "start_time", "end_time").
+		First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	history, err := ParsePhaseHistory(session.PhaseHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &PhaseTimeline{SessionID: sessionID, History: []PhaseTiming{}}
+	var current *PhaseTiming
+	for i := range history {
+		if history[i].LeftAt == nil {
+			current = &history[i]
+			continue
+		}
+		timeline.History = append(timeline.History, history[i])
+	}
+	if current == nil {
+		enteredAt := session.PhaseStartTime
+		if enteredAt.IsZero() {
+			enteredAt = session.StartTime
+		}
+		current = &PhaseTiming{PhaseID: session.Phase, EnteredAt: enteredAt}
+	}
+
+	// The open phase is timed up to now, or to the end of a finished session
+	until := time.Now()
+	if session.EndTime != nil {
+		until = *session.EndTime
+	}
+	var messageCount int64
+	db.Model(&Message{}).
+		Where("session_id = ? AND created_at >= ? AND created_at < ?", sessionID, current.EnteredAt, until).
+		Count(&messageCount)
+	current.DurationSeconds = until.Sub(current.EnteredAt).Seconds()
+	current.MessageCount = int(messageCount)
+	current.TurnCount = int(messageCount) / 2
+
+	if session.EndTime != nil || session.Status == SessionStatusCompleted || session.Status == SessionStatusCancelled {
+		// The final phase ended with the session
+		left := until
+		current.LeftAt = &left
+		timeline.History = append(timeline.History, *current)
+	} else {
+		timeline.Current = current
+	}
+
+	entered := make(map[string]int)
+	for _, timing := range timeline.History {
+		entered[timing.PhaseID]++
+	}
+	if timeline.Current != nil {
+		entered[timeline.Current.PhaseID]++
+	}
+	for phase, count := range entered {
+		if count > 1 {
+			if timeline.Revisits == nil {
+				timeline.Revisits = make(map[string]int)
+			}
+			timeline.Revisits[phase] = count
+		}
+	}
+	return timeline, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Phase IDs are primary keys referenced by sessions, transitions, phase data and tools, so
+// a typo or stray whitespace silently breaks lookups. New phases get normalized IDs, and
+// rows referencing a phase must point at one that exists.
+var phaseIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// phaseIDValidation enables the phase ID hooks; configured from PHASE_ID_VALIDATION
+var phaseIDValidation = true
+
+// phaseAliases map legacy phase IDs to the canonical ones, e.g. "completion" was used for
+// the phase the state machine calls "complete"
+var phaseAliases = map[string]string{"completion": "complete"}
+
+// SetPhaseAliases configures legacy phase IDs from "old=new,old2=new2"
+func SetPhaseAliases(spec string) {
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		from, to := NormalizePhaseID(parts[0]), NormalizePhaseID(parts[1])
+		if from != "" && to != "" && from != to {
+			aliases[from] = to
+		}
+	}
+	phaseAliases = aliases
+}
+
+// CanonicalPhaseID normalizes a phase ID and resolves legacy aliases
+func CanonicalPhaseID(id string) string {
+	id = NormalizePhaseID(id)
+	if canonical, ok := phaseAliases[id]; ok {
+		return canonical
+	}
+	return id
+}
+
+// NormalizePhaseID lowercases an ID and joins words with underscores ("Status Check" -> "status_check")
+func NormalizePhaseID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return strings.Join(strings.FieldsFunc(id, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '\t'
+	}), "_")
+}
+
+// ValidatePhaseID checks an already-normalized phase ID against the allowed charset
+func ValidatePhaseID(id string) error {
+	if !phaseIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid phase ID %q: use lowercase letters, digits and underscores, starting with a letter", id)
+	}
+	return nil
+}
+
+// requirePhase returns an error when phaseID doesn't name an existing phase
+func requirePhase(tx *gorm.DB, phaseID string, field string) error {
+	if !phaseIDValidation {
+		return nil
+	}
+	var count int64
+	if err := tx.Session(&gorm.Session{NewDB: true}).Model(&Phase{}).Where("id = ?", phaseID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%s %q does not name an existing phase", field, phaseID)
+	}
+	return nil
+}
+
+// BeforeCreate hook for Phase normalizes and validates the ID and rejects duplicates
+func (p *Phase) BeforeCreate(tx *gorm.DB) error {
+	if !phaseIDValidation {
+		return nil
+	}
+	p.ID = CanonicalPhaseID(p.ID)
+	if err := ValidatePhaseID(p.ID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Session(&gorm.Session{NewDB: true}).Model(&Phase{}).Where("id = ?", p.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("phase %q already exists", p.ID)
+	}
+	return nil
+}
+
+// BeforeCreate hook for PhaseTransition checks both ends exist
+func (t *PhaseTransition) BeforeCreate(tx *gorm.DB) error {
+	if err := requirePhase(tx, t.FromPhaseID, "from_phase_id"); err != nil {
+		return err
+	}
+	return requirePhase(tx, t.ToPhaseID, "to_phase_id")
+}
+
+// BeforeCreate hook for PhaseData checks the phase exists
+func (pd *PhaseData) BeforeCreate(tx *gorm.DB) error {
+	return requirePhase(tx, pd.PhaseID, "phase_id")
+}
+
+// BeforeCreate hook for PhaseConstraint checks the phase exists
+func (pc *PhaseConstraint) BeforeCreate(tx *gorm.DB) error {
+	return requirePhase(tx, pc.PhaseID, "phase_id")
+}
+
+// CheckPhaseReferences lists rows that reference phase IDs with no matching phase, e.g.
+// from data written before validation existed
+func CheckPhaseReferences(db *gorm.DB) ([]string, error) {
+	references := []struct {
+		table  string
+		column string
+	}{
+		{"phase_transitions", "from_phase_id"},
+		{"phase_transitions", "to_phase_id"},
+		{"phase_data", "phase_id"},
+		{"phase_constraints", "phase_id"},
+		{"phase_tools", "phase_id"},
+		{"prompts", "workflow_phase"},
+		{"sessions", "phase"},
+	}
+
+	var problems []string
+	for _, ref := range references {
+		var dangling []string
+		if err := db.Table(ref.table).
+			Distinct(ref.column).
+			Where(fmt.Sprintf("%s <> '' AND %s NOT IN (?)", ref.column, ref.column), db.Model(&Phase{}).Select("id")).
+			Pluck(ref.column, &dangling).Error; err != nil {
+			return nil, fmt.Errorf("failed to check %s.%s: %w", ref.table, ref.column, err)
+		}
+		for _, id := range dangling {
+			problems = append(problems, fmt.Sprintf("%s.%s references unknown phase %q", ref.table, ref.column, id))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Startup handling of prompts that drift from their phase's PhaseData: "off" skips the
+// check, "warn" logs misaligned phases, "strict" refuses to start
+const (
+	PromptDataCheckOff    = "off"
+	PromptDataCheckWarn   = "warn"
+	PromptDataCheckStrict = "strict"
+)
+
+// promptDataCheckMode is configured from PHASE_PROMPT_DATA_CHECK
+var promptDataCheckMode = PromptDataCheckWarn
+
+// promptDataIgnored are data keys prompts use that aren't collected fields: tool arguments
+// and tool results
+var promptDataIgnored = map[string]bool{
+	"session_id":          true,
+	"target_phase":        true,
+	"ready_to_transition": true,
+}
+
+// SetPromptDataCheck configures the startup check and the extra keys it ignores
+func SetPromptDataCheck(mode string, ignored string) {
+	switch mode {
+	case PromptDataCheckOff, PromptDataCheckWarn, PromptDataCheckStrict:
+		promptDataCheckMode = mode
+	}
+	for _, key := range strings.Split(ignored, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			promptDataIgnored[key] = true
+		}
+	}
+}
+
+// PromptDataCheckMode returns the configured startup check mode
+func PromptDataCheckMode() string {
+	return promptDataCheckMode
+}
+
+// promptDataKey matches a snake_case name used as a data key - "field: ..." in a list or
+// "field": in an example call - rather than mentioned in passing
+var promptDataKey = regexp.MustCompile(`\b([a-z][a-z0-9]*(?:_[a-z0-9]+)+)"?\s*:`)
+
+// PhasePromptAlignment reports where a phase's active prompt and its PhaseData disagree
+type PhasePromptAlignment struct {
+	PhaseID    string   `json:"phase_id"`
+	Prompts    []string `json:"prompts"`              // Active phase prompts checked
+	Unmodeled  []string `json:"unmodeled,omitempty"`  // Data keys the prompt asks for that aren't PhaseData fields
+	Unprompted []string `json:"unprompted,omitempty"` // Required fields the prompt never mentions
+	NoPrompt   bool     `json:"no_prompt,omitempty"`  // The phase requires data but has no active prompt
+}
+
+// Aligned reports whether the prompt and PhaseData agree
+func (a PhasePromptAlignment) Aligned() bool {
+	return len(a.Unmodeled) == 0 && len(a.Unprompted) == 0 && !a.NoPrompt
+}
+
+// CheckPhasePromptAlignment cross-checks every phase's active prompts against its PhaseData.
+// A data key the prompt asks for must be a field of the phase (tool names, phase IDs and
+// enum values in the phase's schemas are not data keys), and every required field must be
+// mentioned in the prompt, as written or with spaces for underscores. Only misaligned
+// phases are returned.
+func CheckPhasePromptAlignment(db *gorm.DB) ([]PhasePromptAlignment, error) {
+	var phases []Phase
+	if err := db.Order("position ASC").Find(&phases).Error; err != nil {
+		return nil, fmt.Errorf("failed to load phases: %w", err)
+	}
+
+	ignored := make(map[string]bool, len(promptDataIgnored))
+	for key := range promptDataIgnored {
+		ignored[key] = true
+	}
+	for _, phase := range phases {
+		ignored[phase.ID] = true
+	}
+	var toolNames []string
+	if err := db.Model(&Tool{}).Pluck("name", &toolNames).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tools: %w", err)
+	}
+	for _, name := range toolNames {
+		ignored[name] = true
+	}
+
+	var misaligned []PhasePromptAlignment
+	for _, phase := range phases {
+		var prompts []Prompt
+		if err := db.Where("workflow_phase = ? AND is_active = ? AND COALESCE(feature_flag, '') = ''", phase.ID, true).
+			Find(&prompts).Error; err != nil {
+			return nil, fmt.Errorf("failed to load prompts for %s: %w", phase.ID, err)
+		}
+		var fields []PhaseData
+		if err := db.Where("phase_id = ?", phase.ID).Find(&fields).Error; err != nil {
+			return nil, fmt.Errorf("failed to load phase data for %s: %w", phase.ID, err)
+		}
+
+		alignment := PhasePromptAlignment{PhaseID: phase.ID}
+		var content strings.Builder
+		for _, prompt := range prompts {
+			alignment.Prompts = append(alignment.Prompts, prompt.Name)
+			content.WriteString(prompt.Content)
+			content.WriteString("\n")
+		}
+
+		modeled := make(map[string]bool, len(fields))
+		var schemas strings.Builder
+		for _, field := range fields {
+			modeled[field.Name] = true
+			schemas.WriteString(field.Schema)
+		}
+
+		if len(prompts) == 0 {
+			for _, field := range fields {
+				if field.IsRequired() {
+					alignment.NoPrompt = true
+					break
+				}
+			}
+		} else {
+			text := content.String()
+			enumValues := schemas.String()
+			seen := make(map[string]bool)
+			for _, match := range promptDataKey.FindAllStringSubmatch(text, -1) {
+				key := match[1]
+				if seen[key] || modeled[key] || ignored[key] || strings.Contains(enumValues, `"`+key+`"`) {
+					continue
+				}
+				seen[key] = true
+				alignment.Unmodeled = append(alignment.Unmodeled, key)
+			}
+
+			lower := strings.ToLower(text)
+			for _, field := range fields {
+				if !field.IsRequired() {
+					continue
+				}
+				name := strings.ToLower(field.Name)
+				if !strings.Contains(lower, name) && !strings.Contains(lower, strings.ReplaceAll(name, "_", " ")) {
+					alignment.Unprompted = append(alignment.Unprompted, field.Name)
+				}
+			}
+		}
+
+		if !alignment.Aligned() {
+			sort.Strings(alignment.Unmodeled)
+			sort.Strings(alignment.Unprompted)
+			misaligned = append(misaligned, alignment)
+		}
+	}
+	return misaligned, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// promptCoverageCheckMode is configured from PHASE_PROMPT_COVERAGE_CHECK and takes the same
+// off, warn and strict values as the prompt data check
+var promptCoverageCheckMode = PromptDataCheckWarn
+
+// SetPromptCoverageCheck configures the startup check for phases without a prompt
+func SetPromptCoverageCheck(mode string) {
+	switch mode {
+	case PromptDataCheckOff, PromptDataCheckWarn, PromptDataCheckStrict:
+		promptCoverageCheckMode = mode
+	}
+}
+
+// PromptCoverageCheckMode returns the configured startup check mode
+func PromptCoverageCheckMode() string {
+	return promptCoverageCheckMode
+}
+
+// PhasesWithoutPrompts lists the non-terminal phases with no active prompt that applies to
+// every session (feature-flagged prompts don't count). Sessions in these phases get only the
+// system prompt.
+func PhasesWithoutPrompts(db *gorm.DB) ([]string, error) {
+	var phaseIDs []string
+	if err := db.Model(&Phase{}).
+		Where("is_terminal = ?", false).
+		Where("id NOT IN (?)", db.Model(&Prompt{}).
+			Select("workflow_phase").
+			Where("workflow_phase IS NOT NULL AND is_active = ? AND COALESCE(feature_flag, '') = ''", true)).
+		Order("position ASC").
+		Pluck("id", &phaseIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to check phase prompt coverage: %w", err)
+	}
+	return phaseIDs, nil
+}
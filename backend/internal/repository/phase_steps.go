@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Dependencies returns the field names that must be collected before this field
+func (pd PhaseData) Dependencies() []string {
+	if pd.DependsOn == "" {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(pd.DependsOn), &names); err != nil {
+		return nil
+	}
+	return names
+}
+
+// PendingPrerequisites returns, for each field of a phase that can't be collected yet,
+// the prerequisite fields still missing. A field's prerequisites are every required
+// field in an earlier step plus its explicit DependsOn list.
+func PendingPrerequisites(fields []PhaseData, collected func(name string) bool) map[string][]string {
+	pending := make(map[string][]string)
+	for _, field := range fields {
+		seen := make(map[string]bool)
+		var missing []string
+		for _, other := range fields {
+			if other.IsRequired() && other.Step < field.Step && !collected(other.Name) && !seen[other.Name] {
+				seen[other.Name] = true
+				missing = append(missing, other.Name)
+			}
+		}
+		for _, name := range field.Dependencies() {
+			if !collected(name) && !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			pending[field.Name] = missing
+		}
+	}
+	return pending
+}
+
+// CurrentStep returns the earliest step of a phase that still has required fields to collect
+func CurrentStep(fields []PhaseData, collected func(name string) bool) int {
+	current := -1
+	for _, field := range fields {
+		if field.IsRequired() && !collected(field.Name) && (current == -1 || field.Step < current) {
+			current = field.Step
+		}
+	}
+	if current == -1 {
+		return 0
+	}
+	return current
+}
+
+// IsMultiStep reports whether a phase's fields are split across more than one step
+func IsMultiStep(fields []PhaseData) bool {
+	for _, field := range fields {
+		if field.Step != fields[0].Step {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// workflowEntryPhase is where every session starts; reachability is measured from here
+const workflowEntryPhase = "pre_session"
+
+// ErrTransitionNotFound is returned when a transition ID doesn't exist
+var ErrTransitionNotFound = errors.New("transition not found")
+
+// ErrInvalidTransition is returned when a transition's endpoints are unusable
+var ErrInvalidTransition = errors.New("invalid transition")
+
+// ErrPhaseOrphaned is returned when a change would leave phases unreachable from the entry phase
+var ErrPhaseOrphaned = errors.New("change would orphan phases")
+
+// validateTransitionPhases checks both endpoints name existing phases. Unlike the
+// BeforeCreate hook this always runs, since updates bypass the hook.
+func validateTransitionPhases(db *gorm.DB, fromPhaseID, toPhaseID string) error {
+	if fromPhaseID == "" || toPhaseID == "" {
+		return fmt.Errorf("%w: from_phase_id and to_phase_id are required", ErrInvalidTransition)
+	}
+	if fromPhaseID == toPhaseID {
+		return fmt.Errorf("%w: a phase can't transition to itself", ErrInvalidTransition)
+	}
+
+	var count int64
+	if err := db.Model(&Phase{}).Where("id IN ?", []string{fromPhaseID, toPhaseID}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != 2 {
+		return fmt.Errorf("%w: from_phase_id %q and to_phase_id %q must both name existing phases", ErrInvalidTransition, fromPhaseID, toPhaseID)
+	}
+	return nil
+}
+
+// CreatePhaseTransition validates and inserts a transition between existing phases
+func CreatePhaseTransition(db *gorm.DB, t *PhaseTransition) error {
+	t.FromPhaseID = CanonicalPhaseID(t.FromPhaseID)
+	t.ToPhaseID = CanonicalPhaseID(t.ToPhaseID)
+	if err := validateTransitionPhases(db, t.FromPhaseID, t.ToPhaseID); err != nil {
+		return err
+	}
+	if err := ValidateTransitionCondition(db, t.Condition); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTransition, err)
+	}
+
+	var existing int64
+	if err := db.Model(&PhaseTransition{}).
+		Where("from_phase_id = ? AND to_phase_id = ?", t.FromPhaseID, t.ToPhaseID).
+		Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return fmt.Errorf("%w: %s -> %s already exists", ErrInvalidTransition, t.FromPhaseID, t.ToPhaseID)
+	}
+
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return db.Create(t).Error
+}
+
+// UpdatePhaseTransition applies changes to an existing transition, rejecting edits that
+// point at missing phases or leave phases unreachable
+func UpdatePhaseTransition(db *gorm.DB, id string, apply func(t *PhaseTransition)) (*PhaseTransition, error) {
+	var updated PhaseTransition
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&updated, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransitionNotFound
+			}
+			return err
+		}
+
+		apply(&updated)
+		updated.FromPhaseID = CanonicalPhaseID(updated.FromPhaseID)
+		updated.ToPhaseID = CanonicalPhaseID(updated.ToPhaseID)
+		if err := validateTransitionPhases(tx, updated.FromPhaseID, updated.ToPhaseID); err != nil {
+			return err
+		}
+		if err := ValidateTransitionCondition(tx, updated.Condition); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTransition, err)
+		}
+
+		if err := checkNoNewOrphans(tx, id, &updated); err != nil {
+			return err
+		}
+		return tx.Save(&updated).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeletePhaseTransition removes a transition unless that would make a phase unreachable
+func DeletePhaseTransition(db *gorm.DB, id string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var transition PhaseTransition
+		if err := tx.First(&transition, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransitionNotFound
+			}
+			return err
+		}
+
+		if err := checkNoNewOrphans(tx, id, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&transition).Error
+	})
+}
+
+// checkNoNewOrphans compares reachability from the entry phase before and after replacing
+// transition id with changed (nil for a delete), so phases that were already unreachable
+// don't block unrelated edits
+func checkNoNewOrphans(tx *gorm.DB, id string, changed *PhaseTransition) error {
+	var before []PhaseTransition
+	if err := tx.Where("is_active = ?", true).Find(&before).Error; err != nil {
+		return err
+	}
+
+	after := make([]PhaseTransition, 0, len(before)+1)
+	for _, t := range before {
+		if t.ID != id {
+			after = append(after, t)
+		}
+	}
+	if changed != nil && changed.IsActive {
+		after = append(after, *changed)
+	}
+
+	wasReachable := reachablePhases(before)
+	nowReachable := reachablePhases(after)
+
+	var orphaned []string
+	for phaseID := range wasReachable {
+		if !nowReachable[phaseID] {
+			orphaned = append(orphaned, phaseID)
+		}
+	}
+	if len(orphaned) > 0 {
+		sort.Strings(orphaned)
+		return fmt.Errorf("%w: %s would no longer be reachable from %s", ErrPhaseOrphaned, strings.Join(orphaned, ", "), workflowEntryPhase)
+	}
+	return nil
+}
+
+// reachablePhases walks active transitions from the entry phase
+func reachablePhases(transitions []PhaseTransition) map[string]bool {
+	next := make(map[string][]string)
+	for _, t := range transitions {
+		next[t.FromPhaseID] = append(next[t.FromPhaseID], t.ToPhaseID)
+	}
+
+	reachable := map[string]bool{workflowEntryPhase: true}
+	queue := []string{workflowEntryPhase}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, to := range next[current] {
+			if !reachable[to] {
+				reachable[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return reachable
+}
+
+// HasConditionalTransitions reports whether any active transition out of the phase has a condition
+func HasConditionalTransitions(db *gorm.DB, phaseID string) bool {
+	var count int64
+	db.Model(&PhaseTransition{}).
+		Where("from_phase_id = ? AND is_active = ? AND COALESCE(condition, '') <> ''", phaseID, true).
+		Count(&count)
+	return count > 0
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Prompt log turn types
+const (
+	PromptTurnRequest  = "REQUEST"
+	PromptTurnResponse = "RESPONSE"
+)
+
+// RecordPromptLog inserts one prompt log row, filling in its ID and timestamp if unset
+func RecordPromptLog(db *gorm.DB, entry *PromptLog) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	return db.Create(entry).Error
+}
+
+// SessionPromptLogs returns a session's prompt log rows oldest first
+func SessionPromptLogs(db *gorm.DB, sessionID string) ([]PromptLog, error) {
+	var entries []PromptLog
+	err := db.Where("session_id = ?", sessionID).
+		Order("timestamp ASC").
+		Order("created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// Entry returns the row in the shape of the legacy JSONL prompt log, which the prompt
+// endpoints still serve
+func (p PromptLog) Entry() map[string]interface{} {
+	entry := map[string]interface{}{
+		"timestamp":      p.Timestamp.Format(time.RFC3339Nano),
+		"session_id":     p.SessionID,
+		"turn_type":      p.TurnType,
+		"phase":          p.Phase,
+		"model":          p.Model,
+		"content_logged": p.ContentLogged,
+	}
+
+	var functionCalls interface{}
+	if p.FunctionCalls != "" {
+		json.Unmarshal([]byte(p.FunctionCalls), &functionCalls)
+	}
+
+	switch p.TurnType {
+	case PromptTurnRequest:
+		entry["prompt_hash"] = p.PromptHash
+		entry["prompt_length"] = p.Length
+		entry["token_total"] = p.TokenCount
+		entry["max_output_tokens"] = p.MaxOutputTokens
+		if p.ContentLogged {
+			entry["user_message"] = p.UserMessage
+			entry["prompt"] = p.Prompt
+		}
+	case PromptTurnResponse:
+		entry["response_hash"] = p.ResponseHash
+		entry["response_length"] = p.Length
+		entry["tool_calls_count"] = p.ToolCallsCount
+		entry["response_time_ms"] = p.ResponseTimeMs
+		if p.ContentLogged {
+			entry["response_text"] = p.Response
+			entry["function_calls"] = functionCalls
+		} else {
+			entry["function_names"] = functionCalls
+		}
+	}
+	return entry
+}
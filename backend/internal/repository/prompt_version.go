@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -110,4 +112,80 @@ func RevertToVersion(promptID string, versionID string) error {
 	DB.Save(&prompt)
 
 	return nil
-}
\ No newline at end of file
+}
+
+// versionSuffix matches the " vN" suffix PublishPromptVersion adds to prompt names
+var versionSuffix = regexp.MustCompile(` v\d+$`)
+
+// activePromptScope limits a query to the prompts that compete with p for the active slot:
+// the same phase and category (and experiment flag, since flagged variants run alongside)
+func activePromptScope(db *gorm.DB, p Prompt) *gorm.DB {
+	return db.Model(&Prompt{}).
+		Where("workflow_phase = ? AND category = ?", p.WorkflowPhase, p.Category).
+		Where("COALESCE(feature_flag, '') = ?", p.FeatureFlag)
+}
+
+// PublishPromptVersion stores content as the next version of base's (phase, category) prompt
+// and makes it the only active one. Earlier versions are kept as inactive history rows.
+func PublishPromptVersion(db *gorm.DB, base Prompt, content string, author string) (*Prompt, error) {
+	var published Prompt
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var maxVersion int
+		if err := activePromptScope(tx, base).Select("COALESCE(MAX(version), 0)").Scan(&maxVersion).Error; err != nil {
+			return fmt.Errorf("failed to read latest prompt version: %w", err)
+		}
+
+		if err := activePromptScope(tx, base).Where("is_active = ?", true).Update("is_active", false).Error; err != nil {
+			return fmt.Errorf("failed to deactivate superseded prompts: %w", err)
+		}
+
+		version := maxVersion + 1
+		published = Prompt{
+			Name:          fmt.Sprintf("%s v%d", versionSuffix.ReplaceAllString(base.Name, ""), version),
+			Description:   base.Description,
+			Category:      base.Category,
+			Content:       content,
+			Version:       version,
+			Variables:     base.Variables,
+			Parameters:    base.Parameters,
+			IsActive:      true,
+			IsSystem:      base.IsSystem,
+			IsPrimary:     base.IsPrimary,
+			WorkflowPhase: base.WorkflowPhase,
+			FeatureFlag:   base.FeatureFlag,
+			CreatedBy:     author,
+			UpdatedBy:     author,
+		}
+		if err := tx.Create(&published).Error; err != nil {
+			return fmt.Errorf("failed to create prompt version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &published, nil
+}
+
+// SetPrimaryPrompt flags a prompt as the primary one for its phase, category and experiment
+// flag, clearing the flag on the others. PHASE_PROMPT_SELECTION=primary prefers it when
+// several prompts are active.
+func SetPrimaryPrompt(db *gorm.DB, promptID string) (*Prompt, error) {
+	var prompt Prompt
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&prompt, "id = ?", promptID).Error; err != nil {
+			return err
+		}
+		if err := activePromptScope(tx, prompt).Where("is_primary = ?", true).Update("is_primary", false).Error; err != nil {
+			return fmt.Errorf("failed to clear primary prompt: %w", err)
+		}
+		if err := tx.Model(&prompt).Update("is_primary", true).Error; err != nil {
+			return fmt.Errorf("failed to set primary prompt: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
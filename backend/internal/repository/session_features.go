@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ParseSessionFeatures decodes the Session.Features column into a set of enabled flags
+func ParseSessionFeatures(raw string) map[string]bool {
+	features := make(map[string]bool)
+	if raw == "" {
+		return features
+	}
+	if err := json.Unmarshal([]byte(raw), &features); err != nil {
+		return make(map[string]bool)
+	}
+	return features
+}
+
+// EncodeSessionFeatures builds the Session.Features column from a list of flag names
+func EncodeSessionFeatures(flags []string) (string, error) {
+	if len(flags) == 0 {
+		return "", nil
+	}
+	features := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		if flag != "" {
+			features[flag] = true
+		}
+	}
+	encoded, err := json.Marshal(features)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode session features: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// HasFeature reports whether a feature flag is enabled for the session
+func (s *Session) HasFeature(flag string) bool {
+	return ParseSessionFeatures(s.Features)[flag]
+}
+
+// FeatureEnabled reports whether content gated by flag is active for the session.
+// Ungated content (empty flag) is active for every session.
+func (s *Session) FeatureEnabled(flag string) bool {
+	return flag == "" || s.HasFeature(flag)
+}
+
+// NextEnabledPhase returns the first phase after position that is active for the session
+func NextEnabledPhase(db *gorm.DB, session *Session, position int) (*Phase, error) {
+	var candidates []Phase
+	if err := db.Where("position > ?", position).Order("position ASC").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		if session.FeatureEnabled(candidates[i].FeatureFlag) {
+			return &candidates[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// PreviousEnabledPhase returns the last phase before position that is active for the session
+func PreviousEnabledPhase(db *gorm.DB, session *Session, position int) (*Phase, error) {
+	var candidates []Phase
+	if err := db.Where("position < ?", position).Order("position DESC").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		if session.FeatureEnabled(candidates[i].FeatureFlag) {
+			return &candidates[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
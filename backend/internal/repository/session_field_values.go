@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Who wrote a session field value
+const (
+	FieldSourceAI         = "ai"
+	FieldSourceManual     = "manual"
+	FieldSourceExtraction = "extraction"
+)
+
+// UpsertSessionFieldValue stores a session's value for a field, replacing any earlier one.
+// It's a single INSERT ... ON CONFLICT against the unique (session_id, field_name) index, so
+// concurrent writes of the same field coalesce into one row instead of duplicating it.
+func UpsertSessionFieldValue(db *gorm.DB, value *SessionFieldValue) error {
+	now := time.Now()
+	if value.CreatedAt.IsZero() {
+		value.CreatedAt = now
+	}
+	value.UpdatedAt = now
+	if value.Source == "" {
+		value.Source = FieldSourceAI
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "field_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"field_value", "field_type", "phase_id", "source", "updated_at"}),
+	}).Create(value).Error
+	if err != nil {
+		return fmt.Errorf("failed to store field %s: %w", value.FieldName, err)
+	}
+	return nil
+}
+
+// Decoding of stored values that aren't valid JSON (written before values were JSON-encoded,
+// or by hand): "lenient" reads them as plain text, "strict" reports them as errors
+const (
+	FieldValueDecodingLenient = "lenient"
+	FieldValueDecodingStrict  = "strict"
+)
+
+// fieldValueDecoding is configured from FIELD_VALUE_DECODING
+var fieldValueDecoding = FieldValueDecodingLenient
+
+// SetFieldValueDecoding configures how ParseFieldValue treats values that aren't JSON
+func SetFieldValueDecoding(mode string) {
+	switch mode {
+	case FieldValueDecodingLenient, FieldValueDecodingStrict:
+		fieldValueDecoding = mode
+	}
+}
+
+// ParseFieldValue decodes a stored field value. FieldValue holds the collected value
+// JSON-encoded (a string is stored quoted), and numeric or boolean fields stored as text
+// are converted back to their FieldType.
+func ParseFieldValue(value SessionFieldValue) (interface{}, error) {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value.FieldValue), &parsed); err != nil {
+		if fieldValueDecoding == FieldValueDecodingStrict {
+			return nil, fmt.Errorf("field %s is not valid JSON: %w", value.FieldName, err)
+		}
+		parsed = strings.TrimSpace(value.FieldValue)
+	}
+
+	text, isText := parsed.(string)
+	if !isText {
+		return parsed, nil
+	}
+	switch value.FieldType {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			return n, nil
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(strings.TrimSpace(text)); err == nil {
+			return b, nil
+		}
+	}
+	return parsed, nil
+}
+
+// FieldValueText renders a stored field value for prompts and summaries: strings without
+// their JSON quotes, numbers as written, objects as compact JSON
+func FieldValueText(value SessionFieldValue) string {
+	parsed, err := ParseFieldValue(value)
+	if err != nil {
+		return strings.TrimSpace(value.FieldValue)
+	}
+	switch v := parsed.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	encoded, _ := json.Marshal(parsed)
+	return string(encoded)
+}
+
+// FieldValuePresent reports whether a stored value holds something: null and empty
+// strings don't count as collected
+func FieldValuePresent(value SessionFieldValue) bool {
+	parsed, err := ParseFieldValue(value)
+	if err != nil {
+		return strings.TrimSpace(value.FieldValue) != ""
+	}
+	if text, ok := parsed.(string); ok {
+		return strings.TrimSpace(text) != ""
+	}
+	return parsed != nil
+}
+
+// ParsedFieldValues returns the values keyed by field name, decoded with ParseFieldValue.
+// Values that fail to decode are left out and returned as errors.
+func ParsedFieldValues(values []SessionFieldValue) (map[string]interface{}, []error) {
+	parsed := make(map[string]interface{}, len(values))
+	var errs []error
+	for _, value := range values {
+		v, err := ParseFieldValue(value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		parsed[value.FieldName] = v
+	}
+	return parsed, errs
+}
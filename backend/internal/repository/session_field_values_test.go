@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestUpsertSessionFieldValueConcurrentCollectsKeepOneRow(t *testing.T) {
+	db := newTestDB(t, &SessionFieldValue{})
+	if err := migrate023UniqueFieldValues(db); err != nil {
+		t.Fatalf("failed to add the unique index: %v", err)
+	}
+
+	const writers = 2
+	const rounds = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*rounds)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				errs <- UpsertSessionFieldValue(db, &SessionFieldValue{
+					SessionID:  "session-1",
+					PhaseID:    "information_gathering",
+					FieldName:  "suds_level",
+					FieldValue: fmt.Sprint(w*rounds + i),
+					FieldType:  "integer",
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("upsert failed: %v", err)
+		}
+	}
+
+	var rows []SessionFieldValue
+	if err := db.Where("session_id = ? AND field_name = ?", "session-1", "suds_level").Find(&rows).Error; err != nil {
+		t.Fatalf("failed to load field values: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows for one field, want 1", len(rows))
+	}
+	if rows[0].Source != FieldSourceAI {
+		t.Errorf("source = %q, want %q", rows[0].Source, FieldSourceAI)
+	}
+}
+
+func TestUpsertSessionFieldValueReplacesEarlierValue(t *testing.T) {
+	db := newTestDB(t, &SessionFieldValue{})
+	if err := migrate023UniqueFieldValues(db); err != nil {
+		t.Fatalf("failed to add the unique index: %v", err)
+	}
+
+	for _, value := range []string{"4", "7"} {
+		if err := UpsertSessionFieldValue(db, &SessionFieldValue{
+			SessionID: "session-1", FieldName: "suds_level", FieldValue: value, FieldType: "integer",
+		}); err != nil {
+			t.Fatalf("upsert failed: %v", err)
+		}
+	}
+
+	var rows []SessionFieldValue
+	db.Where("session_id = ?", "session-1").Find(&rows)
+	if len(rows) != 1 || rows[0].FieldValue != "7" {
+		t.Fatalf("got %+v, want the single latest value 7", rows)
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GoalFields are the collected fields that state the client's desired outcome
+var GoalFields = map[string]bool{
+	"desired_outcome":             true,
+	"desired_outcome_if_resolved": true,
+}
+
+// Goal progress indicators
+const (
+	GoalOpen        = "open"
+	GoalAchieved    = "achieved"
+	GoalProgressing = "progressing"
+	GoalNoChange    = "no_change"
+	GoalWorse       = "worse"
+)
+
+// goalAchievedSUDS is the final SUDS at or below which a goal counts as achieved
+const goalAchievedSUDS = 1
+
+// RecordSessionGoal stores the client's stated outcome for the session, replacing an
+// earlier statement from the same session
+func RecordSessionGoal(db *gorm.DB, sessionID string, statement string) (*SessionGoal, error) {
+	statement = strings.Trim(strings.TrimSpace(statement), "\"")
+	if statement == "" {
+		return nil, fmt.Errorf("goal statement is empty")
+	}
+
+	var session Session
+	if err := db.Select("id", "client_id", "organization_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	goal := SessionGoal{
+		ClientID:       session.ClientID,
+		SessionID:      sessionID,
+		OrganizationID: session.OrganizationID,
+		Statement:      statement,
+		Progress:       GoalOpen,
+		BaselineSUDS:   latestFieldNumber(db, sessionID, "suds_level"),
+	}
+	err := db.Where("session_id = ?", sessionID).
+		Assign(SessionGoal{Statement: statement, BaselineSUDS: goal.BaselineSUDS}).
+		FirstOrCreate(&goal).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record session goal: %w", err)
+	}
+	return &goal, nil
+}
+
+// EvaluateSessionGoals compares a completed session's final state against the client's open
+// goals, including goals stated in earlier sessions
+func EvaluateSessionGoals(db *gorm.DB, sessionID string) ([]SessionGoal, error) {
+	var session Session
+	if err := db.Select("id", "client_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	var goals []SessionGoal
+	if err := db.Where("client_id = ? AND progress <> ?", session.ClientID, GoalAchieved).
+		Order("created_at ASC").Find(&goals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load goals: %w", err)
+	}
+	if len(goals) == 0 {
+		return goals, nil
+	}
+
+	finalSUDS := latestFieldNumber(db, sessionID, "final_suds")
+	feedback := latestFieldText(db, sessionID, "client_feedback")
+	now := time.Now()
+	for i := range goals {
+		goal := &goals[i]
+		// Goals from earlier sessions are measured against this session's starting point
+		baseline := goal.BaselineSUDS
+		if goal.SessionID != sessionID {
+			if current := latestFieldNumber(db, sessionID, "suds_level"); current != nil {
+				baseline = current
+			}
+		}
+		goal.FinalSUDS = finalSUDS
+		goal.ClientFeedback = feedback
+		goal.Progress = goalProgress(baseline, finalSUDS)
+		goal.EvaluatedAt = &now
+		if err := db.Model(goal).Updates(map[string]interface{}{
+			"final_suds":      goal.FinalSUDS,
+			"client_feedback": goal.ClientFeedback,
+			"progress":        goal.Progress,
+			"evaluated_at":    now,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to update goal %s: %w", goal.ID, err)
+		}
+	}
+	return goals, nil
+}
+
+// goalProgress rates the change from the baseline SUDS to the final SUDS
+func goalProgress(baseline, final *float64) string {
+	switch {
+	case final == nil:
+		return GoalOpen
+	case *final <= goalAchievedSUDS:
+		return GoalAchieved
+	case baseline == nil:
+		return GoalOpen
+	case *final < *baseline:
+		return GoalProgressing
+	case *final == *baseline:
+		return GoalNoChange
+	default:
+		return GoalWorse
+	}
+}
+
+// latestFieldText returns a collected field's value as text
+func latestFieldText(db *gorm.DB, sessionID string, fieldName string) string {
+	var value SessionFieldValue
+	if err := db.Where("session_id = ? AND field_name = ?", sessionID, fieldName).
+		Order("updated_at DESC").First(&value).Error; err != nil {
+		return ""
+	}
+	return FieldValueText(value)
+}
+
+// latestFieldNumber returns a collected numeric field, or nil when absent or not a number
+func latestFieldNumber(db *gorm.DB, sessionID string, fieldName string) *float64 {
+	number, err := strconv.ParseFloat(latestFieldText(db, sessionID, fieldName), 64)
+	if err != nil {
+		return nil
+	}
+	return &number
+}
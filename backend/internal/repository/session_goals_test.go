@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionGoalFieldsAreSeeded(t *testing.T) {
+	db := newTestDB(t, &Phase{}, &PhaseData{})
+	for _, migrate := range []func() error{
+		func() error { return migrate002Phases(db) },
+		func() error { return migrate004PhaseData(db) },
+		func() error { return migrate025CompletionFields(db) },
+	} {
+		if err := migrate(); err != nil {
+			t.Fatalf("failed to seed phases: %v", err)
+		}
+	}
+
+	// EvaluateSessionGoals reads these; an unseeded one is never collected
+	for _, name := range []string{"suds_level", "final_suds", "client_feedback"} {
+		var count int64
+		if err := db.Model(&PhaseData{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			t.Fatalf("failed to look up %s: %v", name, err)
+		}
+		if count == 0 {
+			t.Errorf("expected a seeded phase_data field named %s", name)
+		}
+	}
+}
+
+func TestEvaluateSessionGoalsRecordsClientFeedback(t *testing.T) {
+	db := newTestDB(t, &Session{}, &SessionGoal{}, &SessionFieldValue{})
+	session := Session{ID: "session-1", ClientID: "client-1", TherapistID: "therapist-1", Phase: "complete", StartTime: time.Now()}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	for name, value := range map[string]string{"suds_level": "7", "final_suds": "2", "client_feedback": `"I feel lighter"`} {
+		if err := db.Create(&SessionFieldValue{SessionID: session.ID, PhaseID: "complete", FieldName: name, FieldValue: value}).Error; err != nil {
+			t.Fatalf("failed to store %s: %v", name, err)
+		}
+	}
+	if _, err := RecordSessionGoal(db, session.ID, "feel calmer at work"); err != nil {
+		t.Fatalf("failed to record goal: %v", err)
+	}
+
+	goals, err := EvaluateSessionGoals(db, session.ID)
+	if err != nil {
+		t.Fatalf("EvaluateSessionGoals: %v", err)
+	}
+	if len(goals) != 1 {
+		t.Fatalf("expected 1 goal, got %d", len(goals))
+	}
+	if goals[0].ClientFeedback != "I feel lighter" {
+		t.Errorf("expected the client's feedback on the goal, got %q", goals[0].ClientFeedback)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// DefaultLocale is the language sessions are conducted in when their template doesn't set one
+const DefaultLocale = "en"
+
+// NormalizeLocale validates a BCP 47 language tag and returns it in canonical form. An empty
+// locale is the default.
+func NormalizeLocale(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return DefaultLocale, nil
+	}
+	tag, err := language.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid locale %q: use a BCP 47 language tag such as \"en\" or \"es-MX\"", raw)
+	}
+	return tag.String(), nil
+}
+
+// LocaleLanguage returns the English name of a session's language, e.g. "Mexican Spanish"
+// for es-MX, and whether it differs from English. Unparseable locales count as English.
+func LocaleLanguage(locale string) (name string, nonEnglish bool) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil {
+		return "English", false
+	}
+	if base, _ := tag.Base(); base.String() == "en" {
+		return "English", false
+	}
+	if name = display.English.Tags().Name(tag); name == "" {
+		name = tag.String()
+	}
+	return name, true
+}
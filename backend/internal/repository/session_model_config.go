@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// SessionModelConfig is a session template's model overrides. Zero values leave the
+// configured default in place; a phase's own model or output limit still wins.
+type SessionModelConfig struct {
+	Model       string   `json:"model,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+// ParseSessionModelConfig decodes a template's model_config, rejecting unknown keys and
+// out-of-range values. An empty config has no overrides.
+func ParseSessionModelConfig(raw string) (SessionModelConfig, error) {
+	var config SessionModelConfig
+	if raw == "" {
+		return config, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("invalid model_config: %w", err)
+	}
+	if config.Temperature != nil && (*config.Temperature < 0 || *config.Temperature > 2) {
+		return config, fmt.Errorf("invalid model_config: temperature must be between 0 and 2")
+	}
+	if config.MaxTokens < 0 {
+		return config, fmt.Errorf("invalid model_config: max_tokens can't be negative")
+	}
+	return config, nil
+}
+
+// LoadSessionModelConfig returns the model overrides of the template a session was created
+// from; sessions without a template, or whose template is gone, have none
+func LoadSessionModelConfig(db *gorm.DB, sessionID string) SessionModelConfig {
+	var template SessionTemplate
+	err := db.Select("session_templates.model_config").
+		Joins("JOIN sessions ON sessions.template_id = session_templates.id").
+		Where("sessions.id = ?", sessionID).
+		First(&template).Error
+	if err != nil {
+		return SessionModelConfig{}
+	}
+	config, _ := ParseSessionModelConfig(template.ModelConfig)
+	return config
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Participant roles every session has: the primary client and therapist
+const (
+	ParticipantRoleClient    = "client"
+	ParticipantRoleTherapist = "therapist"
+)
+
+// participantRoles are the roles a participant may hold, configured from
+// SESSION_PARTICIPANT_ROLES on top of client and therapist
+var participantRoles = map[string]bool{
+	ParticipantRoleClient:    true,
+	ParticipantRoleTherapist: true,
+	"supervisor":             true,
+	"co_therapist":           true,
+	"observer":               true,
+}
+
+// clinicianRoles may change the clinical record directly, e.g. correct collected fields
+var clinicianRoles = map[string]bool{
+	ParticipantRoleTherapist: true,
+	"co_therapist":           true,
+	"supervisor":             true,
+}
+
+// ParticipantIsClinician reports whether a participant role may change the clinical record
+func ParticipantIsClinician(role string) bool {
+	return clinicianRoles[role]
+}
+
+// ErrUnknownParticipantRole is returned for a role that isn't configured
+var ErrUnknownParticipantRole = errors.New("unknown participant role")
+
+// ErrPrimaryParticipant is returned when removing the session's primary client or therapist
+var ErrPrimaryParticipant = errors.New("primary client and therapist can't be removed")
+
+// SetParticipantRoles configures the roles participants may hold beyond client and therapist
+func SetParticipantRoles(spec string) {
+	roles := map[string]bool{
+		ParticipantRoleClient:    true,
+		ParticipantRoleTherapist: true,
+	}
+	for _, role := range strings.Split(spec, ",") {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			roles[role] = true
+		}
+	}
+	participantRoles = roles
+}
+
+// ParticipantRoleAllowed reports whether role is a configured participant role
+func ParticipantRoleAllowed(role string) bool {
+	return participantRoles[role]
+}
+
+// AddSessionParticipant adds a person to a session in the given role, or updates the role and
+// display name of a person already taking part
+func AddSessionParticipant(db *gorm.DB, sessionID string, personID string, role string, displayName string) (*SessionParticipant, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !ParticipantRoleAllowed(role) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownParticipantRole, role)
+	}
+	if personID == "" {
+		return nil, fmt.Errorf("person_id is required")
+	}
+
+	var session Session
+	if err := db.Select("id", "client_id", "therapist_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	if personID == session.ClientID || personID == session.TherapistID {
+		return nil, fmt.Errorf("%w: %s is already a primary participant", ErrPrimaryParticipant, personID)
+	}
+
+	participant := SessionParticipant{SessionID: sessionID, PersonID: personID}
+	err := db.Where("session_id = ? AND person_id = ?", sessionID, personID).
+		Assign(SessionParticipant{Role: role, DisplayName: displayName}).
+		FirstOrCreate(&participant).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to add session participant: %w", err)
+	}
+	return &participant, nil
+}
+
+// RemoveSessionParticipant removes a person from a session. The primary client and therapist
+// stay on the session itself and can't be removed.
+func RemoveSessionParticipant(db *gorm.DB, sessionID string, personID string) error {
+	var session Session
+	if err := db.Select("id", "client_id", "therapist_id").First(&session, "id = ?", sessionID).Error; err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+	if personID == session.ClientID || personID == session.TherapistID {
+		return ErrPrimaryParticipant
+	}
+
+	result := db.Where("session_id = ? AND person_id = ?", sessionID, personID).Delete(&SessionParticipant{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove session participant: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SessionParticipants lists everyone taking part in a session: the primary client and
+// therapist first, then the stored participants in the order they joined
+func SessionParticipants(db *gorm.DB, sessionID string) ([]SessionParticipant, error) {
+	var session Session
+	if err := db.Select("id", "client_id", "therapist_id", "created_at").First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	participants := []SessionParticipant{
+		{SessionID: sessionID, PersonID: session.ClientID, Role: ParticipantRoleClient, Primary: true, CreatedAt: session.CreatedAt},
+		{SessionID: sessionID, PersonID: session.TherapistID, Role: ParticipantRoleTherapist, Primary: true, CreatedAt: session.CreatedAt},
+	}
+
+	var stored []SessionParticipant
+	if err := db.Where("session_id = ?", sessionID).Order("created_at ASC, id ASC").Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to load session participants: %w", err)
+	}
+	return append(participants, stored...), nil
+}
+
+// FindSessionParticipant returns the session participant with the given person ID
+func FindSessionParticipant(db *gorm.DB, sessionID string, personID string) (*SessionParticipant, error) {
+	participants, err := SessionParticipants(db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range participants {
+		if participants[i].PersonID == personID {
+			return &participants[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultProtocol is the protocol of sessions not created from a template
+const DefaultProtocol = "brainspotting"
+
+// Session plan review states. Only an approved plan changes how the session runs.
+const (
+	PlanProposed = "proposed"
+	PlanApproved = "approved"
+	PlanRejected = "rejected"
+)
+
+// PlannedPhase is the plan for one phase of the session
+type PlannedPhase struct {
+	PhaseID         string `json:"phase_id"`
+	DurationSeconds int    `json:"duration_seconds"` // Replaces the phase's timed duration; 0 keeps it
+	Focus           string `json:"focus,omitempty"`
+}
+
+// SessionPlan is the proposed shape of a session, generated from the client's intake and
+// history before it starts and reviewed by the therapist
+type SessionPlan struct {
+	Status      string         `json:"status"`
+	Summary     string         `json:"summary"`
+	FocusAreas  []string       `json:"focus_areas"`
+	Phases      []PlannedPhase `json:"phases"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	ReviewedAt  *time.Time     `json:"reviewed_at,omitempty"`
+}
+
+// ParseSessionPlan decodes the Session.Plan column; a session without a plan returns nil
+func ParseSessionPlan(raw string) (*SessionPlan, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var plan SessionPlan
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return nil, fmt.Errorf("failed to decode session plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// SaveSessionPlan stores the plan on the session, replacing any earlier one
+func SaveSessionPlan(db *gorm.DB, sessionID string, plan *SessionPlan) error {
+	encoded, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to encode session plan: %w", err)
+	}
+	result := db.Model(&Session{}).Where("id = ?", sessionID).Update("plan", string(encoded))
+	if result.Error != nil {
+		return fmt.Errorf("failed to save session plan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PlannedPhaseDuration returns the approved plan's duration for a phase, or 0 when the
+// session has no approved plan or the plan leaves the phase's duration alone
+func (s *Session) PlannedPhaseDuration(phaseID string) int {
+	plan, err := ParseSessionPlan(s.Plan)
+	if err != nil || plan == nil || plan.Status != PlanApproved {
+		return 0
+	}
+	for _, phase := range plan.Phases {
+		if phase.PhaseID == phaseID {
+			return phase.DurationSeconds
+		}
+	}
+	return 0
+}
+
+// SessionProtocol returns the protocol a session runs: its template's, or DefaultProtocol
+func SessionProtocol(db *gorm.DB, session *Session) string {
+	if session.TemplateID == "" {
+		return DefaultProtocol
+	}
+	var template SessionTemplate
+	if err := db.Select("protocol").First(&template, "id = ?", session.TemplateID).Error; err != nil || template.Protocol == "" {
+		return DefaultProtocol
+	}
+	return template.Protocol
+}
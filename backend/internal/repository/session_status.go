@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+
+	"gorm.io/gorm"
+)
+
+// Session lifecycle statuses
+const (
+	SessionStatusScheduled = "scheduled"
+	SessionStatusActive    = "active"
+	SessionStatusCompleted = "completed"
+	SessionStatusCancelled = "cancelled"
+)
+
+// sessionStatusTransitions lists the statuses each status may move to. Completed and
+// cancelled are final.
+var sessionStatusTransitions = map[string][]string{
+	SessionStatusScheduled: {SessionStatusActive, SessionStatusCancelled},
+	SessionStatusActive:    {SessionStatusCompleted, SessionStatusCancelled},
+	SessionStatusCompleted: {},
+	SessionStatusCancelled: {},
+}
+
+// Status transition enforcement: "enforce" rejects invalid transitions, "warn" logs and
+// applies them, "off" skips the check. Changes are recorded in every mode.
+const (
+	SessionStatusEnforce = "enforce"
+	SessionStatusWarn    = "warn"
+	SessionStatusOff     = "off"
+)
+
+// sessionStatusMode is configured from SESSION_STATUS_ENFORCEMENT
+var sessionStatusMode = SessionStatusEnforce
+
+// ErrInvalidStatusTransition is returned when a status change isn't allowed
+var ErrInvalidStatusTransition = errors.New("invalid session status transition")
+
+// errStatusChangedConcurrently means another writer changed the status first
+var errStatusChangedConcurrently = errors.New("session status changed concurrently")
+
+// sessionStatusChangedCallback is told about every applied status change, after it commits.
+// It's set by the api package to keep the session gauges in step without a circular import.
+var sessionStatusChangedCallback func(from string, to string)
+
+// SetSessionStatusChangedCallback sets the function called after each applied status change
+func SetSessionStatusChangedCallback(callback func(from string, to string)) {
+	sessionStatusChangedCallback = callback
+}
+
+// SetSessionStatusMode configures how invalid status transitions are handled
+func SetSessionStatusMode(mode string) {
+	switch mode {
+	case SessionStatusEnforce, SessionStatusWarn, SessionStatusOff:
+		sessionStatusMode = mode
+	}
+}
+
+// CanTransitionSessionStatus reports whether a session may move from one status to another
+func CanTransitionSessionStatus(from string, to string) bool {
+	for _, allowed := range sessionStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChangeRejected reports whether ChangeSessionStatus would refuse the change under the
+// configured enforcement mode
+func StatusChangeRejected(from string, to string) bool {
+	return sessionStatusMode == SessionStatusEnforce && from != to && !CanTransitionSessionStatus(from, to)
+}
+
+// ChangeSessionStatus is the one guarded path for changing Session.Status. It validates the
+// transition, stamps EndTime when the session reaches a final status, and records the
+// change in the session_status_changes audit log. Setting the current status is a no-op.
+func ChangeSessionStatus(db *gorm.DB, sessionID string, to string, reason string) error {
+	_, err := changeSessionStatus(db, sessionID, to, reason)
+	return err
+}
+
+// ActivateSession moves a scheduled session to active. changed is true only for the call
+// that made the change, so callers can count activations exactly once.
+func ActivateSession(db *gorm.DB, sessionID string, reason string) (changed bool, err error) {
+	var session Session
+	if err := db.Select("id", "status").First(&session, "id = ?", sessionID).Error; err != nil {
+		return false, fmt.Errorf("session not found: %w", err)
+	}
+	if session.Status != SessionStatusScheduled {
+		return false, nil
+	}
+	changed, err = changeSessionStatus(db, sessionID, SessionStatusActive, reason)
+	if errors.Is(err, errStatusChangedConcurrently) {
+		// Another message activated it first
+		return false, nil
+	}
+	return changed, err
+}
+
+func changeSessionStatus(db *gorm.DB, sessionID string, to string, reason string) (changed bool, err error) {
+	if _, known := sessionStatusTransitions[to]; !known {
+		return false, fmt.Errorf("%w: unknown status %q", ErrInvalidStatusTransition, to)
+	}
+
+	var from string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var session Session
+		if err := tx.Select("id", "status", "end_time").First(&session, "id = ?", sessionID).Error; err != nil {
+			return fmt.Errorf("session not found: %w", err)
+		}
+		from = session.Status
+		if from == to {
+			return nil
+		}
+
+		if sessionStatusMode != SessionStatusOff && !CanTransitionSessionStatus(from, to) {
+			if sessionStatusMode == SessionStatusEnforce {
+				return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+			}
+			logger.AppLogger.WithFields(map[string]interface{}{
+				"session_id": sessionID,
+				"from":       from,
+				"to":         to,
+			}).Warn("⚠️ Applying invalid session status transition")
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":     to,
+			"updated_at": now,
+		}
+		if (to == SessionStatusCompleted || to == SessionStatusCancelled) && session.EndTime == nil {
+			updates["end_time"] = now
+		}
+		// Guard against a concurrent change between the read and the write
+		result := tx.Model(&Session{}).Where("id = ? AND status = ?", sessionID, from).Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update session status: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("session %s: %w", sessionID, errStatusChangedConcurrently)
+		}
+
+		if err := tx.Create(&SessionStatusChange{
+			SessionID:  sessionID,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+			CreatedAt:  now,
+		}).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if changed && sessionStatusChangedCallback != nil {
+		sessionStatusChangedCallback(from, to)
+	}
+	return changed, nil
+}
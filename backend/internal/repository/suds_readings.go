@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SUDS ratings run from 0 (no distress) to 10 (worst imaginable)
+const (
+	MinSUDS = 0
+	MaxSUDS = 10
+)
+
+// RecordSudsReading appends a SUDS reading taken in the given phase
+func RecordSudsReading(db *gorm.DB, sessionID string, phaseID string, value float64) (*SudsReading, error) {
+	if value < MinSUDS || value > MaxSUDS {
+		return nil, fmt.Errorf("SUDS must be between %d and %d, got %v", MinSUDS, MaxSUDS, value)
+	}
+	reading := SudsReading{
+		SessionID:  sessionID,
+		PhaseID:    phaseID,
+		Value:      value,
+		RecordedAt: time.Now(),
+	}
+	if err := db.Create(&reading).Error; err != nil {
+		return nil, fmt.Errorf("failed to record SUDS reading: %w", err)
+	}
+	return &reading, nil
+}
+
+// SessionSudsReadings returns a session's SUDS readings, oldest first
+func SessionSudsReadings(db *gorm.DB, sessionID string) ([]SudsReading, error) {
+	var readings []SudsReading
+	if err := db.Where("session_id = ?", sessionID).Order("recorded_at ASC, id ASC").Find(&readings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load SUDS readings: %w", err)
+	}
+	return readings, nil
+}
+
+// SudsTrend describes the direction of a series of readings from first to last: increasing,
+// decreasing or stable. A single reading has no trend.
+func SudsTrend(readings []SudsReading) string {
+	if len(readings) < 2 {
+		return ""
+	}
+	first, last := readings[0].Value, readings[len(readings)-1].Value
+	switch {
+	case last < first:
+		return "decreasing"
+	case last > first:
+		return "increasing"
+	default:
+		return "stable"
+	}
+}
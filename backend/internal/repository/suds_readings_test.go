@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordSudsReadingChecksTheRange(t *testing.T) {
+	db := newTestDB(t, &SudsReading{})
+
+	for _, tc := range []struct {
+		value  float64
+		wantOK bool
+	}{
+		{MinSUDS, true},
+		{4.5, true},
+		{MaxSUDS, true},
+		{-1, false},
+		{-0.5, false},
+		{10.5, false},
+		{100, false},
+	} {
+		reading, err := RecordSudsReading(db, "session-1", "status_check", tc.value)
+		if tc.wantOK && (err != nil || reading == nil || reading.Value != tc.value || reading.ID == 0) {
+			t.Errorf("RecordSudsReading(%v) = %+v, %v; want it stored", tc.value, reading, err)
+		}
+		if !tc.wantOK && (err == nil || reading != nil) {
+			t.Errorf("RecordSudsReading(%v) = %+v, %v; want a range error", tc.value, reading, err)
+		}
+	}
+
+	readings, err := SessionSudsReadings(db, "session-1")
+	if err != nil {
+		t.Fatalf("SessionSudsReadings: %v", err)
+	}
+	if len(readings) != 3 {
+		t.Errorf("stored %d readings, want only the 3 in range", len(readings))
+	}
+}
+
+func TestSessionSudsReadingsAreOldestFirst(t *testing.T) {
+	db := newTestDB(t, &SudsReading{})
+	now := time.Now()
+	for _, reading := range []SudsReading{
+		{SessionID: "session-1", PhaseID: "status_check", Value: 3, RecordedAt: now},
+		{SessionID: "session-1", PhaseID: "body_scan", Value: 7, RecordedAt: now.Add(-time.Hour)},
+		{SessionID: "session-2", PhaseID: "body_scan", Value: 9, RecordedAt: now.Add(-2 * time.Hour)},
+		{SessionID: "session-1", PhaseID: "status_check", Value: 5, RecordedAt: now.Add(-time.Minute)},
+	} {
+		if err := db.Create(&reading).Error; err != nil {
+			t.Fatalf("failed to store reading: %v", err)
+		}
+	}
+
+	readings, err := SessionSudsReadings(db, "session-1")
+	if err != nil {
+		t.Fatalf("SessionSudsReadings: %v", err)
+	}
+	var values []float64
+	for _, reading := range readings {
+		values = append(values, reading.Value)
+	}
+	if len(values) != 3 || values[0] != 7 || values[1] != 5 || values[2] != 3 {
+		t.Errorf("readings = %v, want session-1's [7 5 3]", values)
+	}
+}
+
+func TestSudsTrend(t *testing.T) {
+	series := func(values ...float64) []SudsReading {
+		readings := make([]SudsReading, len(values))
+		for i, v := range values {
+			readings[i] = SudsReading{Value: v}
+		}
+		return readings
+	}
+
+	for _, tc := range []struct {
+		name     string
+		readings []SudsReading
+		want     string
+	}{
+		{"no readings", nil, ""},
+		{"single reading", series(6), ""},
+		{"falling", series(7, 4), "decreasing"},
+		{"rising", series(2, 5), "increasing"},
+		{"unchanged", series(4, 4), "stable"},
+		{"first to last, ignoring the middle", series(7, 2, 9, 3), "decreasing"},
+		{"back to where it started", series(5, 1, 5), "stable"},
+		{"fractional change", series(3, 3.5), "increasing"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SudsTrend(tc.readings); got != tc.want {
+				t.Errorf("SudsTrend = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
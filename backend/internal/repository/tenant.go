@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tenant isolation: Client, Therapist and Session carry an organization_id.
+// Child rows (messages, field values, phase state) are reached through their
+// session, so scoping the session scopes them too.
+
+type organizationContextKey struct{}
+type systemScopeContextKey struct{}
+
+// ErrMissingOrganization is returned when a tenant-scoped model is queried from a
+// request context that carries no organization - a programming error, not a 404
+var ErrMissingOrganization = errors.New("tenant-scoped query without organization scope")
+
+// WithOrganization scopes every tenant-scoped query made with ctx to orgID
+func WithOrganization(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, organizationContextKey{}, orgID)
+}
+
+// OrganizationFromContext returns the organization ctx is scoped to, if any
+func OrganizationFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	orgID, _ := ctx.Value(organizationContextKey{}).(string)
+	return orgID
+}
+
+// WithSystemScope marks ctx as internal (migrations, WebSocket/MCP workers keyed
+// by an already-authorized session) so it bypasses organization scoping
+func WithSystemScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemScopeContextKey{}, true)
+}
+
+func isSystemScope(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	system, _ := ctx.Value(systemScopeContextKey{}).(bool)
+	return system
+}
+
+// Scoped returns a DB handle bound to a request context; tenant-scoped queries
+// through it are filtered to the context's organization
+func Scoped(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx)
+}
+
+// registerTenantCallbacks installs the organization scoping callbacks (multi-tenant mode only)
+func registerTenantCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant:scope_query", scopeToOrganization); err != nil {
+		return fmt.Errorf("failed to register tenant query callback: %w", err)
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:scope_row", scopeToOrganization); err != nil {
+		return fmt.Errorf("failed to register tenant row callback: %w", err)
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:scope_update", scopeToOrganization); err != nil {
+		return fmt.Errorf("failed to register tenant update callback: %w", err)
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:scope_delete", scopeToOrganization); err != nil {
+		return fmt.Errorf("failed to register tenant delete callback: %w", err)
+	}
+	if err := cb.Create().Before("gorm:create").Register("tenant:assign_create", assignOrganization); err != nil {
+		return fmt.Errorf("failed to register tenant create callback: %w", err)
+	}
+	return nil
+}
+
+// tenantOrganization resolves the organization for a statement on a tenant-scoped model.
+// ok is false when the statement doesn't need scoping.
+func tenantOrganization(db *gorm.DB) (orgID string, ok bool) {
+	if db.Statement.Schema == nil || db.Statement.Schema.LookUpField("organization_id") == nil {
+		return "", false
+	}
+	ctx := db.Statement.Context
+	if isSystemScope(ctx) {
+		return "", false
+	}
+	orgID = OrganizationFromContext(ctx)
+	if orgID == "" {
+		db.AddError(fmt.Errorf("%w: %s", ErrMissingOrganization, db.Statement.Schema.Table))
+		return "", false
+	}
+	return orgID, true
+}
+
+func scopeToOrganization(db *gorm.DB) {
+	orgID, ok := tenantOrganization(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: "organization_id"}, Value: orgID},
+	}})
+}
+
+func assignOrganization(db *gorm.DB) {
+	orgID, ok := tenantOrganization(db)
+	if !ok {
+		return
+	}
+	// Always overwrite so a caller can't create rows in another organization
+	db.Statement.SetColumn("organization_id", orgID)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"therapy-navigation-system/internal/logger"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestDB opens a throwaway SQLite database with the given models migrated and installs it
+// as DB for the duration of the test. It's file-backed so concurrent writers wait on the
+// lock instead of failing.
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	if logger.AppLogger == nil {
+		logger.AppLogger = logrus.New()
+		logger.AppLogger.SetLevel(logrus.WarnLevel)
+	}
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
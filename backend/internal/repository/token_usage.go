@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenPrice is a model's price in USD per million tokens
+type TokenPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// tokenPrices are configured from GEMINI_TOKEN_PRICES; without them usage isn't costed
+var tokenPrices = map[string]TokenPrice{}
+
+// SetTokenPrices configures model prices from "model=input/output,...", in USD per million
+// tokens. A model name also prices versions that extend it, e.g. "gemini-2.5-flash" covers
+// "gemini-2.5-flash-001".
+func SetTokenPrices(spec string) {
+	prices := make(map[string]TokenPrice)
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		rates := strings.SplitN(parts[1], "/", 2)
+		if len(rates) != 2 {
+			continue
+		}
+		input, inputErr := strconv.ParseFloat(strings.TrimSpace(rates[0]), 64)
+		output, outputErr := strconv.ParseFloat(strings.TrimSpace(rates[1]), 64)
+		if inputErr != nil || outputErr != nil || input < 0 || output < 0 {
+			continue
+		}
+		prices[strings.TrimSpace(parts[0])] = TokenPrice{Input: input, Output: output}
+	}
+	tokenPrices = prices
+}
+
+// modelPrice finds the price for a model, preferring the longest configured name it starts with
+func modelPrice(model string) (TokenPrice, bool) {
+	var best string
+	for name := range tokenPrices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return TokenPrice{}, false
+	}
+	return tokenPrices[best], true
+}
+
+// RecordTokenUsage adds one request's token counts to the session's totals for its agent
+// and model
+func RecordTokenUsage(db *gorm.DB, usage SessionTokenUsage) error {
+	now := time.Now()
+	usage.Requests = 1
+	usage.CreatedAt = now
+	usage.UpdatedAt = now
+
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "session_id"}, {Name: "agent_type"}, {Name: "model"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":         gorm.Expr("session_token_usages.requests + 1"),
+			"prompt_tokens":    gorm.Expr("session_token_usages.prompt_tokens + ?", usage.PromptTokens),
+			"candidate_tokens": gorm.Expr("session_token_usages.candidate_tokens + ?", usage.CandidateTokens),
+			"thoughts_tokens":  gorm.Expr("session_token_usages.thoughts_tokens + ?", usage.ThoughtsTokens),
+			"total_tokens":     gorm.Expr("session_token_usages.total_tokens + ?", usage.TotalTokens),
+			"updated_at":       now,
+		}),
+	}).Create(&usage).Error
+	if err != nil {
+		return fmt.Errorf("failed to record token usage: %w", err)
+	}
+	return nil
+}
+
+// SessionUsage is a session's token totals across agents and models, with an estimated cost
+// when every model used has a configured price
+type SessionUsage struct {
+	SessionID       string              `json:"session_id"`
+	Requests        int                 `json:"requests"`
+	PromptTokens    int64               `json:"prompt_tokens"`
+	CandidateTokens int64               `json:"candidate_tokens"`
+	ThoughtsTokens  int64               `json:"thoughts_tokens"`
+	TotalTokens     int64               `json:"total_tokens"`
+	CostUSD         *float64            `json:"cost_usd,omitempty"`
+	UnpricedModels  []string            `json:"unpriced_models,omitempty"`
+	Breakdown       []SessionTokenUsage `json:"breakdown"`
+}
+
+// SessionTokenUsageReport sums a session's recorded token usage
+func SessionTokenUsageReport(db *gorm.DB, sessionID string) (*SessionUsage, error) {
+	var rows []SessionTokenUsage
+	if err := db.Where("session_id = ?", sessionID).Order("agent_type, model").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load token usage: %w", err)
+	}
+
+	report := &SessionUsage{SessionID: sessionID, Breakdown: rows}
+	var cost float64
+	for i := range rows {
+		row := &rows[i]
+		report.Requests += row.Requests
+		report.PromptTokens += row.PromptTokens
+		report.CandidateTokens += row.CandidateTokens
+		report.ThoughtsTokens += row.ThoughtsTokens
+		report.TotalTokens += row.TotalTokens
+
+		price, ok := modelPrice(row.Model)
+		if !ok {
+			report.UnpricedModels = append(report.UnpricedModels, row.Model)
+			continue
+		}
+		// Thinking tokens are billed as output
+		row.CostUSD = (float64(row.PromptTokens)*price.Input + float64(row.CandidateTokens+row.ThoughtsTokens)*price.Output) / 1e6
+		cost += row.CostUSD
+	}
+	if len(rows) > 0 && len(report.UnpricedModels) == 0 {
+		report.CostUSD = &cost
+	}
+	return report, nil
+}
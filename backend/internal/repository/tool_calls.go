@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClaimToolCall records that a tool call is being applied. claimed is false when the call
+// was already claimed, in which case the existing record is returned. A claim still pending
+// after staleAfter is taken over, since whoever held it stopped before finishing.
+func ClaimToolCall(db *gorm.DB, id string, sessionID string, toolName string, staleAfter time.Duration) (existing *ToolCallRecord, claimed bool, err error) {
+	record := ToolCallRecord{
+		ID:        id,
+		SessionID: sessionID,
+		ToolName:  toolName,
+		Status:    "pending",
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to claim tool call %s: %w", id, result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, true, nil
+	}
+
+	// Only one retry can move a stale claim's timestamp forward
+	now := time.Now()
+	takeover := db.Model(&ToolCallRecord{}).
+		Where("id = ? AND session_id = ? AND tool_name = ? AND status = ? AND updated_at < ?",
+			id, sessionID, toolName, "pending", now.Add(-staleAfter)).
+		Update("updated_at", now)
+	if takeover.Error != nil {
+		return nil, false, fmt.Errorf("failed to take over tool call %s: %w", id, takeover.Error)
+	}
+	if takeover.RowsAffected == 1 {
+		return nil, true, nil
+	}
+
+	prior, err := FindToolCall(db, id, sessionID, toolName)
+	if err != nil {
+		return nil, false, err
+	}
+	return prior, false, nil
+}
+
+// FindToolCall loads a claimed tool call
+func FindToolCall(db *gorm.DB, id string, sessionID string, toolName string) (*ToolCallRecord, error) {
+	var record ToolCallRecord
+	if err := db.First(&record, "id = ? AND session_id = ? AND tool_name = ?", id, sessionID, toolName).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tool call %s: %w", id, err)
+	}
+	return &record, nil
+}
+
+// CompleteToolCall stores the result of an applied tool call
+func CompleteToolCall(db *gorm.DB, id string, sessionID string, toolName string, result interface{}) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode tool call result: %w", err)
+	}
+	return db.Model(&ToolCallRecord{}).
+		Where("id = ? AND session_id = ? AND tool_name = ?", id, sessionID, toolName).
+		Updates(map[string]interface{}{
+			"status":     "completed",
+			"result":     string(encoded),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// ReleaseToolCall removes the claim on a failed tool call so a retry can apply it
+func ReleaseToolCall(db *gorm.DB, id string, sessionID string, toolName string) error {
+	return db.Where("id = ? AND session_id = ? AND tool_name = ? AND status = ?", id, sessionID, toolName, "pending").
+		Delete(&ToolCallRecord{}).Error
+}
+
+// DecodeResult returns the stored result of a completed tool call
+func (r *ToolCallRecord) DecodeResult() (interface{}, error) {
+	var result interface{}
+	if r.Result == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(r.Result), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode tool call result: %w", err)
+	}
+	return result, nil
+}
+
+// PurgeToolCalls deletes tool call records older than the retention window
+func PurgeToolCalls(db *gorm.DB, retention time.Duration) error {
+	return db.Where("created_at < ?", time.Now().Add(-retention)).Delete(&ToolCallRecord{}).Error
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClaimToolCallKeysOnSessionAndTool(t *testing.T) {
+	db := newTestDB(t, &ToolCallRecord{})
+
+	for _, tc := range []struct {
+		sessionID, toolName string
+		wantClaimed         bool
+	}{
+		{"session-1", "collect_data", true},
+		{"session-2", "collect_data", true}, // Same model call ID in another session
+		{"session-1", "transition", true},   // Same ID for another tool
+		{"session-1", "collect_data", false},
+	} {
+		existing, claimed, err := ClaimToolCall(db, "call_0", tc.sessionID, tc.toolName, time.Minute)
+		if err != nil {
+			t.Fatalf("ClaimToolCall(%s, %s): %v", tc.sessionID, tc.toolName, err)
+		}
+		if claimed != tc.wantClaimed {
+			t.Errorf("ClaimToolCall(%s, %s) claimed = %t, want %t", tc.sessionID, tc.toolName, claimed, tc.wantClaimed)
+		}
+		if !claimed && (existing == nil || existing.SessionID != tc.sessionID || existing.ToolName != tc.toolName) {
+			t.Errorf("ClaimToolCall(%s, %s) returned %+v as the existing claim", tc.sessionID, tc.toolName, existing)
+		}
+	}
+
+	if err := CompleteToolCall(db, "call_0", "session-2", "collect_data", map[string]interface{}{"ok": true}); err != nil {
+		t.Fatalf("CompleteToolCall: %v", err)
+	}
+	first, err := FindToolCall(db, "call_0", "session-1", "collect_data")
+	if err != nil {
+		t.Fatalf("FindToolCall: %v", err)
+	}
+	if first.Status != "pending" {
+		t.Errorf("completing session-2's call changed session-1's to %s", first.Status)
+	}
+}
+
+func TestClaimToolCallTakesOverStalePendingClaims(t *testing.T) {
+	db := newTestDB(t, &ToolCallRecord{})
+
+	for _, id := range []string{"stale", "fresh", "done"} {
+		if _, claimed, err := ClaimToolCall(db, id, "session-1", "collect_data", time.Minute); err != nil || !claimed {
+			t.Fatalf("initial claim of %s: claimed=%t err=%v", id, claimed, err)
+		}
+	}
+	if err := CompleteToolCall(db, "done", "session-1", "collect_data", "result"); err != nil {
+		t.Fatalf("CompleteToolCall: %v", err)
+	}
+	old := time.Now().Add(-5 * time.Minute)
+	db.Model(&ToolCallRecord{}).Where("id IN ?", []string{"stale", "done"}).UpdateColumn("updated_at", old)
+
+	for id, want := range map[string]bool{"stale": true, "fresh": false, "done": false} {
+		_, claimed, err := ClaimToolCall(db, id, "session-1", "collect_data", time.Minute)
+		if err != nil {
+			t.Fatalf("retry of %s: %v", id, err)
+		}
+		if claimed != want {
+			t.Errorf("retry of %s claimed = %t, want %t", id, claimed, want)
+		}
+	}
+
+	// The takeover renews the claim, so a second retry waits on it
+	if _, claimed, _ := ClaimToolCall(db, "stale", "session-1", "collect_data", time.Minute); claimed {
+		t.Error("a taken-over claim was taken over again immediately")
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Transition conditions compare a session's collected field values with literals:
+//
+//	suds_current <= 0
+//	next_action == 'squeeze_hug'
+//	suds_current > 2 && next_action != 'complete'
+//
+// Operators are == != < <= > >=. A literal is a number, a 'single' or "double" quoted
+// string, or true/false. Clauses combine with && and ||, && binding tighter; there are no
+// parentheses, and quoted strings can't contain && or ||. Numbers compare numerically;
+// everything else compares as text, case-insensitively, where only == and != apply. An
+// empty condition always holds.
+
+// ErrInvalidCondition is returned for a condition that doesn't parse
+var ErrInvalidCondition = errors.New("invalid transition condition")
+
+// ErrUnknownConditionField is returned when a condition names a field the workflow doesn't define
+var ErrUnknownConditionField = errors.New("condition references an unknown field")
+
+// conditionClause is a single "field op literal" comparison
+type conditionClause struct {
+	field   string
+	op      string
+	literal interface{} // float64, string or bool
+}
+
+// TransitionCondition is a parsed condition: clauses OR-ed, each a list AND-ed
+type TransitionCondition struct {
+	expr  string
+	anyOf [][]conditionClause
+}
+
+var conditionClausePattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(==|!=|<=|>=|<|>)\s*(.+?)\s*$`)
+
+// ParseTransitionCondition parses a condition expression
+func ParseTransitionCondition(expr string) (*TransitionCondition, error) {
+	condition := &TransitionCondition{expr: strings.TrimSpace(expr)}
+	if condition.expr == "" {
+		return condition, nil
+	}
+
+	for _, alternative := range strings.Split(condition.expr, "||") {
+		var all []conditionClause
+		for _, part := range strings.Split(alternative, "&&") {
+			match := conditionClausePattern.FindStringSubmatch(part)
+			if match == nil {
+				return nil, fmt.Errorf("%w: %q is not a \"field op value\" comparison", ErrInvalidCondition, strings.TrimSpace(part))
+			}
+			literal, err := parseConditionLiteral(match[3])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+			}
+			if _, numeric := literal.(float64); !numeric && match[2] != "==" && match[2] != "!=" {
+				return nil, fmt.Errorf("%w: %s only compares numbers", ErrInvalidCondition, match[2])
+			}
+			all = append(all, conditionClause{field: match[1], op: match[2], literal: literal})
+		}
+		condition.anyOf = append(condition.anyOf, all)
+	}
+	return condition, nil
+}
+
+func parseConditionLiteral(raw string) (interface{}, error) {
+	if len(raw) >= 2 && (raw[0] == '\'' || raw[0] == '"') && raw[len(raw)-1] == raw[0] {
+		return raw[1 : len(raw)-1], nil
+	}
+	switch raw {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	number, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number, quoted string or boolean", raw)
+	}
+	return number, nil
+}
+
+// Fields lists the fields the condition reads
+func (c *TransitionCondition) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, all := range c.anyOf {
+		for _, clause := range all {
+			if !seen[clause.field] {
+				seen[clause.field] = true
+				fields = append(fields, clause.field)
+			}
+		}
+	}
+	return fields
+}
+
+// String returns the condition as written
+func (c *TransitionCondition) String() string {
+	return c.expr
+}
+
+// Evaluate reports whether the condition holds for the collected values. A clause on a field
+// that hasn't been collected is false.
+func (c *TransitionCondition) Evaluate(values map[string]interface{}) bool {
+	if len(c.anyOf) == 0 {
+		return true
+	}
+	for _, all := range c.anyOf {
+		holds := true
+		for _, clause := range all {
+			if !clause.holds(values) {
+				holds = false
+				break
+			}
+		}
+		if holds {
+			return true
+		}
+	}
+	return false
+}
+
+func (cl conditionClause) holds(values map[string]interface{}) bool {
+	value, collected := values[cl.field]
+	if !collected || value == nil {
+		return false
+	}
+
+	if want, numeric := cl.literal.(float64); numeric {
+		got, ok := conditionNumber(value)
+		if !ok {
+			return false
+		}
+		switch cl.op {
+		case "==":
+			return got == want
+		case "!=":
+			return got != want
+		case "<":
+			return got < want
+		case "<=":
+			return got <= want
+		case ">":
+			return got > want
+		case ">=":
+			return got >= want
+		}
+		return false
+	}
+
+	equal := strings.EqualFold(fmt.Sprint(value), fmt.Sprint(cl.literal))
+	if cl.op == "!=" {
+		return !equal
+	}
+	return equal
+}
+
+func conditionNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// CheckConditionFields returns ErrUnknownConditionField when the condition reads a field no
+// phase defines in PhaseData
+func CheckConditionFields(db *gorm.DB, condition *TransitionCondition) error {
+	fields := condition.Fields()
+	if len(fields) == 0 {
+		return nil
+	}
+	var known []string
+	if err := db.Model(&PhaseData{}).Where("name IN ?", fields).Distinct("name").Pluck("name", &known).Error; err != nil {
+		return fmt.Errorf("failed to check condition fields: %w", err)
+	}
+	defined := make(map[string]bool, len(known))
+	for _, name := range known {
+		defined[name] = true
+	}
+	for _, field := range fields {
+		if !defined[field] {
+			return fmt.Errorf("%w: %s (condition %q)", ErrUnknownConditionField, field, condition.expr)
+		}
+	}
+	return nil
+}
+
+// ValidateTransitionCondition parses a condition and checks its fields exist
+func ValidateTransitionCondition(db *gorm.DB, expr string) error {
+	condition, err := ParseTransitionCondition(expr)
+	if err != nil {
+		return err
+	}
+	return CheckConditionFields(db, condition)
+}
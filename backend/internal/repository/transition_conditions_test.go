@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTransitionConditionRejectsMalformedConditions(t *testing.T) {
+	for _, expr := range []string{
+		"suds_current",
+		"suds_current <= ",
+		"<= 2",
+		"suds_current => 2",
+		"suds_current <= two",
+		"next_action == squeeze_hug",
+		"next_action == 'squeeze_hug\"",
+		"next_action < 'squeeze_hug'",
+		"ready >= true",
+		"suds_current <= 2 &&",
+		"|| suds_current <= 2",
+	} {
+		if _, err := ParseTransitionCondition(expr); !errors.Is(err, ErrInvalidCondition) {
+			t.Errorf("ParseTransitionCondition(%q) error = %v, want ErrInvalidCondition", expr, err)
+		}
+	}
+}
+
+func TestTransitionConditionEvaluate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		expr   string
+		values map[string]interface{}
+		want   bool
+	}{
+		{"empty condition holds", "", nil, true},
+		{"blank condition holds", "   ", nil, true},
+
+		{"numeric less or equal", "suds_current <= 0", map[string]interface{}{"suds_current": 0.0}, true},
+		{"numeric greater", "suds_current > 2", map[string]interface{}{"suds_current": 2.0}, false},
+		{"numeric from stored text", "suds_current >= 3", map[string]interface{}{"suds_current": " 4 "}, true},
+		{"numeric equality ignores formatting", "suds_current == 3", map[string]interface{}{"suds_current": "3.0"}, true},
+		{"numeric against non-number", "suds_current < 5", map[string]interface{}{"suds_current": "high"}, false},
+		{"number literal against bool", "suds_current != 1", map[string]interface{}{"suds_current": true}, false},
+
+		{"single-quoted literal", "next_action == 'squeeze_hug'", map[string]interface{}{"next_action": "squeeze_hug"}, true},
+		{"double-quoted literal", `next_action == "squeeze_hug"`, map[string]interface{}{"next_action": "squeeze_hug"}, true},
+		{"text compares case-insensitively", "next_action == 'Squeeze_Hug'", map[string]interface{}{"next_action": "squeeze_HUG"}, true},
+		{"quoted number compares as text", "level == '3'", map[string]interface{}{"level": "3.0"}, false},
+		{"quoted literal keeps spaces", "feeling == 'a bit calmer'", map[string]interface{}{"feeling": "a bit calmer"}, true},
+		{"text inequality", "next_action != 'complete'", map[string]interface{}{"next_action": "squeeze_hug"}, true},
+		{"boolean literal", "ready == true", map[string]interface{}{"ready": true}, true},
+		{"boolean literal mismatch", "ready == false", map[string]interface{}{"ready": true}, false},
+
+		{"uncollected field is false", "suds_current <= 0", map[string]interface{}{}, false},
+		{"null field is false", "suds_current <= 0", map[string]interface{}{"suds_current": nil}, false},
+		{"uncollected field is false under !=", "next_action != 'complete'", map[string]interface{}{}, false},
+		{"uncollected field fails its && group", "suds_current > 2 && next_action == 'squeeze_hug'",
+			map[string]interface{}{"suds_current": 5.0}, false},
+
+		{"&& needs every clause", "suds_current > 2 && next_action != 'complete'",
+			map[string]interface{}{"suds_current": 5.0, "next_action": "complete"}, false},
+		{"|| needs one group", "suds_current <= 0 || next_action == 'complete'",
+			map[string]interface{}{"suds_current": 4.0, "next_action": "complete"}, true},
+		// a || b && c is a || (b && c), not (a || b) && c: true when only a holds
+		{"&& binds tighter than || (left)", "suds_current <= 0 || suds_current > 8 && next_action == 'complete'",
+			map[string]interface{}{"suds_current": 0.0, "next_action": "squeeze_hug"}, true},
+		// ... and false when only b holds
+		{"&& binds tighter than || (right)", "suds_current <= 0 || suds_current > 8 && next_action == 'complete'",
+			map[string]interface{}{"suds_current": 9.0, "next_action": "squeeze_hug"}, false},
+		// a && b || c holds on c alone
+		{"&& group then || alternative", "suds_current > 2 && next_action == 'squeeze_hug' || ready == true",
+			map[string]interface{}{"suds_current": 0.0, "ready": true}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			condition, err := ParseTransitionCondition(tc.expr)
+			if err != nil {
+				t.Fatalf("ParseTransitionCondition(%q): %v", tc.expr, err)
+			}
+			if got := condition.Evaluate(tc.values); got != tc.want {
+				t.Errorf("%q with %v = %t, want %t", tc.expr, tc.values, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTransitionConditionFields(t *testing.T) {
+	condition, err := ParseTransitionCondition("suds_current > 2 && next_action != 'complete' || suds_current <= 0")
+	if err != nil {
+		t.Fatalf("ParseTransitionCondition: %v", err)
+	}
+	fields := condition.Fields()
+	if len(fields) != 2 || fields[0] != "suds_current" || fields[1] != "next_action" {
+		t.Errorf("Fields() = %v, want [suds_current next_action]", fields)
+	}
+}
+
+func TestValidateTransitionConditionRequiresDefinedFields(t *testing.T) {
+	db := newTestDB(t, &Phase{}, &PhaseData{})
+	if err := db.Create(&Phase{ID: "status_check", DisplayName: "Status Check", Position: 1}).Error; err != nil {
+		t.Fatalf("failed to create phase: %v", err)
+	}
+	for _, field := range []PhaseData{
+		{ID: "status_check_suds_current", PhaseID: "status_check", Name: "suds_current"},
+		{ID: "status_check_next_action", PhaseID: "status_check", Name: "next_action"},
+	} {
+		if err := db.Create(&field).Error; err != nil {
+			t.Fatalf("failed to create phase data: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		expr string
+		want error
+	}{
+		{"", nil},
+		{"suds_current <= 0", nil},
+		{"suds_current > 2 && next_action == 'squeeze_hug'", nil},
+		{"suds_curent <= 0", ErrUnknownConditionField},
+		{"suds_current <= 0 || nxt_action == 'complete'", ErrUnknownConditionField},
+		{"suds_current <=", ErrInvalidCondition},
+	} {
+		err := ValidateTransitionCondition(db, tc.expr)
+		if tc.want == nil && err != nil {
+			t.Errorf("ValidateTransitionCondition(%q) = %v, want nil", tc.expr, err)
+		}
+		if tc.want != nil && !errors.Is(err, tc.want) {
+			t.Errorf("ValidateTransitionCondition(%q) = %v, want %v", tc.expr, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// maxPhaseTransitions is how many transitions a session may make since its last review
+// before it's treated as a runaway loop; 0 disables the guard
+var maxPhaseTransitions = 50
+
+// SetMaxPhaseTransitions configures the runaway-loop threshold
+func SetMaxPhaseTransitions(limit int) {
+	if limit >= 0 {
+		maxPhaseTransitions = limit
+	}
+}
+
+// MaxPhaseTransitions returns the configured runaway-loop threshold
+func MaxPhaseTransitions() int {
+	return maxPhaseTransitions
+}
+
+// CheckTransitionLoop holds a session's automatic transitions once it has transitioned more
+// than the threshold since its last review. held is true only for the call that placed
+// the hold, so the loop is reported once.
+func CheckTransitionLoop(db *gorm.DB, sessionID string) (held bool, count int, err error) {
+	var session Session
+	if err := db.Select("id", "phase_transition_count", "transition_count_reviewed", "auto_transitions_held").
+		First(&session, "id = ?", sessionID).Error; err != nil {
+		return false, 0, fmt.Errorf("session not found: %w", err)
+	}
+	count = session.PhaseTransitionCount
+	if maxPhaseTransitions == 0 || session.AutoTransitionsHeld || count-session.TransitionCountReviewed <= maxPhaseTransitions {
+		return false, count, nil
+	}
+
+	result := db.Model(&Session{}).
+		Where("id = ? AND auto_transitions_held = ?", sessionID, false).
+		Update("auto_transitions_held", true)
+	if result.Error != nil {
+		return false, count, fmt.Errorf("failed to hold transitions: %w", result.Error)
+	}
+	return result.RowsAffected == 1, count, nil
+}
+
+// ReviewTransitionLoop releases a held session and restarts the count from its current value
+func ReviewTransitionLoop(db *gorm.DB, sessionID string) (count int, err error) {
+	result := db.Model(&Session{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"auto_transitions_held":     false,
+		"transition_count_reviewed": gorm.Expr("phase_transition_count"),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to release transitions: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("session not found: %w", gorm.ErrRecordNotFound)
+	}
+
+	var session Session
+	if err := db.Select("phase_transition_count").First(&session, "id = ?", sessionID).Error; err != nil {
+		return 0, fmt.Errorf("session not found: %w", err)
+	}
+	return session.PhaseTransitionCount, nil
+}
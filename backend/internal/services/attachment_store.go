@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+
+	"therapy-navigation-system/internal/config"
+)
+
+// AttachmentStore holds session attachment files; the database only keeps their metadata
+type AttachmentStore interface {
+	// Backend names the store, recorded on each attachment so it can be read back
+	Backend() string
+	Put(ctx context.Context, key string, contentType string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewAttachmentStore creates the configured attachment store: "local" disk or "gcs"
+func NewAttachmentStore(cfg *config.Config) (AttachmentStore, error) {
+	switch cfg.AttachmentBackend {
+	case "local", "":
+		return NewLocalAttachmentStore(cfg.AttachmentDir)
+	case "gcs":
+		return NewGCSAttachmentStore(context.Background(), cfg.AttachmentBucket)
+	default:
+		return nil, fmt.Errorf("unknown attachment backend %q", cfg.AttachmentBackend)
+	}
+}
+
+// LocalAttachmentStore keeps attachments on local disk
+type LocalAttachmentStore struct {
+	dir string
+}
+
+// NewLocalAttachmentStore creates a store rooted at dir, creating it if needed
+func NewLocalAttachmentStore(dir string) (*LocalAttachmentStore, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	return &LocalAttachmentStore{dir: dir}, nil
+}
+
+// Backend returns "local"
+func (s *LocalAttachmentStore) Backend() string {
+	return "local"
+}
+
+// Put writes the attachment to disk
+func (s *LocalAttachmentStore) Put(ctx context.Context, key string, contentType string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment file: %w", err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return file.Close()
+}
+
+// Open reads the attachment from disk
+func (s *LocalAttachmentStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes the attachment from disk
+func (s *LocalAttachmentStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
+
+// path resolves key under the store directory, rejecting keys that escape it
+func (s *LocalAttachmentStore) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid attachment key %q", key)
+	}
+	return path, nil
+}
+
+// GCSAttachmentStore keeps attachments in a Google Cloud Storage bucket
+type GCSAttachmentStore struct {
+	bucket *storage.BucketHandle
+}
+
+// NewGCSAttachmentStore creates a store for bucket using Application Default Credentials
+func NewGCSAttachmentStore(ctx context.Context, bucket string) (*GCSAttachmentStore, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("ATTACHMENT_BUCKET is required for the gcs attachment backend")
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+	return &GCSAttachmentStore{bucket: client.Bucket(bucket)}, nil
+}
+
+// Backend returns "gcs"
+func (s *GCSAttachmentStore) Backend() string {
+	return "gcs"
+}
+
+// Put uploads the attachment to the bucket
+func (s *GCSAttachmentStore) Put(ctx context.Context, key string, contentType string, r io.Reader) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to upload attachment: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to upload attachment: %w", err)
+	}
+	return nil
+}
+
+// Open downloads the attachment from the bucket
+func (s *GCSAttachmentStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.bucket.Object(key).NewReader(ctx)
+}
+
+// Delete removes the attachment from the bucket
+func (s *GCSAttachmentStore) Delete(ctx context.Context, key string) error {
+	if err := s.bucket.Object(key).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	contextbuilder "therapy-navigation-system/internal/context"
 	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/genai"
 )
@@ -31,16 +32,67 @@ func NewCoachService(geminiService *GeminiService) *CoachService {
 type CoachResponse struct {
 	Message   string      `json:"message"`
 	ToolCalls []ToolCall  `json:"tool_calls,omitempty"`
+	Model     string      `json:"model"` // Model that generated the response
 }
 
 // ToolCall represents a function call the coach wants to make
 type ToolCall struct {
+	ID        string                 `json:"id"` // Stable across retries so a re-delivered call is applied once
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	// Correction is set when the arguments don't match the tool's declaration; the call
+	// must not be executed and the directive is returned to the model instead
+	Correction string `json:"correction,omitempty"`
+}
+
+// coachRequest is a prepared Gemini request for one coach turn
+type coachRequest struct {
+	sessionID    string
+	currentPhase string
+	model        string
+	startTime    time.Time
+	logContent   bool
+	allowedTools []*genai.FunctionDeclaration
+	contents     []*genai.Content
+	config       *genai.GenerateContentConfig
 }
 
 // GenerateResponse creates a therapeutic response using Context Builder and phase-specific prompts
 func (cs *CoachService) GenerateResponse(ctx context.Context, sessionID string, userMessage string, currentPhase string) (*CoachResponse, error) {
+	req, err := cs.prepareRequest(sessionID, userMessage, currentPhase)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] About to call Gemini GenerateContent")
+	
+	callStart := time.Now()
+	resp, err := cs.geminiService.generateContentWithRetry(
+		ctx,
+		"coach",
+		req.model,
+		req.contents,
+		req.config,
+	)
+	
+	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] Gemini GenerateContent completed")
+	if err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to generate coach response")
+		return nil, err
+	}
+
+	recordTokenUsage(sessionID, "coach", req.model, resp.UsageMetadata, time.Since(callStart))
+
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no response generated")
+	}
+
+	return cs.finishResponse(req, resp.Candidates[0].Content.Parts, resp.UsageMetadata), nil
+}
+
+// prepareRequest builds the prompt, tools and generation settings for a coach turn and
+// logs the prompt
+func (cs *CoachService) prepareRequest(sessionID string, userMessage string, currentPhase string) (*coachRequest, error) {
 	startTime := time.Now()
 	
 	// Use Context Builder for proper prompt construction (IMPLEMENTATION_PLAN.md)
@@ -76,53 +128,69 @@ func (cs *CoachService) GenerateResponse(ctx context.Context, sessionID string,
 		}(),
 	}).Info("[COACH_DEBUG] Checking Context Builder bundle")
 
+	// Full content is only logged for sessions with prompt logging enabled
+	logContent := logPromptContent(sessionID)
+
 	// [PROMPT_LOGGER] Log complete prompt (critical for iteration)
-	logger.AppLogger.WithFields(map[string]interface{}{
-		"session_id":         sessionID,
-		"current_phase":      currentPhase,
-		"constructed_prompt": bundle.ConstructedPrompt,
-		"user_message":       userMessage,
-		"prompt_length":      len(bundle.ConstructedPrompt),
-		"token_budget":       bundle.TokenReport,
-	}).Info("[PROMPT_LOGGER] === COMPLETE PROMPT TO GEMINI ===")
-	
+	if logContent {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"session_id":         sessionID,
+			"current_phase":      currentPhase,
+			"constructed_prompt": bundle.ConstructedPrompt,
+			"user_message":       userMessage,
+			"prompt_length":      len(bundle.ConstructedPrompt),
+			"token_budget":       bundle.TokenReport,
+		}).Info("[PROMPT_LOGGER] === COMPLETE PROMPT TO GEMINI ===")
+	}
+
+	// Assessment phases get short replies, rapport phases more room
+	modelConfig := sessionModelConfig(sessionID)
+	maxOutputTokens := phaseMaxOutputTokens(currentPhase, modelConfig)
+
+	// The phase picks the model; the context builder resolves its default
+	model := bundle.Model
+	if model == "" {
+		model = cs.geminiService.GetModelName()
+	}
+
 	// Simple raw prompt logging for analysis
-	if file, err := os.OpenFile("logs/prompts.jsonl", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		promptEntry := map[string]interface{}{
-			"timestamp":    time.Now(),
-			"session_id":   sessionID,
-			"turn_type":    "REQUEST",
-			"phase":        currentPhase,
-			"user_message": userMessage,
-			"prompt":       bundle.ConstructedPrompt,
-			"prompt_hash":  bundle.PromptHash,
-			"token_total":  bundle.TokenReport.Total,
-			// TODO: Add prompt version tracking - need to get versions from Context Builder
-		}
-		json.NewEncoder(file).Encode(promptEntry)
-		file.Close()
+	// TODO: Add prompt version tracking - need to get versions from Context Builder
+	promptEntry := repository.PromptLog{
+		SessionID:       sessionID,
+		AgentType:       "coach",
+		Model:           model,
+		TurnType:        repository.PromptTurnRequest,
+		Phase:           currentPhase,
+		PromptHash:      bundle.PromptHash,
+		Length:          len(bundle.ConstructedPrompt),
+		TokenCount:      bundle.TokenReport.Total,
+		MaxOutputTokens: maxOutputTokens,
+		ContentLogged:   logContent,
+	}
+	if logContent {
+		promptEntry.UserMessage = userMessage
+		promptEntry.Prompt = bundle.ConstructedPrompt
 	}
+	recordPromptLog(promptEntry)
 
 	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] Building final prompt string")
 
 	// Build final prompt combining context + user message
-	var finalPrompt string
+	var turnPrompt string
 	if userMessage == "" {
 		// Initial greeting - no patient message yet
-		finalPrompt = bundle.ConstructedPrompt + "\n\n[This is the beginning of a new session. Greet the patient warmly and ask how they're doing today.]\n\nCOACH:"
+		turnPrompt = "[This is the beginning of a new session. Greet the patient warmly and ask how they're doing today.]\n\nCOACH:"
 	} else {
 		// Normal conversation flow
-		finalPrompt = bundle.ConstructedPrompt + "\n\nPATIENT: " + userMessage + "\n\nCOACH:"
+		turnPrompt = "PATIENT: " + userMessage + "\n\nCOACH:"
 	}
+	contents := turnContents(bundle.ConstructedPrompt, turnPrompt, bundle.ToolExchanges)
 	
 	logger.AppLogger.WithFields(map[string]interface{}{
 		"session_id":     sessionID,
-		"final_prompt_length": len(finalPrompt),
+		"final_prompt_length": len(bundle.ConstructedPrompt) + len(turnPrompt),
+		"tool_exchanges": len(bundle.ToolExchanges),
 	}).Info("[COACH_DEBUG] Final prompt built successfully")
-	promptContent := &genai.Content{
-		Parts: []*genai.Part{{Text: finalPrompt}},
-		Role:  "user",
-	}
 
 	// Tools are provided by the context builder (no fallbacks, no DB queries)
 	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] Getting tools from context bundle")
@@ -137,41 +205,44 @@ func (cs *CoachService) GenerateResponse(ctx context.Context, sessionID string,
 		"tools_list":   bundle.Tools,
 	}).Info("[COACH_DEBUG] Tools loaded from context bundle, calling Gemini API")
 	
+	temperature := coachTemperature
+	if modelConfig.Temperature != nil {
+		temperature = *modelConfig.Temperature
+	}
+
 	// Generate response with proper Google function calling
 	cfg := &genai.GenerateContentConfig{
-		Tools:       []*genai.Tool{{FunctionDeclarations: allowedTools}},
-		Temperature: genai.Ptr(float32(0.7)), // Warm but focused
+		Tools:           []*genai.Tool{{FunctionDeclarations: allowedTools}},
+		Temperature:     genai.Ptr(temperature),
+		MaxOutputTokens: int32(maxOutputTokens),
+		Seed:            sessionCoachSeed(sessionID),
 		// Note: Go SDK doesn't have FunctionCallingConfig, but auto-transition will handle it
 	}
 
-	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] About to call Gemini GenerateContent")
-	
-	resp, err := cs.geminiService.GetClient().Models.GenerateContent(
-		ctx, 
-		"gemini-2.0-flash", 
-		[]*genai.Content{promptContent}, 
-		cfg,
-	)
-	
-	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] Gemini GenerateContent completed")
-	if err != nil {
-		logger.AppLogger.WithError(err).Error("Failed to generate coach response")
-		return nil, err
-	}
+	return &coachRequest{
+		sessionID:    sessionID,
+		currentPhase: currentPhase,
+		model:        model,
+		startTime:    startTime,
+		logContent:   logContent,
+		allowedTools: allowedTools,
+		contents:     contents,
+		config:       cfg,
+	}, nil
+}
 
-	responseTime := time.Since(startTime)
+// finishResponse turns the model's reply parts into a CoachResponse, checking function-call
+// arguments, and logs the response
+func (cs *CoachService) finishResponse(req *coachRequest, parts []*genai.Part, usage *genai.GenerateContentResponseUsageMetadata) *CoachResponse {
+	sessionID, currentPhase := req.sessionID, req.currentPhase
+	allowedTools, logContent := req.allowedTools, req.logContent
+	responseTime := time.Since(req.startTime)
 
-	if len(resp.Candidates) == 0 {
-		return nil, fmt.Errorf("no response generated")
-	}
-
-	candidate := resp.Candidates[0]
-	
 	// Parse response using Google's proper function calling format
 	var responseText string
 	var toolCalls []ToolCall
 
-	for _, part := range candidate.Content.Parts {
+	for _, part := range parts {
 		if part.FunctionCall != nil {
 			// Function call detected
 			funcCall := part.FunctionCall
@@ -179,10 +250,37 @@ func (cs *CoachService) GenerateResponse(ctx context.Context, sessionID string,
 			for k, v := range funcCall.Args {
 				args[k] = v
 			}
-			toolCalls = append(toolCalls, ToolCall{
+			callID := funcCall.ID
+			if callID == "" {
+				callID = uuid.New().String()
+			}
+			call := ToolCall{
+				ID:        callID,
 				Name:      funcCall.Name,
 				Arguments: args,
-			})
+			}
+
+			// Check the arguments against the declaration the model was given
+			if toolArgValidation != ToolArgValidationOff {
+				var decl *genai.FunctionDeclaration
+				for _, tool := range allowedTools {
+					if tool.Name == funcCall.Name {
+						decl = tool
+						break
+					}
+				}
+				if problems := checkToolArgs(decl, sessionID, args); len(problems) > 0 {
+					logger.AppLogger.WithFields(logrus.Fields{
+						"function_name": funcCall.Name,
+						"session_id":    sessionID,
+						"problems":      problems,
+					}).Warn("⚠️ Function call arguments don't match the tool declaration")
+					if toolArgValidation == ToolArgValidationEnforce {
+						call.Correction = toolArgCorrection(decl, funcCall.Name, problems)
+					}
+				}
+			}
+			toolCalls = append(toolCalls, call)
 			
 			logger.AppLogger.WithFields(logrus.Fields{
 				"function_name": funcCall.Name,
@@ -195,34 +293,53 @@ func (cs *CoachService) GenerateResponse(ctx context.Context, sessionID string,
 	}
 
 	// [PROMPT_LOGGER] Log complete response (critical for iteration)
-	logger.AppLogger.WithFields(logrus.Fields{
-		"session_id":        sessionID,
-		"current_phase":     currentPhase,
-		"response_text":     responseText,
-		"tool_calls_count":  len(toolCalls),
-		"response_time_ms":  responseTime.Milliseconds(),
-		"response_length":   len(responseText),
-	}).Info("[PROMPT_LOGGER] === COMPLETE RESPONSE FROM GEMINI ===")
-	
-	// Log raw response to same prompt log file
-	if file, err := os.OpenFile("logs/prompts.jsonl", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		responseEntry := map[string]interface{}{
-			"timestamp":      time.Now(),
-			"session_id":     sessionID,
-			"turn_type":      "RESPONSE", 
-			"phase":          currentPhase,
-			"response_text":  responseText,
-			"function_calls": toolCalls,
+	if logContent {
+		logger.AppLogger.WithFields(logrus.Fields{
+			"session_id":       sessionID,
+			"current_phase":    currentPhase,
+			"response_text":    responseText,
+			"tool_calls_count": len(toolCalls),
 			"response_time_ms": responseTime.Milliseconds(),
+			"response_length":  len(responseText),
+		}).Info("[PROMPT_LOGGER] === COMPLETE RESPONSE FROM GEMINI ===")
+	}
+
+	// Log raw response alongside its prompt
+	responseEntry := repository.PromptLog{
+		SessionID:      sessionID,
+		AgentType:      "coach",
+		Model:          req.model,
+		TurnType:       repository.PromptTurnResponse,
+		Phase:          currentPhase,
+		ResponseHash:   contentHash(responseText),
+		Length:         len(responseText),
+		ToolCallsCount: len(toolCalls),
+		ResponseTimeMs: responseTime.Milliseconds(),
+		ContentLogged:  logContent,
+	}
+	if usage != nil {
+		responseEntry.TokenCount = int(usage.CandidatesTokenCount)
+	}
+	var functionCalls interface{} = toolCalls
+	if logContent {
+		responseEntry.Response = responseText
+	} else {
+		toolNames := make([]string, len(toolCalls))
+		for i, tc := range toolCalls {
+			toolNames[i] = tc.Name
 		}
-		json.NewEncoder(file).Encode(responseEntry)
-		file.Close()
+		functionCalls = toolNames
+	}
+	if callsJSON, err := json.Marshal(functionCalls); err == nil {
+		responseEntry.FunctionCalls = string(callsJSON)
 	}
+	recordPromptLog(responseEntry)
 
 	return &CoachResponse{
 		Message:   responseText,
 		ToolCalls: toolCalls,
-	}, nil
+		Model:     req.model,
+	}
 }
 
 // parseToolsFromBundle converts tool strings from context builder into Gemini function declarations
@@ -263,7 +380,8 @@ func (cs *CoachService) extractToolName(toolString string) string {
 	return toolString
 }
 
-// getToolDeclaration returns the Gemini function declaration for a tool
+// getToolDeclaration returns the Gemini function declaration for a tool. Every tool the
+// context builder advertises (contextbuilder coachTools) needs a declaration here.
 func (cs *CoachService) getToolDeclaration(toolName string, sessionID string) *genai.FunctionDeclaration {
 	// Handle our single universal MCP tool
 	if toolName == "collect_structured_data" {
@@ -287,6 +405,27 @@ func (cs *CoachService) getToolDeclaration(toolName string, sessionID string) *g
 		}
 	}
 
+	if toolName == "record_suds" {
+		return &genai.FunctionDeclaration{
+			Name:        "record_suds",
+			Description: "Record the SUDS rating (0-10) the client just gave. Every reading is kept as the session's distress trend. Only record a rating the client actually stated.",
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"session_id": {
+						Type:        genai.TypeString,
+						Description: "The session ID",
+					},
+					"value": {
+						Type:        genai.TypeNumber,
+						Description: "The client's SUDS rating, from 0 (no distress) to 10 (worst imaginable)",
+					},
+				},
+				Required: []string{"session_id", "value"},
+			},
+		}
+	}
+
 	// Unknown tool
 	return nil
 }
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+)
+
+// sessionCoachSeed returns the session's fixed sampling seed, or nil to leave generation
+// unseeded. Test sessions set one so the full conversation flow, including function
+// calls, is reproducible.
+func sessionCoachSeed(sessionID string) *int32 {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var session repository.Session
+	if err := db.Select("id", "coach_seed").First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil
+	}
+	if session.CoachSeed != nil {
+		logger.AppLogger.WithField("session_id", sessionID).WithField("seed", *session.CoachSeed).Debug("Using fixed coach seed")
+	}
+	return session.CoachSeed
+}
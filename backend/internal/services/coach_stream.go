@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+
+	"google.golang.org/genai"
+)
+
+// GenerateResponseStream is GenerateResponse using Gemini's streaming API: onText is called
+// with each piece of reply text as it arrives. Function calls are buffered and returned
+// with the assembled text once the stream completes, so they only run on a finished reply.
+func (cs *CoachService) GenerateResponseStream(ctx context.Context, sessionID string, userMessage string, currentPhase string, onText func(text string)) (*CoachResponse, error) {
+	req, err := cs.prepareRequest(sessionID, userMessage, currentPhase)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.AppLogger.WithField("session_id", sessionID).Info("[COACH_DEBUG] About to call Gemini GenerateContentStream")
+
+	callStart := time.Now()
+	parts, usage, err := cs.geminiService.streamContentWithRetry(ctx, "coach", req.model, req.contents, req.config, onText)
+	if err != nil {
+		logger.AppLogger.WithError(err).Error("Failed to stream coach response")
+		return nil, err
+	}
+	recordTokenUsage(sessionID, "coach", req.model, usage, time.Since(callStart))
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no response generated")
+	}
+
+	return cs.finishResponse(req, parts, usage), nil
+}
+
+// streamContentWithRetry streams a response, passing text parts to onText and returning all
+// parts, with the usage metadata of the final chunk, once the stream ends. Retryable failures are retried like generateContentWithRetry,
+// but only until text has been emitted - a half-delivered reply can't be taken back.
+func (s *GeminiService) streamContentWithRetry(ctx context.Context, agentType string, model string, contents []*genai.Content, cfg *genai.GenerateContentConfig, onText func(text string)) ([]*genai.Part, *genai.GenerateContentResponseUsageMetadata, error) {
+	delay := geminiRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		var parts []*genai.Part
+		var usage *genai.GenerateContentResponseUsageMetadata
+		emitted := false
+		var streamErr error
+
+		for resp, err := range s.client.Models.GenerateContentStream(ctx, model, contents, cfg) {
+			if err != nil {
+				streamErr = err
+				break
+			}
+			if resp.UsageMetadata != nil {
+				usage = resp.UsageMetadata
+			}
+			if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+				continue
+			}
+			for _, part := range resp.Candidates[0].Content.Parts {
+				parts = append(parts, part)
+				if part.FunctionCall == nil && part.Text != "" && onText != nil {
+					onText(part.Text)
+					emitted = true
+				}
+			}
+		}
+		if streamErr == nil {
+			return parts, usage, nil
+		}
+
+		reason, retryable := geminiRetryReason(ctx, streamErr)
+		if emitted || !retryable || attempt >= geminiMaxAttempts {
+			if retryable && !emitted && updateGeminiRetryCallback != nil {
+				updateGeminiRetryCallback(agentType, "exhausted")
+			}
+			return nil, nil, streamErr
+		}
+
+		if updateGeminiRetryCallback != nil {
+			updateGeminiRetryCallback(agentType, reason)
+		}
+		logger.AppLogger.WithError(streamErr).WithFields(map[string]interface{}{
+			"agent_type": agentType,
+			"attempt":    attempt,
+			"reason":     reason,
+			"delay_ms":   delay.Milliseconds(),
+		}).Warn("🔁 Gemini stream failed before any output, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, streamErr
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
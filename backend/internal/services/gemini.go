@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"therapy-navigation-system/internal/config"
 	"therapy-navigation-system/internal/repository"
+	"therapy-navigation-system/internal/tokenizer"
 	"time"
 
 	"google.golang.org/genai"
@@ -74,7 +75,14 @@ func (s *GeminiService) GenerateStructuredResponse(ctx context.Context, prompt s
 	}
 
 	// Using Pro for therapy - better reasoning and context handling
-	resp, err := s.client.Models.GenerateContent(ctx, "gemini-2.0-pro", []*genai.Content{content}, nil)
+	var cfg *genai.GenerateContentConfig
+	if responseSchema != nil {
+		cfg = &genai.GenerateContentConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   responseSchema,
+		}
+	}
+	resp, err := s.generateContentWithRetry(ctx, "structured", "gemini-2.0-pro", []*genai.Content{content}, cfg)
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -92,11 +100,14 @@ func (s *GeminiService) GenerateStructuredResponse(ctx context.Context, prompt s
 		return "", fmt.Errorf("no response generated")
 	}
 
-	// Calculate approximate token count (rough estimate: 4 chars per token)
+	// Token count for metrics, estimated when the response has no usage metadata
 	responseText := resp.Candidates[0].Content.Parts[0].Text
-	promptTokens := len(prompt) / 4
-	responseTokens := len(responseText) / 4
-	totalTokens := promptTokens + responseTokens
+	var totalTokens int
+	if resp.UsageMetadata != nil {
+		totalTokens = int(resp.UsageMetadata.TotalTokenCount)
+	} else {
+		totalTokens = tokenizer.CountTokens(prompt) + tokenizer.CountTokens(responseText)
+	}
 
 	// Report metrics
 	if updateGeminiMetricsCallback != nil {
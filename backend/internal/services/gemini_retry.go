@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+
+	"google.golang.org/genai"
+)
+
+// Gemini calls are retried on these statuses: rate limiting and transient server errors
+var retryableGeminiStatus = map[int]bool{
+	408: true,
+	429: true,
+	500: true,
+	502: true,
+	503: true,
+	504: true,
+}
+
+var (
+	geminiMaxAttempts    = 3
+	geminiRetryBaseDelay = 500 * time.Millisecond
+)
+
+// SetGeminiRetry configures retries of failed Gemini calls: attempts includes the first
+// call, and the delay before retry n is baseDelay*2^(n-1). 1 attempt disables retries.
+func SetGeminiRetry(attempts int, baseDelay time.Duration) {
+	if attempts > 0 {
+		geminiMaxAttempts = attempts
+	}
+	if baseDelay > 0 {
+		geminiRetryBaseDelay = baseDelay
+	}
+}
+
+// geminiRetryReason returns why a failed call is worth retrying, or false when it isn't.
+// A cancelled or expired request context is never retried.
+func geminiRetryReason(ctx context.Context, err error) (string, bool) {
+	if ctx.Err() != nil {
+		return "", false
+	}
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return strconv.Itoa(apiErr.Code), retryableGeminiStatus[apiErr.Code]
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout", true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout", true
+	}
+	return "", false
+}
+
+// generateContentWithRetry calls GenerateContent, retrying retryable failures with
+// exponential backoff. Waits end early when ctx is done.
+func (s *GeminiService) generateContentWithRetry(ctx context.Context, agentType string, model string, contents []*genai.Content, cfg *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	delay := geminiRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := s.client.Models.GenerateContent(ctx, model, contents, cfg)
+		if err == nil {
+			return resp, nil
+		}
+
+		reason, retryable := geminiRetryReason(ctx, err)
+		if !retryable || attempt >= geminiMaxAttempts {
+			if retryable && updateGeminiRetryCallback != nil {
+				updateGeminiRetryCallback(agentType, "exhausted")
+			}
+			return nil, err
+		}
+
+		if updateGeminiRetryCallback != nil {
+			updateGeminiRetryCallback(agentType, reason)
+		}
+		logger.AppLogger.WithError(err).WithFields(map[string]interface{}{
+			"agent_type": agentType,
+			"attempt":    attempt,
+			"reason":     reason,
+			"delay_ms":   delay.Milliseconds(),
+		}).Warn("🔁 Gemini call failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
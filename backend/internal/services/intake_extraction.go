@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+
+	"google.golang.org/genai"
+)
+
+// ExtractIntake reads a session's transcript for the given intake fields and returns the
+// values the client stated, keyed by field name. Fields the transcript doesn't answer are
+// left out; nothing is stored.
+func (s *GeminiService) ExtractIntake(ctx context.Context, sessionID string, fields []repository.IntakeFieldCompletion) (map[string]string, error) {
+	if len(fields) == 0 {
+		return map[string]string{}, nil
+	}
+
+	var messages []repository.Message
+	if err := repository.Scoped(ctx).
+		Where("session_id = ? AND message_type NOT IN ?", sessionID, []string{"tool_call", "tool_result"}).
+		Order("created_at ASC").
+		Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transcript: %w", err)
+	}
+	var transcript strings.Builder
+	for _, msg := range messages {
+		if strings.TrimSpace(msg.Content) != "" {
+			fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, msg.Content)
+		}
+	}
+	if transcript.Len() == 0 {
+		return map[string]string{}, nil
+	}
+
+	schema := &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{}}
+	fieldLines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		label := field.Label
+		if label == "" {
+			label = field.FieldName
+		}
+		schema.Properties[field.FieldName] = &genai.Schema{Type: genai.TypeString, Description: label}
+		fieldLines = append(fieldLines, fmt.Sprintf("- %s: %s", field.FieldName, label))
+	}
+
+	prompt := fmt.Sprintf(`You are reviewing a therapy intake conversation to fill in intake fields that were not recorded during the session.
+
+FIELDS:
+%s
+
+TRANSCRIPT:
+%s
+Fill in a field only with what the client actually said, in their words where possible. Leave out any field the transcript doesn't answer.`,
+		strings.Join(fieldLines, "\n"), transcript.String())
+
+	response, err := s.GenerateStructuredResponse(ctx, prompt, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var extracted map[string]string
+	if err := json.Unmarshal([]byte(response), &extracted); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted intake: %w", err)
+	}
+
+	values := make(map[string]string, len(extracted))
+	for _, field := range fields {
+		if value := strings.TrimSpace(extracted[field.FieldName]); value != "" {
+			values[field.FieldName] = value
+		}
+	}
+	return values, nil
+}
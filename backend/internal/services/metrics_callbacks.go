@@ -6,13 +6,16 @@ import "time"
 var (
 	updateGeminiMetricsCallback   func(agentType string, tokens int, duration time.Duration)
 	updateChromaDBMetricsCallback func()
+	updateGeminiRetryCallback     func(agentType string, reason string)
 )
 
 // SetMetricsCallbacks sets the callback functions for updating metrics
 func SetMetricsCallbacks(
 	geminiMetrics func(agentType string, tokens int, duration time.Duration),
 	chromaDBMetrics func(),
+	geminiRetryMetrics func(agentType string, reason string),
 ) {
 	updateGeminiMetricsCallback = geminiMetrics
 	updateChromaDBMetricsCallback = chromaDBMetrics
+	updateGeminiRetryCallback = geminiRetryMetrics
 }
\ No newline at end of file
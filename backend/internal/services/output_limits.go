@@ -0,0 +1,42 @@
+package services
+
+import (
+	"therapy-navigation-system/internal/repository"
+)
+
+// defaultMaxOutputTokens caps coach replies in phases without their own limit
+var defaultMaxOutputTokens = 500
+
+// SetDefaultMaxOutputTokens configures the fallback output limit
+func SetDefaultMaxOutputTokens(tokens int) {
+	if tokens > 0 {
+		defaultMaxOutputTokens = tokens
+	}
+}
+
+// phaseMaxOutputTokens returns the phase's output limit, then the session template's, or
+// the default when neither is set
+func phaseMaxOutputTokens(phaseID string, modelConfig repository.SessionModelConfig) int {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var phase repository.Phase
+	if err := db.Select("id", "max_output_tokens").First(&phase, "id = ?", phaseID).Error; err == nil && phase.MaxOutputTokens > 0 {
+		return phase.MaxOutputTokens
+	}
+	if modelConfig.MaxTokens > 0 {
+		return modelConfig.MaxTokens
+	}
+	return defaultMaxOutputTokens
+}
+
+// coachTemperature is the sampling temperature for coach replies unless the session's
+// template sets one
+const coachTemperature = float32(0.7) // Warm but focused
+
+// sessionModelConfig loads the model overrides from the session's template
+func sessionModelConfig(sessionID string) repository.SessionModelConfig {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+	return repository.LoadSessionModelConfig(db, sessionID)
+}
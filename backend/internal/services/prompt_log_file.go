@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+)
+
+// PromptLogPath is the optional JSONL debug log of prompts sent to and responses from the model.
+// When it would exceed the configured size it's rotated to PromptLogPath+".1", so at most
+// two files' worth of history is kept on disk.
+const PromptLogPath = "logs/prompts.jsonl"
+
+var (
+	promptLogMutex    sync.Mutex
+	promptLogMaxBytes int64 = 100 << 20
+	promptLogFile     bool
+)
+
+// SetPromptLogFile also mirrors prompt log rows to PromptLogPath, for local debugging
+func SetPromptLogFile(enabled bool) {
+	promptLogMutex.Lock()
+	defer promptLogMutex.Unlock()
+	promptLogFile = enabled
+}
+
+// recordPromptLog stores one prompt log row, and appends it to the JSONL file when enabled.
+// A failed write is logged rather than failing the coach turn.
+func recordPromptLog(entry repository.PromptLog) {
+	if repository.DB != nil {
+		if err := repository.RecordPromptLog(repository.DB, &entry); err != nil {
+			logger.AppLogger.WithError(err).WithField("session_id", entry.SessionID).Warn("⚠️ Failed to store prompt log")
+		}
+	}
+
+	promptLogMutex.Lock()
+	toFile := promptLogFile
+	promptLogMutex.Unlock()
+	if toFile {
+		appendPromptLog(entry.Entry())
+	}
+}
+
+// SetPromptLogMaxSize sets the size at which the prompt log rotates; 0 disables rotation
+func SetPromptLogMaxSize(bytes int64) {
+	promptLogMutex.Lock()
+	defer promptLogMutex.Unlock()
+	promptLogMaxBytes = bytes
+}
+
+// appendPromptLog writes one entry to the prompt log, rotating it first if the entry
+// would push it past the size limit
+func appendPromptLog(entry map[string]interface{}) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	promptLogMutex.Lock()
+	defer promptLogMutex.Unlock()
+
+	if promptLogMaxBytes > 0 {
+		if info, err := os.Stat(PromptLogPath); err == nil && info.Size()+int64(len(line)) > promptLogMaxBytes {
+			if err := os.Rename(PromptLogPath, PromptLogPath+".1"); err != nil {
+				logger.AppLogger.WithError(err).Warn("⚠️ Failed to rotate prompt log")
+			} else {
+				logger.AppLogger.WithField("size_bytes", info.Size()).Info("🔄 Rotated prompt log")
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(PromptLogPath), 0755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(PromptLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.Write(line)
+}
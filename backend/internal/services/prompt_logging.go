@@ -0,0 +1,66 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+// Full prompt/response content is privacy-sensitive, so it's only written to the prompt log
+// when enabled globally or for specific sessions. Otherwise only metadata is logged.
+var (
+	promptLoggingMutex    sync.RWMutex
+	promptContentLogging  bool
+	promptLoggingSessions = make(map[string]bool)
+)
+
+// SetPromptContentLogging enables or disables full prompt/response logging for all sessions
+func SetPromptContentLogging(enabled bool) {
+	promptLoggingMutex.Lock()
+	defer promptLoggingMutex.Unlock()
+	promptContentLogging = enabled
+}
+
+// SetSessionPromptLogging enables or disables full prompt/response logging for one session
+func SetSessionPromptLogging(sessionID string, enabled bool) {
+	promptLoggingMutex.Lock()
+	defer promptLoggingMutex.Unlock()
+	if enabled {
+		promptLoggingSessions[sessionID] = true
+	} else {
+		delete(promptLoggingSessions, sessionID)
+	}
+}
+
+// PromptContentLogging reports whether full content logging is on globally
+func PromptContentLogging() bool {
+	promptLoggingMutex.RLock()
+	defer promptLoggingMutex.RUnlock()
+	return promptContentLogging
+}
+
+// PromptLoggingSessions returns the sessions with content logging enabled individually
+func PromptLoggingSessions() []string {
+	promptLoggingMutex.RLock()
+	defer promptLoggingMutex.RUnlock()
+	sessions := make([]string, 0, len(promptLoggingSessions))
+	for sessionID := range promptLoggingSessions {
+		sessions = append(sessions, sessionID)
+	}
+	sort.Strings(sessions)
+	return sessions
+}
+
+// logPromptContent reports whether a session's prompts and responses are logged in full
+func logPromptContent(sessionID string) bool {
+	promptLoggingMutex.RLock()
+	defer promptLoggingMutex.RUnlock()
+	return promptContentLogging || promptLoggingSessions[sessionID]
+}
+
+// contentHash identifies logged content without storing it
+func contentHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
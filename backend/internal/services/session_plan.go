@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+
+	"google.golang.org/genai"
+	"gorm.io/gorm"
+)
+
+// planMaxDurationFactor caps a planned phase duration at this multiple of the phase's own
+const planMaxDurationFactor = 3
+
+// planHistorySessions is how many of the client's earlier sessions inform the plan
+const planHistorySessions = 3
+
+var (
+	sessionPlanMutex     sync.RWMutex
+	sessionPlanProtocols = map[string]bool{}
+)
+
+// SetSessionPlanProtocols configures which protocols offer a session plan, from a
+// comma-separated list of protocol names
+func SetSessionPlanProtocols(raw string) {
+	protocols := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			protocols[name] = true
+		}
+	}
+
+	sessionPlanMutex.Lock()
+	defer sessionPlanMutex.Unlock()
+	sessionPlanProtocols = protocols
+}
+
+// SessionPlanEnabled reports whether sessions running protocol get a session plan
+func SessionPlanEnabled(protocol string) bool {
+	sessionPlanMutex.RLock()
+	defer sessionPlanMutex.RUnlock()
+	return sessionPlanProtocols[protocol]
+}
+
+// sessionPlanSchema is the structured output the model fills in
+var sessionPlanSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"summary": {Type: genai.TypeString, Description: "Two or three sentences on what this session should work on and why"},
+		"focus_areas": {
+			Type:  genai.TypeArray,
+			Items: &genai.Schema{Type: genai.TypeString},
+		},
+		"phases": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"phase_id":         {Type: genai.TypeString},
+					"duration_seconds": {Type: genai.TypeInteger, Description: "Planned time for timed phases; 0 to keep the default"},
+					"focus":            {Type: genai.TypeString},
+				},
+				Required: []string{"phase_id", "duration_seconds"},
+			},
+		},
+	},
+	Required: []string{"summary", "focus_areas", "phases"},
+}
+
+// GenerateSessionPlan proposes a plan for a session that hasn't started yet, from the
+// client's intake so far and their earlier sessions. The plan is stored on the session as
+// proposed; it takes effect once the therapist approves it.
+func (s *GeminiService) GenerateSessionPlan(ctx context.Context, sessionID string) (*repository.SessionPlan, error) {
+	db := repository.Scoped(ctx)
+
+	var session repository.Session
+	if err := db.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	var allPhases []repository.Phase
+	if err := db.Order("position ASC").Find(&allPhases).Error; err != nil {
+		return nil, fmt.Errorf("failed to load phases: %w", err)
+	}
+	phases := make(map[string]repository.Phase, len(allPhases))
+	var phaseLines []string
+	for _, phase := range allPhases {
+		if !session.FeatureEnabled(phase.FeatureFlag) {
+			continue
+		}
+		phases[phase.ID] = phase
+		line := fmt.Sprintf("- %s (%s): %s", phase.ID, phase.DisplayName, phase.Description)
+		if phase.DurationSeconds > 0 {
+			line += fmt.Sprintf(" [timed, default %ds]", phase.DurationSeconds)
+		}
+		phaseLines = append(phaseLines, line)
+	}
+
+	history, err := clientHistory(db, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	// The plan is written for the protocol the session's template runs
+	protocol := repository.SessionProtocol(db, &session)
+
+	prompt := fmt.Sprintf(`You are preparing a %s session plan for the therapist to review before the session begins.
+
+PHASES (in order):
+%s
+
+CLIENT INTAKE AND HISTORY:
+%s
+
+Propose a plan: a short summary, the focus areas for this session, and for each phase its focus and planned duration. Only change durations of timed phases, and only when the client's history suggests it (e.g. more processing time for a heavy issue); use 0 to keep the default. Use the phase IDs exactly as listed.`,
+		protocol, strings.Join(phaseLines, "\n"), history)
+
+	response, err := s.GenerateStructuredResponse(ctx, prompt, sessionPlanSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan repository.SessionPlan
+	if err := json.Unmarshal([]byte(response), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse session plan: %w", err)
+	}
+
+	// Keep only phases that exist for this session, with durations the timer can honor
+	planned := plan.Phases[:0]
+	for _, p := range plan.Phases {
+		phase, ok := phases[p.PhaseID]
+		if !ok {
+			continue
+		}
+		if phase.DurationSeconds <= 0 || p.DurationSeconds < 0 {
+			p.DurationSeconds = 0
+		} else if limit := phase.DurationSeconds * planMaxDurationFactor; p.DurationSeconds > limit {
+			p.DurationSeconds = limit
+		}
+		planned = append(planned, p)
+	}
+	plan.Phases = planned
+	plan.Status = repository.PlanProposed
+	plan.GeneratedAt = time.Now()
+	plan.ReviewedAt = nil
+
+	if err := repository.SaveSessionPlan(db, sessionID, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// clientHistory describes what's known about the client for planning: this session's
+// collected fields, their goals, and fields from their most recent earlier sessions
+func clientHistory(db *gorm.DB, session *repository.Session) (string, error) {
+	var b strings.Builder
+
+	writeFields := func(sessionID string) error {
+		var values []repository.SessionFieldValue
+		if err := db.Where("session_id = ?", sessionID).Order("created_at ASC").Find(&values).Error; err != nil {
+			return fmt.Errorf("failed to load session fields: %w", err)
+		}
+		for _, v := range values {
+			fmt.Fprintf(&b, "  %s: %s\n", v.FieldName, repository.FieldValueText(v))
+		}
+		return nil
+	}
+
+	b.WriteString("This session's intake:\n")
+	if err := writeFields(session.ID); err != nil {
+		return "", err
+	}
+
+	var goals []repository.SessionGoal
+	if err := db.Where("client_id = ?", session.ClientID).Order("created_at DESC").Find(&goals).Error; err != nil {
+		return "", fmt.Errorf("failed to load client goals: %w", err)
+	}
+	if len(goals) > 0 {
+		b.WriteString("Goals:\n")
+		for _, goal := range goals {
+			fmt.Fprintf(&b, "  %s (%s)\n", goal.Statement, goal.Progress)
+		}
+	}
+
+	var earlier []repository.Session
+	if err := db.Select("id", "start_time").
+		Where("client_id = ? AND id <> ? AND status = ?", session.ClientID, session.ID, repository.SessionStatusCompleted).
+		Order("start_time DESC").Limit(planHistorySessions).
+		Find(&earlier).Error; err != nil {
+		return "", fmt.Errorf("failed to load earlier sessions: %w", err)
+	}
+	for _, prior := range earlier {
+		fmt.Fprintf(&b, "Session on %s:\n", prior.StartTime.Format("2006-01-02"))
+		if err := writeFields(prior.ID); err != nil {
+			return "", err
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"time"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"google.golang.org/genai"
+)
+
+// tokenUsageAudit stores per-session token totals; configured from TOKEN_USAGE_AUDIT
+var tokenUsageAudit = true
+
+// SetTokenUsageAudit enables or disables storing per-session token totals. Token metrics
+// are reported either way.
+func SetTokenUsageAudit(enabled bool) {
+	tokenUsageAudit = enabled
+}
+
+// recordTokenUsage reports a Gemini call's token counts from its usage metadata to the
+// token metrics and, when auditing, adds them to the session's totals
+func recordTokenUsage(sessionID string, agentType string, model string, usage *genai.GenerateContentResponseUsageMetadata, duration time.Duration) {
+	if usage == nil {
+		logger.AppLogger.WithField("session_id", sessionID).Debug("Gemini response carried no usage metadata")
+		if updateGeminiMetricsCallback != nil {
+			updateGeminiMetricsCallback(agentType, 0, duration)
+		}
+		return
+	}
+
+	if updateGeminiMetricsCallback != nil {
+		updateGeminiMetricsCallback(agentType, int(usage.TotalTokenCount), duration)
+	}
+	if !tokenUsageAudit || sessionID == "" || repository.DB == nil {
+		return
+	}
+
+	if err := repository.RecordTokenUsage(repository.DB, repository.SessionTokenUsage{
+		SessionID:       sessionID,
+		AgentType:       agentType,
+		Model:           model,
+		PromptTokens:    int64(usage.PromptTokenCount),
+		CandidateTokens: int64(usage.CandidatesTokenCount),
+		ThoughtsTokens:  int64(usage.ThoughtsTokenCount),
+		TotalTokens:     int64(usage.TotalTokenCount),
+	}); err != nil {
+		logger.AppLogger.WithError(err).WithField("session_id", sessionID).Warn("⚠️ Failed to record token usage")
+	}
+}
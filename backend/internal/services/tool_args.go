@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"therapy-navigation-system/internal/logger"
+
+	"google.golang.org/genai"
+)
+
+// Model-supplied function-call arguments are checked against the tool's declaration
+// before the call is executed
+const (
+	ToolArgValidationOff     = "off"     // Pass arguments through unchecked
+	ToolArgValidationWarn    = "warn"    // Log problems but still execute
+	ToolArgValidationEnforce = "enforce" // Don't execute; return a correction directive instead
+)
+
+var toolArgValidation = ToolArgValidationEnforce
+
+// SetToolArgValidation configures how malformed function-call arguments are handled
+func SetToolArgValidation(mode string) {
+	switch mode {
+	case ToolArgValidationOff, ToolArgValidationWarn, ToolArgValidationEnforce:
+		toolArgValidation = mode
+	}
+}
+
+// checkToolArgs validates args against the declaration's required parameters and types.
+// A missing session_id is filled in with the known session rather than reported.
+// Returns the problems found, empty when the arguments are usable.
+func checkToolArgs(decl *genai.FunctionDeclaration, sessionID string, args map[string]interface{}) []string {
+	if decl == nil {
+		return []string{"tool is not available in this phase"}
+	}
+	if decl.Parameters == nil {
+		return nil
+	}
+
+	var problems []string
+	for _, name := range decl.Parameters.Required {
+		if value, ok := args[name]; ok && value != nil {
+			continue
+		}
+		if name == "session_id" && sessionID != "" {
+			args[name] = sessionID
+			logger.AppLogger.WithFields(map[string]interface{}{
+				"session_id": sessionID,
+				"tool":       decl.Name,
+			}).Warn("⚠️ Function call omitted session_id - using the current session")
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("missing required argument %q", name))
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, declared := decl.Parameters.Properties[name]
+		if !declared || prop == nil || args[name] == nil {
+			continue
+		}
+		if !matchesSchemaType(prop.Type, args[name]) {
+			problems = append(problems, fmt.Sprintf("argument %q must be %s, got %T", name, strings.ToLower(string(prop.Type)), args[name]))
+		}
+	}
+	return problems
+}
+
+// matchesSchemaType reports whether a decoded JSON value has the declared type
+func matchesSchemaType(t genai.Type, value interface{}) bool {
+	switch t {
+	case genai.TypeString:
+		_, ok := value.(string)
+		return ok
+	case genai.TypeNumber:
+		switch value.(type) {
+		case float64, float32, int, int32, int64:
+			return true
+		}
+		return false
+	case genai.TypeInteger:
+		// Decoded JSON numbers are all float64; an integer has no fractional part
+		switch v := value.(type) {
+		case int, int32, int64:
+			return true
+		case float64:
+			return v == math.Trunc(v)
+		case float32:
+			return v == float32(math.Trunc(float64(v)))
+		}
+		return false
+	case genai.TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case genai.TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case genai.TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return true
+}
+
+// toolArgCorrection is the directive returned to the model for a rejected call
+func toolArgCorrection(decl *genai.FunctionDeclaration, name string, problems []string) string {
+	var required []string
+	if decl != nil && decl.Parameters != nil {
+		required = decl.Parameters.Required
+	}
+	return fmt.Sprintf("The %s call was not executed: %s. Call it again with arguments matching its declaration (required: %s).",
+		name, strings.Join(problems, "; "), strings.Join(required, ", "))
+}
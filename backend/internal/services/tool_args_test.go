@@ -0,0 +1,156 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"therapy-navigation-system/internal/logger"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genai"
+)
+
+func TestCheckToolArgs(t *testing.T) {
+	if logger.AppLogger == nil {
+		logger.AppLogger = logrus.New()
+		logger.AppLogger.SetLevel(logrus.ErrorLevel)
+	}
+
+	decl := &genai.FunctionDeclaration{
+		Name: "record_suds",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"session_id": {Type: genai.TypeString},
+				"value":      {Type: genai.TypeInteger},
+				"confidence": {Type: genai.TypeNumber},
+				"notes":      {Type: genai.TypeString},
+				"confirmed":  {Type: genai.TypeBoolean},
+			},
+			Required: []string{"session_id", "value"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name         string
+		decl         *genai.FunctionDeclaration
+		sessionID    string
+		args         map[string]interface{}
+		wantProblems []string
+		wantArgs     map[string]interface{}
+	}{
+		{
+			name:      "valid",
+			decl:      decl,
+			sessionID: "session-1",
+			args:      map[string]interface{}{"session_id": "session-1", "value": float64(6), "confidence": 0.8},
+		},
+		{
+			name:         "missing required argument",
+			decl:         decl,
+			sessionID:    "session-1",
+			args:         map[string]interface{}{"session_id": "session-1"},
+			wantProblems: []string{`missing required argument "value"`},
+		},
+		{
+			name:         "null required argument",
+			decl:         decl,
+			sessionID:    "session-1",
+			args:         map[string]interface{}{"session_id": "session-1", "value": nil},
+			wantProblems: []string{`missing required argument "value"`},
+		},
+		{
+			name:      "session_id filled in from the session",
+			decl:      decl,
+			sessionID: "session-1",
+			args:      map[string]interface{}{"value": float64(6)},
+			wantArgs:  map[string]interface{}{"session_id": "session-1", "value": float64(6)},
+		},
+		{
+			name:         "session_id missing with no known session",
+			decl:         decl,
+			args:         map[string]interface{}{"value": float64(6)},
+			wantProblems: []string{`missing required argument "session_id"`},
+		},
+		{
+			name:      "wrong types, reported in argument order",
+			decl:      decl,
+			sessionID: "session-1",
+			args:      map[string]interface{}{"session_id": "session-1", "value": "six", "confirmed": "yes"},
+			wantProblems: []string{
+				`argument "confirmed" must be boolean, got string`,
+				`argument "value" must be integer, got string`,
+			},
+		},
+		{
+			name:         "fractional value for an integer",
+			decl:         decl,
+			sessionID:    "session-1",
+			args:         map[string]interface{}{"session_id": "session-1", "value": 6.5},
+			wantProblems: []string{`argument "value" must be integer, got float64`},
+		},
+		{
+			name:      "whole float for an integer, fraction for a number",
+			decl:      decl,
+			sessionID: "session-1",
+			args:      map[string]interface{}{"session_id": "session-1", "value": float64(6), "confidence": 0.5},
+		},
+		{
+			name:      "undeclared argument is ignored",
+			decl:      decl,
+			sessionID: "session-1",
+			args:      map[string]interface{}{"session_id": "session-1", "value": float64(6), "mood": 3},
+		},
+		{
+			name:         "undeclared tool",
+			sessionID:    "session-1",
+			args:         map[string]interface{}{"session_id": "session-1"},
+			wantProblems: []string{"tool is not available in this phase"},
+		},
+		{
+			name:      "tool without parameters",
+			decl:      &genai.FunctionDeclaration{Name: "get_phase"},
+			sessionID: "session-1",
+			args:      map[string]interface{}{"anything": 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			problems := checkToolArgs(tc.decl, tc.sessionID, tc.args)
+			if !reflect.DeepEqual(problems, tc.wantProblems) {
+				t.Errorf("problems = %q, want %q", problems, tc.wantProblems)
+			}
+			if tc.wantArgs != nil && !reflect.DeepEqual(tc.args, tc.wantArgs) {
+				t.Errorf("args = %v, want %v", tc.args, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestMatchesSchemaType(t *testing.T) {
+	for _, tc := range []struct {
+		schemaType genai.Type
+		value      interface{}
+		want       bool
+	}{
+		{genai.TypeInteger, float64(3), true},
+		{genai.TypeInteger, 3.25, false},
+		{genai.TypeInteger, float32(2), true},
+		{genai.TypeInteger, float32(2.5), false},
+		{genai.TypeInteger, int64(3), true},
+		{genai.TypeInteger, "3", false},
+		{genai.TypeNumber, 3.25, true},
+		{genai.TypeNumber, 3, true},
+		{genai.TypeNumber, "3.25", false},
+		{genai.TypeString, "text", true},
+		{genai.TypeString, 1.0, false},
+		{genai.TypeBoolean, false, true},
+		{genai.TypeObject, map[string]interface{}{}, true},
+		{genai.TypeObject, []interface{}{}, false},
+		{genai.TypeArray, []interface{}{"a"}, true},
+		{genai.TypeUnspecified, "anything", true},
+	} {
+		if got := matchesSchemaType(tc.schemaType, tc.value); got != tc.want {
+			t.Errorf("matchesSchemaType(%s, %#v) = %t, want %t", tc.schemaType, tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	contextbuilder "therapy-navigation-system/internal/context"
+
+	"google.golang.org/genai"
+)
+
+// functionRole is the role of the turn answering the model's function calls
+const functionRole genai.Role = "function"
+
+// turnContents builds a coach turn's contents. Without previous tool calls it is one user
+// turn; with them the context comes first, then the model's function calls, their responses
+// in a function turn, and the patient's message in a final user turn.
+func turnContents(constructedPrompt string, turnPrompt string, exchanges []contextbuilder.ToolExchange) []*genai.Content {
+	if len(exchanges) == 0 {
+		return []*genai.Content{
+			genai.NewContentFromText(constructedPrompt+"\n\n"+turnPrompt, genai.RoleUser),
+		}
+	}
+
+	calls := make([]*genai.Part, 0, len(exchanges))
+	responses := make([]*genai.Part, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		calls = append(calls, genai.NewPartFromFunctionCall(exchange.Name, exchange.Arguments))
+		responses = append(responses, genai.NewPartFromFunctionResponse(exchange.Name, exchange.Response))
+	}
+
+	return []*genai.Content{
+		genai.NewContentFromText(constructedPrompt, genai.RoleUser),
+		genai.NewContentFromParts(calls, genai.RoleModel),
+		genai.NewContentFromParts(responses, functionRole),
+		genai.NewContentFromText(contextbuilder.ToolResultsDirective+"\n\n"+turnPrompt, genai.RoleUser),
+	}
+}
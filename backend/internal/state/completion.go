@@ -0,0 +1,87 @@
+package state
+
+import (
+	"fmt"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// CompletionReadiness reports whether CompleteSession would succeed now, and if not, why
+type CompletionReadiness struct {
+	SessionID       string   `json:"session_id"`
+	Ready           bool     `json:"ready"`
+	CurrentPhase    string   `json:"current_phase"`
+	TerminalPhase   string   `json:"terminal_phase"`
+	InTerminalPhase bool     `json:"in_terminal_phase"`
+	Status          string   `json:"status"`
+	MissingFields   []string `json:"missing_fields"` // Required terminal-phase fields not yet collected
+	Blockers        []string `json:"blockers"`       // Every reason completion would be rejected
+}
+
+// CompletionReadiness runs CompleteSession's checks without completing the session. Missing
+// fields are those of the terminal phase the session is in or, before it gets there, the
+// first terminal phase of the workflow.
+func (m *Machine) CompletionReadiness() (*CompletionReadiness, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var session repository.Session
+	if err := db.Select("id", "phase", "status").First(&session, "id = ?", m.sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	readiness := &CompletionReadiness{
+		SessionID:       m.sessionID,
+		CurrentPhase:    session.Phase,
+		TerminalPhase:   session.Phase,
+		InTerminalPhase: m.IsTerminalPhase(session.Phase),
+		Status:          session.Status,
+		MissingFields:   []string{},
+		Blockers:        []string{},
+	}
+
+	if !readiness.InTerminalPhase {
+		var terminal repository.Phase
+		if err := db.Select("id").Where("is_terminal = ?", true).Order("position ASC").First(&terminal).Error; err != nil {
+			return nil, fmt.Errorf("no terminal phase configured: %w", err)
+		}
+		readiness.TerminalPhase = terminal.ID
+		readiness.Blockers = append(readiness.Blockers,
+			fmt.Sprintf("not in a terminal phase (currently in %s)", session.Phase))
+	}
+
+	missing, err := m.GetMissingFields(readiness.TerminalPhase)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		readiness.MissingFields = missing
+		readiness.Blockers = append(readiness.Blockers,
+			fmt.Sprintf("missing required data for phase %s: %v", readiness.TerminalPhase, missing))
+	}
+
+	// Turns and time are counted in the current phase, so they only gate a session that's
+	// already in its terminal phase
+	if readiness.InTerminalPhase {
+		if err := m.validateMinimumTurns(session.Phase); err != nil {
+			readiness.Blockers = append(readiness.Blockers, err.Error())
+		}
+		if err := m.validateMinimumDuration(session.Phase); err != nil {
+			readiness.Blockers = append(readiness.Blockers, err.Error())
+		}
+	}
+
+	// CompleteSession moves a scheduled session through active, so only a status that can
+	// never reach completed blocks it
+	from := session.Status
+	if from == repository.SessionStatusScheduled {
+		from = repository.SessionStatusActive
+	}
+	if repository.StatusChangeRejected(from, repository.SessionStatusCompleted) {
+		readiness.Blockers = append(readiness.Blockers,
+			fmt.Sprintf("session is %s and can't be completed", session.Status))
+	}
+
+	readiness.Ready = len(readiness.Blockers) == 0
+	return readiness, nil
+}
@@ -0,0 +1,117 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// ErrTransitionConditionNotMet is returned when no transition condition holds for the
+// session's collected data
+var ErrTransitionConditionNotMet = errors.New("transition condition not met")
+
+// ConditionalTransition resolves "next" for a phase whose transitions carry conditions: the
+// highest-priority active transition whose condition holds for the collected data (a
+// transition without a condition always holds). ok is false when none of the phase's
+// transitions have a condition, leaving "next" to phase order.
+func (m *Machine) ConditionalTransition(fromPhase string) (transition *repository.PhaseTransition, ok bool, err error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var transitions []repository.PhaseTransition
+	if err := db.Where("from_phase_id = ? AND is_active = ?", fromPhase, true).
+		Order("priority DESC, to_phase_id ASC").Find(&transitions).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to load transitions from %s: %w", fromPhase, err)
+	}
+	conditional := false
+	for _, t := range transitions {
+		if strings.TrimSpace(t.Condition) != "" {
+			conditional = true
+			break
+		}
+	}
+	if !conditional {
+		return nil, false, nil
+	}
+
+	values, err := m.collectedValues()
+	if err != nil {
+		return nil, true, err
+	}
+	var tried []string
+	for i := range transitions {
+		holds, err := evaluateTransitionCondition(transitions[i], values)
+		if err != nil {
+			return nil, true, err
+		}
+		if holds {
+			return &transitions[i], true, nil
+		}
+		tried = append(tried, fmt.Sprintf("%s (%s)", transitions[i].ToPhaseID, transitions[i].Condition))
+	}
+	return nil, true, fmt.Errorf("%w: no transition from %s applies to the collected data; conditions: %s",
+		ErrTransitionConditionNotMet, fromPhase, strings.Join(tried, ", "))
+}
+
+// CheckTransitionCondition returns ErrTransitionConditionNotMet when the transition from one
+// phase to another has a condition the collected data doesn't satisfy
+func (m *Machine) CheckTransitionCondition(fromPhase, toPhase string) error {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var transition repository.PhaseTransition
+	if err := db.Where("from_phase_id = ? AND to_phase_id = ?", fromPhase, toPhase).First(&transition).Error; err != nil {
+		// No row: IsValidTransition decides, e.g. the always-allowed move to a terminal phase
+		return nil
+	}
+	if strings.TrimSpace(transition.Condition) == "" {
+		return nil
+	}
+
+	values, err := m.collectedValues()
+	if err != nil {
+		return err
+	}
+	holds, err := evaluateTransitionCondition(transition, values)
+	if err != nil {
+		return err
+	}
+	if !holds {
+		return fmt.Errorf("%w: %s -> %s requires %s", ErrTransitionConditionNotMet, fromPhase, toPhase, transition.Condition)
+	}
+	return nil
+}
+
+// collectedValues returns the session's collected field values, decoded
+func (m *Machine) collectedValues() (map[string]interface{}, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var fieldValues []repository.SessionFieldValue
+	if err := db.Where("session_id = ?", m.sessionID).Find(&fieldValues).Error; err != nil {
+		return nil, fmt.Errorf("failed to load collected fields: %w", err)
+	}
+	values, _ := repository.ParsedFieldValues(fieldValues)
+	return values, nil
+}
+
+// evaluateTransitionCondition parses and evaluates a transition's condition. Fields no phase
+// defines are an error rather than quietly false, so a typo in a condition is visible.
+func evaluateTransitionCondition(transition repository.PhaseTransition, values map[string]interface{}) (bool, error) {
+	condition, err := repository.ParseTransitionCondition(transition.Condition)
+	if err != nil {
+		return false, fmt.Errorf("transition %s -> %s: %w", transition.FromPhaseID, transition.ToPhaseID, err)
+	}
+	for _, field := range condition.Fields() {
+		if _, collected := values[field]; collected {
+			continue
+		}
+		if err := repository.CheckConditionFields(repository.DB, condition); err != nil {
+			return false, fmt.Errorf("transition %s -> %s: %w", transition.FromPhaseID, transition.ToPhaseID, err)
+		}
+		break
+	}
+	return condition.Evaluate(values), nil
+}
@@ -0,0 +1,128 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"therapy-navigation-system/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// seedConditionalTransitions stores a status check branching on suds_current and next_action
+func seedConditionalTransitions(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &repository.Phase{}, &repository.PhaseData{}, &repository.PhaseTransition{}, &repository.SessionFieldValue{})
+	for i, id := range []string{"body_scan", "eye_position", "focused_mindfulness", StatusCheckPhase, "squeeze_hug", "positive_installation"} {
+		if err := db.Create(&repository.Phase{ID: id, DisplayName: id, Position: i + 1}).Error; err != nil {
+			t.Fatalf("failed to create phase %s: %v", id, err)
+		}
+	}
+	for _, field := range []string{"suds_current", "next_action"} {
+		if err := db.Create(&repository.PhaseData{ID: "status_check_" + field, PhaseID: StatusCheckPhase, Name: field}).Error; err != nil {
+			t.Fatalf("failed to create phase data: %v", err)
+		}
+	}
+	for _, transition := range []repository.PhaseTransition{
+		{ID: "to_positive", ToPhaseID: "positive_installation", Condition: "suds_current <= 0", Priority: 10},
+		{ID: "to_squeeze", ToPhaseID: "squeeze_hug", Condition: "next_action == 'squeeze_hug'"},
+		{ID: "to_mindfulness", ToPhaseID: "focused_mindfulness", Condition: "next_action == 'focused_mindfulness'"},
+	} {
+		transition.FromPhaseID = StatusCheckPhase
+		transition.IsActive = true
+		if err := db.Create(&transition).Error; err != nil {
+			t.Fatalf("failed to create transition: %v", err)
+		}
+	}
+	return db
+}
+
+func collectTestField(t *testing.T, db *gorm.DB, sessionID, name, value, fieldType string) {
+	t.Helper()
+	if err := db.Create(&repository.SessionFieldValue{
+		SessionID: sessionID, PhaseID: StatusCheckPhase, FieldName: name, FieldValue: value, FieldType: fieldType,
+	}).Error; err != nil {
+		t.Fatalf("failed to store %s: %v", name, err)
+	}
+}
+
+func TestConditionalTransitionPicksTheHighestPriorityThatHolds(t *testing.T) {
+	db := seedConditionalTransitions(t)
+
+	for _, tc := range []struct {
+		name      string
+		sessionID string
+		fields    map[string]string
+		want      string
+	}{
+		{"next_action decides", "s1", map[string]string{"next_action": `"squeeze_hug"`}, "squeeze_hug"},
+		{"higher priority wins", "s2", map[string]string{"suds_current": "0", "next_action": `"squeeze_hug"`}, "positive_installation"},
+		{"numeric field stored as text", "s3", map[string]string{"suds_current": `"0"`}, "positive_installation"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, value := range tc.fields {
+				collectTestField(t, db, tc.sessionID, name, value, "string")
+			}
+			transition, ok, err := New(tc.sessionID).ConditionalTransition(StatusCheckPhase)
+			if !ok || err != nil {
+				t.Fatalf("ConditionalTransition: ok = %t, err = %v", ok, err)
+			}
+			if transition.ToPhaseID != tc.want {
+				t.Errorf("resolved to %s, want %s", transition.ToPhaseID, tc.want)
+			}
+		})
+	}
+}
+
+func TestConditionalTransitionReportsUnmetConditions(t *testing.T) {
+	db := seedConditionalTransitions(t)
+	collectTestField(t, db, "s1", "suds_current", "4", "integer")
+
+	_, ok, err := New("s1").ConditionalTransition(StatusCheckPhase)
+	if !ok || !errors.Is(err, ErrTransitionConditionNotMet) {
+		t.Errorf("ConditionalTransition: ok = %t, err = %v, want ErrTransitionConditionNotMet", ok, err)
+	}
+}
+
+func TestConditionalTransitionRejectsUnknownFields(t *testing.T) {
+	db := seedConditionalTransitions(t)
+	if err := db.Model(&repository.PhaseTransition{}).Where("id = ?", "to_positive").
+		Update("condition", "suds_curent <= 0").Error; err != nil {
+		t.Fatalf("failed to update condition: %v", err)
+	}
+
+	_, ok, err := New("s1").ConditionalTransition(StatusCheckPhase)
+	if !ok || !errors.Is(err, repository.ErrUnknownConditionField) {
+		t.Errorf("ConditionalTransition: ok = %t, err = %v, want ErrUnknownConditionField", ok, err)
+	}
+}
+
+func TestConditionalTransitionLeavesUnconditionedPhasesToPhaseOrder(t *testing.T) {
+	db := seedConditionalTransitions(t)
+	if err := db.Create(&repository.PhaseTransition{
+		ID: "body_to_eye", FromPhaseID: "body_scan", ToPhaseID: "eye_position", IsActive: true,
+	}).Error; err != nil {
+		t.Fatalf("failed to create transition: %v", err)
+	}
+
+	transition, ok, err := New("s1").ConditionalTransition("body_scan")
+	if ok || transition != nil || err != nil {
+		t.Errorf("ConditionalTransition(body_scan) = %+v, %t, %v; want nothing", transition, ok, err)
+	}
+}
+
+func TestCheckTransitionCondition(t *testing.T) {
+	db := seedConditionalTransitions(t)
+	collectTestField(t, db, "s1", "next_action", `"squeeze_hug"`, "string")
+	machine := New("s1")
+
+	if err := machine.CheckTransitionCondition(StatusCheckPhase, "squeeze_hug"); err != nil {
+		t.Errorf("transition whose condition holds: %v", err)
+	}
+	if err := machine.CheckTransitionCondition(StatusCheckPhase, "focused_mindfulness"); !errors.Is(err, ErrTransitionConditionNotMet) {
+		t.Errorf("transition whose condition fails: %v, want ErrTransitionConditionNotMet", err)
+	}
+	if err := machine.CheckTransitionCondition(StatusCheckPhase, "complete"); err != nil {
+		t.Errorf("transition without a row: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// Status-check decision criteria from the protocol's status_check_loop, mapped onto the
+// DB workflow's branches:
+//   - suds_equals_0                                 -> positive_installation
+//   - suds_above_0_after_20min (or rising)          -> squeeze_hug (de-escalation)
+//   - suds_decreasing_or_stable_and_time_remaining  -> focused_mindfulness
+const (
+	StatusCheckPhase = "status_check"
+
+	DecisionModeOff     = "off"     // No recommendation or validation
+	DecisionModeAdvise  = "advise"  // Recommend and flag mismatched next_action values
+	DecisionModeEnforce = "enforce" // Reject next_action values that contradict the recommendation
+)
+
+// statusDecisionMode controls how the recommendation is applied to the model's next_action
+var statusDecisionMode = DecisionModeAdvise
+
+// processingLimit is the total focused processing time before residual activation is de-escalated
+var processingLimit = 20 * time.Minute
+
+// processingPhases accumulate toward the processing limit
+var processingPhases = map[string]bool{"focused_mindfulness": true, StatusCheckPhase: true}
+
+// SetStatusDecisionPolicy configures how the status-check recommendation is applied
+func SetStatusDecisionPolicy(mode string, limit time.Duration) {
+	switch mode {
+	case DecisionModeOff, DecisionModeAdvise, DecisionModeEnforce:
+		statusDecisionMode = mode
+	}
+	if limit > 0 {
+		processingLimit = limit
+	}
+}
+
+// StatusDecisionMode returns the configured decision mode
+func StatusDecisionMode() string {
+	return statusDecisionMode
+}
+
+// StatusDecision is the server-side recommended next_action for the status-check loop
+type StatusDecision struct {
+	Action            string   `json:"action"`
+	Criterion         string   `json:"criterion"`
+	Reason            string   `json:"reason"`
+	CurrentSUDS       float64  `json:"current_suds"`
+	PreviousSUDS      *float64 `json:"previous_suds,omitempty"`
+	ProcessingMinutes float64  `json:"processing_minutes"`
+}
+
+// EvaluateStatusDecision applies the decision criteria to a SUDS trajectory and processing time
+func EvaluateStatusDecision(current float64, previous *float64, processing time.Duration) StatusDecision {
+	decision := StatusDecision{
+		CurrentSUDS:       current,
+		PreviousSUDS:      previous,
+		ProcessingMinutes: processing.Minutes(),
+	}
+	rising := previous != nil && current > *previous
+
+	switch {
+	case current <= 0:
+		decision.Action = "positive_installation"
+		decision.Criterion = "suds_equals_0"
+		decision.Reason = "No activation left"
+	case processing >= processingLimit:
+		decision.Action = "squeeze_hug"
+		decision.Criterion = "suds_above_0_after_20min"
+		decision.Reason = fmt.Sprintf("SUDS %.0f after %.0f minutes of processing", current, processing.Minutes())
+	case rising:
+		decision.Action = "squeeze_hug"
+		decision.Criterion = "suds_increasing"
+		decision.Reason = fmt.Sprintf("SUDS rose from %.0f to %.0f", *previous, current)
+	default:
+		decision.Action = "focused_mindfulness"
+		decision.Criterion = "suds_decreasing_or_stable_and_time_remaining"
+		decision.Reason = fmt.Sprintf("SUDS %.0f with %.0f minutes of processing remaining", current, (processingLimit - processing).Minutes())
+	}
+	return decision
+}
+
+// RecommendStatusAction evaluates the decision criteria for the session. pendingSUDS is a
+// reading supplied with the current tool call that is not stored yet. Returns nil when no
+// current SUDS reading is available.
+func (m *Machine) RecommendStatusAction(pendingSUDS *float64) (*StatusDecision, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var session repository.Session
+	if err := db.Select("id", "phase", "phase_history", "phase_start_time").First(&session, "id = ?", m.sessionID).Error; err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	// Field values are overwritten, so the trajectory comes from the reading history
+	stored, err := repository.SessionSudsReadings(db, m.sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// Newest first: the pending reading, then the stored ones from the latest back
+	var readings []float64
+	if pendingSUDS != nil {
+		readings = append(readings, *pendingSUDS)
+	}
+	for i := len(stored) - 1; i >= 0 && len(readings) < 2; i-- {
+		readings = append(readings, stored[i].Value)
+	}
+	if len(readings) == 0 {
+		return nil, nil
+	}
+
+	var previous *float64
+	if len(readings) > 1 {
+		previous = &readings[1]
+	}
+	decision := EvaluateStatusDecision(readings[0], previous, processingTime(session))
+	return &decision, nil
+}
+
+// processingTime sums the time spent in processing phases, including the open one
+func processingTime(session repository.Session) time.Duration {
+	history, err := repository.ParsePhaseHistory(session.PhaseHistory)
+	if err != nil {
+		history = nil
+	}
+
+	var total time.Duration
+	open := false
+	for _, timing := range history {
+		if !processingPhases[timing.PhaseID] {
+			continue
+		}
+		if timing.LeftAt == nil {
+			total += time.Since(timing.EnteredAt)
+			open = true
+		} else {
+			total += time.Duration(timing.DurationSeconds * float64(time.Second))
+		}
+	}
+	// Sessions that predate history tracking only know the current phase's start
+	if !open && processingPhases[session.Phase] && !session.PhaseStartTime.IsZero() {
+		total += time.Since(session.PhaseStartTime)
+	}
+	return total
+}
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+func TestEvaluateStatusDecision(t *testing.T) {
+	suds := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name          string
+		current       float64
+		previous      *float64
+		processing    time.Duration
+		wantAction    string
+		wantCriterion string
+	}{
+		{"no activation left", 0, suds(3), 5 * time.Minute, "positive_installation", "suds_equals_0"},
+		{"zero wins over the time limit", 0, nil, 30 * time.Minute, "positive_installation", "suds_equals_0"},
+		{"activation after the time limit", 2, suds(3), 20 * time.Minute, "squeeze_hug", "suds_above_0_after_20min"},
+		{"rising activation", 5, suds(3), 5 * time.Minute, "squeeze_hug", "suds_increasing"},
+		{"falling activation", 3, suds(5), 5 * time.Minute, "focused_mindfulness", "suds_decreasing_or_stable_and_time_remaining"},
+		{"stable activation", 4, suds(4), 5 * time.Minute, "focused_mindfulness", "suds_decreasing_or_stable_and_time_remaining"},
+		{"first reading", 6, nil, 0, "focused_mindfulness", "suds_decreasing_or_stable_and_time_remaining"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decision := EvaluateStatusDecision(tc.current, tc.previous, tc.processing)
+			if decision.Action != tc.wantAction || decision.Criterion != tc.wantCriterion {
+				t.Errorf("got %s (%s), want %s (%s)", decision.Action, decision.Criterion, tc.wantAction, tc.wantCriterion)
+			}
+		})
+	}
+}
+
+func TestRecommendStatusActionUsesTheLatestTwoReadings(t *testing.T) {
+	db := newTestDB(t, &repository.Session{}, &repository.SessionFieldValue{}, &repository.SudsReading{})
+	session := repository.Session{ID: "11111111-1111-1111-1111-111111111111", Phase: StatusCheckPhase, PhaseStartTime: time.Now()}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	// The overwritten field only knows the latest value; the history shows it rose
+	for _, value := range []float64{7, 3, 5} {
+		if _, err := repository.RecordSudsReading(db, session.ID, StatusCheckPhase, value); err != nil {
+			t.Fatalf("failed to record reading: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := db.Create(&repository.SessionFieldValue{SessionID: session.ID, PhaseID: StatusCheckPhase,
+		FieldName: "suds_current", FieldValue: "5"}).Error; err != nil {
+		t.Fatalf("failed to store field: %v", err)
+	}
+
+	decision, err := New(session.ID).RecommendStatusAction(nil)
+	if err != nil {
+		t.Fatalf("RecommendStatusAction: %v", err)
+	}
+	if decision == nil || decision.CurrentSUDS != 5 || decision.PreviousSUDS == nil || *decision.PreviousSUDS != 3 {
+		t.Fatalf("got %+v, want current 5 after 3", decision)
+	}
+	if decision.Criterion != "suds_increasing" {
+		t.Errorf("criterion %s, want suds_increasing", decision.Criterion)
+	}
+
+	// A reading that comes with the tool call is the newest
+	pending := 2.0
+	decision, err = New(session.ID).RecommendStatusAction(&pending)
+	if err != nil {
+		t.Fatalf("RecommendStatusAction: %v", err)
+	}
+	if decision.CurrentSUDS != 2 || *decision.PreviousSUDS != 5 {
+		t.Errorf("with a pending reading got current %v after %v, want 2 after 5", decision.CurrentSUDS, *decision.PreviousSUDS)
+	}
+}
+
+func TestRecommendStatusActionWithoutReadings(t *testing.T) {
+	db := newTestDB(t, &repository.Session{}, &repository.SudsReading{})
+	session := repository.Session{ID: "11111111-1111-1111-1111-111111111111", Phase: StatusCheckPhase}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	decision, err := New(session.ID).RecommendStatusAction(nil)
+	if err != nil || decision != nil {
+		t.Errorf("got %+v, %v; want no recommendation", decision, err)
+	}
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"therapy-navigation-system/internal/repository"
+)
+
+// ConstraintMinimumDuration is the PhaseConstraint type for a minimum amount of therapy
+// time in a phase, measured by the session timer (paused time doesn't count)
+const ConstraintMinimumDuration = "minimum_duration_seconds"
+
+// phaseElapsedSource reports a running timer's accumulated time in the current phase
+var phaseElapsedSource func(sessionID string) (time.Duration, bool)
+
+// SetPhaseElapsedSource registers where live phase time comes from; the WebSocket timer
+// owns it, and persisted time is used when no timer is running
+func SetPhaseElapsedSource(source func(sessionID string) (time.Duration, bool)) {
+	phaseElapsedSource = source
+}
+
+// phaseElapsed returns the accumulated (non-paused) time spent in currentPhase. Wall-clock
+// time since the phase started is deliberately not used: it counts pauses and disconnects.
+func (m *Machine) phaseElapsed(session repository.Session, currentPhase string) time.Duration {
+	if phaseElapsedSource != nil && session.Phase == currentPhase {
+		if elapsed, running := phaseElapsedSource(m.sessionID); running {
+			return elapsed
+		}
+	}
+	if session.TimerPhase == currentPhase {
+		return time.Duration(session.PhaseAccumulatedSeconds) * time.Second
+	}
+	return 0
+}
+
+// minimumDurationRemaining returns how much more time blocking minimum-duration constraints
+// require in currentPhase, and the longest required duration (0 when there is none)
+func (m *Machine) minimumDurationRemaining(currentPhase string) (remaining time.Duration, required time.Duration, err error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var constraints []repository.PhaseConstraint
+	if err := db.Where("phase_id = ? AND constraint_type = ? AND behavior_type = ? AND is_active = ?",
+		currentPhase, ConstraintMinimumDuration, "blocking", true).
+		Find(&constraints).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to get phase constraints: %w", err)
+	}
+	for _, c := range constraints {
+		if d := time.Duration(c.Value) * time.Second; d > required {
+			required = d
+		}
+	}
+	if required == 0 {
+		return 0, 0, nil
+	}
+
+	var session repository.Session
+	if err := db.Select("id", "phase", "phase_accumulated_seconds", "timer_phase").
+		First(&session, "id = ?", m.sessionID).Error; err != nil {
+		return 0, 0, fmt.Errorf("session not found: %w", err)
+	}
+
+	if elapsed := m.phaseElapsed(session, currentPhase); elapsed < required {
+		remaining = required - elapsed
+	}
+	return remaining, required, nil
+}
+
+// validateMinimumDuration checks blocking minimum-duration constraints are met
+func (m *Machine) validateMinimumDuration(currentPhase string) error {
+	remaining, required, err := m.minimumDurationRemaining(currentPhase)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return fmt.Errorf("minimum %d seconds required in phase %s, %d seconds remaining",
+			int(required.Seconds()), currentPhase, int(remaining.Seconds()))
+	}
+	return nil
+}
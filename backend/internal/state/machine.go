@@ -1,9 +1,10 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 	"strings"
-	"time"
+	"therapy-navigation-system/internal/logger"
 	"therapy-navigation-system/internal/repository"
 )
 
@@ -31,24 +32,33 @@ func (m *Machine) ValidatePhaseRequirements(currentPhase string) error {
 		return err
 	}
 
+	// Check blocking minimum-duration constraints against accumulated therapy time
+	if err := m.validateMinimumDuration(currentPhase); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // validateDataRequirements checks if all required data fields are collected
 func (m *Machine) validateDataRequirements(currentPhase string) error {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Get all required PhaseData for CURRENT phase to see if we can leave it
 	var phaseData []repository.PhaseData
-	if err := repository.DB.Where("phase_id = ? AND required = ?", currentPhase, true).Find(&phaseData).Error; err != nil {
+	if err := db.Where("phase_id = ? AND requirement = ?", currentPhase, repository.PhaseDataRequired).Find(&phaseData).Error; err != nil {
 		return fmt.Errorf("failed to get phase requirements: %w", err)
 	}
 
 	if len(phaseData) == 0 {
+		m.reportPhaseWithoutRequirements(currentPhase)
 		return nil // No requirements for this phase
 	}
 
 	// Get current session data
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
+	if err := db.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
 		return fmt.Errorf("session not found: %w", err)
 	}
 
@@ -69,21 +79,24 @@ func (m *Machine) validateDataRequirements(currentPhase string) error {
 
 // validateMinimumTurns checks if minimum conversation exchanges have been met
 func (m *Machine) validateMinimumTurns(currentPhase string) error {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Get phase with minimum turns requirement
 	var phase repository.Phase
-	if err := repository.DB.Where("id = ?", currentPhase).First(&phase).Error; err != nil {
+	if err := db.Where("id = ?", currentPhase).First(&phase).Error; err != nil {
 		return fmt.Errorf("phase not found: %w", err)
 	}
 
 	// Get session to find when current phase started
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
+	if err := db.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
 		return fmt.Errorf("session not found: %w", err)
 	}
 
 	// Count messages since current phase started (use session.PhaseStartTime)
 	var messageCount int64
-	if err := repository.DB.Model(&repository.Message{}).
+	if err := db.Model(&repository.Message{}).
 		Where("session_id = ? AND created_at >= ?", m.sessionID, session.PhaseStartTime).
 		Count(&messageCount).Error; err != nil {
 		return fmt.Errorf("failed to count messages: %w", err)
@@ -102,35 +115,46 @@ func (m *Machine) validateMinimumTurns(currentPhase string) error {
 
 // GetPhaseGuidance returns guidance for current phase
 func (m *Machine) GetPhaseGuidance(currentPhase string) (string, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Get current session
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
+	if err := db.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
 		return "", fmt.Errorf("session not found: %w", err)
 	}
 
-	// Get phase requirements
-	var required []repository.PhaseData
-	if err := repository.DB.Where("phase_id = ? AND required = ?", currentPhase, true).Find(&required).Error; err != nil {
+	// Get phase fields (optional fields can still carry step dependencies)
+	var fields []repository.PhaseData
+	if err := db.Where("phase_id = ?", currentPhase).Order("step ASC").Find(&fields).Error; err != nil {
 		return "", fmt.Errorf("failed to get phase requirements: %w", err)
 	}
 
-	// Check what's missing
+	// Check what's missing, holding back fields whose earlier steps aren't collected yet
+	populated := func(name string) bool { return m.isFieldPopulated(session, name) }
+	blocked := repository.PendingPrerequisites(fields, populated)
 	var missing []string
-	for _, req := range required {
-		if !m.isFieldPopulated(session, req.Name) {
+	var locked []string
+	for _, req := range fields {
+		if !req.IsRequired() || populated(req.Name) {
+			continue
+		}
+		if _, isBlocked := blocked[req.Name]; isBlocked {
+			locked = append(locked, req.Name)
+		} else {
 			missing = append(missing, req.Name)
 		}
 	}
 
 	// Get phase for minimum turns requirement
 	var phase repository.Phase
-	if err := repository.DB.Where("id = ?", currentPhase).First(&phase).Error; err != nil {
+	if err := db.Where("id = ?", currentPhase).First(&phase).Error; err != nil {
 		return "", fmt.Errorf("phase not found: %w", err)
 	}
 
 	// Count total messages for turn calculation
 	var messageCount int64
-	if err := repository.DB.Model(&repository.Message{}).
+	if err := db.Model(&repository.Message{}).
 		Where("session_id = ?", m.sessionID).
 		Count(&messageCount).Error; err != nil {
 		return "", fmt.Errorf("failed to count messages: %w", err)
@@ -141,10 +165,17 @@ func (m *Machine) GetPhaseGuidance(currentPhase string) (string, error) {
 	turnsNeeded := phase.MinimumTurns - currentTurns
 	turnsOK := turnsNeeded <= 0
 
+	// Time still required by blocking minimum-duration constraints
+	durationRemaining, durationRequired, err := m.minimumDurationRemaining(currentPhase)
+	if err != nil {
+		return "", err
+	}
+	durationOK := durationRemaining <= 0
+
 	// Build simple guidance message
 	var guidance strings.Builder
 
-	if len(missing) == 0 && turnsOK {
+	if len(missing) == 0 && len(locked) == 0 && turnsOK && durationOK {
 		guidance.WriteString("✅ ALL REQUIREMENTS MET - Ready to transition!\n")
 		guidance.WriteString("Use therapy_session_transition() when therapeutically appropriate.\n")
 	} else {
@@ -152,7 +183,11 @@ func (m *Machine) GetPhaseGuidance(currentPhase string) (string, error) {
 
 		// Show data requirements with specific guidance
 		if len(missing) > 0 {
-			guidance.WriteString("❌ DATA REQUIREMENTS:\n")
+			if repository.IsMultiStep(fields) {
+				guidance.WriteString(fmt.Sprintf("❌ DATA REQUIREMENTS (step %d - collect these first):\n", repository.CurrentStep(fields, populated)))
+			} else {
+				guidance.WriteString("❌ DATA REQUIREMENTS:\n")
+			}
 			for _, field := range missing {
 				if field == "consent_given" {
 					guidance.WriteString("- consent_given: ASK patient for consent and WAIT for their explicit agreement before calling collect_structured_data\n")
@@ -161,7 +196,15 @@ func (m *Machine) GetPhaseGuidance(currentPhase string) (string, error) {
 				}
 			}
 			guidance.WriteString("\n🔧 IMPORTANT: Only call collect_structured_data() AFTER patient provides the required information.\n\n")
-		} else {
+		}
+		if len(locked) > 0 {
+			guidance.WriteString("🔒 LATER STEPS (do NOT ask for or collect these yet):\n")
+			for _, field := range locked {
+				guidance.WriteString(fmt.Sprintf("- %s: waits on %s\n", field, strings.Join(blocked[field], ", ")))
+			}
+			guidance.WriteString("\n")
+		}
+		if len(missing) == 0 && len(locked) == 0 {
 			guidance.WriteString("✅ DATA REQUIREMENTS: Complete\n\n")
 		}
 
@@ -172,6 +215,14 @@ func (m *Machine) GetPhaseGuidance(currentPhase string) (string, error) {
 		} else {
 			guidance.WriteString("✅ MINIMUM TURNS: Complete\n\n")
 		}
+
+		// Show time requirements
+		if !durationOK {
+			guidance.WriteString(fmt.Sprintf("❌ MINIMUM TIME: %d more seconds needed in this phase (%ds required) - keep the client engaged here\n\n",
+				int(durationRemaining.Seconds()), int(durationRequired.Seconds())))
+		} else if durationRequired > 0 {
+			guidance.WriteString("✅ MINIMUM TIME: Complete\n\n")
+		}
 	}
 
 	guidance.WriteString(fmt.Sprintf("📊 Current: %d turns, %d messages",
@@ -182,15 +233,18 @@ func (m *Machine) GetPhaseGuidance(currentPhase string) (string, error) {
 
 // GetMissingFields returns list of missing fields for current phase
 func (m *Machine) GetMissingFields(currentPhase string) ([]string, error) {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Get current session data
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
+	if err := db.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
 		return nil, fmt.Errorf("session not found: %w", err)
 	}
 
 	// Get phase requirements
 	var required []repository.PhaseData
-	if err := repository.DB.Where("phase_id = ? AND required = ?", currentPhase, true).Find(&required).Error; err != nil {
+	if err := db.Where("phase_id = ? AND requirement = ?", currentPhase, repository.PhaseDataRequired).Find(&required).Error; err != nil {
 		return nil, fmt.Errorf("failed to get phase requirements: %w", err)
 	}
 
@@ -207,9 +261,12 @@ func (m *Machine) GetMissingFields(currentPhase string) ([]string, error) {
 
 // isFieldPopulated checks if a session field has data by querying SessionFieldValue table
 func (m *Machine) isFieldPopulated(session repository.Session, fieldName string) bool {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Query SessionFieldValue table for this field
 	var fieldValue repository.SessionFieldValue
-	err := repository.DB.Where("session_id = ? AND field_name = ?", m.sessionID, fieldName).
+	err := db.Where("session_id = ? AND field_name = ?", m.sessionID, fieldName).
 		First(&fieldValue).Error
 
 	if err != nil {
@@ -217,35 +274,87 @@ func (m *Machine) isFieldPopulated(session repository.Session, fieldName string)
 		return false
 	}
 
-	// Check if the field value is not empty
-	return strings.TrimSpace(fieldValue.FieldValue) != ""
+	// Check if the field value is not empty (null or "")
+	return repository.FieldValuePresent(fieldValue)
 }
 
 
+// reportPhaseWithoutRequirements distinguishes conversational-only phases from
+// phases that are missing their phase_data (which would auto-transition immediately)
+func (m *Machine) reportPhaseWithoutRequirements(phaseID string) {
+	if m.PhaseExpectsData(phaseID) {
+		logger.AppLogger.WithFields(map[string]interface{}{
+			"session_id": m.sessionID,
+			"phase":      phaseID,
+		}).Error("🚨 MISCONFIGURED PHASE: expects data but has no required phase_data - it will never block transitions")
+		return
+	}
+
+	logger.AppLogger.WithFields(map[string]interface{}{
+		"session_id": m.sessionID,
+		"phase":      phaseID,
+	}).Debug("Conversational-only phase has no data requirements")
+}
+
+// PhaseExpectsData reports whether the phase is configured to collect data
+func (m *Machine) PhaseExpectsData(phaseID string) bool {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var phase repository.Phase
+	if err := db.Select("expects_data").Where("id = ?", phaseID).First(&phase).Error; err != nil {
+		return false
+	}
+	return phase.ExpectsData
+}
+
 // IsValidTransition validates phase transitions from database
 func (m *Machine) IsValidTransition(fromPhase, toPhase string) bool {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	// Terminal phases are absorbing - leaving one requires an explicit reopen
+	if m.IsTerminalPhase(fromPhase) {
+		return false
+	}
+
 	// Check if transition exists in database
 	var transition repository.PhaseTransition
-	err := repository.DB.Where("from_phase_id = ? AND to_phase_id = ?", fromPhase, toPhase).First(&transition).Error
+	err := db.Where("from_phase_id = ? AND to_phase_id = ?", fromPhase, toPhase).First(&transition).Error
 
 	// If transition exists in DB, it's valid
 	if err == nil {
 		return true
 	}
 
-	// Special case: can always transition to complete phase
-	if toPhase == "complete" {
+	// Special case: can always transition to a terminal phase
+	if m.IsTerminalPhase(toPhase) {
 		return true
 	}
 
 	return false
 }
 
+// IsTerminalPhase reports whether the phase is marked terminal in the database
+func (m *Machine) IsTerminalPhase(phaseID string) bool {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
+	var phase repository.Phase
+	if err := db.Select("is_terminal").Where("id = ?", phaseID).First(&phase).Error; err != nil {
+		return false
+	}
+	return phase.IsTerminal
+}
+
 // GetPhaseDescription returns phase description from database
 func (m *Machine) GetPhaseDescription(phaseID string) string {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Get phase from database
 	var phase repository.Phase
-	if err := repository.DB.Where("id = ?", phaseID).First(&phase).Error; err != nil {
+	if err := db.Where("id = ?", phaseID).First(&phase).Error; err != nil {
 		return phaseID // Return ID if not found
 	}
 	return phase.Description
@@ -261,31 +370,41 @@ func (m *Machine) ValidateMinimumTurns(currentPhase string) error {
 	return m.validateMinimumTurns(currentPhase)
 }
 
+// ErrCannotCompleteSession is returned when a session isn't in a terminal phase or hasn't met
+// that phase's requirements
+var ErrCannotCompleteSession = errors.New("cannot complete session")
+
 // CompleteSession marks a session as completed when all requirements are met
 func (m *Machine) CompleteSession() error {
+	db, cancel := repository.WithQueryTimeout()
+	defer cancel()
+
 	// Get current session
 	var session repository.Session
-	if err := repository.DB.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
+	if err := db.Where("id = ?", m.sessionID).First(&session).Error; err != nil {
 		return fmt.Errorf("session not found: %w", err)
 	}
 
-	// Verify we're in the complete phase
-	if session.Phase != "complete" {
-		return fmt.Errorf("cannot complete session: not in complete phase (currently in %s)", session.Phase)
+	// Verify we're in a terminal phase
+	if !m.IsTerminalPhase(session.Phase) {
+		return fmt.Errorf("%w: not in a terminal phase (currently in %s)", ErrCannotCompleteSession, session.Phase)
 	}
 
-	// Validate that all requirements for the complete phase are met
-	if err := m.ValidatePhaseRequirements("complete"); err != nil {
-		return fmt.Errorf("cannot complete session: requirements not met: %w", err)
+	// Validate that all requirements for the terminal phase are met
+	if err := m.ValidatePhaseRequirements(session.Phase); err != nil {
+		return fmt.Errorf("%w: requirements not met: %v", ErrCannotCompleteSession, err)
 	}
 
-	// Mark session as completed
-	updates := map[string]interface{}{
-		"status":     "completed",
-		"updated_at": time.Now(),
+	// A session that reached its terminal phase has been running, even if it was never
+	// explicitly started
+	if session.Status == repository.SessionStatusScheduled {
+		if err := repository.ChangeSessionStatus(db, m.sessionID, repository.SessionStatusActive, "reached terminal phase"); err != nil {
+			return fmt.Errorf("failed to mark session as active: %w", err)
+		}
 	}
 
-	if err := repository.DB.Model(&session).Updates(updates).Error; err != nil {
+	// Mark session as completed
+	if err := repository.ChangeSessionStatus(db, m.sessionID, repository.SessionStatusCompleted, "terminal phase requirements met"); err != nil {
 		return fmt.Errorf("failed to mark session as completed: %w", err)
 	}
 
@@ -0,0 +1,43 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"therapy-navigation-system/internal/logger"
+	"therapy-navigation-system/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestDB installs a throwaway SQLite database with the given models migrated as
+// repository.DB, restoring the previous one when the test ends
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	if logger.AppLogger == nil {
+		logger.AppLogger = logrus.New()
+		logger.AppLogger.SetLevel(logrus.WarnLevel)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")),
+		&gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := repository.DB
+	repository.DB = db
+	t.Cleanup(func() {
+		repository.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
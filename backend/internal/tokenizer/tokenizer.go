@@ -0,0 +1,169 @@
+// Package tokenizer counts prompt tokens with a byte-level BPE vocabulary in tiktoken format
+// (e.g. cl100k_base.tiktoken). Until a vocabulary is loaded, or if it fails to load, counts
+// fall back to the old ~4 chars/token estimate.
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// charsPerToken is the fallback estimate
+const charsPerToken = 4
+
+var (
+	mu    sync.RWMutex
+	ranks map[string]int // token bytes -> merge rank
+)
+
+// pretokenize approximates the cl100k pre-tokenizer. Go's regexp has no lookahead, so the
+// "whitespace not followed by a word" rule is applied in splitPieces instead.
+var pretokenize = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// Load reads a tiktoken vocabulary: one "<base64 token> <rank>" pair per line
+func Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	loaded := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("%s:%d: expected \"<token> <rank>\"", path, line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return fmt.Errorf("%s:%d: invalid token: %w", path, line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("%s:%d: invalid rank: %w", path, line, err)
+		}
+		loaded[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(loaded) == 0 {
+		return fmt.Errorf("%s: empty vocabulary", path)
+	}
+
+	mu.Lock()
+	ranks = loaded
+	mu.Unlock()
+	return nil
+}
+
+// Loaded reports whether a BPE vocabulary is in use
+func Loaded() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return ranks != nil
+}
+
+// CountTokens returns the number of tokens in text
+func CountTokens(text string) int {
+	mu.RLock()
+	vocab := ranks
+	mu.RUnlock()
+	if vocab == nil {
+		return len(text) / charsPerToken
+	}
+
+	total := 0
+	for _, piece := range splitPieces(text) {
+		total += bpeCount(vocab, piece)
+	}
+	return total
+}
+
+// PrefixLen returns the byte length of the longest prefix of text that fits in maxTokens.
+// The prefix ends on a pre-token boundary, so words are never split.
+func PrefixLen(text string, maxTokens int) int {
+	if maxTokens <= 0 {
+		return 0
+	}
+	mu.RLock()
+	vocab := ranks
+	mu.RUnlock()
+	if vocab == nil {
+		cut := maxTokens * charsPerToken
+		if cut >= len(text) {
+			return len(text)
+		}
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		return cut
+	}
+
+	used, length := 0, 0
+	for _, piece := range splitPieces(text) {
+		n := bpeCount(vocab, piece)
+		if used+n > maxTokens {
+			break
+		}
+		used += n
+		length += len(piece)
+	}
+	return length
+}
+
+// splitPieces pre-tokenizes text. A whitespace run before a word or punctuation gives its
+// last space to that piece, as cl100k's "\s+(?!\S)" rule does.
+func splitPieces(text string) []string {
+	pieces := pretokenize.FindAllString(text, -1)
+	for i := 0; i+1 < len(pieces); i++ {
+		current, next := pieces[i], pieces[i+1]
+		if len(current) < 2 || strings.TrimSpace(current) != "" || strings.ContainsAny(current, "\r\n") {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(next); unicode.IsSpace(r) || unicode.IsDigit(r) {
+			continue
+		}
+		last := len(current) - 1
+		pieces[i], pieces[i+1] = current[:last], current[last:]+next
+	}
+	return pieces
+}
+
+// bpeCount applies byte-pair merges to one piece and returns the resulting token count
+func bpeCount(vocab map[string]int, piece string) int {
+	if _, ok := vocab[piece]; ok {
+		return 1
+	}
+
+	parts := make([]string, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts[i] = piece[i : i+1]
+	}
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i+1 < len(parts); i++ {
+			if rank, ok := vocab[parts[i]+parts[i+1]]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return len(parts)
+}
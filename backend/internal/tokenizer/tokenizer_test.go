@@ -0,0 +1,156 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useVocab loads a vocabulary for the test, restoring the previous one when it ends
+func useVocab(t *testing.T, path string) {
+	t.Helper()
+	mu.RLock()
+	previous := ranks
+	mu.RUnlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		ranks = previous
+		mu.Unlock()
+	})
+	if err := Load(path); err != nil {
+		t.Fatalf("failed to load vocabulary: %v", err)
+	}
+}
+
+// writeVocab writes tokens in tiktoken format, ranked in the order given after every single byte
+func writeVocab(t *testing.T, merges ...string) string {
+	t.Helper()
+	var sb strings.Builder
+	rank := 0
+	for b := 0; b < 256; b++ {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), rank)
+		rank++
+	}
+	for _, token := range merges {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+		rank++
+	}
+	path := filepath.Join(t.TempDir(), "vocab.tiktoken")
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		t.Fatalf("failed to write vocabulary: %v", err)
+	}
+	return path
+}
+
+func TestSplitPiecesMatchesCl100kPretokenizer(t *testing.T) {
+	tests := map[string][]string{
+		"hello world":     {"hello", " world"},
+		"I'm here":        {"I", "'m", " here"},
+		"1234567":         {"123", "456", "7"},
+		"wait   now":      {"wait", "  ", " now"},
+		"done.\n\nNext":   {"done", ".\n\n", "Next"},
+		"SUDS: 7/10":      {"SUDS", ":", " ", "7", "/", "10"},
+		"trailing space ": {"trailing", " space", " "},
+	}
+	for text, want := range tests {
+		if got := splitPieces(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitPieces(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestCountTokensAppliesMergesByRank(t *testing.T) {
+	useVocab(t, writeVocab(t, "ll", "he", "hell", "hello", " w", "or", " wor", "ld"))
+
+	tests := map[string]int{
+		"hello":       1, // A whole-piece token
+		"hell":        1,
+		" world":      2, // " w" + "or" -> " wor", then "ld"; " world" itself isn't a token
+		"hello world": 3,
+		"lo":          2, // No merge applies
+		"":            0,
+	}
+	for text, want := range tests {
+		if got := CountTokens(text); got != want {
+			t.Errorf("CountTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestPrefixLenStopsAtPieceBoundary(t *testing.T) {
+	useVocab(t, writeVocab(t, "ll", "he", "hell", "hello", " w", "or", " wor", "ld"))
+
+	text := "hello world"
+	for maxTokens, want := range map[int]int{0: 0, 1: len("hello"), 2: len("hello"), 3: len(text), 10: len(text)} {
+		if got := PrefixLen(text, maxTokens); got != want {
+			t.Errorf("PrefixLen(%q, %d) = %d, want %d", text, maxTokens, got, want)
+		}
+	}
+}
+
+func TestCountTokensFallsBackToEstimateWithoutVocabulary(t *testing.T) {
+	mu.Lock()
+	previous := ranks
+	ranks = nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		ranks = previous
+		mu.Unlock()
+	})
+
+	if got := CountTokens(strings.Repeat("a", 40)); got != 10 {
+		t.Errorf("CountTokens = %d, want the 4 chars/token estimate of 10", got)
+	}
+	// The estimate's cut never splits a multi-byte rune
+	if got := PrefixLen("aaé", 0); got != 0 {
+		t.Errorf("PrefixLen with no budget = %d, want 0", got)
+	}
+	if got := PrefixLen("aaaé", 1); got != 3 {
+		t.Errorf("PrefixLen = %d, want 3 (before the two-byte é)", got)
+	}
+}
+
+func TestLoadRejectsMalformedVocabulary(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":        "",
+		"missing rank": "aGVsbG8=\n",
+		"bad base64":   "!!! 1\n",
+		"bad rank":     "aGVsbG8= one\n",
+	} {
+		path := filepath.Join(t.TempDir(), "vocab.tiktoken")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write vocabulary: %v", err)
+		}
+		if err := Load(path); err == nil {
+			t.Errorf("%s: expected Load to fail", name)
+		}
+	}
+}
+
+// TestCountTokensMatchesCl100k checks counts tiktoken reports for cl100k_base. It needs the
+// real vocabulary: TOKENIZER_VOCAB, or the file make tokenizer-vocab fetches.
+func TestCountTokensMatchesCl100k(t *testing.T) {
+	path := os.Getenv("TOKENIZER_VOCAB")
+	if path == "" {
+		path = filepath.Join("..", "..", "assets", "cl100k_base.tiktoken")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("cl100k_base vocabulary not found; run make tokenizer-vocab")
+	}
+	useVocab(t, path)
+
+	tests := map[string]int{
+		"hello world":        2,
+		"tiktoken is great!": 6,
+	}
+	for text, want := range tests {
+		if got := CountTokens(text); got != want {
+			t.Errorf("CountTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,17 +21,35 @@ import (
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: go run generate-types.go <output-file>")
+	check := flag.Bool("check", false, "verify the output file is up to date instead of writing it")
+	sourceDir := flag.String("source", "shared", "directory containing the shared Go source to extract constants from")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		log.Fatal("Usage: go run generate-types.go [--check] <output-file>")
 	}
 
-	outputFile := os.Args[1]
+	outputFile := flag.Arg(0)
+
+	constants, err := extractConstants(*sourceDir)
+	if err != nil {
+		log.Fatalf("Failed to extract constants: %v", err)
+	}
 
 	// Generate TypeScript interfaces
-	output := generateTypeScriptInterfaces()
+	output := generateTypeScriptInterfaces(constants)
+
+	if *check {
+		if err := checkUpToDate(outputFile, output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("TypeScript types at %s are up to date\n", outputFile)
+		return
+	}
 
 	// Write to file
-	err := os.WriteFile(outputFile, []byte(output), 0644)
+	err = os.WriteFile(outputFile, []byte(output), 0644)
 	if err != nil {
 		log.Fatalf("Failed to write output file: %v", err)
 	}
@@ -30,74 +57,32 @@ func main() {
 	fmt.Printf("Generated TypeScript types at %s\n", outputFile)
 }
 
-func generateTypeScriptInterfaces() string {
+func generateTypeScriptInterfaces(constants *sharedConstants) string {
 	var sb strings.Builder
 
 	// Header
 	sb.WriteString("// AUTO-GENERATED: Do not edit manually\n")
 	sb.WriteString("// Generated from Go structs in shared/websocket-types.go\n\n")
 
-	// Message type constants
-	sb.WriteString("// Message Types\n")
-	sb.WriteString("export const MESSAGE_TYPES = {\n")
-	sb.WriteString("  // Inbound (frontend -> backend)\n")
-	sb.WriteString("  GET_WORKFLOW_STATUS: 'get_workflow_status',\n")
-	sb.WriteString("  TOOL_CALL: 'tool_call',\n")
-	sb.WriteString("  PAUSE_TIMER: 'pause_timer',\n")
-	sb.WriteString("  RESUME_TIMER: 'resume_timer',\n")
-	sb.WriteString("  STOP_TIMER: 'stop_timer',\n")
-	sb.WriteString("  \n")
-	sb.WriteString("  // Outbound (backend -> frontend)\n")
-	sb.WriteString("  WORKFLOW_UPDATE: 'workflow_update',\n")
-	sb.WriteString("  THERAPY_SESSION_UPDATE: 'therapy_session_update',\n")
-	sb.WriteString("  TIMER_UPDATE: 'timer_update',\n")
-	sb.WriteString("  PHASE_TIMER_STARTED: 'phase_timer_started',\n")
-	sb.WriteString("  PHASE_TIMER_STOPPED: 'phase_timer_stopped',\n")
-	sb.WriteString("  PHASE_TIMER_PAUSED: 'phase_timer_paused',\n")
-	sb.WriteString("  PHASE_TIMER_RESUMED: 'phase_timer_resumed',\n")
-	sb.WriteString("  PHASE_TIMER_COMPLETED: 'phase_timer_completed',\n")
-	sb.WriteString("  PHASE_TIMER_CHECKIN: 'phase_timer_checkin',\n")
-	sb.WriteString("} as const;\n\n")
-
-	// Timer state enum
-	sb.WriteString("export enum TimerState {\n")
-	sb.WriteString("  IDLE = 'idle',\n")
-	sb.WriteString("  RUNNING = 'running',\n")
-	sb.WriteString("  PAUSED = 'paused',\n")
-	sb.WriteString("  STOPPED = 'stopped',\n")
-	sb.WriteString("  COMPLETED = 'completed',\n")
-	sb.WriteString("  EXPIRED = 'expired',\n")
-	sb.WriteString("}\n\n")
+	// Protocol version constants
+	sb.WriteString("// WebSocket protocol version (send as protocol_version query parameter on connect)\n")
+	sb.WriteString(fmt.Sprintf("export const PROTOCOL_VERSION = %d;\n", shared.ProtocolVersion))
+	sb.WriteString(fmt.Sprintf("export const MIN_PROTOCOL_VERSION = %d;\n\n", shared.MinProtocolVersion))
 
-	// Timer stop reason enum
-	sb.WriteString("export enum TimerStopReason {\n")
-	sb.WriteString("  MANUAL = 'manual',\n")
-	sb.WriteString("  PHASE_TRANSITION = 'phase_transition',\n")
-	sb.WriteString("  SESSION_END = 'session_end',\n")
-	sb.WriteString("  TIMEOUT = 'timeout',\n")
-	sb.WriteString("  ERROR = 'error',\n")
-	sb.WriteString("  COMPLETED = 'completed',\n")
-	sb.WriteString("}\n\n")
+	// Message type constants and enums, derived from the Go const blocks
+	sb.WriteString(generateMessageTypes(constants.MessageTypes))
+	for _, enum := range constants.Enums {
+		sb.WriteString(fmt.Sprintf("export enum %s {\n", enum.Name))
+		for _, c := range enum.Values {
+			sb.WriteString(fmt.Sprintf("  %s = '%s',\n", strings.ToUpper(c.Value), c.Value))
+		}
+		sb.WriteString("}\n\n")
+	}
 
-	// Generate interfaces for each struct
-	types := []interface{}{
-		shared.WebSocketMessage{},
-		shared.TherapySessionUpdate{},
-		shared.WorkflowStatusResponse{},
-		shared.TimerStatus{},
-		shared.TimerEvent{},
-		shared.Phase{},
-		shared.PhaseDataField{},
-		shared.TransitionOption{},
-		shared.Message{},
-		shared.ToolCallRequest{},
-		shared.ToolCallResponse{},
-	}
-
-	for _, t := range types {
-		interfaceName := getTypeName(t)
-		sb.WriteString(fmt.Sprintf("export interface %s {\n", interfaceName))
-		sb.WriteString(generateFieldsForType(reflect.TypeOf(t)))
+	// Generate interfaces for each registered struct and every struct reachable from it
+	for _, t := range collectStructTypes(shared.TypeScriptTypes) {
+		sb.WriteString(fmt.Sprintf("export interface %s {\n", t.Name()))
+		sb.WriteString(generateFieldsForType(t))
 		sb.WriteString("}\n\n")
 	}
 
@@ -134,8 +119,190 @@ func generateTypeScriptInterfaces() string {
 	return sb.String()
 }
 
-func getTypeName(t interface{}) string {
-	return reflect.TypeOf(t).Name()
+// constValue is a string constant extracted from the shared package source
+type constValue struct {
+	Name    string
+	Value   string
+	Comment string // Doc comment preceding the constant, if any
+}
+
+// constEnum is a group of constants sharing a named string type
+type constEnum struct {
+	Name   string
+	Values []constValue
+}
+
+// sharedConstants holds the const blocks mirrored into TypeScript
+type sharedConstants struct {
+	MessageTypes []constValue
+	Enums        []constEnum
+}
+
+// extractConstants parses the shared package and collects the MessageType*
+// constants and every typed string const group, in source order
+func extractConstants(dir string) (*sharedConstants, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	result := &sharedConstants{}
+	enumIndex := make(map[string]int)
+	fset := token.NewFileSet()
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		for _, decl := range parsed.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != len(vs.Values) {
+					continue
+				}
+				for i, name := range vs.Names {
+					lit, ok := vs.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING || !name.IsExported() {
+						continue
+					}
+					value, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						return nil, fmt.Errorf("invalid string constant %s: %w", name.Name, err)
+					}
+					c := constValue{Name: name.Name, Value: value}
+					if vs.Doc != nil {
+						c.Comment = strings.TrimSpace(vs.Doc.Text())
+					}
+
+					if typeIdent, ok := vs.Type.(*ast.Ident); ok {
+						idx, exists := enumIndex[typeIdent.Name]
+						if !exists {
+							idx = len(result.Enums)
+							enumIndex[typeIdent.Name] = idx
+							result.Enums = append(result.Enums, constEnum{Name: typeIdent.Name})
+						}
+						result.Enums[idx].Values = append(result.Enums[idx].Values, c)
+					} else if strings.HasPrefix(name.Name, "MessageType") {
+						result.MessageTypes = append(result.MessageTypes, c)
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// generateMessageTypes renders the MESSAGE_TYPES map, carrying over section comments
+func generateMessageTypes(messageTypes []constValue) string {
+	var sb strings.Builder
+
+	sb.WriteString("// Message Types\n")
+	sb.WriteString("export const MESSAGE_TYPES = {\n")
+	for i, c := range messageTypes {
+		if c.Comment != "" {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("  // %s\n", c.Comment))
+		}
+		sb.WriteString(fmt.Sprintf("  %s: '%s',\n", strings.ToUpper(c.Value), c.Value))
+	}
+	sb.WriteString("} as const;\n\n")
+
+	return sb.String()
+}
+
+// checkUpToDate regenerates into a temp file and diffs it against the committed output
+func checkUpToDate(outputFile string, output string) error {
+	committed, err := os.ReadFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", outputFile, err)
+	}
+	if bytes.Equal(committed, []byte(output)) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "websocket-*.ts")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(output); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	tmp.Close()
+
+	// diff exits non-zero when files differ; only its output matters here
+	diff, _ := exec.Command("diff", "-u", outputFile, tmp.Name()).CombinedOutput()
+	return fmt.Errorf("%s is stale; run 'make generate-websocket-types'\n%s", outputFile, diff)
+}
+
+// collectStructTypes returns the registered roots followed by every named struct
+// type referenced from them, so generated interfaces never reference undefined types
+func collectStructTypes(roots []interface{}) []reflect.Type {
+	var ordered []reflect.Type
+	seen := make(map[reflect.Type]bool)
+
+	var visit func(t reflect.Type)
+	visit = func(t reflect.Type) {
+		t = underlyingElem(t)
+		if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) || seen[t] {
+			return
+		}
+		if t.Name() != "" {
+			seen[t] = true
+			ordered = append(ordered, t)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				visit(t.Field(i).Type)
+			}
+		}
+	}
+
+	// Roots first so the registry controls output order
+	for _, root := range roots {
+		t := reflect.TypeOf(root)
+		if !seen[t] {
+			seen[t] = true
+			ordered = append(ordered, t)
+		}
+	}
+	for _, root := range roots {
+		t := reflect.TypeOf(root)
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				visit(t.Field(i).Type)
+			}
+		}
+	}
+
+	return ordered
+}
+
+// underlyingElem strips pointers, slices, arrays and map values down to the element type
+func underlyingElem(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
 }
 
 func generateFieldsForType(t reflect.Type) string {
@@ -195,22 +362,45 @@ func convertGoTypeToTypeScript(t reflect.Type) string {
 	case reflect.Bool:
 		return "boolean"
 	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // []byte marshals as base64
+		}
 		elemType := convertGoTypeToTypeScript(t.Elem())
+		if strings.Contains(elemType, " ") {
+			elemType = "(" + elemType + ")"
+		}
 		return fmt.Sprintf("%s[]", elemType)
 	case reflect.Map:
 		keyType := convertGoTypeToTypeScript(t.Key())
 		valueType := convertGoTypeToTypeScript(t.Elem())
 		return fmt.Sprintf("Record<%s, %s>", keyType, valueType)
 	case reflect.Ptr:
-		return convertGoTypeToTypeScript(t.Elem()) + " | null"
+		elemType := convertGoTypeToTypeScript(t.Elem())
+		if strings.HasSuffix(elemType, " | null") {
+			return elemType
+		}
+		return elemType + " | null"
 	case reflect.Interface:
 		return "any"
 	case reflect.Struct:
 		if t == reflect.TypeOf(time.Time{}) {
 			return "string" // ISO date string
 		}
+		if t.Name() == "" {
+			// Anonymous structs are inlined
+			return "{\n" + indent(generateFieldsForType(t)) + "  }"
+		}
 		return t.Name()
 	default:
 		return "any"
 	}
-}
\ No newline at end of file
+}
+
+// indent nests generated field lines one level deeper for inline struct types
+func indent(fields string) string {
+	lines := strings.Split(strings.TrimSuffix(fields, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "  " + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
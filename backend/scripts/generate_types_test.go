@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRoot struct {
+	Child    *testChild             `json:"child,omitempty"`
+	Items    []testItem             `json:"items"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+	ByName   map[string]testLeaf    `json:"by_name"`
+	Inline   struct{ Note string }  `json:"inline"`
+	At       time.Time              `json:"at"`
+	internal testLeaf
+}
+
+type testChild struct {
+	Leaf testLeaf `json:"leaf"`
+}
+
+type testItem struct {
+	Name string `json:"name"`
+}
+
+type testLeaf struct {
+	Value int `json:"value"`
+}
+
+func TestCollectStructTypesFollowsNestedStructs(t *testing.T) {
+	var names []string
+	for _, typ := range collectStructTypes([]interface{}{testRoot{}}) {
+		names = append(names, typ.Name())
+	}
+
+	want := []string{"testRoot", "testChild", "testLeaf", "testItem"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("collected %v, want %v", names, want)
+	}
+}
+
+func TestConvertGoTypeToTypeScript(t *testing.T) {
+	root := reflect.TypeOf(testRoot{})
+	cases := map[string]string{
+		"Child":  "testChild | null",
+		"Items":  "testItem[]",
+		"Meta":   "Record<string, any>",
+		"ByName": "Record<string, testLeaf>",
+		"At":     "string",
+	}
+	for field, want := range cases {
+		f, _ := root.FieldByName(field)
+		if got := convertGoTypeToTypeScript(f.Type); got != want {
+			t.Errorf("%s: got %q, want %q", field, got, want)
+		}
+	}
+}
+
+// declaredTypes and referencedTypes approximate tsc's undefined-name check for the
+// generated file: every capitalized type a field uses must be declared in it
+var (
+	declaredTypes   = regexp.MustCompile(`(?m)^export (?:interface|enum|type) (\w+)`)
+	fieldTypes      = regexp.MustCompile(`(?m)^\s+\w+\??: (.+);`)
+	typeIdentifiers = regexp.MustCompile(`\b[A-Z]\w*\b`)
+	builtinTypes    = map[string]bool{"Record": true}
+)
+
+func TestGeneratedTypesDeclareEveryReferencedType(t *testing.T) {
+	constants, err := extractConstants("../shared")
+	if err != nil {
+		t.Fatalf("failed to extract constants: %v", err)
+	}
+	output := generateTypeScriptInterfaces(constants)
+
+	declared := make(map[string]bool)
+	for _, m := range declaredTypes.FindAllStringSubmatch(output, -1) {
+		declared[m[1]] = true
+	}
+	fields := fieldTypes.FindAllStringSubmatch(output, -1)
+	if len(declared) == 0 || len(fields) == 0 {
+		t.Fatal("found no declarations or fields in the generated output")
+	}
+	for _, m := range fields {
+		for _, name := range typeIdentifiers.FindAllString(m[1], -1) {
+			if !declared[name] && !builtinTypes[name] {
+				t.Errorf("generated output references undeclared type %s in %q", name, strings.TrimSpace(m[0]))
+			}
+		}
+	}
+}
+
+// TestGeneratedTypesCompile type-checks the generated file with the frontend's tsc, when
+// its dependencies are installed
+func TestGeneratedTypesCompile(t *testing.T) {
+	tsc, err := filepath.Abs("../../frontend/node_modules/.bin/tsc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tsc); err != nil {
+		if tsc, err = exec.LookPath("tsc"); err != nil {
+			t.Skip("tsc not available; run npm install in frontend/")
+		}
+	}
+
+	constants, err := extractConstants("../shared")
+	if err != nil {
+		t.Fatalf("failed to extract constants: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "websocket.ts")
+	if err := os.WriteFile(file, []byte(generateTypeScriptInterfaces(constants)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(tsc, "--noEmit", "--strict", "--target", "es2020", file).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated types don't compile: %v\n%s", err, out)
+	}
+}
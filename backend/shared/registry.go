@@ -0,0 +1,18 @@
+package shared
+
+// TypeScriptTypes is the registry of shared structs emitted as TypeScript
+// interfaces by scripts/generate-types.go, in output order. Register new
+// shared types here so they are picked up by the generator.
+var TypeScriptTypes = []interface{}{
+	WebSocketMessage{},
+	TherapySessionUpdate{},
+	WorkflowStatusResponse{},
+	TimerStatus{},
+	TimerEvent{},
+	Phase{},
+	PhaseDataField{},
+	TransitionOption{},
+	Message{},
+	ToolCallRequest{},
+	ToolCallResponse{},
+}
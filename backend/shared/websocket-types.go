@@ -10,6 +10,16 @@ type WebSocketMessage struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// WebSocket protocol versions. Clients send the version they support as the
+// protocol_version query parameter on connect; clients that omit it are treated
+// as MinProtocolVersion and receive events in the legacy format. v2 added the
+// protocol_version envelope field; v3 added the event types from phase_preview on,
+// Phase.Visualization and Message.ParticipantID.
+const (
+	ProtocolVersion    = 3 // Version spoken by this server
+	MinProtocolVersion = 1 // Oldest client version the server can still serve
+)
+
 // Message types as constants for type safety
 const (
 	// Inbound message types (frontend -> backend)
@@ -29,6 +39,24 @@ const (
 	MessageTypePhaseTimerResumed   = "phase_timer_resumed"
 	MessageTypePhaseTimerCompleted = "phase_timer_completed"
 	MessageTypePhaseTimerCheckin   = "phase_timer_checkin"
+	MessageTypeConnected           = "connected"
+	MessageTypeProtocolIncompatible = "protocol_incompatible"
+	MessageTypePhasePreview        = "phase_preview"
+	MessageTypeCoachTyping         = "coach_typing"
+	MessageTypePhaseVisualization  = "phase_visualization"
+	MessageTypeServiceUnavailable  = "service_unavailable"
+	MessageTypeAttachment          = "attachment"
+	MessageTypeRequirementSatisfied = "requirement_satisfied"
+	MessageTypePhaseRollback       = "phase_rollback"
+	MessageTypeWorkflowGraphChanged = "workflow_graph_changed"
+	MessageTypeMessageChunk        = "message_chunk"    // Streamed coach reply text, in order, keyed by metadata.message_id
+	MessageTypeMessageComplete     = "message_complete" // End of a streamed reply, carrying the saved message
+	MessageTypeMCPActivity         = "mcp_activity"     // Tool call status and progress (0.0-1.0), keyed by metadata.tool_call_id
+	MessageTypeCorrectField        = "correct_field"    // Inbound: clinician's correction of a collected field
+	MessageTypeFieldCorrected      = "field_corrected"  // Outcome of a correct_field, with the requirements it left missing
+	MessageTypeTransitionLoopDetected = "transition_loop_detected" // Coach transitions held after MAX_PHASE_TRANSITIONS
+	MessageTypeTransitionLoopReviewed = "transition_loop_reviewed" // A clinician released the hold
+	MessageTypeParticipantsUpdated    = "participants_updated"     // Session participants after one was added or removed
 )
 
 // TherapySessionUpdate represents a real-time update for therapy sessions
@@ -42,6 +70,7 @@ type TherapySessionUpdate struct {
 	RecentMessages  []Message              `json:"recent_messages,omitempty"`    // Recent chat messages (sent in initial_state)
 	Message         *Message               `json:"message,omitempty"`            // New message (for message events)
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`           // For timer_update and other special events that need custom data
+	ProtocolVersion int                    `json:"protocol_version,omitempty"`   // Protocol version the event was encoded for (omitted for v1 clients)
 	Timestamp       time.Time              `json:"timestamp"`
 }
 
@@ -119,6 +148,14 @@ type Phase struct {
 	Color       string           `json:"color,omitempty"`
 	Icon        string           `json:"icon,omitempty"`
 	PhaseData   []PhaseDataField `json:"phase_data"` // Schema for this phase
+
+	Visualization *PhaseVisualization `json:"visualization,omitempty"`
+}
+
+// PhaseVisualization is the client-side ambiance a phase asks the UI to render
+type PhaseVisualization struct {
+	Type   string                 `json:"type"`             // e.g. "breathing_circle", "ocean_waves"
+	Config map[string]interface{} `json:"config,omitempty"` // Colors, animation speed, etc.
 }
 
 // PhaseDataField represents a data field required or optional for a phase
@@ -138,14 +175,15 @@ type TransitionOption struct {
 
 // Message represents a therapy session message
 type Message struct {
-	ID           string    `json:"id"`
-	SessionID    string    `json:"session_id"`
-	Content      string    `json:"content"`
-	Role         string    `json:"role"` // "user", "assistant", "system"
-	MessageType  string    `json:"message_type"`
-	Metadata     string    `json:"metadata"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            string    `json:"id"`
+	SessionID     string    `json:"session_id"`
+	Content       string    `json:"content"`
+	Role          string    `json:"role"` // "user", "assistant", "system"
+	MessageType   string    `json:"message_type"`
+	Metadata      string    `json:"metadata"`
+	ParticipantID string    `json:"participant_id,omitempty"` // Sender's person ID in a group or supervised session
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // ToolCallRequest represents an inbound tool call from frontend
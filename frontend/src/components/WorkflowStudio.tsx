@@ -27,7 +27,7 @@ interface PhaseData {
   phase_id: string;
   name: string;
   description: string;
-  required: boolean;
+  requirement: 'required' | 'recommended' | 'optional';
   data_type: string;
 }
 
@@ -649,11 +649,13 @@ export const WorkflowStudio: React.FC = () => {
                               <div className="flex items-center gap-2 mb-2">
                                 <span className="font-medium">{field.name}</span>
                                 <span className={`px-2 py-0.5 text-xs rounded-full ${
-                                  field.required
+                                  field.requirement === 'required'
                                     ? 'bg-red-500/20 text-red-400 border border-red-500/30'
-                                    : 'bg-green-500/20 text-green-400 border border-green-500/30'
+                                    : field.requirement === 'recommended'
+                                      ? 'bg-amber-500/20 text-amber-400 border border-amber-500/30'
+                                      : 'bg-green-500/20 text-green-400 border border-green-500/30'
                                 }`}>
-                                  {field.required ? 'Required' : 'Optional'}
+                                  {field.requirement === 'required' ? 'Required' : field.requirement === 'recommended' ? 'Recommended' : 'Optional'}
                                 </span>
                                 <span className="px-2 py-0.5 text-xs rounded-full bg-violet-500/20 text-violet-400 border border-violet-500/30">
                                   {field.data_type}
@@ -725,8 +727,8 @@ export const WorkflowStudio: React.FC = () => {
                       {phases.map((phase, index) => {
                         const hasPrompt = !!prompts[phase.id];
                         const hasData = (phaseData[phase.id]?.length || 0) > 0;
-                        const requiredFields = phaseData[phase.id]?.filter(d => d.required).length || 0;
-                        const optionalFields = phaseData[phase.id]?.filter(d => !d.required).length || 0;
+                        const requiredFields = phaseData[phase.id]?.filter(d => d.requirement === 'required').length || 0;
+                        const optionalFields = phaseData[phase.id]?.filter(d => d.requirement !== 'required').length || 0;
 
                         return (
                           <div key={phase.id} className="flex items-start gap-4 relative">
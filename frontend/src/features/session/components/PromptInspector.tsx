@@ -11,6 +11,8 @@ interface PromptContext {
   token_report: {
     sections: Record<string, number>;
     total: number;
+    budget?: number;
+    caps?: Record<string, number>;
     hash: string;
   };
   sections: {
@@ -149,6 +151,7 @@ export const PromptInspector: React.FC<PromptInspectorProps> = ({ currentPhase,
           <div className="text-xs text-white/60 mt-2">
             Phase: <span className="text-blue-300">{currentPhase}</span> •
             Total: <span className="text-green-300">{context.token_report.total} tokens</span>
+            {context.token_report.budget ? <> / {context.token_report.budget} budget</> : null}
           </div>
         )}
       </div>
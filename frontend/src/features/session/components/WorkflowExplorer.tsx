@@ -54,13 +54,13 @@ export const WorkflowExplorer: React.FC<WorkflowExplorerProps> = ({
       let requiredFilled = 0;
 
       phaseFields.forEach((field: any) => {
-        if (field.required) {
+        if (field.requirement === 'required') {
           requiredCount++;
         }
         if (field.name && phaseDataValues[field.name] !== undefined && phaseDataValues[field.name] !== null && phaseDataValues[field.name] !== '') {
           collectedValues[field.name] = phaseDataValues[field.name];
           hasAnyData = true;
-          if (field.required) {
+          if (field.requirement === 'required') {
             requiredFilled++;
           }
         }
@@ -2,6 +2,7 @@ import { useState, useEffect, useCallback, useRef } from 'react';
 import { getWebSocketUrl } from '../../../utils/ws';
 import {
   MESSAGE_TYPES,
+  PROTOCOL_VERSION,
   TypedWebSocketMessage,
   ToolCallRequest
 } from '../../../types/websocket';
@@ -9,6 +10,7 @@ import {
 export interface WebSocketHook {
   ws: WebSocket | null;
   isConnected: boolean;
+  protocolError: string | null;
   sendMessage: (data: ToolCallRequest | { type: string; [key: string]: any }) => void;
   sendTypedMessage: (message: TypedWebSocketMessage) => void;
   reconnect: () => void;
@@ -16,6 +18,7 @@ export interface WebSocketHook {
 
 export const useWebSocket = (sessionId: string): WebSocketHook => {
   const [isConnected, setIsConnected] = useState(false);
+  const [protocolError, setProtocolError] = useState<string | null>(null);
   const wsRef = useRef<WebSocket | null>(null);
   const reconnectTimeoutRef = useRef<ReturnType<typeof setTimeout>>();
   const isConnectingRef = useRef(false);
@@ -26,8 +29,11 @@ export const useWebSocket = (sessionId: string): WebSocketHook => {
       return;
     }
 
-    const wsUrl = getWebSocketUrl(`/api/sessions/${sessionId}/ws`);
-    console.log('Connecting to WebSocket:', wsUrl);
+    // Browsers can't set an Authorization header on the handshake; the token goes in the query
+    const token = sessionStorage.getItem('firebase_token');
+    const tokenParam = token ? `&token=${encodeURIComponent(token)}` : '';
+    const wsUrl = getWebSocketUrl(`/api/sessions/${sessionId}/ws?protocol_version=${PROTOCOL_VERSION}${tokenParam}`);
+    console.log('Connecting to WebSocket for session:', sessionId);
 
     isConnectingRef.current = true;
     const ws = new WebSocket(wsUrl);
@@ -46,11 +52,37 @@ export const useWebSocket = (sessionId: string): WebSocketHook => {
       ws.send(JSON.stringify({ type: MESSAGE_TYPES.GET_WORKFLOW_STATUS }));
     };
 
+    // Detect an incompatible server from the handshake
+    ws.addEventListener('message', (event) => {
+      try {
+        const data = JSON.parse(event.data);
+        if (data.type === MESSAGE_TYPES.PROTOCOL_INCOMPATIBLE) {
+          setProtocolError('This app version is no longer supported by the server. Please reload.');
+        } else if (data.type === MESSAGE_TYPES.CONNECTED) {
+          const serverVersion = data.metadata?.server_protocol_version;
+          const minVersion = data.metadata?.min_protocol_version;
+          if (typeof minVersion === 'number' && minVersion > PROTOCOL_VERSION) {
+            setProtocolError(`Server requires protocol v${minVersion}, client speaks v${PROTOCOL_VERSION}. Please reload.`);
+          } else if (typeof serverVersion === 'number' && serverVersion !== PROTOCOL_VERSION) {
+            console.warn('WebSocket protocol version mismatch', { client: PROTOCOL_VERSION, server: serverVersion });
+          }
+        }
+      } catch {
+        // Non-JSON frames are handled by consumers
+      }
+    });
+
     ws.onclose = (event) => {
       console.log('WebSocket disconnected', { code: event.code, reason: event.reason });
       isConnectingRef.current = false;
       setIsConnected(false);
 
+      // Protocol errors won't resolve by reconnecting
+      if (event.code === 1002) {
+        setProtocolError(event.reason || 'Incompatible WebSocket protocol version');
+        return;
+      }
+
       // Auto-reconnect after 3 seconds if not intentional
       if (!event.wasClean && event.code !== 1000) {
         console.log('Scheduling reconnect in 3 seconds...');
@@ -101,6 +133,7 @@ export const useWebSocket = (sessionId: string): WebSocketHook => {
   return {
     ws: wsRef.current,
     isConnected,
+    protocolError,
     sendMessage,
     sendTypedMessage,
     reconnect: connect
@@ -132,8 +132,8 @@ export const useWorkflow = (ws: WebSocket | null, sessionId: string): WorkflowHo
             };
           });
           setIsLoading(false);
-        } else if (data.type === 'phase_transition') {
-          // Phase transitions use metadata for from_phase/to_phase (special event type)
+        } else if (data.type === 'phase_transition' || data.type === 'phase_rollback') {
+          // Phase transitions and rollbacks use metadata for from_phase/to_phase (special event type)
           const phaseDataValues = data.phase_data_values;
           const fromPhase = data.metadata?.from_phase;
           const toPhase = data.metadata?.to_phase || data.phase;
@@ -1,16 +1,20 @@
 // AUTO-GENERATED: Do not edit manually
 // Generated from Go structs in shared/websocket-types.go
 
+// WebSocket protocol version (send as protocol_version query parameter on connect)
+export const PROTOCOL_VERSION = 3;
+export const MIN_PROTOCOL_VERSION = 1;
+
 // Message Types
 export const MESSAGE_TYPES = {
-  // Inbound (frontend -> backend)
+  // Inbound message types (frontend -> backend)
   GET_WORKFLOW_STATUS: 'get_workflow_status',
   TOOL_CALL: 'tool_call',
   PAUSE_TIMER: 'pause_timer',
   RESUME_TIMER: 'resume_timer',
   STOP_TIMER: 'stop_timer',
-  
-  // Outbound (backend -> frontend)
+
+  // Outbound message types (backend -> frontend)
   WORKFLOW_UPDATE: 'workflow_update',
   THERAPY_SESSION_UPDATE: 'therapy_session_update',
   TIMER_UPDATE: 'timer_update',
@@ -20,6 +24,24 @@ export const MESSAGE_TYPES = {
   PHASE_TIMER_RESUMED: 'phase_timer_resumed',
   PHASE_TIMER_COMPLETED: 'phase_timer_completed',
   PHASE_TIMER_CHECKIN: 'phase_timer_checkin',
+  CONNECTED: 'connected',
+  PROTOCOL_INCOMPATIBLE: 'protocol_incompatible',
+  PHASE_PREVIEW: 'phase_preview',
+  COACH_TYPING: 'coach_typing',
+  PHASE_VISUALIZATION: 'phase_visualization',
+  SERVICE_UNAVAILABLE: 'service_unavailable',
+  ATTACHMENT: 'attachment',
+  REQUIREMENT_SATISFIED: 'requirement_satisfied',
+  PHASE_ROLLBACK: 'phase_rollback',
+  WORKFLOW_GRAPH_CHANGED: 'workflow_graph_changed',
+  MESSAGE_CHUNK: 'message_chunk',
+  MESSAGE_COMPLETE: 'message_complete',
+  MCP_ACTIVITY: 'mcp_activity',
+  CORRECT_FIELD: 'correct_field',
+  FIELD_CORRECTED: 'field_corrected',
+  TRANSITION_LOOP_DETECTED: 'transition_loop_detected',
+  TRANSITION_LOOP_REVIEWED: 'transition_loop_reviewed',
+  PARTICIPANTS_UPDATED: 'participants_updated',
 } as const;
 
 export enum TimerState {
@@ -55,6 +77,7 @@ export interface TherapySessionUpdate {
   recent_messages?: Message[];
   message?: Message | null;
   metadata?: Record<string, any>;
+  protocol_version?: number;
   timestamp: string;
 }
 
@@ -104,6 +127,7 @@ export interface Phase {
   color?: string;
   icon?: string;
   phase_data: PhaseDataField[];
+  visualization?: PhaseVisualization | null;
 }
 
 export interface PhaseDataField {
@@ -126,6 +150,7 @@ export interface Message {
   role: string;
   message_type: string;
   metadata: string;
+  participant_id?: string;
   created_at: string;
   updated_at: string;
 }
@@ -141,6 +166,11 @@ export interface ToolCallResponse {
   error?: string;
 }
 
+export interface PhaseVisualization {
+  type: string;
+  config?: Record<string, any>;
+}
+
 // Union type for all possible WebSocket message data
 export type WebSocketMessageData = 
   | TherapySessionUpdate
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -27,6 +28,8 @@ type FileMetadata struct {
 	Dependencies    []string  `json:"dependencies"`
 	LastModified    time.Time `json:"last_modified"`
 	BullshitLevel   string    `json:"bullshit_level"`
+	BullshitScore   float64            `json:"bullshit_score"`
+	BullshitFactors map[string]float64 `json:"bullshit_factors,omitempty"` // Contribution of each factor to the score
 	HasTodos        bool      `json:"has_todos"`
 	HasDeadCode     bool      `json:"has_dead_code"`
 	SecurityIssues  []string  `json:"security_concerns"`
@@ -50,6 +53,95 @@ type Stats struct {
 	Bytes int64 `json:"bytes"`
 }
 
+// SeverityConfig weights the factors behind a file's bullshit level and sets the score
+// thresholds for each level. Load a calibrated copy with -severity-config.
+type SeverityConfig struct {
+	Weights struct {
+		HasTodos         float64 `json:"has_todos"`
+		HasDeadCode      float64 `json:"has_dead_code"`
+		SecurityConcerns float64 `json:"security_concerns"` // Per concern
+		ShouldDelete     float64 `json:"should_delete"`
+		Issues           float64 `json:"issues"` // Per issue
+	} `json:"weights"`
+	Thresholds struct {
+		Medium   float64 `json:"medium"`
+		High     float64 `json:"high"`
+		Critical float64 `json:"critical"`
+	} `json:"thresholds"`
+}
+
+// defaultSeverityConfig weights security concerns well above TODOs
+func defaultSeverityConfig() SeverityConfig {
+	var cfg SeverityConfig
+	cfg.Weights.HasTodos = 1
+	cfg.Weights.HasDeadCode = 4
+	cfg.Weights.SecurityConcerns = 3
+	cfg.Weights.ShouldDelete = 10
+	cfg.Weights.Issues = 1
+	cfg.Thresholds.Medium = 1
+	cfg.Thresholds.High = 4
+	cfg.Thresholds.Critical = 10
+	return cfg
+}
+
+// loadSeverityConfig overlays the JSON file at path onto the default weights and thresholds
+func loadSeverityConfig(path string) (SeverityConfig, error) {
+	cfg := defaultSeverityConfig()
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if cfg.Thresholds.Medium > cfg.Thresholds.High || cfg.Thresholds.High > cfg.Thresholds.Critical {
+		return cfg, fmt.Errorf("thresholds must satisfy medium <= high <= critical")
+	}
+	return cfg, nil
+}
+
+// severity is the active severity configuration
+var severity = defaultSeverityConfig()
+
+// scoreBullshit returns the level, total score and per-factor contributions for an analysis
+func scoreBullshit(analysis *GeminiAnalysis, cfg SeverityConfig) (string, float64, map[string]float64) {
+	factors := make(map[string]float64)
+	if analysis.HasTodos {
+		factors["has_todos"] = cfg.Weights.HasTodos
+	}
+	if analysis.HasDeadCode {
+		factors["has_dead_code"] = cfg.Weights.HasDeadCode
+	}
+	if n := len(analysis.SecurityConcerns); n > 0 {
+		factors["security_concerns"] = float64(n) * cfg.Weights.SecurityConcerns
+	}
+	if analysis.ShouldDelete {
+		factors["should_delete"] = cfg.Weights.ShouldDelete
+	}
+	if n := len(analysis.Issues); n > 0 {
+		factors["issues"] = float64(n) * cfg.Weights.Issues
+	}
+
+	var score float64
+	for _, contribution := range factors {
+		score += contribution
+	}
+
+	switch {
+	case score >= cfg.Thresholds.Critical:
+		return "critical", score, factors
+	case score >= cfg.Thresholds.High:
+		return "high", score, factors
+	case score >= cfg.Thresholds.Medium && score > 0:
+		return "medium", score, factors
+	default:
+		return "low", score, factors
+	}
+}
+
 type GeminiAnalysis struct {
 	Purpose          string   `json:"purpose"`
 	Quality          int      `json:"quality"`
@@ -63,6 +155,27 @@ type GeminiAnalysis struct {
 }
 
 func main() {
+	severityPath := flag.String("severity-config", "", "JSON file with bullshit level weights and thresholds")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-severity-config file] [path]\n\nScans path (default \".\") for code files.\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+	severity, err = loadSeverityConfig(*severityPath)
+	if err != nil {
+		log.Fatalf("Invalid severity config: %v", err)
+	}
+
+	root := "."
+	if flag.NArg() > 0 {
+		root = flag.Arg(0)
+	}
+	if err := validateRoot(root); err != nil {
+		log.Fatalf("Invalid scan path: %v", err)
+	}
+
 	fmt.Println("🔍 Code Inventory Analyzer")
 	fmt.Println("==========================")
 
@@ -99,8 +212,8 @@ func main() {
 	}
 
 	// Find all code files using fd
-	fmt.Println("\n📁 Finding all code files...")
-	files, err := findCodeFiles()
+	fmt.Printf("\n📁 Finding all code files in %s...\n", root)
+	files, err := findCodeFiles(root)
 	if err != nil {
 		log.Fatalf("Failed to find files: %v", err)
 	}
@@ -156,13 +269,25 @@ func main() {
 	fmt.Println("\n✅ Complete! Open code-inventory.html in your browser")
 }
 
-func findCodeFiles() ([]string, error) {
+// validateRoot checks that the scan path exists and is a directory
+func validateRoot(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+	return nil
+}
+
+func findCodeFiles(root string) ([]string, error) {
 	cmd := exec.Command("fd",
 		"-t", "f",
 		"-e", "go", "-e", "ts", "-e", "tsx", "-e", "js", "-e", "jsx",
 		"-e", "css", "-e", "json", "-e", "md", "-e", "yaml", "-e", "yml",
 		"-E", "node_modules", "-E", ".git", "-E", "*.min.js", "-E", "dist",
-		".", ".")
+		".", root)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -227,15 +352,7 @@ func analyzeFile(path string, client *genai.Client, ctx context.Context) (FileMe
 			metadata.Issues = analysis.Issues
 
 			// Calculate bullshit level
-			if analysis.ShouldDelete {
-				metadata.BullshitLevel = "critical"
-			} else if analysis.HasDeadCode || len(analysis.Issues) > 3 {
-				metadata.BullshitLevel = "high"
-			} else if analysis.HasTodos || len(analysis.Issues) > 0 {
-				metadata.BullshitLevel = "medium"
-			} else {
-				metadata.BullshitLevel = "low"
-			}
+			metadata.BullshitLevel, metadata.BullshitScore, metadata.BullshitFactors = scoreBullshit(analysis, severity)
 		}
 	}
 
@@ -562,6 +679,8 @@ func generateVisualization(inventory *CodeInventory) error {
                         path: file.path,
                         value: file.lines,
                         bullshit: file.bullshit_level,
+                        score: file.bullshit_score,
+                        factors: file.bullshit_factors || {},
                         purpose: file.purpose,
                         quality: file.quality_score,
                         issues: file.issues || [],
@@ -631,6 +750,10 @@ func generateVisualization(inventory *CodeInventory) error {
                 let securityHtml = d.data.security.length > 0
                     ? '<br>🔒 Security: ' + d.data.security.join(', ')
                     : '';
+                let factorsHtml = Object.keys(d.data.factors).length > 0
+                    ? '<br>Score: ' + d.data.score + ' (' + Object.entries(d.data.factors)
+                        .map(([factor, value]) => factor + ' +' + value).join(', ') + ')'
+                    : '';
                 let deleteHtml = d.data.shouldDelete
                     ? '<br>⚠️ <b>Consider deleting this file</b>'
                     : '';
@@ -641,7 +764,7 @@ func generateVisualization(inventory *CodeInventory) error {
                     'Lines: ' + d.data.value + '<br>' +
                     'Quality: ' + d.data.quality + '/10<br>' +
                     'Refactor Priority: ' + d.data.refactor +
-                    issuesHtml + securityHtml + deleteHtml
+                    factorsHtml + issuesHtml + securityHtml + deleteHtml
                 )
                 .style('left', (event.pageX + 10) + 'px')
                 .style('top', (event.pageY - 28) + 'px');